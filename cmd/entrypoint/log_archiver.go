@@ -0,0 +1,82 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/entrypoint"
+	"github.com/tektoncd/pipeline/pkg/logarchive"
+)
+
+// logArchiveTimeout is the time allowed to archive the logs of a step.
+const logArchiveTimeout = time.Minute
+
+// realLogArchiver copies the output of the step to a temporary file, which
+// is archived to the sink once the step completes.
+type realLogArchiver struct {
+	location string
+	sink     logarchive.Sink
+
+	mu   sync.Mutex
+	file *os.File
+}
+
+var _ entrypoint.LogArchiver = (*realLogArchiver)(nil)
+
+// newLogArchiver returns a log archiver uploading the output of the step to
+// the given upload URL, pre-signed by the controller for the log of this step
+// only, and reporting the given location. The output is copied to a
+// temporary file of dir, a volume owned by Tekton, since the root filesystem
+// of the step may be read-only.
+func newLogArchiver(location, uploadURL, dir string) (*realLogArchiver, error) {
+	sink, err := logarchive.NewSink(uploadURL)
+	if err != nil {
+		return nil, err
+	}
+	f, err := ioutil.TempFile(dir, "step-log-")
+	if err != nil {
+		return nil, err
+	}
+	return &realLogArchiver{location: location, sink: sink, file: f}, nil
+}
+
+// Write copies the output of the step, it is safe to call concurrently for
+// stdout and stderr.
+func (a *realLogArchiver) Write(p []byte) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.file.Write(p)
+}
+
+func (a *realLogArchiver) Archive() (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	defer os.Remove(a.file.Name())
+	defer a.file.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), logArchiveTimeout)
+	defer cancel()
+	if err := a.sink.Archive(ctx, a.file); err != nil {
+		return "", err
+	}
+	return a.location, nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestRealLogArchiver tests whether both output streams of the command are archived to the
+// upload URL once the step completes, and the temporary copy is kept in the given directory.
+func TestRealLogArchiver(t *testing.T) {
+	var archived, query string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		archived, query = string(body), r.URL.RawQuery
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "tools")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	location := server.URL + "/ns/taskrun/build.log"
+	a, err := newLogArchiver(location, location+"?expires=1&signature=abc", dir)
+	if err != nil {
+		t.Fatalf("unexpected error creating the log archiver: %v", err)
+	}
+	if filepath.Dir(a.file.Name()) != dir {
+		t.Errorf("expected the temporary log file to be in %s, got %s", dir, a.file.Name())
+	}
+
+	rr := realRunner{logWriter: a}
+	if err := rr.Run(context.Background(), "sh", "-c", "echo hello; echo world >&2"); err != nil {
+		t.Fatalf("unexpected error running command: %v", err)
+	}
+	got, err := a.Archive()
+	if err != nil {
+		t.Fatalf("unexpected error archiving the logs: %v", err)
+	}
+	if got != location {
+		t.Errorf("archived the logs to %q, want %q", got, location)
+	}
+	if !strings.Contains(archived, "hello\n") || !strings.Contains(archived, "world\n") {
+		t.Errorf("archived logs = %q, want both hello and world", archived)
+	}
+	if query != "expires=1&signature=abc" {
+		t.Errorf("expected the logs to be sent to the upload URL, got query %q", query)
+	}
+	if _, err := os.Stat(a.file.Name()); !os.IsNotExist(err) {
+		t.Errorf("expected the temporary log file to be removed, got %v", err)
+	}
+}
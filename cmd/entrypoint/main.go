@@ -18,10 +18,13 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"time"
@@ -33,15 +36,27 @@ import (
 	"github.com/tektoncd/pipeline/pkg/termination"
 )
 
+// envSourcePrefix is the prefix of the sources of the cp mode which are environment variables.
+const envSourcePrefix = "env:"
+
 var (
 	ep                  = flag.String("entrypoint", "", "Original specified entrypoint to execute")
 	waitFiles           = flag.String("wait_file", "", "Comma-separated list of paths to wait for")
 	waitFileContent     = flag.Bool("wait_file_content", false, "If specified, expect wait_file to have content")
+	onFailureFile       = flag.String("on_failure_file", "", "If specified, only run once the .err file of this post file is present, and skip once it is present itself")
+	sidecarReadyFiles   = flag.String("sidecar_ready_files", "", "Comma-separated list of paths which have content once the sidecars needed by the step are ready")
 	postFile            = flag.String("post_file", "", "If specified, file to write upon completion")
 	terminationPath     = flag.String("termination_path", "/tekton/termination", "If specified, file to write upon termination")
 	results             = flag.String("results", "", "If specified, list of file names that might contain task results")
 	waitPollingInterval = time.Second
 	timeout             = flag.Duration("timeout", time.Duration(0), "If specified, sets timeout for step")
+	onError             = flag.String("on_error", "", "Set to \"continue\" to ignore an error and continue when a container terminates with a non-zero exit code."+
+		" Set to \"stopAndFail\" to declare a failure with a step error and stop executing the rest of the steps.")
+	stdoutPath          = flag.String("stdout_path", "", "If specified, file to copy stdout to")
+	stderrPath          = flag.String("stderr_path", "", "If specified, file to copy stderr to")
+	breakpointOnFailure = flag.Bool("breakpoint_on_failure", false, "If specified, wait at a breakpoint instead of exiting when the step fails")
+	logArchiveLocation  = flag.String("log_archive_location", "", "If specified, location the output of the step is archived to once it completes")
+	logArchiveUploadURL = flag.String("log_archive_upload_url", "", "URL the output of the step is uploaded to, to archive it to log_archive_location")
 )
 
 func cp(src, dst string) error {
@@ -63,6 +78,17 @@ func cp(src, dst string) error {
 	return err
 }
 
+// cpEnv writes the content of the environment variable env to the dst path.
+// Anybody has permission to read and execute the file, so that it can be run
+// as a script by any user.
+func cpEnv(env, dst string) error {
+	content, ok := os.LookupEnv(env)
+	if !ok {
+		return fmt.Errorf("environment variable %s is not set", env)
+	}
+	return ioutil.WriteFile(dst, []byte(content), 0755)
+}
+
 func main() {
 	// Add credential flags originally used in creds-init.
 	gitcreds.AddFlags(flag.CommandLine)
@@ -70,19 +96,46 @@ func main() {
 
 	flag.Parse()
 
-	// If invoked in "cp mode" (`entrypoint cp <src> <dst>`), simply copy
-	// the src path to the dst path. This is used to place the entrypoint
-	// binary in the tools directory, without requiring the cp command to
-	// exist in the base image.
-	if len(flag.Args()) == 3 && flag.Args()[0] == "cp" {
-		src, dst := flag.Args()[1], flag.Args()[2]
-		if err := cp(src, dst); err != nil {
-			log.Fatal(err)
+	// If invoked in "cp mode" (`entrypoint cp <src> <dst> [<src> <dst>...]`),
+	// simply copy each src path to its dst path. This is used to place the
+	// entrypoint binary in the tools directory, without requiring the cp
+	// command to exist in the base image. A src of the form `env:<name>`
+	// copies the content of the environment variable instead, which is used
+	// to place the scripts of the steps without requiring a shell.
+	if len(flag.Args()) >= 3 && len(flag.Args())%2 == 1 && flag.Args()[0] == "cp" {
+		for i := 1; i < len(flag.Args()); i += 2 {
+			src, dst := flag.Args()[i], flag.Args()[i+1]
+			var err error
+			if strings.HasPrefix(src, envSourcePrefix) {
+				err = cpEnv(strings.TrimPrefix(src, envSourcePrefix), dst)
+			} else {
+				err = cp(src, dst)
+			}
+			if err != nil {
+				log.Fatal(err)
+			}
+			log.Println("Copied", src, "to", dst)
+		}
+		return
+	}
+
+	// If invoked in "breakpoint-probe mode" (`entrypoint breakpoint-probe <post_file>`),
+	// exit successfully only if the step writing the post file is waiting at a
+	// breakpoint. This is used as the readiness probe of the steps in debug mode,
+	// without requiring any command to exist in the base image.
+	if len(flag.Args()) == 2 && flag.Args()[0] == "breakpoint-probe" {
+		if !entrypoint.IsAtBreakpoint(flag.Args()[1]) {
+			os.Exit(1)
 		}
-		log.Println("Copied", src, "to", dst)
 		return
 	}
 
+	switch *onError {
+	case "", entrypoint.ContinueOnError, entrypoint.FailOnError:
+	default:
+		log.Fatalf("invalid value for -on_error %q, must be one of %q or %q", *onError, entrypoint.ContinueOnError, entrypoint.FailOnError)
+	}
+
 	// Copy creds-init credentials from secret volume mounts to /tekton/creds
 	// This is done to support the expansion of a variable, $(credentials.path), that
 	// resolves to a single place with all the stored credentials.
@@ -93,18 +146,36 @@ func main() {
 		}
 	}
 
+	runner := &realRunner{stdoutPath: *stdoutPath, stderrPath: *stderrPath}
+	var logArchiver entrypoint.LogArchiver
+	if *logArchiveLocation != "" {
+		// Failing to set up the archiving of the logs does not fail the step
+		// The output is copied next to the post file, in the tools volume.
+		if a, err := newLogArchiver(*logArchiveLocation, *logArchiveUploadURL, filepath.Dir(*postFile)); err != nil {
+			log.Printf("Error setting up the archiving of the logs: %v", err)
+		} else {
+			runner.logWriter = a
+			logArchiver = a
+		}
+	}
+
 	e := entrypoint.Entrypointer{
-		Entrypoint:      *ep,
-		WaitFiles:       strings.Split(*waitFiles, ","),
-		WaitFileContent: *waitFileContent,
-		PostFile:        *postFile,
-		TerminationPath: *terminationPath,
-		Args:            flag.Args(),
-		Waiter:          &realWaiter{},
-		Runner:          &realRunner{},
-		PostWriter:      &realPostWriter{},
-		Results:         strings.Split(*results, ","),
-		Timeout:         timeout,
+		Entrypoint:          *ep,
+		WaitFiles:           strings.Split(*waitFiles, ","),
+		WaitFileContent:     *waitFileContent,
+		SidecarReadyFiles:   strings.Split(*sidecarReadyFiles, ","),
+		OnFailureFile:       *onFailureFile,
+		PostFile:            *postFile,
+		TerminationPath:     *terminationPath,
+		Args:                flag.Args(),
+		Waiter:              &realWaiter{},
+		Runner:              runner,
+		PostWriter:          &realPostWriter{},
+		LogArchiver:         logArchiver,
+		Results:             strings.Split(*results, ","),
+		Timeout:             timeout,
+		OnError:             *onError,
+		BreakpointOnFailure: *breakpointOnFailure,
 	}
 
 	// Copy any creds injected by the controller into the $HOME directory of the current
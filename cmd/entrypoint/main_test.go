@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCpEnv(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "cp-env")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	script := "#!/bin/sh\nset -xe\necho hello\n"
+	os.Setenv("TEKTON_TEST_SCRIPT", script)
+	defer os.Unsetenv("TEKTON_TEST_SCRIPT")
+
+	dst := filepath.Join(tmp, "script-0")
+	if err := cpEnv("TEKTON_TEST_SCRIPT", dst); err != nil {
+		t.Fatalf("cpEnv: %v", err)
+	}
+	got, err := ioutil.ReadFile(dst)
+	if err != nil {
+		t.Fatalf("Error reading %s: %v", dst, err)
+	}
+	if string(got) != script {
+		t.Errorf("Expected %s to contain %q, got %q", dst, script, string(got))
+	}
+	info, err := os.Stat(dst)
+	if err != nil {
+		t.Fatalf("Error getting info of %s: %v", dst, err)
+	}
+	if info.Mode().Perm()&0111 != 0111 {
+		t.Errorf("Expected %s to be executable by anybody, got mode %s", dst, info.Mode())
+	}
+}
+
+func TestCpEnv_NotSet(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "cp-env")
+	if err != nil {
+		t.Fatalf("Error creating temp dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	if err := cpEnv("TEKTON_TEST_SCRIPT_NOT_SET", filepath.Join(tmp, "script-0")); err == nil {
+		t.Error("Expected an error copying an environment variable which is not set")
+	}
+}
@@ -2,9 +2,11 @@ package main
 
 import (
 	"context"
+	"io"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
 	"syscall"
 
 	"github.com/tektoncd/pipeline/pkg/entrypoint"
@@ -16,6 +18,13 @@ import (
 // realRunner actually runs commands.
 type realRunner struct {
 	signals chan os.Signal
+	// stdoutPath and stderrPath are the optional paths of the files
+	// the output streams of the command are copied to.
+	stdoutPath string
+	stderrPath string
+	// logWriter is the optional writer both output streams of the command
+	// are copied to, to archive them.
+	logWriter io.Writer
 }
 
 var _ entrypoint.Runner = (*realRunner)(nil)
@@ -35,8 +44,22 @@ func (rr *realRunner) Run(ctx context.Context, args ...string) error {
 	defer signal.Reset()
 
 	cmd := exec.CommandContext(ctx, name, args...)
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
+	stdout, closeStdout, err := newTeeWriter(os.Stdout, rr.stdoutPath)
+	if err != nil {
+		return err
+	}
+	defer closeStdout()
+	stderr, closeStderr, err := newTeeWriter(os.Stderr, rr.stderrPath)
+	if err != nil {
+		return err
+	}
+	defer closeStderr()
+	if rr.logWriter != nil {
+		stdout = io.MultiWriter(stdout, rr.logWriter)
+		stderr = io.MultiWriter(stderr, rr.logWriter)
+	}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
 	// dedicated PID group used to forward signals to
 	// main process and all children
 	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
@@ -69,3 +92,19 @@ func (rr *realRunner) Run(ctx context.Context, args ...string) error {
 
 	return nil
 }
+
+// newTeeWriter returns a writer copying everything written to it to both the
+// given stream and the file at path, if any, and a function closing that file.
+func newTeeWriter(stream *os.File, path string) (io.Writer, func(), error) {
+	if path == "" {
+		return stream, func() {}, nil
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, nil, err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return io.MultiWriter(stream, f), func() { f.Close() }, nil
+}
@@ -2,7 +2,9 @@ package main
 
 import (
 	"context"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"syscall"
 	"testing"
 	"time"
@@ -37,3 +39,30 @@ func TestRealRunnerTimeout(t *testing.T) {
 		t.Fatalf("step didn't timeout")
 	}
 }
+
+// TestRealRunnerStdoutAndStderrPaths tests whether the output streams of the command are copied
+// to the files at the specified paths, creating their parent directories.
+func TestRealRunnerStdoutAndStderrPaths(t *testing.T) {
+	tmp, err := ioutil.TempDir("", "")
+	if err != nil {
+		t.Fatalf("unexpected error creating temporary dir: %v", err)
+	}
+	defer os.RemoveAll(tmp)
+
+	rr := realRunner{
+		stdoutPath: filepath.Join(tmp, "out", "stdout"),
+		stderrPath: filepath.Join(tmp, "err", "stderr"),
+	}
+	if err := rr.Run(context.Background(), "sh", "-c", "echo hello; echo world >&2"); err != nil {
+		t.Fatalf("unexpected error running command: %v", err)
+	}
+	for path, want := range map[string]string{rr.stdoutPath: "hello\n", rr.stderrPath: "world\n"} {
+		got, err := ioutil.ReadFile(path)
+		if err != nil {
+			t.Fatalf("unexpected error reading %s: %v", path, err)
+		}
+		if string(got) != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+}
@@ -4,7 +4,9 @@ go 1.13
 
 require (
 	cloud.google.com/go/storage v1.11.0 // indirect
+	contrib.go.opencensus.io/exporter/ocagent v0.7.1-0.20200907061046-05415f1de66d
 	github.com/GoogleCloudPlatform/cloud-builders/gcs-fetcher v0.0.0-20191203181535-308b93ad1f39
+	github.com/aws/aws-sdk-go v1.31.12
 	github.com/cloudevents/sdk-go/v2 v2.1.0
 	github.com/ghodss/yaml v1.0.0
 	github.com/google/go-cmp v0.5.2
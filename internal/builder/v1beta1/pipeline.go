@@ -111,6 +111,18 @@ func PipelineRunCancelled(spec *v1beta1.PipelineRunSpec) {
 	spec.Status = v1beta1.PipelineRunSpecStatusCancelled
 }
 
+// PipelineRunPaused sets the status to paused to the PipelineRunSpec.
+func PipelineRunPaused(spec *v1beta1.PipelineRunSpec) {
+	spec.Status = v1beta1.PipelineRunSpecStatusPaused
+}
+
+// PipelineRunRerunOf sets the name of the PipelineRun the PipelineRunSpec is a rerun of.
+func PipelineRunRerunOf(name string) PipelineRunSpecOp {
+	return func(spec *v1beta1.PipelineRunSpec) {
+		spec.RerunOf = name
+	}
+}
+
 // PipelineDeclaredResource adds a resource declaration to the Pipeline Spec,
 // with the specified name and type.
 func PipelineDeclaredResource(name string, t v1beta1.PipelineResourceType) PipelineSpecOp {
@@ -557,6 +569,13 @@ func PipelineRunStartTime(startTime time.Time) PipelineRunStatusOp {
 	}
 }
 
+// PipelineRunPausedTime sets the paused time to the PipelineRunStatus.
+func PipelineRunPausedTime(pausedTime time.Time) PipelineRunStatusOp {
+	return func(s *v1beta1.PipelineRunStatus) {
+		s.PausedTime = &metav1.Time{Time: pausedTime}
+	}
+}
+
 // PipelineRunCompletionTime sets the completion time  to the PipelineRunStatus.
 func PipelineRunCompletionTime(t time.Time) PipelineRunStatusOp {
 	return func(s *v1beta1.PipelineRunStatus) {
@@ -723,6 +723,26 @@ func TaskRunPodTemplate(podTemplate *v1beta1.PodTemplate) TaskRunSpecOp {
 	}
 }
 
+// TaskRunStepOverride adds a StepOverride to the TaskRunSpec.
+func TaskRunStepOverride(name string, resources corev1.ResourceRequirements) TaskRunSpecOp {
+	return func(spec *v1beta1.TaskRunSpec) {
+		spec.StepOverrides = append(spec.StepOverrides, v1beta1.TaskRunStepOverride{
+			Name:      name,
+			Resources: resources,
+		})
+	}
+}
+
+// TaskRunSidecarOverride adds a SidecarOverride setting env vars to the TaskRunSpec.
+func TaskRunSidecarOverride(name string, env ...corev1.EnvVar) TaskRunSpecOp {
+	return func(spec *v1beta1.TaskRunSpec) {
+		spec.SidecarOverrides = append(spec.SidecarOverrides, v1beta1.TaskRunSidecarOverride{
+			Name: name,
+			Env:  env,
+		})
+	}
+}
+
 // TaskRunWorkspaceEmptyDir adds a workspace binding to an empty dir volume source.
 func TaskRunWorkspaceEmptyDir(name, subPath string) TaskRunSpecOp {
 	return func(spec *v1beta1.TaskRunSpec) {
@@ -19,7 +19,9 @@ package config
 import (
 	"fmt"
 	"os"
+	"reflect"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ghodss/yaml"
@@ -28,17 +30,22 @@ import (
 )
 
 const (
-	DefaultTimeoutMinutes          = 60
-	NoTimeoutDuration              = 0 * time.Minute
-	defaultTimeoutMinutesKey       = "default-timeout-minutes"
-	defaultServiceAccountKey       = "default-service-account"
-	DefaultServiceAccountValue     = "default"
-	defaultManagedByLabelValueKey  = "default-managed-by-label-value"
-	DefaultManagedByLabelValue     = "tekton-pipelines"
-	defaultPodTemplateKey          = "default-pod-template"
-	defaultCloudEventsSinkKey      = "default-cloud-events-sink"
-	DefaultCloudEventSinkValue     = ""
-	defaultTaskRunWorkspaceBinding = "default-task-run-workspace-binding"
+	DefaultTimeoutMinutes                 = 60
+	NoTimeoutDuration                     = 0 * time.Minute
+	defaultTimeoutMinutesKey              = "default-timeout-minutes"
+	defaultServiceAccountKey              = "default-service-account"
+	DefaultServiceAccountValue            = "default"
+	defaultManagedByLabelValueKey         = "default-managed-by-label-value"
+	DefaultManagedByLabelValue            = "tekton-pipelines"
+	defaultPodTemplateKey                 = "default-pod-template"
+	defaultCloudEventsSinkKey             = "default-cloud-events-sink"
+	DefaultCloudEventSinkValue            = ""
+	defaultTaskRunWorkspaceBinding        = "default-task-run-workspace-binding"
+	DefaultResourceQuotaMaxWaitMinutes    = 0
+	defaultResourceQuotaMaxWaitMinutesKey = "default-resource-quota-max-wait-minutes"
+	DefaultCloudEventsMaxRetries          = 10
+	defaultCloudEventsMaxRetriesKey       = "default-cloud-events-max-retries"
+	defaultCloudEventsAllowedSinksKey     = "default-cloud-events-allowed-sinks"
 )
 
 // Defaults holds the default configurations
@@ -50,6 +57,16 @@ type Defaults struct {
 	DefaultPodTemplate             *pod.Template
 	DefaultCloudEventsSink         string
 	DefaultTaskRunWorkspaceBinding string
+	// DefaultResourceQuotaMaxWaitMinutes is the maximum number of minutes a
+	// TaskRun stays queued waiting for a ResourceQuota to admit its pod.
+	// 0 means there is no maximum other than the TaskRun timeout.
+	DefaultResourceQuotaMaxWaitMinutes int
+	// DefaultCloudEventsMaxRetries is the number of times the delivery of a
+	// cloud event to the default sink is retried after the first attempt.
+	DefaultCloudEventsMaxRetries int
+	// DefaultCloudEventsAllowedSinks are the sinks that a TaskRun or a
+	// PipelineRun can send its cloud events to with an annotation.
+	DefaultCloudEventsAllowedSinks []string
 }
 
 // GetDefaultsConfigName returns the name of the configmap containing all
@@ -76,16 +93,21 @@ func (cfg *Defaults) Equals(other *Defaults) bool {
 		other.DefaultManagedByLabelValue == cfg.DefaultManagedByLabelValue &&
 		other.DefaultPodTemplate.Equals(cfg.DefaultPodTemplate) &&
 		other.DefaultCloudEventsSink == cfg.DefaultCloudEventsSink &&
-		other.DefaultTaskRunWorkspaceBinding == cfg.DefaultTaskRunWorkspaceBinding
+		other.DefaultTaskRunWorkspaceBinding == cfg.DefaultTaskRunWorkspaceBinding &&
+		other.DefaultResourceQuotaMaxWaitMinutes == cfg.DefaultResourceQuotaMaxWaitMinutes &&
+		other.DefaultCloudEventsMaxRetries == cfg.DefaultCloudEventsMaxRetries &&
+		reflect.DeepEqual(other.DefaultCloudEventsAllowedSinks, cfg.DefaultCloudEventsAllowedSinks)
 }
 
 // NewDefaultsFromMap returns a Config given a map corresponding to a ConfigMap
 func NewDefaultsFromMap(cfgMap map[string]string) (*Defaults, error) {
 	tc := Defaults{
-		DefaultTimeoutMinutes:      DefaultTimeoutMinutes,
-		DefaultServiceAccount:      DefaultServiceAccountValue,
-		DefaultManagedByLabelValue: DefaultManagedByLabelValue,
-		DefaultCloudEventsSink:     DefaultCloudEventSinkValue,
+		DefaultTimeoutMinutes:              DefaultTimeoutMinutes,
+		DefaultServiceAccount:              DefaultServiceAccountValue,
+		DefaultManagedByLabelValue:         DefaultManagedByLabelValue,
+		DefaultCloudEventsSink:             DefaultCloudEventSinkValue,
+		DefaultResourceQuotaMaxWaitMinutes: DefaultResourceQuotaMaxWaitMinutes,
+		DefaultCloudEventsMaxRetries:       DefaultCloudEventsMaxRetries,
 	}
 
 	if defaultTimeoutMin, ok := cfgMap[defaultTimeoutMinutesKey]; ok {
@@ -119,6 +141,33 @@ func NewDefaultsFromMap(cfgMap map[string]string) (*Defaults, error) {
 	if bindingYAML, ok := cfgMap[defaultTaskRunWorkspaceBinding]; ok {
 		tc.DefaultTaskRunWorkspaceBinding = bindingYAML
 	}
+
+	if maxWaitMin, ok := cfgMap[defaultResourceQuotaMaxWaitMinutesKey]; ok {
+		maxWait, err := strconv.ParseInt(maxWaitMin, 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing defaults config %q", defaultResourceQuotaMaxWaitMinutesKey)
+		}
+		tc.DefaultResourceQuotaMaxWaitMinutes = int(maxWait)
+	}
+
+	if maxRetries, ok := cfgMap[defaultCloudEventsMaxRetriesKey]; ok {
+		retries, err := strconv.ParseInt(maxRetries, 10, 0)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing defaults config %q", defaultCloudEventsMaxRetriesKey)
+		}
+		if retries < 0 {
+			return nil, fmt.Errorf("%q must not be negative, got %d", defaultCloudEventsMaxRetriesKey, retries)
+		}
+		tc.DefaultCloudEventsMaxRetries = int(retries)
+	}
+
+	if allowedSinks, ok := cfgMap[defaultCloudEventsAllowedSinksKey]; ok {
+		for _, sink := range strings.Split(allowedSinks, ",") {
+			if sink = strings.TrimSpace(sink); sink != "" {
+				tc.DefaultCloudEventsAllowedSinks = append(tc.DefaultCloudEventsAllowedSinks, sink)
+			}
+		}
+	}
 	return &tc, nil
 }
 
@@ -36,9 +36,12 @@ func TestNewDefaultsFromConfigMap(t *testing.T) {
 	testCases := []testCase{
 		{
 			expectedConfig: &config.Defaults{
-				DefaultTimeoutMinutes:      50,
-				DefaultServiceAccount:      "tekton",
-				DefaultManagedByLabelValue: "something-else",
+				DefaultTimeoutMinutes:              50,
+				DefaultServiceAccount:              "tekton",
+				DefaultManagedByLabelValue:         "something-else",
+				DefaultResourceQuotaMaxWaitMinutes: 30,
+				DefaultCloudEventsMaxRetries:       5,
+				DefaultCloudEventsAllowedSinks:     []string{"http://team-a-receiver.team-a.svc.cluster.local", "https://events.example.com"},
 			},
 			fileName: config.GetDefaultsConfigName(),
 		},
@@ -52,6 +55,7 @@ func TestNewDefaultsFromConfigMap(t *testing.T) {
 						"label": "value",
 					},
 				},
+				DefaultCloudEventsMaxRetries: config.DefaultCloudEventsMaxRetries,
 			},
 			fileName: "config-defaults-with-pod-template",
 		},
@@ -79,9 +83,10 @@ func TestNewDefaultsFromConfigMap(t *testing.T) {
 func TestNewDefaultsFromEmptyConfigMap(t *testing.T) {
 	DefaultsConfigEmptyName := "config-defaults-empty"
 	expectedConfig := &config.Defaults{
-		DefaultTimeoutMinutes:      60,
-		DefaultManagedByLabelValue: "tekton-pipelines",
-		DefaultServiceAccount:      "default",
+		DefaultTimeoutMinutes:        60,
+		DefaultManagedByLabelValue:   "tekton-pipelines",
+		DefaultServiceAccount:        "default",
+		DefaultCloudEventsMaxRetries: config.DefaultCloudEventsMaxRetries,
 	}
 	verifyConfigFileWithExpectedConfig(t, DefaultsConfigEmptyName, expectedConfig)
 }
@@ -173,6 +178,16 @@ func TestEquals(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "different resource quota max wait",
+			left: &config.Defaults{
+				DefaultResourceQuotaMaxWaitMinutes: 10,
+			},
+			right: &config.Defaults{
+				DefaultResourceQuotaMaxWaitMinutes: 20,
+			},
+			expected: false,
+		},
 		{
 			name: "different default workspace",
 			left: &config.Defaults{
@@ -30,11 +30,15 @@ const (
 	disableAffinityAssistantKey             = "disable-affinity-assistant"
 	runningInEnvWithInjectedSidecarsKey     = "running-in-environment-with-injected-sidecars"
 	requireGitSSHSecretKnownHostsKey        = "require-git-ssh-secret-known-hosts" // nolint: gosec
+	startTimeoutAtPodCreationKey            = "start-timeout-at-pod-creation"
+	placeScriptsWithEntrypointKey           = "place-scripts-with-entrypoint"
 	DefaultDisableHomeEnvOverwrite          = false
 	DefaultDisableWorkingDirOverwrite       = false
 	DefaultDisableAffinityAssistant         = false
 	DefaultRunningInEnvWithInjectedSidecars = true
 	DefaultRequireGitSSHSecretKnownHosts    = false
+	DefaultStartTimeoutAtPodCreation        = false
+	DefaultPlaceScriptsWithEntrypoint       = false
 )
 
 // FeatureFlags holds the features configurations
@@ -45,6 +49,8 @@ type FeatureFlags struct {
 	DisableAffinityAssistant         bool
 	RunningInEnvWithInjectedSidecars bool
 	RequireGitSSHSecretKnownHosts    bool
+	StartTimeoutAtPodCreation        bool
+	PlaceScriptsWithEntrypoint       bool
 }
 
 // GetFeatureFlagsConfigName returns the name of the configmap containing all
@@ -87,6 +93,12 @@ func NewFeatureFlagsFromMap(cfgMap map[string]string) (*FeatureFlags, error) {
 	if err := setFeature(requireGitSSHSecretKnownHostsKey, DefaultRequireGitSSHSecretKnownHosts, &tc.RequireGitSSHSecretKnownHosts); err != nil {
 		return nil, err
 	}
+	if err := setFeature(startTimeoutAtPodCreationKey, DefaultStartTimeoutAtPodCreation, &tc.StartTimeoutAtPodCreation); err != nil {
+		return nil, err
+	}
+	if err := setFeature(placeScriptsWithEntrypointKey, DefaultPlaceScriptsWithEntrypoint, &tc.PlaceScriptsWithEntrypoint); err != nil {
+		return nil, err
+	}
 	return &tc, nil
 }
 
@@ -46,6 +46,8 @@ func TestNewFeatureFlagsFromConfigMap(t *testing.T) {
 				DisableAffinityAssistant:         true,
 				RunningInEnvWithInjectedSidecars: false,
 				RequireGitSSHSecretKnownHosts:    true,
+				StartTimeoutAtPodCreation:        true,
+				PlaceScriptsWithEntrypoint:       true,
 			},
 			fileName: "feature-flags-all-flags-set",
 		},
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// LogArchiveLocationKey is the name of the configmap entry that specifies
+	// where the logs of the steps are archived: an s3:// bucket, a pvc://
+	// PersistentVolumeClaim or an http(s):// endpoint. The logs are not
+	// archived when it is empty.
+	LogArchiveLocationKey = "location"
+
+	// LogArchiveSecretNameKey is the name of the configmap entry that specifies
+	// the name of the secret holding the credentials used to archive the logs.
+	// The secret must exist in the namespace of the controller.
+	LogArchiveSecretNameKey = "secret.name"
+)
+
+// LogArchive holds the configurations for the archiving of the logs of the steps
+// +k8s:deepcopy-gen=true
+type LogArchive struct {
+	Location   string
+	SecretName string
+}
+
+// GetLogArchiveConfigName returns the name of the configmap containing all
+// customizations for the archiving of the logs.
+func GetLogArchiveConfigName() string {
+	if e := os.Getenv("CONFIG_LOG_ARCHIVE_NAME"); e != "" {
+		return e
+	}
+	return "config-log-archive"
+}
+
+// Equals returns true if two Configs are identical
+func (cfg *LogArchive) Equals(other *LogArchive) bool {
+	if cfg == nil && other == nil {
+		return true
+	}
+
+	if cfg == nil || other == nil {
+		return false
+	}
+
+	return other.Location == cfg.Location &&
+		other.SecretName == cfg.SecretName
+}
+
+// NewLogArchiveFromMap returns a Config given a map corresponding to a ConfigMap
+func NewLogArchiveFromMap(cfgMap map[string]string) (*LogArchive, error) {
+	tc := LogArchive{}
+
+	if location, ok := cfgMap[LogArchiveLocationKey]; ok && location != "" {
+		u, err := url.Parse(location)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing log archive config %q: %v", LogArchiveLocationKey, err)
+		}
+		switch u.Scheme {
+		case "s3", "pvc", "http", "https":
+		default:
+			return nil, fmt.Errorf("unsupported log archive location %q, the scheme must be one of s3, pvc, http or https", location)
+		}
+		if u.Host == "" {
+			return nil, fmt.Errorf("log archive location %q has no bucket, claim or host", location)
+		}
+		tc.Location = location
+	}
+
+	if secretName, ok := cfgMap[LogArchiveSecretNameKey]; ok {
+		tc.SecretName = secretName
+	}
+
+	return &tc, nil
+}
+
+// NewLogArchiveFromConfigMap returns a Config for the given configmap
+func NewLogArchiveFromConfigMap(config *corev1.ConfigMap) (*LogArchive, error) {
+	return NewLogArchiveFromMap(config.Data)
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	test "github.com/tektoncd/pipeline/pkg/reconciler/testing"
+	"github.com/tektoncd/pipeline/test/diff"
+)
+
+func TestNewLogArchiveFromConfigMap(t *testing.T) {
+	type testCase struct {
+		expectedConfig *config.LogArchive
+		fileName       string
+	}
+
+	testCases := []testCase{
+		{
+			expectedConfig: &config.LogArchive{
+				Location:   "s3://tekton-logs/archive?endpoint=http://minio.minio:9000&region=us-east-1",
+				SecretName: "log-archive-credentials",
+			},
+			fileName: config.GetLogArchiveConfigName(),
+		},
+		{
+			expectedConfig: &config.LogArchive{},
+			fileName:       "config-log-archive-empty",
+		},
+	}
+
+	for _, tc := range testCases {
+		cm := test.ConfigMapFromTestFile(t, tc.fileName)
+		if la, err := config.NewLogArchiveFromConfigMap(cm); err == nil {
+			if d := cmp.Diff(tc.expectedConfig, la); d != "" {
+				t.Errorf("Diff:\n%s", diff.PrintWantGot(d))
+			}
+		} else {
+			t.Errorf("NewLogArchiveFromConfigMap(actual) = %v", err)
+		}
+	}
+}
+
+func TestNewLogArchiveFromMap(t *testing.T) {
+	for _, tc := range []struct {
+		location string
+		wantErr  bool
+	}{
+		{location: "pvc://logs-claim/archive"},
+		{location: "https://logs.example.com/upload"},
+		{location: "http://localhost:8080"},
+		{location: "s3://bucket"},
+		{location: "gs://bucket", wantErr: true},
+		{location: "pvc:///archive", wantErr: true},
+		{location: "logs.example.com/upload", wantErr: true},
+	} {
+		t.Run(tc.location, func(t *testing.T) {
+			la, err := config.NewLogArchiveFromMap(map[string]string{config.LogArchiveLocationKey: tc.location})
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error for location %q, got %v", tc.location, la)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if la.Location != tc.location {
+				t.Errorf("Expected location %q, got %q", tc.location, la.Location)
+			}
+		})
+	}
+}
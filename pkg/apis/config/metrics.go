@@ -0,0 +1,175 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// MetricsTaskRunLevelKey is the name of the configmap entry that specifies
+	// the level at which the TaskRun metrics are aggregated
+	MetricsTaskRunLevelKey = "metrics.taskrun.level"
+	// MetricsPipelineRunLevelKey is the name of the configmap entry that
+	// specifies the level at which the PipelineRun metrics are aggregated
+	MetricsPipelineRunLevelKey = "metrics.pipelinerun.level"
+	// MetricsRunLabelsKey is the name of the configmap entry that specifies
+	// the comma separated labels of the runs copied to their metrics
+	MetricsRunLabelsKey = "metrics.run-labels"
+
+	// TaskRunLevelAtTaskRun tags the TaskRun metrics with the Task and the TaskRun
+	TaskRunLevelAtTaskRun = "taskrun"
+	// TaskRunLevelAtTask tags the TaskRun metrics with the Task only
+	TaskRunLevelAtTask = "task"
+	// TaskRunLevelAtNS tags the TaskRun metrics with the namespace only
+	TaskRunLevelAtNS = "namespace"
+
+	// PipelineRunLevelAtPipelineRun tags the PipelineRun metrics with the
+	// Pipeline and the PipelineRun
+	PipelineRunLevelAtPipelineRun = "pipelinerun"
+	// PipelineRunLevelAtPipeline tags the PipelineRun metrics with the
+	// Pipeline only
+	PipelineRunLevelAtPipeline = "pipeline"
+	// PipelineRunLevelAtNS tags the PipelineRun metrics with the namespace only
+	PipelineRunLevelAtNS = "namespace"
+
+	// DefaultTaskRunLevel is the level of the TaskRun metrics when none is configured
+	DefaultTaskRunLevel = TaskRunLevelAtTaskRun
+	// DefaultPipelineRunLevel is the level of the PipelineRun metrics when none is configured
+	DefaultPipelineRunLevel = PipelineRunLevelAtPipelineRun
+)
+
+// reservedMetricsTags are the tags set by the controller, that cannot be
+// used for the labels of the runs.
+var reservedMetricsTags = map[string]bool{
+	"namespace":   true,
+	"pipeline":    true,
+	"pipelinerun": true,
+	"pod":         true,
+	"status":      true,
+	"step":        true,
+	"task":        true,
+	"taskrun":     true,
+}
+
+var invalidMetricsTagChars = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// Metrics holds the configurations of the metrics of the runs, read from the
+// config-observability ConfigMap
+// +k8s:deepcopy-gen=true
+type Metrics struct {
+	TaskRunLevel     string
+	PipelineRunLevel string
+	// RunLabels are the labels of the runs whose values are copied to the
+	// tags of their metrics.
+	RunLabels []string
+}
+
+// GetMetricsConfigName returns the name of the configmap containing all
+// customizations for the metrics.
+func GetMetricsConfigName() string {
+	if e := os.Getenv("CONFIG_OBSERVABILITY_NAME"); e != "" {
+		return e
+	}
+	return "config-observability"
+}
+
+// MetricsTagName returns the name of the metrics tag holding the value of the
+// given run label.
+func MetricsTagName(label string) string {
+	return invalidMetricsTagChars.ReplaceAllString(label, "_")
+}
+
+// Equals returns true if two Configs are identical
+func (cfg *Metrics) Equals(other *Metrics) bool {
+	if cfg == nil && other == nil {
+		return true
+	}
+
+	if cfg == nil || other == nil {
+		return false
+	}
+
+	if len(other.RunLabels) != len(cfg.RunLabels) {
+		return false
+	}
+	for i := range cfg.RunLabels {
+		if other.RunLabels[i] != cfg.RunLabels[i] {
+			return false
+		}
+	}
+
+	return other.TaskRunLevel == cfg.TaskRunLevel &&
+		other.PipelineRunLevel == cfg.PipelineRunLevel
+}
+
+// NewMetricsFromMap returns a Config given a map corresponding to a ConfigMap
+func NewMetricsFromMap(cfgMap map[string]string) (*Metrics, error) {
+	tc := Metrics{
+		TaskRunLevel:     DefaultTaskRunLevel,
+		PipelineRunLevel: DefaultPipelineRunLevel,
+	}
+
+	if level, ok := cfgMap[MetricsTaskRunLevelKey]; ok {
+		switch level {
+		case TaskRunLevelAtTaskRun, TaskRunLevelAtTask, TaskRunLevelAtNS:
+			tc.TaskRunLevel = level
+		default:
+			return nil, fmt.Errorf("invalid value for %q: %q, must be one of %q, %q or %q", MetricsTaskRunLevelKey, level, TaskRunLevelAtTaskRun, TaskRunLevelAtTask, TaskRunLevelAtNS)
+		}
+	}
+
+	if level, ok := cfgMap[MetricsPipelineRunLevelKey]; ok {
+		switch level {
+		case PipelineRunLevelAtPipelineRun, PipelineRunLevelAtPipeline, PipelineRunLevelAtNS:
+			tc.PipelineRunLevel = level
+		default:
+			return nil, fmt.Errorf("invalid value for %q: %q, must be one of %q, %q or %q", MetricsPipelineRunLevelKey, level, PipelineRunLevelAtPipelineRun, PipelineRunLevelAtPipeline, PipelineRunLevelAtNS)
+		}
+	}
+
+	if labels, ok := cfgMap[MetricsRunLabelsKey]; ok {
+		tags := map[string]bool{}
+		for _, label := range strings.Split(labels, ",") {
+			label = strings.TrimSpace(label)
+			if label == "" {
+				continue
+			}
+			tag := MetricsTagName(label)
+			if reservedMetricsTags[tag] {
+				return nil, fmt.Errorf("invalid value for %q: label %q conflicts with the %q metrics tag", MetricsRunLabelsKey, label, tag)
+			}
+			if tags[tag] {
+				return nil, fmt.Errorf("invalid value for %q: labels conflict on the %q metrics tag", MetricsRunLabelsKey, tag)
+			}
+			tags[tag] = true
+			tc.RunLabels = append(tc.RunLabels, label)
+		}
+	}
+
+	return &tc, nil
+}
+
+// NewMetricsFromConfigMap returns a Config for the given configmap
+func NewMetricsFromConfigMap(config *corev1.ConfigMap) (*Metrics, error) {
+	return NewMetricsFromMap(config.Data)
+}
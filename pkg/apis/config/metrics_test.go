@@ -0,0 +1,99 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	test "github.com/tektoncd/pipeline/pkg/reconciler/testing"
+	"github.com/tektoncd/pipeline/test/diff"
+)
+
+func TestNewMetricsFromConfigMap(t *testing.T) {
+	type testCase struct {
+		expectedConfig *config.Metrics
+		fileName       string
+	}
+
+	testCases := []testCase{
+		{
+			expectedConfig: &config.Metrics{
+				TaskRunLevel:     config.TaskRunLevelAtTask,
+				PipelineRunLevel: config.PipelineRunLevelAtNS,
+				RunLabels:        []string{"team", "example.com/repo"},
+			},
+			fileName: config.GetMetricsConfigName(),
+		},
+		{
+			expectedConfig: &config.Metrics{
+				TaskRunLevel:     config.DefaultTaskRunLevel,
+				PipelineRunLevel: config.DefaultPipelineRunLevel,
+			},
+			fileName: "config-observability-empty",
+		},
+	}
+
+	for _, tc := range testCases {
+		cm := test.ConfigMapFromTestFile(t, tc.fileName)
+		if m, err := config.NewMetricsFromConfigMap(cm); err == nil {
+			if d := cmp.Diff(tc.expectedConfig, m); d != "" {
+				t.Errorf("Diff:\n%s", diff.PrintWantGot(d))
+			}
+		} else {
+			t.Errorf("NewMetricsFromConfigMap(actual) = %v", err)
+		}
+	}
+}
+
+func TestNewMetricsFromMap_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		cfgMap map[string]string
+	}{{
+		name:   "invalid taskrun level",
+		cfgMap: map[string]string{config.MetricsTaskRunLevelKey: "pipeline"},
+	}, {
+		name:   "invalid pipelinerun level",
+		cfgMap: map[string]string{config.MetricsPipelineRunLevelKey: "task"},
+	}, {
+		name:   "label conflicting with a tag of the controller",
+		cfgMap: map[string]string{config.MetricsRunLabelsKey: "team,namespace"},
+	}, {
+		name:   "labels conflicting with each other",
+		cfgMap: map[string]string{config.MetricsRunLabelsKey: "example.com/team,example.com.team"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := config.NewMetricsFromMap(tc.cfgMap); err == nil {
+				t.Error("Expected an error parsing the metrics configuration, got nil")
+			}
+		})
+	}
+}
+
+func TestMetricsTagName(t *testing.T) {
+	for label, want := range map[string]string{
+		"team":                   "team",
+		"example.com/repo":       "example_com_repo",
+		"app.kubernetes.io/name": "app_kubernetes_io_name",
+	} {
+		if got := config.MetricsTagName(label); got != want {
+			t.Errorf("MetricsTagName(%q) = %q, want %q", label, got, want)
+		}
+	}
+}
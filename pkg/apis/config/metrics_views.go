@@ -0,0 +1,127 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"sync"
+
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/system"
+)
+
+// MetricsViews holds the views of the metrics of the runs, which are
+// registered again with other tags when the metrics configuration changes.
+// +k8s:deepcopy-gen=false
+type MetricsViews struct {
+	// build returns the views of the metrics for the configuration, also
+	// tagged with the given tags of the run labels.
+	build func(cfg *Metrics, labelTags []tag.Key) []*view.View
+
+	// mu guards the registered views, and the tags of the run labels they
+	// are aggregated by
+	mu        sync.Mutex
+	cfg       *Metrics
+	views     []*view.View
+	runLabels map[string]tag.Key
+}
+
+// NewMetricsViews returns the MetricsViews built by build, which are not
+// registered yet.
+func NewMetricsViews(build func(cfg *Metrics, labelTags []tag.Key) []*view.View) *MetricsViews {
+	return &MetricsViews{build: build}
+}
+
+// Register registers the views of the metrics, tagged at the level of the
+// given configuration, in place of the ones registered so far. The views are
+// kept if they were registered for the same configuration already.
+func (v *MetricsViews) Register(cfg *Metrics) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.cfg != nil && v.cfg.Equals(cfg) {
+		return nil
+	}
+
+	runLabels := make(map[string]tag.Key, len(cfg.RunLabels))
+	labelTags := make([]tag.Key, 0, len(cfg.RunLabels))
+	for _, label := range cfg.RunLabels {
+		key, err := tag.NewKey(MetricsTagName(label))
+		if err != nil {
+			return err
+		}
+		runLabels[label] = key
+		labelTags = append(labelTags, key)
+	}
+	views := v.build(cfg, labelTags)
+
+	// Views are identified by name, the ones registered with other tags
+	// have to be dropped first
+	view.Unregister(v.views...)
+	if err := view.Register(views...); err != nil {
+		return err
+	}
+	v.cfg = cfg
+	v.views = views
+	v.runLabels = runLabels
+	return nil
+}
+
+// RunLabelsContext returns a context tagged with the values of the labels of
+// the run configured to be copied to the metrics.
+func (v *MetricsViews) RunLabelsContext(labels map[string]string) context.Context {
+	v.mu.Lock()
+	mutators := make([]tag.Mutator, 0, len(v.runLabels))
+	for label, key := range v.runLabels {
+		if value, ok := labels[label]; ok {
+			mutators = append(mutators, tag.Insert(key, value))
+		}
+	}
+	v.mu.Unlock()
+
+	ctx, err := tag.New(context.Background(), mutators...)
+	if err != nil {
+		return context.Background()
+	}
+	return ctx
+}
+
+// WatchMetricsConfig calls update with the configuration of the metrics in
+// the config-observability ConfigMap whenever it changes.
+func WatchMetricsConfig(cmw configmap.Watcher, logger *zap.SugaredLogger, update func(cfg *Metrics) error) {
+	observer := func(cm *corev1.ConfigMap) {
+		cfg, err := NewMetricsFromConfigMap(cm)
+		if err != nil {
+			logger.Errorf("Failed to read the metrics configuration from %q: %v", cm.Name, err)
+			return
+		}
+		if err := update(cfg); err != nil {
+			logger.Errorf("Failed to update the metrics configuration: %v", err)
+		}
+	}
+	if dw, ok := cmw.(configmap.DefaultingWatcher); ok {
+		dw.WatchWithDefault(corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: GetMetricsConfigName(), Namespace: system.Namespace()},
+		}, observer)
+		return
+	}
+	cmw.Watch(GetMetricsConfigName(), observer)
+}
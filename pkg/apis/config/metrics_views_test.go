@@ -0,0 +1,74 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/test/diff"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var testMeasure = stats.Float64("metrics_views_test_count", "count of the metrics views test", stats.UnitDimensionless)
+
+func TestMetricsViews(t *testing.T) {
+	builds := 0
+	views := config.NewMetricsViews(func(cfg *config.Metrics, labelTags []tag.Key) []*view.View {
+		builds++
+		return []*view.View{{
+			Description: testMeasure.Description(),
+			Measure:     testMeasure,
+			Aggregation: view.Count(),
+			TagKeys:     labelTags,
+		}}
+	})
+
+	cfg := &config.Metrics{
+		TaskRunLevel:     config.DefaultTaskRunLevel,
+		PipelineRunLevel: config.DefaultPipelineRunLevel,
+	}
+	if err := views.Register(cfg); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	defer view.Unregister(view.Find(testMeasure.Name()))
+	if err := views.Register(cfg.DeepCopy()); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if builds != 1 {
+		t.Errorf("Expected the views to be kept for the same configuration, got built %d times", builds)
+	}
+
+	withLabels := cfg.DeepCopy()
+	withLabels.RunLabels = []string{"example.com/team"}
+	if err := views.Register(withLabels); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	v := view.Find(testMeasure.Name())
+	if v == nil || len(v.TagKeys) != 1 || v.TagKeys[0].Name() != "example_com_team" {
+		t.Fatalf("Expected the view to be registered again with the run label tag, got %v", v)
+	}
+
+	ctx := views.RunLabelsContext(map[string]string{"example.com/team": "team-1", "other": "value"})
+	got, _ := tag.FromContext(ctx).Value(v.TagKeys[0])
+	if d := cmp.Diff("team-1", got); d != "" {
+		t.Errorf("Unexpected run label tag %s", diff.PrintWantGot(d))
+	}
+}
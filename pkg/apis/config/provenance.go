@@ -0,0 +1,132 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/google/go-containerregistry/pkg/name"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ProvenanceStorageKey is the name of the configmap entry that specifies
+	// where the provenance of the TaskRuns is stored. No provenance is
+	// generated when it is empty.
+	ProvenanceStorageKey = "storage"
+
+	// ProvenanceOCIRepositoryKey is the name of the configmap entry that
+	// specifies the OCI repository the provenance is pushed to, when it is
+	// stored in an OCI registry.
+	ProvenanceOCIRepositoryKey = "oci.repository"
+
+	// ProvenanceOCISecretNameKey is the name of the configmap entry that
+	// specifies the name of the docker config secret holding the credentials
+	// used to push the provenance to the OCI repository. The secret must exist
+	// in the namespace of the controller.
+	ProvenanceOCISecretNameKey = "oci.secret.name"
+
+	// ProvenanceSigningSecretNameKey is the name of the configmap entry that
+	// specifies the name of the secret holding the private key used to sign
+	// the provenance. The secret must exist in the namespace of the controller,
+	// it is required when the provenance is stored.
+	ProvenanceSigningSecretNameKey = "signing-secret.name"
+
+	// ProvenanceStorageAnnotation stores the provenance in an annotation of
+	// the TaskRun.
+	ProvenanceStorageAnnotation = "annotation"
+
+	// ProvenanceStorageOCI pushes the provenance to an OCI registry.
+	ProvenanceStorageOCI = "oci"
+)
+
+// Provenance holds the configurations for the provenance of the TaskRuns
+// +k8s:deepcopy-gen=true
+type Provenance struct {
+	Storage           string
+	OCIRepository     string
+	OCISecretName     string
+	SigningSecretName string
+}
+
+// GetProvenanceConfigName returns the name of the configmap containing all
+// customizations for the provenance of the TaskRuns.
+func GetProvenanceConfigName() string {
+	if e := os.Getenv("CONFIG_PROVENANCE_NAME"); e != "" {
+		return e
+	}
+	return "config-provenance"
+}
+
+// Equals returns true if two Configs are identical
+func (cfg *Provenance) Equals(other *Provenance) bool {
+	if cfg == nil && other == nil {
+		return true
+	}
+
+	if cfg == nil || other == nil {
+		return false
+	}
+
+	return other.Storage == cfg.Storage &&
+		other.OCIRepository == cfg.OCIRepository &&
+		other.OCISecretName == cfg.OCISecretName &&
+		other.SigningSecretName == cfg.SigningSecretName
+}
+
+// NewProvenanceFromMap returns a Config given a map corresponding to a ConfigMap
+func NewProvenanceFromMap(cfgMap map[string]string) (*Provenance, error) {
+	tc := Provenance{}
+
+	if storage, ok := cfgMap[ProvenanceStorageKey]; ok {
+		switch storage {
+		case "", ProvenanceStorageAnnotation, ProvenanceStorageOCI:
+			tc.Storage = storage
+		default:
+			return nil, fmt.Errorf("unsupported provenance storage %q, it must be one of %s or %s", storage, ProvenanceStorageAnnotation, ProvenanceStorageOCI)
+		}
+	}
+
+	if repository, ok := cfgMap[ProvenanceOCIRepositoryKey]; ok && repository != "" {
+		if _, err := name.NewRepository(repository); err != nil {
+			return nil, fmt.Errorf("failed parsing provenance config %q: %v", ProvenanceOCIRepositoryKey, err)
+		}
+		tc.OCIRepository = repository
+	}
+	if tc.Storage == ProvenanceStorageOCI && tc.OCIRepository == "" {
+		return nil, fmt.Errorf("provenance config %q is required to store the provenance in an OCI registry", ProvenanceOCIRepositoryKey)
+	}
+
+	if secretName, ok := cfgMap[ProvenanceOCISecretNameKey]; ok {
+		tc.OCISecretName = secretName
+	}
+
+	if secretName, ok := cfgMap[ProvenanceSigningSecretNameKey]; ok {
+		tc.SigningSecretName = secretName
+	}
+	if tc.Storage != "" && tc.SigningSecretName == "" {
+		return nil, fmt.Errorf("provenance config %q is required to sign the provenance when it is stored", ProvenanceSigningSecretNameKey)
+	}
+
+	return &tc, nil
+}
+
+// NewProvenanceFromConfigMap returns a Config for the given configmap
+func NewProvenanceFromConfigMap(config *corev1.ConfigMap) (*Provenance, error) {
+	return NewProvenanceFromMap(config.Data)
+}
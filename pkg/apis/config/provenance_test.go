@@ -0,0 +1,123 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	test "github.com/tektoncd/pipeline/pkg/reconciler/testing"
+	"github.com/tektoncd/pipeline/test/diff"
+)
+
+func TestNewProvenanceFromConfigMap(t *testing.T) {
+	type testCase struct {
+		expectedConfig *config.Provenance
+		fileName       string
+	}
+
+	testCases := []testCase{
+		{
+			expectedConfig: &config.Provenance{
+				Storage:           config.ProvenanceStorageOCI,
+				OCIRepository:     "registry.example.com/tekton/provenance",
+				OCISecretName:     "provenance-push-credentials",
+				SigningSecretName: "provenance-signing-key",
+			},
+			fileName: config.GetProvenanceConfigName(),
+		},
+		{
+			expectedConfig: &config.Provenance{},
+			fileName:       "config-provenance-empty",
+		},
+	}
+
+	for _, tc := range testCases {
+		cm := test.ConfigMapFromTestFile(t, tc.fileName)
+		if p, err := config.NewProvenanceFromConfigMap(cm); err == nil {
+			if d := cmp.Diff(tc.expectedConfig, p); d != "" {
+				t.Errorf("Diff:\n%s", diff.PrintWantGot(d))
+			}
+		} else {
+			t.Errorf("NewProvenanceFromConfigMap(actual) = %v", err)
+		}
+	}
+}
+
+func TestNewProvenanceFromMap(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		data    map[string]string
+		wantErr bool
+	}{{
+		name: "annotation",
+		data: map[string]string{
+			config.ProvenanceStorageKey:           config.ProvenanceStorageAnnotation,
+			config.ProvenanceSigningSecretNameKey: "provenance-signing-key",
+		},
+	}, {
+		name: "oci",
+		data: map[string]string{
+			config.ProvenanceStorageKey:           config.ProvenanceStorageOCI,
+			config.ProvenanceOCIRepositoryKey:     "gcr.io/project/provenance",
+			config.ProvenanceSigningSecretNameKey: "provenance-signing-key",
+		},
+	}, {
+		name: "oci without repository",
+		data: map[string]string{
+			config.ProvenanceStorageKey:           config.ProvenanceStorageOCI,
+			config.ProvenanceSigningSecretNameKey: "provenance-signing-key",
+		},
+		wantErr: true,
+	}, {
+		name: "invalid repository",
+		data: map[string]string{
+			config.ProvenanceStorageKey:           config.ProvenanceStorageOCI,
+			config.ProvenanceOCIRepositoryKey:     "gcr.io/Project:provenance",
+			config.ProvenanceSigningSecretNameKey: "provenance-signing-key",
+		},
+		wantErr: true,
+	}, {
+		name:    "storage without signing secret",
+		data:    map[string]string{config.ProvenanceStorageKey: config.ProvenanceStorageAnnotation},
+		wantErr: true,
+	}, {
+		name: "signing secret without storage",
+		data: map[string]string{config.ProvenanceSigningSecretNameKey: "provenance-signing-key"},
+	}, {
+		name:    "unsupported storage",
+		data:    map[string]string{config.ProvenanceStorageKey: "gcs"},
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			p, err := config.NewProvenanceFromMap(tc.data)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("Expected an error for %v, got %v", tc.data, p)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if p.Storage != tc.data[config.ProvenanceStorageKey] {
+				t.Errorf("Expected storage %q, got %q", tc.data[config.ProvenanceStorageKey], p.Storage)
+			}
+		})
+	}
+}
@@ -31,6 +31,8 @@ type Config struct {
 	FeatureFlags   *FeatureFlags
 	ArtifactBucket *ArtifactBucket
 	ArtifactPVC    *ArtifactPVC
+	LogArchive     *LogArchive
+	Provenance     *Provenance
 }
 
 // FromContext extracts a Config from the provided context.
@@ -52,11 +54,15 @@ func FromContextOrDefaults(ctx context.Context) *Config {
 	featureFlags, _ := NewFeatureFlagsFromMap(map[string]string{})
 	artifactBucket, _ := NewArtifactBucketFromMap(map[string]string{})
 	artifactPVC, _ := NewArtifactPVCFromMap(map[string]string{})
+	logArchive, _ := NewLogArchiveFromMap(map[string]string{})
+	provenance, _ := NewProvenanceFromMap(map[string]string{})
 	return &Config{
 		Defaults:       defaults,
 		FeatureFlags:   featureFlags,
 		ArtifactBucket: artifactBucket,
 		ArtifactPVC:    artifactPVC,
+		LogArchive:     logArchive,
+		Provenance:     provenance,
 	}
 }
 
@@ -76,13 +82,15 @@ type Store struct {
 func NewStore(logger configmap.Logger, onAfterStore ...func(name string, value interface{})) *Store {
 	store := &Store{
 		UntypedStore: configmap.NewUntypedStore(
-			"defaults/features/artifacts",
+			"defaults/features/artifacts/logs/provenance",
 			logger,
 			configmap.Constructors{
 				GetDefaultsConfigName():       NewDefaultsFromConfigMap,
 				GetFeatureFlagsConfigName():   NewFeatureFlagsFromConfigMap,
 				GetArtifactBucketConfigName(): NewArtifactBucketFromConfigMap,
 				GetArtifactPVCConfigName():    NewArtifactPVCFromConfigMap,
+				GetLogArchiveConfigName():     NewLogArchiveFromConfigMap,
+				GetProvenanceConfigName():     NewProvenanceFromConfigMap,
 			},
 			onAfterStore...,
 		),
@@ -114,11 +122,21 @@ func (s *Store) Load() *Config {
 	if artifactPVC == nil {
 		artifactPVC, _ = NewArtifactPVCFromMap(map[string]string{})
 	}
+	logArchive := s.UntypedLoad(GetLogArchiveConfigName())
+	if logArchive == nil {
+		logArchive, _ = NewLogArchiveFromMap(map[string]string{})
+	}
+	provenance := s.UntypedLoad(GetProvenanceConfigName())
+	if provenance == nil {
+		provenance, _ = NewProvenanceFromMap(map[string]string{})
+	}
 
 	return &Config{
 		Defaults:       defaults.(*Defaults).DeepCopy(),
 		FeatureFlags:   featureFlags.(*FeatureFlags).DeepCopy(),
 		ArtifactBucket: artifactBucket.(*ArtifactBucket).DeepCopy(),
 		ArtifactPVC:    artifactPVC.(*ArtifactPVC).DeepCopy(),
+		LogArchive:     logArchive.(*LogArchive).DeepCopy(),
+		Provenance:     provenance.(*Provenance).DeepCopy(),
 	}
 }
@@ -32,17 +32,23 @@ func TestStoreLoadWithContext(t *testing.T) {
 	featuresConfig := test.ConfigMapFromTestFile(t, "feature-flags-all-flags-set")
 	artifactBucketConfig := test.ConfigMapFromTestFile(t, "config-artifact-bucket")
 	artifactPVCConfig := test.ConfigMapFromTestFile(t, "config-artifact-pvc")
+	logArchiveConfig := test.ConfigMapFromTestFile(t, "config-log-archive")
+	provenanceConfig := test.ConfigMapFromTestFile(t, "config-provenance")
 
 	expectedDefaults, _ := config.NewDefaultsFromConfigMap(defaultConfig)
 	expectedFeatures, _ := config.NewFeatureFlagsFromConfigMap(featuresConfig)
 	expectedArtifactBucket, _ := config.NewArtifactBucketFromConfigMap(artifactBucketConfig)
 	expectedArtifactPVC, _ := config.NewArtifactPVCFromConfigMap(artifactPVCConfig)
+	expectedLogArchive, _ := config.NewLogArchiveFromConfigMap(logArchiveConfig)
+	expectedProvenance, _ := config.NewProvenanceFromConfigMap(provenanceConfig)
 
 	expected := &config.Config{
 		Defaults:       expectedDefaults,
 		FeatureFlags:   expectedFeatures,
 		ArtifactBucket: expectedArtifactBucket,
 		ArtifactPVC:    expectedArtifactPVC,
+		LogArchive:     expectedLogArchive,
+		Provenance:     expectedProvenance,
 	}
 
 	store := config.NewStore(logtesting.TestLogger(t))
@@ -50,6 +56,8 @@ func TestStoreLoadWithContext(t *testing.T) {
 	store.OnConfigChanged(featuresConfig)
 	store.OnConfigChanged(artifactBucketConfig)
 	store.OnConfigChanged(artifactPVCConfig)
+	store.OnConfigChanged(logArchiveConfig)
+	store.OnConfigChanged(provenanceConfig)
 
 	cfg := config.FromContext(store.ToContext(context.Background()))
 
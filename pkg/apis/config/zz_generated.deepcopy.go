@@ -64,6 +64,11 @@ func (in *Defaults) DeepCopyInto(out *Defaults) {
 		*out = new(pod.Template)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.DefaultCloudEventsAllowedSinks != nil {
+		in, out := &in.DefaultCloudEventsAllowedSinks, &out.DefaultCloudEventsAllowedSinks
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -92,3 +97,56 @@ func (in *FeatureFlags) DeepCopy() *FeatureFlags {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *LogArchive) DeepCopyInto(out *LogArchive) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new LogArchive.
+func (in *LogArchive) DeepCopy() *LogArchive {
+	if in == nil {
+		return nil
+	}
+	out := new(LogArchive)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Metrics) DeepCopyInto(out *Metrics) {
+	*out = *in
+	if in.RunLabels != nil {
+		in, out := &in.RunLabels, &out.RunLabels
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Metrics.
+func (in *Metrics) DeepCopy() *Metrics {
+	if in == nil {
+		return nil
+	}
+	out := new(Metrics)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Provenance) DeepCopyInto(out *Provenance) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Provenance.
+func (in *Provenance) DeepCopy() *Provenance {
+	if in == nil {
+		return nil
+	}
+	out := new(Provenance)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -48,6 +48,8 @@ func (source *TaskSpec) ConvertTo(ctx context.Context, sink *v1beta1.TaskSpec) e
 	sink.Resources = source.Resources
 	sink.Params = source.Params
 	sink.Description = source.Description
+	sink.ScriptPreamble = source.ScriptPreamble
+	sink.OnFailure = source.OnFailure
 	if source.Inputs != nil {
 		if len(source.Inputs.Params) > 0 && len(source.Params) > 0 {
 			// This shouldn't happen as it shouldn't pass validation
@@ -122,5 +124,7 @@ func (sink *TaskSpec) ConvertFrom(ctx context.Context, source *v1beta1.TaskSpec)
 	sink.Params = source.Params
 	sink.Resources = source.Resources
 	sink.Description = source.Description
+	sink.ScriptPreamble = source.ScriptPreamble
+	sink.OnFailure = source.OnFailure
 	return nil
 }
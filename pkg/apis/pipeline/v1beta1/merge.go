@@ -18,6 +18,7 @@ package v1beta1
 
 import (
 	"encoding/json"
+	"fmt"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/strategicpatch"
@@ -45,6 +46,11 @@ func MergeStepsWithStepTemplate(template *v1.Container, steps []Step) ([]Step, e
 	}
 
 	for i, s := range steps {
+		// Steps referencing a StepAction are merged once the reference is resolved.
+		if s.Ref != nil {
+			continue
+		}
+
 		// Marshal the step's to JSON
 		stepAsJSON, err := json.Marshal(s.Container)
 		if err != nil {
@@ -85,7 +91,154 @@ func MergeStepsWithStepTemplate(template *v1.Container, steps []Step) ([]Step, e
 		}
 
 		// Pass through original step Script, for later conversion.
-		steps[i] = Step{Container: *merged, Script: s.Script}
+		steps[i] = Step{Container: *merged, Script: s.Script, ScriptLanguage: s.ScriptLanguage, OnError: s.OnError, StdoutConfig: s.StdoutConfig, StderrConfig: s.StderrConfig, Sidecars: s.Sidecars}
 	}
 	return steps, nil
 }
+
+// MergeStepsWithOverrides takes a list of steps and the overrides of a
+// TaskRun, merging the overrides into the steps of the same name, and
+// returning the resulting list. Steps should already be merged with the
+// step template.
+func MergeStepsWithOverrides(steps []Step, overrides []TaskRunStepOverride) ([]Step, error) {
+	if len(overrides) == 0 {
+		return steps, nil
+	}
+	patches := make(map[string]v1.Container, len(overrides))
+	for _, o := range overrides {
+		patches[o.Name] = v1.Container{Name: o.Name, Image: o.Image, Env: o.Env, SecurityContext: o.SecurityContext, Resources: o.Resources}
+	}
+	merged := make([]Step, len(steps))
+	containers := make([]*v1.Container, len(steps))
+	for i, s := range steps {
+		merged[i] = *s.DeepCopy()
+		containers[i] = &merged[i].Container
+	}
+	if err := mergeContainersWithOverrides(containers, patches); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// MergeSidecarsWithOverrides takes a list of sidecars and the overrides of a
+// TaskRun, merging the overrides into the sidecars of the same name, and
+// returning the resulting list.
+func MergeSidecarsWithOverrides(sidecars []Sidecar, overrides []TaskRunSidecarOverride) ([]Sidecar, error) {
+	if len(overrides) == 0 {
+		return sidecars, nil
+	}
+	patches := make(map[string]v1.Container, len(overrides))
+	for _, o := range overrides {
+		patches[o.Name] = v1.Container{Name: o.Name, Image: o.Image, Env: o.Env, SecurityContext: o.SecurityContext, Resources: o.Resources}
+	}
+	merged := make([]Sidecar, len(sidecars))
+	containers := make([]*v1.Container, len(sidecars))
+	for i, s := range sidecars {
+		merged[i] = *s.DeepCopy()
+		containers[i] = &merged[i].Container
+	}
+	if err := mergeContainersWithOverrides(containers, patches); err != nil {
+		return nil, err
+	}
+	return merged, nil
+}
+
+// mergeContainersWithOverrides merges the patches, keyed by container name,
+// into the containers of the same name, in place.
+func mergeContainersWithOverrides(containers []*v1.Container, patches map[string]v1.Container) error {
+	for _, c := range containers {
+		patch, ok := patches[c.Name]
+		if !ok {
+			continue
+		}
+		merged, err := mergeContainerWithOverride(*c, patch)
+		if err != nil {
+			return err
+		}
+		*c = merged
+	}
+	return nil
+}
+
+// mergeContainerWithOverride applies the override to the container as a
+// strategic merge patch: lists such as env are merged by name and the values
+// set in the override win.
+func mergeContainerWithOverride(container, override v1.Container) (v1.Container, error) {
+	containerAsJSON, err := json.Marshal(container)
+	if err != nil {
+		return v1.Container{}, err
+	}
+	overrideAsJSON, err := json.Marshal(override)
+	if err != nil {
+		return v1.Container{}, err
+	}
+	mergedAsJSON, err := strategicpatch.StrategicMergePatch(containerAsJSON, overrideAsJSON, v1.Container{})
+	if err != nil {
+		return v1.Container{}, err
+	}
+	merged := v1.Container{}
+	if err := json.Unmarshal(mergedAsJSON, &merged); err != nil {
+		return v1.Container{}, err
+	}
+	return merged, nil
+}
+
+// MergeStepWithStepAction resolves a Step referencing a StepAction. The image,
+// command, args, env and script of the StepAction are used for the Step, with
+// the StepAction params replaced by the values bound in the Step, or by their
+// defaults. The other fields of the Step, e.g. its name, are kept.
+func MergeStepWithStepAction(step Step, action StepActionSpec) (Step, error) {
+	declared := make(map[string]ParamSpec, len(action.Params))
+	for _, p := range action.Params {
+		declared[p.Name] = p
+	}
+	bindings := make(map[string]ArrayOrString, len(step.Params))
+	for _, p := range step.Params {
+		if _, ok := declared[p.Name]; !ok {
+			return Step{}, fmt.Errorf("param %q is not declared by the StepAction", p.Name)
+		}
+		bindings[p.Name] = p.Value
+	}
+
+	stringReplacements := map[string]string{}
+	arrayReplacements := map[string][]string{}
+	for _, p := range action.Params {
+		value, ok := bindings[p.Name]
+		if !ok {
+			if p.Default == nil {
+				return Step{}, fmt.Errorf("missing value for param %q", p.Name)
+			}
+			value = *p.Default
+		}
+		if value.Type != p.Type {
+			return Step{}, fmt.Errorf("param %q must be of type %q, got %q", p.Name, p.Type, value.Type)
+		}
+		if value.Type == ParamTypeArray {
+			arrayReplacements[fmt.Sprintf("params.%s", p.Name)] = value.ArrayVal
+		} else {
+			stringReplacements[fmt.Sprintf("params.%s", p.Name)] = value.StringVal
+		}
+	}
+
+	// Only the fields coming from the StepAction can reference its params.
+	resolved := Step{
+		Container: v1.Container{
+			Image:   action.Image,
+			Command: append([]string(nil), action.Command...),
+			Args:    append([]string(nil), action.Args...),
+			Env:     append([]v1.EnvVar(nil), action.Env...),
+		},
+		Script: action.Script,
+	}
+	ApplyStepReplacements(&resolved, stringReplacements, arrayReplacements)
+
+	merged := *step.DeepCopy()
+	merged.Ref = nil
+	merged.Params = nil
+	merged.Image = resolved.Image
+	merged.Command = resolved.Command
+	merged.Args = resolved.Args
+	merged.Env = append(merged.Env, resolved.Env...)
+	merged.Script = resolved.Script
+	return merged, nil
+}
@@ -101,6 +101,55 @@ func TestMergeStepsWithStepTemplate(t *testing.T) {
 				Value: "NEW_VALUE",
 			}},
 		}}},
+	}, {
+		name: "output-configs-passed-through",
+		template: &corev1.Container{
+			Image: "some-image",
+		},
+		steps: []Step{{
+			StdoutConfig: &StepOutputConfig{Path: "/workspace/stdout"},
+			StderrConfig: &StepOutputConfig{Path: "/workspace/stderr"},
+		}},
+		expected: []Step{{
+			Container: corev1.Container{
+				Image: "some-image",
+			},
+			StdoutConfig: &StepOutputConfig{Path: "/workspace/stdout"},
+			StderrConfig: &StepOutputConfig{Path: "/workspace/stderr"},
+		}},
+	}, {
+		name: "script-language-passed-through",
+		template: &corev1.Container{
+			Image: "python",
+		},
+		steps: []Step{{
+			Script:         "print(\"hello\")",
+			ScriptLanguage: ScriptLanguagePython,
+		}},
+		expected: []Step{{
+			Container: corev1.Container{
+				Image: "python",
+			},
+			Script:         "print(\"hello\")",
+			ScriptLanguage: ScriptLanguagePython,
+		}},
+	}, {
+		name: "step-ref-not-merged",
+		template: &corev1.Container{
+			Image: "some-image",
+		},
+		steps: []Step{{
+			Container: corev1.Container{
+				Name: "clone",
+			},
+			Ref: &Ref{Name: "git-clone"},
+		}},
+		expected: []Step{{
+			Container: corev1.Container{
+				Name: "clone",
+			},
+			Ref: &Ref{Name: "git-clone"},
+		}},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			result, err := MergeStepsWithStepTemplate(tc.template, tc.steps)
@@ -114,3 +163,248 @@ func TestMergeStepsWithStepTemplate(t *testing.T) {
 		})
 	}
 }
+
+func TestMergeStepWithStepAction(t *testing.T) {
+	action := StepActionSpec{
+		Image:   "alpine/git:$(params.version)",
+		Command: []string{"git"},
+		Args:    []string{"clone", "$(params.flags[*])", "$(params.url)"},
+		Env: []corev1.EnvVar{{
+			Name:  "GIT_DIR",
+			Value: "$(params.dir)",
+		}},
+		Params: []ParamSpec{{
+			Name: "url",
+			Type: ParamTypeString,
+		}, {
+			Name:    "version",
+			Type:    ParamTypeString,
+			Default: NewArrayOrString("latest"),
+		}, {
+			Name:    "dir",
+			Type:    ParamTypeString,
+			Default: NewArrayOrString("/workspace/source"),
+		}, {
+			Name:    "flags",
+			Type:    ParamTypeArray,
+			Default: NewArrayOrString("--depth", "1"),
+		}},
+	}
+
+	for _, tc := range []struct {
+		name     string
+		step     Step
+		expected Step
+	}{{
+		name: "defaults",
+		step: Step{
+			Container: corev1.Container{
+				Name: "clone",
+			},
+			Ref: &Ref{Name: "git-clone"},
+			Params: []Param{{
+				Name:  "url",
+				Value: *NewArrayOrString("$(params.repo)"),
+			}},
+		},
+		expected: Step{
+			Container: corev1.Container{
+				Name:    "clone",
+				Image:   "alpine/git:latest",
+				Command: []string{"git"},
+				Args:    []string{"clone", "--depth", "1", "$(params.repo)"},
+				Env: []corev1.EnvVar{{
+					Name:  "GIT_DIR",
+					Value: "/workspace/source",
+				}},
+			},
+		},
+	}, {
+		name: "bound-params-and-step-fields-kept",
+		step: Step{
+			Container: corev1.Container{
+				Name:       "clone",
+				WorkingDir: "/workspace",
+				Env: []corev1.EnvVar{{
+					Name:  "HOME",
+					Value: "/tekton/home",
+				}},
+			},
+			OnError: StepContinue,
+			Ref:     &Ref{Name: "git-clone"},
+			Params: []Param{{
+				Name:  "url",
+				Value: *NewArrayOrString("https://github.com/tektoncd/pipeline"),
+			}, {
+				Name:  "version",
+				Value: *NewArrayOrString("v2.26.2"),
+			}, {
+				Name:  "flags",
+				Value: *NewArrayOrString("--single-branch", "--depth", "1"),
+			}},
+		},
+		expected: Step{
+			Container: corev1.Container{
+				Name:       "clone",
+				Image:      "alpine/git:v2.26.2",
+				Command:    []string{"git"},
+				Args:       []string{"clone", "--single-branch", "--depth", "1", "https://github.com/tektoncd/pipeline"},
+				WorkingDir: "/workspace",
+				Env: []corev1.EnvVar{{
+					Name:  "HOME",
+					Value: "/tekton/home",
+				}, {
+					Name:  "GIT_DIR",
+					Value: "/workspace/source",
+				}},
+			},
+			OnError: StepContinue,
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := MergeStepWithStepAction(tc.step, action)
+			if err != nil {
+				t.Errorf("expected no error. Got error %v", err)
+			}
+
+			if d := cmp.Diff(tc.expected, result); d != "" {
+				t.Errorf("merged step doesn't match, diff: %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestMergeStepsWithOverrides(t *testing.T) {
+	resourceQuantityCmp := cmp.Comparer(func(x, y resource.Quantity) bool {
+		return x.Cmp(y) == 0
+	})
+	runAsNonRoot := true
+	steps := []Step{{
+		Container: corev1.Container{
+			Name:  "build",
+			Image: "golang:1.15",
+			Env: []corev1.EnvVar{{
+				Name:  "GOFLAGS",
+				Value: "-mod=vendor",
+			}, {
+				Name:  "CGO_ENABLED",
+				Value: "0",
+			}},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU:    resource.MustParse("500m"),
+					corev1.ResourceMemory: resource.MustParse("1Gi"),
+				},
+			},
+		},
+		Script: "go build ./...",
+	}, {
+		Container: corev1.Container{
+			Name:  "push",
+			Image: "ko",
+		},
+	}}
+	for _, tc := range []struct {
+		name      string
+		overrides []TaskRunStepOverride
+		expected  []Step
+	}{{
+		name:     "no overrides",
+		expected: steps,
+	}, {
+		name: "override merged into the step",
+		overrides: []TaskRunStepOverride{{
+			Name:  "build",
+			Image: "golang:1.16",
+			Env: []corev1.EnvVar{{
+				Name:  "CGO_ENABLED",
+				Value: "1",
+			}, {
+				Name:  "GOOS",
+				Value: "linux",
+			}},
+			SecurityContext: &corev1.SecurityContext{RunAsNonRoot: &runAsNonRoot},
+			Resources: corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceCPU: resource.MustParse("8"),
+				},
+			},
+		}},
+		expected: []Step{{
+			Container: corev1.Container{
+				Name:  "build",
+				Image: "golang:1.16",
+				Env: []corev1.EnvVar{{
+					Name:  "GOFLAGS",
+					Value: "-mod=vendor",
+				}, {
+					Name:  "CGO_ENABLED",
+					Value: "1",
+				}, {
+					Name:  "GOOS",
+					Value: "linux",
+				}},
+				SecurityContext: &corev1.SecurityContext{RunAsNonRoot: &runAsNonRoot},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("8"),
+						corev1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+				},
+			},
+			Script: "go build ./...",
+		}, {
+			Container: corev1.Container{
+				Name:  "push",
+				Image: "ko",
+			},
+		}},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := MergeStepsWithOverrides(steps, tc.overrides)
+			if err != nil {
+				t.Errorf("expected no error. Got error %v", err)
+			}
+
+			if d := cmp.Diff(tc.expected, result, resourceQuantityCmp); d != "" {
+				t.Errorf("merged steps don't match, diff: %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestMergeSidecarsWithOverrides(t *testing.T) {
+	sidecars := []Sidecar{{
+		Container: corev1.Container{
+			Name:  "registry",
+			Image: "registry:2",
+		},
+	}}
+	overrides := []TaskRunSidecarOverride{{
+		Name: "registry",
+		Env: []corev1.EnvVar{{
+			Name:  "REGISTRY_LOG_LEVEL",
+			Value: "debug",
+		}},
+	}}
+	expected := []Sidecar{{
+		Container: corev1.Container{
+			Name:  "registry",
+			Image: "registry:2",
+			Env: []corev1.EnvVar{{
+				Name:  "REGISTRY_LOG_LEVEL",
+				Value: "debug",
+			}},
+		},
+	}}
+	result, err := MergeSidecarsWithOverrides(sidecars, overrides)
+	if err != nil {
+		t.Errorf("expected no error. Got error %v", err)
+	}
+	if d := cmp.Diff(expected, result); d != "" {
+		t.Errorf("merged sidecars don't match, diff: %s", diff.PrintWantGot(d))
+	}
+	if sidecars[0].Env != nil {
+		t.Errorf("expected the sidecars of the Task to be unchanged, got env %v", sidecars[0].Env)
+	}
+}
@@ -158,8 +158,23 @@ type PipelineTask struct {
 	// Refer Go's ParseDuration documentation for expected format: https://golang.org/pkg/time/#ParseDuration
 	// +optional
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// OnError defines the exiting behavior of the PipelineRun when this task fails
+	// can be set to [ continue | stopAndFail ], defaults to stopAndFail
+	// +optional
+	OnError PipelineTaskOnErrorType `json:"onError,omitempty"`
 }
 
+// PipelineTaskOnErrorType defines a list of supported failure handling behaviors of a PipelineTask on error
+type PipelineTaskOnErrorType string
+
+const (
+	// PipelineTaskStopAndFail indicates to stop scheduling new tasks and fail the PipelineRun if the task fails
+	PipelineTaskStopAndFail PipelineTaskOnErrorType = "stopAndFail"
+	// PipelineTaskContinue indicates to record the failure of the task and continue executing the rest of the graph
+	PipelineTaskContinue PipelineTaskOnErrorType = "continue"
+)
+
 func (pt *PipelineTask) TaskSpecMetadata() PipelineTaskMetadata {
 	return pt.TaskSpec.Metadata
 }
@@ -121,9 +121,20 @@ func validatePipelineTask(ctx context.Context, t PipelineTask, taskNames sets.St
 		}
 		taskNames[t.Name] = struct{}{}
 	}
+	errs = errs.Also(validatePipelineTaskOnError(t.OnError))
 	return errs
 }
 
+// validatePipelineTaskOnError ensures that onError is set to one of the supported values
+func validatePipelineTaskOnError(onError PipelineTaskOnErrorType) *apis.FieldError {
+	switch onError {
+	case "", PipelineTaskStopAndFail, PipelineTaskContinue:
+		return nil
+	}
+	return apis.ErrInvalidValue(fmt.Sprintf("%q is not a valid onError value, must be one of %q or %q",
+		onError, PipelineTaskContinue, PipelineTaskStopAndFail), "onError")
+}
+
 // validatePipelineWorkspaces validates the specified workspaces, ensuring having unique name without any empty string,
 // and validates that all the referenced workspaces (by pipeline tasks) are specified in the pipeline
 func validatePipelineWorkspaces(wss []PipelineWorkspaceDeclaration, pts []PipelineTask, finalTasks []PipelineTask) (errs *apis.FieldError) {
@@ -425,6 +425,20 @@ func TestValidatePipelineTasks_Success(t *testing.T) {
 			Name:     "foo",
 			TaskSpec: &EmbeddedTask{TaskSpec: getTaskSpec()},
 		}},
+	}, {
+		name: "pipeline task with onError continue",
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{Name: "foo-task"},
+			OnError: PipelineTaskContinue,
+		}},
+	}, {
+		name: "pipeline task with onError stopAndFail",
+		tasks: []PipelineTask{{
+			Name:    "foo",
+			TaskRef: &TaskRef{Name: "foo-task"},
+			OnError: PipelineTaskStopAndFail,
+		}},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -515,6 +529,13 @@ func TestValidatePipelineTasks_Failure(t *testing.T) {
 			Message: `invalid value: name part must consist of alphanumeric characters, '-', '_' or '.', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my.name',  or '123-abc', regex used for validation is '([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]')`,
 			Paths:   []string{"tasks[0].name"},
 		},
+	}, {
+		name:  "pipeline task with invalid onError value",
+		tasks: []PipelineTask{{Name: "foo", TaskRef: &TaskRef{Name: "foo-task"}, OnError: "ignore"}},
+		expectedError: apis.FieldError{
+			Message: `invalid value: "ignore" is not a valid onError value, must be one of "continue" or "stopAndFail"`,
+			Paths:   []string{"tasks[0].onError"},
+		},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
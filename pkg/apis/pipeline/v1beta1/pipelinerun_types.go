@@ -98,6 +98,33 @@ func (pr *PipelineRun) IsCancelled() bool {
 	return pr.Spec.Status == PipelineRunSpecStatusCancelled
 }
 
+// IsPaused returns true if the PipelineRun's spec status is set to Paused state
+func (pr *PipelineRun) IsPaused() bool {
+	return pr.Spec.Status == PipelineRunSpecStatusPaused
+}
+
+// GetPausedDuration returns the total duration the PipelineRun has been paused for,
+// including the ongoing pause if the PipelineRun is currently paused
+func (pr *PipelineRun) GetPausedDuration() time.Duration {
+	var paused time.Duration
+	if pr.Status.PausedDuration != nil {
+		paused = pr.Status.PausedDuration.Duration
+	}
+	if pr.Status.PausedTime != nil {
+		paused += time.Since(pr.Status.PausedTime.Time)
+	}
+	return paused
+}
+
+// GetTimeoutStartTime returns the time from which the timeout of the PipelineRun is measured,
+// that is its start time shifted by the duration it has been paused for
+func (pr *PipelineRun) GetTimeoutStartTime() metav1.Time {
+	if pr.Status.StartTime == nil {
+		return metav1.Time{}
+	}
+	return metav1.NewTime(pr.Status.StartTime.Add(pr.GetPausedDuration()))
+}
+
 // GetNamespacedName returns a k8s namespaced name that identifies this PipelineRun
 func (pr *PipelineRun) GetNamespacedName() types.NamespacedName {
 	return types.NamespacedName{Namespace: pr.Namespace, Name: pr.Name}
@@ -108,7 +135,8 @@ func (pr *PipelineRun) IsTimedOut() bool {
 	return pr.HasTimedOut()
 }
 
-// HasTimedOut returns true if a pipelinerun has exceeded its spec.Timeout based on its status.Timeout
+// HasTimedOut returns true if a pipelinerun has exceeded its spec.Timeout based on its status.Timeout.
+// The time spent paused does not count towards the timeout.
 func (pr *PipelineRun) HasTimedOut() bool {
 	pipelineTimeout := pr.Spec.Timeout
 	startTime := pr.Status.StartTime
@@ -118,7 +146,7 @@ func (pr *PipelineRun) HasTimedOut() bool {
 		if timeout == config.NoTimeoutDuration {
 			return false
 		}
-		runtime := time.Since(startTime.Time)
+		runtime := time.Since(startTime.Time) - pr.GetPausedDuration()
 		if runtime > timeout {
 			return true
 		}
@@ -167,7 +195,7 @@ type PipelineRunSpec struct {
 	// Deprecated: use taskRunSpecs.ServiceAccountName instead
 	// +optional
 	ServiceAccountNames []PipelineRunSpecServiceAccountName `json:"serviceAccountNames,omitempty"`
-	// Used for cancelling a pipelinerun (and maybe more later on)
+	// Used for cancelling, pausing or resuming a pipelinerun
 	// +optional
 	Status PipelineRunSpecStatus `json:"status,omitempty"`
 	// Time after which the Pipeline times out. Defaults to never.
@@ -183,6 +211,11 @@ type PipelineRunSpec struct {
 	// TaskRunSpecs holds a set of runtime specs
 	// +optional
 	TaskRunSpecs []PipelineTaskRunSpec `json:"taskRunSpecs,omitempty"`
+	// RerunOf is the name of a completed PipelineRun, in the same namespace, that this
+	// PipelineRun re-executes. The PipelineTasks which succeeded in that PipelineRun are
+	// not executed again, their TaskRuns status and results are reused instead.
+	// +optional
+	RerunOf string `json:"rerunOf,omitempty"`
 }
 
 // PipelineRunSpecStatus defines the pipelinerun spec status the user can provide
@@ -192,6 +225,10 @@ const (
 	// PipelineRunSpecStatusCancelled indicates that the user wants to cancel the task,
 	// if not already cancelled or terminated
 	PipelineRunSpecStatusCancelled = "PipelineRunCancelled"
+
+	// PipelineRunSpecStatusPaused indicates that the user wants to pause the pipelinerun:
+	// no new TaskRuns are created and the timeout is suspended until the status is cleared
+	PipelineRunSpecStatusPaused = "PipelineRunPaused"
 )
 
 // PipelineRef can be used to refer to a specific instance of a Pipeline.
@@ -235,6 +272,9 @@ const (
 	// PipelineRunReasonStopping indicates that no new Tasks will be scheduled by the controller, and the
 	// pipeline will stop once all running tasks complete their work
 	PipelineRunReasonStopping PipelineRunReason = "PipelineRunStopping"
+	// PipelineRunReasonPaused indicates that the PipelineRun has been paused by the user, no new
+	// Tasks will be scheduled by the controller until it is resumed
+	PipelineRunReasonPaused PipelineRunReason = "PipelineRunPaused"
 )
 
 func (t PipelineRunReason) String() string {
@@ -320,6 +360,14 @@ type PipelineRunStatusFields struct {
 	// +optional
 	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 
+	// PausedTime is the time the PipelineRun was paused, it is cleared when the PipelineRun is resumed.
+	// +optional
+	PausedTime *metav1.Time `json:"pausedTime,omitempty"`
+
+	// PausedDuration is the total duration the PipelineRun spent paused before being resumed.
+	// +optional
+	PausedDuration *metav1.Duration `json:"pausedDuration,omitempty"`
+
 	// map of PipelineRunTaskRunStatus with the taskRun name as the key
 	// +optional
 	TaskRuns map[string]*PipelineRunTaskRunStatus `json:"taskRuns,omitempty"`
@@ -334,6 +382,11 @@ type PipelineRunStatusFields struct {
 	// list of tasks that were skipped due to when expressions evaluating to false
 	// +optional
 	SkippedTasks []SkippedTask `json:"skippedTasks,omitempty"`
+
+	// CloudEvents describe the state of the delivery of each cloud event sent
+	// to the default cloud events sink about the PipelineRun.
+	// +optional
+	CloudEvents []CloudEventDelivery `json:"cloudEvents,omitempty"`
 }
 
 // SkippedTask is used to describe the Tasks that were skipped due to their When Expressions
@@ -410,6 +463,20 @@ type PipelineTaskRunSpec struct {
 	PipelineTaskName       string       `json:"pipelineTaskName,omitempty"`
 	TaskServiceAccountName string       `json:"taskServiceAccountName,omitempty"`
 	TaskPodTemplate        *PodTemplate `json:"taskPodTemplate,omitempty"`
+	// ComputeResources overrides the compute resources of all the Steps
+	// of the TaskRun, it cannot be set together with the resources of
+	// StepOverrides. The limits are only set on the first Step, so that
+	// they are not summed over all the Steps of the Pod.
+	// +optional
+	ComputeResources *corev1.ResourceRequirements `json:"computeResources,omitempty"`
+	// StepOverrides overrides the values of the Steps of the TaskRun, by
+	// name.
+	// +optional
+	StepOverrides []TaskRunStepOverride `json:"stepOverrides,omitempty"`
+	// SidecarOverrides overrides the values of the Sidecars of the
+	// TaskRun, by name.
+	// +optional
+	SidecarOverrides []TaskRunSidecarOverride `json:"sidecarOverrides,omitempty"`
 }
 
 // GetTaskRunSpecs returns the task specific spec for a given
@@ -425,3 +492,19 @@ func (pr *PipelineRun) GetTaskRunSpecs(pipelineTaskName string) (string, *PodTem
 	}
 	return serviceAccountName, taskPodTemplate
 }
+
+// GetTaskRunOverrides returns the compute resources, Step and Sidecar
+// overrides configured for a given PipelineTask, if any.
+func (pr *PipelineRun) GetTaskRunOverrides(pipelineTaskName string) (*corev1.ResourceRequirements, []TaskRunStepOverride, []TaskRunSidecarOverride) {
+	var computeResources *corev1.ResourceRequirements
+	var stepOverrides []TaskRunStepOverride
+	var sidecarOverrides []TaskRunSidecarOverride
+	for _, task := range pr.Spec.TaskRunSpecs {
+		if task.PipelineTaskName == pipelineTaskName {
+			computeResources = task.ComputeResources
+			stepOverrides = task.StepOverrides
+			sidecarOverrides = task.SidecarOverrides
+		}
+	}
+	return computeResources, stepOverrides, sidecarOverrides
+}
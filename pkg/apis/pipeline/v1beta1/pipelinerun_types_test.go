@@ -146,6 +146,36 @@ func TestPipelineRunIsCancelled(t *testing.T) {
 	}
 }
 
+func TestPipelineRunIsPaused(t *testing.T) {
+	pr := &v1beta1.PipelineRun{
+		Spec: v1beta1.PipelineRunSpec{
+			Status: v1beta1.PipelineRunSpecStatusPaused,
+		},
+	}
+	if !pr.IsPaused() {
+		t.Fatal("Expected pipelinerun status to be paused")
+	}
+}
+
+func TestPipelineRunGetTimeoutStartTime(t *testing.T) {
+	startTime := time.Now().Add(-1 * time.Hour)
+	pr := &v1beta1.PipelineRun{
+		Status: v1beta1.PipelineRunStatus{PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+			StartTime:      &metav1.Time{Time: startTime},
+			PausedDuration: &metav1.Duration{Duration: 10 * time.Minute},
+		}},
+	}
+	if d := cmp.Diff(startTime.Add(10*time.Minute), pr.GetTimeoutStartTime().Time); d != "" {
+		t.Errorf("Unexpected timeout start time %s", diff.PrintWantGot(d))
+	}
+
+	// An ongoing pause also shifts the timeout start time
+	pr.Status.PausedTime = &metav1.Time{Time: time.Now().Add(-5 * time.Minute)}
+	if got := pr.GetTimeoutStartTime().Time; got.Before(startTime.Add(15 * time.Minute)) {
+		t.Errorf("Expected timeout start time to be shifted by at least 15m, got %s", got.Sub(startTime))
+	}
+}
+
 func TestPipelineRunHasVolumeClaimTemplate(t *testing.T) {
 	pr := &v1beta1.PipelineRun{
 		Spec: v1beta1.PipelineRunSpec{
@@ -218,10 +248,12 @@ func TestPipelineRunHasStarted(t *testing.T) {
 
 func TestPipelineRunHasTimedOut(t *testing.T) {
 	tcs := []struct {
-		name      string
-		timeout   time.Duration
-		starttime time.Time
-		expected  bool
+		name           string
+		timeout        time.Duration
+		starttime      time.Time
+		pausedTime     *metav1.Time
+		pausedDuration *metav1.Duration
+		expected       bool
 	}{{
 		name:      "timedout",
 		timeout:   1 * time.Second,
@@ -237,18 +269,38 @@ func TestPipelineRunHasTimedOut(t *testing.T) {
 		timeout:   0 * time.Second,
 		starttime: time.Now().AddDate(0, 0, -1),
 		expected:  false,
+	}, {
+		name:           "nottimedout after being paused",
+		timeout:        2 * time.Hour,
+		starttime:      time.Now().Add(-3 * time.Hour),
+		pausedDuration: &metav1.Duration{Duration: 90 * time.Minute},
+		expected:       false,
+	}, {
+		name:       "nottimedout while paused",
+		timeout:    2 * time.Hour,
+		starttime:  time.Now().Add(-3 * time.Hour),
+		pausedTime: &metav1.Time{Time: time.Now().Add(-2 * time.Hour)},
+		expected:   false,
+	}, {
+		name:           "timedout despite being paused",
+		timeout:        2 * time.Hour,
+		starttime:      time.Now().Add(-3 * time.Hour),
+		pausedDuration: &metav1.Duration{Duration: 30 * time.Minute},
+		expected:       true,
 	},
 	}
 
 	for _, tc := range tcs {
-		t.Run(t.Name(), func(t *testing.T) {
+		t.Run(tc.name, func(t *testing.T) {
 			pr := &v1beta1.PipelineRun{
 				ObjectMeta: metav1.ObjectMeta{Name: "foo"},
 				Spec: v1beta1.PipelineRunSpec{
 					Timeout: &metav1.Duration{Duration: tc.timeout},
 				},
 				Status: v1beta1.PipelineRunStatus{PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
-					StartTime: &metav1.Time{Time: tc.starttime},
+					StartTime:      &metav1.Time{Time: tc.starttime},
+					PausedTime:     tc.pausedTime,
+					PausedDuration: tc.pausedDuration,
 				}},
 			}
 
@@ -19,8 +19,10 @@ package v1beta1
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/tektoncd/pipeline/pkg/apis/validate"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"knative.dev/pkg/apis"
 )
 
@@ -29,6 +31,9 @@ var _ apis.Validatable = (*PipelineRun)(nil)
 // Validate pipelinerun
 func (pr *PipelineRun) Validate(ctx context.Context) *apis.FieldError {
 	errs := validate.ObjectMetadata(pr.GetObjectMeta()).ViaField("metadata")
+	if pr.Spec.RerunOf != "" && pr.Spec.RerunOf == pr.Name {
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("PipelineRun %s cannot be a rerun of itself", pr.Name), "spec.rerunOf"))
+	}
 	return errs.Also(pr.Spec.Validate(apis.WithinSpec(ctx)).ViaField("spec"))
 }
 
@@ -57,8 +62,14 @@ func (ps *PipelineRunSpec) Validate(ctx context.Context) (errs *apis.FieldError)
 	}
 
 	if ps.Status != "" {
-		if ps.Status != PipelineRunSpecStatusCancelled {
-			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s should be %s", ps.Status, PipelineRunSpecStatusCancelled), "status"))
+		if ps.Status != PipelineRunSpecStatusCancelled && ps.Status != PipelineRunSpecStatusPaused {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s should be %s or %s", ps.Status, PipelineRunSpecStatusCancelled, PipelineRunSpecStatusPaused), "status"))
+		}
+	}
+
+	if ps.RerunOf != "" {
+		if err := validation.IsDNS1123Subdomain(ps.RerunOf); len(err) > 0 {
+			errs = errs.Also(apis.ErrInvalidValue(strings.Join(err, ","), "rerunOf"))
 		}
 	}
 
@@ -73,5 +84,9 @@ func (ps *PipelineRunSpec) Validate(ctx context.Context) (errs *apis.FieldError)
 		}
 	}
 
+	for idx, trs := range ps.TaskRunSpecs {
+		errs = errs.Also(validateOverrides(trs.ComputeResources, trs.StepOverrides, trs.SidecarOverrides).ViaFieldIndex("taskRunSpecs", idx))
+	}
+
 	return errs
 }
@@ -89,7 +89,21 @@ func TestPipelineRun_Invalidate(t *testing.T) {
 					Status: "PipelineRunCancell",
 				},
 			},
-			want: apis.ErrInvalidValue("PipelineRunCancell should be PipelineRunCancelled", "spec.status"),
+			want: apis.ErrInvalidValue("PipelineRunCancell should be PipelineRunCancelled or PipelineRunPaused", "spec.status"),
+		}, {
+			name: "pipelinerun rerun of itself",
+			pr: v1beta1.PipelineRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "pipelinelinename",
+				},
+				Spec: v1beta1.PipelineRunSpec{
+					PipelineRef: &v1beta1.PipelineRef{
+						Name: "prname",
+					},
+					RerunOf: "pipelinelinename",
+				},
+			},
+			want: apis.ErrInvalidValue("PipelineRun pipelinelinename cannot be a rerun of itself", "spec.rerunOf"),
 		},
 	}
 
@@ -132,6 +146,32 @@ func TestPipelineRun_Validate(t *testing.T) {
 				Timeout: &metav1.Duration{Duration: 0},
 			},
 		},
+	}, {
+		name: "paused",
+		pr: v1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pipelinelineName",
+			},
+			Spec: v1beta1.PipelineRunSpec{
+				PipelineRef: &v1beta1.PipelineRef{
+					Name: "prname",
+				},
+				Status: v1beta1.PipelineRunSpecStatusPaused,
+			},
+		},
+	}, {
+		name: "rerun of another pipelinerun",
+		pr: v1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pipelinelineName",
+			},
+			Spec: v1beta1.PipelineRunSpec{
+				PipelineRef: &v1beta1.PipelineRef{
+					Name: "prname",
+				},
+				RerunOf: "previous-pipelinerun",
+			},
+		},
 	}, {
 		name: "array param with pipelinespec and taskspec",
 		pr: v1beta1.PipelineRun{
@@ -245,6 +285,33 @@ func TestPipelineRunSpec_Invalidate(t *testing.T) {
 				"workspaces[0].volumeclaimtemplate",
 			},
 		},
+	}, {
+		name: "rerunOf must be a valid pipelinerun name",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{
+				Name: "pipelinerefname",
+			},
+			RerunOf: "Previous_PipelineRun",
+		},
+		wantErr: apis.ErrInvalidValue("a DNS-1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character (e.g. 'example.com', regex used for validation is '[a-z0-9]([-a-z0-9]*[a-z0-9])?(\\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*')", "rerunOf"),
+	}, {
+		name: "taskRunSpecs with computeResources and stepOverrides together",
+		spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{
+				Name: "pipelinerefname",
+			},
+			TaskRunSpecs: []v1beta1.PipelineTaskRunSpec{{
+				PipelineTaskName: "build",
+				ComputeResources: &corev1.ResourceRequirements{},
+				StepOverrides: []v1beta1.TaskRunStepOverride{{
+					Name: "compile",
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{},
+					},
+				}},
+			}},
+		},
+		wantErr: apis.ErrMultipleOneOf("taskRunSpecs[0].computeResources", "taskRunSpecs[0].stepOverrides[0].resources"),
 	}}
 	for _, ps := range tests {
 		t.Run(ps.name, func(t *testing.T) {
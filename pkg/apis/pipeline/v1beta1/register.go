@@ -56,6 +56,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&TaskRunList{},
 		&PipelineRun{},
 		&PipelineRunList{},
+		&StepAction{},
+		&StepActionList{},
 	)
 	// &Condition{},
 	// &ConditionList{},
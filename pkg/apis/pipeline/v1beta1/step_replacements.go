@@ -22,5 +22,11 @@ import (
 
 func ApplyStepReplacements(step *Step, stringReplacements map[string]string, arrayReplacements map[string][]string) {
 	step.Script = substitution.ApplyReplacements(step.Script, stringReplacements)
+	if step.StdoutConfig != nil {
+		step.StdoutConfig.Path = substitution.ApplyReplacements(step.StdoutConfig.Path, stringReplacements)
+	}
+	if step.StderrConfig != nil {
+		step.StderrConfig.Path = substitution.ApplyReplacements(step.StderrConfig.Path, stringReplacements)
+	}
 	ApplyContainerReplacements(&step.Container, stringReplacements, arrayReplacements)
 }
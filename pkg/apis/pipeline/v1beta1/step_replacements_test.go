@@ -34,7 +34,9 @@ func TestApplyStepReplacements(t *testing.T) {
 	}
 
 	s := v1beta1.Step{
-		Script: "$(replace.me)",
+		Script:       "$(replace.me)",
+		StdoutConfig: &v1beta1.StepOutputConfig{Path: "$(replace.me)"},
+		StderrConfig: &v1beta1.StepOutputConfig{Path: "$(replace.me)"},
 		Container: corev1.Container{
 			Name:       "$(replace.me)",
 			Image:      "$(replace.me)",
@@ -80,7 +82,9 @@ func TestApplyStepReplacements(t *testing.T) {
 	}
 
 	expected := v1beta1.Step{
-		Script: "replaced!",
+		Script:       "replaced!",
+		StdoutConfig: &v1beta1.StepOutputConfig{Path: "replaced!"},
+		StderrConfig: &v1beta1.StepOutputConfig{Path: "replaced!"},
 		Container: corev1.Container{
 			Name:       "replaced!",
 			Image:      "replaced!",
@@ -0,0 +1,96 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StepAction represents a single reusable Step. Steps of a Task reference a
+// StepAction by name and bind values to its parameters, instead of repeating
+// the same image and script in every Task.
+//
+// +k8s:openapi-gen=true
+type StepAction struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata"`
+
+	// Spec holds the desired state of the StepAction from the client
+	// +optional
+	Spec StepActionSpec `json:"spec"`
+}
+
+// StepActionSpec defines the desired state of StepAction.
+type StepActionSpec struct {
+	// Description is a user-facing description of the StepAction that may be
+	// used to populate a UI.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Image is the container image the Step runs.
+	Image string `json:"image,omitempty"`
+
+	// Command is the entrypoint array of the Step. It cannot be used with Script.
+	// +optional
+	Command []string `json:"command,omitempty"`
+
+	// Args are the arguments to the entrypoint of the Step.
+	// +optional
+	Args []string `json:"args,omitempty"`
+
+	// Env is the list of environment variables to set in the Step.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+
+	// Script is the contents of an executable file to execute.
+	//
+	// If Script is not empty, the StepAction cannot have a Command.
+	// +optional
+	Script string `json:"script,omitempty"`
+
+	// Params is a list of input parameters of the StepAction. Params must be
+	// bound by the referencing Step unless they declare a default value.
+	// +optional
+	Params []ParamSpec `json:"params,omitempty"`
+
+	// Results are values that the StepAction can output. They are added to
+	// the Results of the Task the StepAction is used in.
+	// +optional
+	Results []TaskResult `json:"results,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// StepActionList contains a list of StepAction
+type StepActionList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []StepAction `json:"items"`
+}
+
+// Ref can be used to refer to a specific instance of a StepAction.
+type Ref struct {
+	// Name of the referenced StepAction.
+	Name string `json:"name,omitempty"`
+}
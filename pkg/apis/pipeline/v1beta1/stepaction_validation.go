@@ -0,0 +1,71 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+
+	"github.com/tektoncd/pipeline/pkg/apis/validate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"knative.dev/pkg/apis"
+)
+
+var _ apis.Validatable = (*StepAction)(nil)
+
+func (s *StepAction) Validate(ctx context.Context) *apis.FieldError {
+	errs := validate.ObjectMetadata(s.GetObjectMeta()).ViaField("metadata")
+	return errs.Also(s.Spec.Validate(apis.WithinSpec(ctx)).ViaField("spec"))
+}
+
+func (ss *StepActionSpec) Validate(ctx context.Context) (errs *apis.FieldError) {
+	if ss.Image == "" {
+		errs = errs.Also(apis.ErrMissingField("image"))
+	}
+	if ss.Script != "" && len(ss.Command) > 0 {
+		errs = errs.Also(apis.ErrGeneric("script cannot be used with command", "script"))
+	}
+	errs = errs.Also(ValidateParameterTypes(ss.Params).ViaField("params"))
+	errs = errs.Also(validateStepActionVariables(ss))
+	errs = errs.Also(validateResults(ctx, ss.Results).ViaField("results"))
+	return errs
+}
+
+// validateStepActionVariables makes sure the StepAction only references the
+// params it declares.
+func validateStepActionVariables(ss *StepActionSpec) *apis.FieldError {
+	parameterNames := sets.NewString()
+	arrayParameterNames := sets.NewString()
+	for _, p := range ss.Params {
+		parameterNames.Insert(p.Name)
+		if p.Type == ParamTypeArray {
+			arrayParameterNames.Insert(p.Name)
+		}
+	}
+
+	step := Step{
+		Container: corev1.Container{
+			Image:   ss.Image,
+			Command: ss.Command,
+			Args:    ss.Args,
+			Env:     ss.Env,
+		},
+		Script: ss.Script,
+	}
+	errs := validateStepVariables(step, "params", parameterNames)
+	return errs.Also(validateStepArrayUsage(step, "params", arrayParameterNames))
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	"knative.dev/pkg/apis"
+)
+
+func TestStepActionSpecValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		spec v1beta1.StepActionSpec
+	}{{
+		name: "image only",
+		spec: v1beta1.StepActionSpec{
+			Image: "alpine/git",
+		},
+	}, {
+		name: "script using params and results",
+		spec: v1beta1.StepActionSpec{
+			Image:  "alpine/git",
+			Script: "git clone $(params.url) && git rev-parse HEAD > $(results.commit.path)",
+			Params: []v1beta1.ParamSpec{{
+				Name: "url",
+			}},
+			Results: []v1beta1.TaskResult{{
+				Name: "commit",
+			}},
+		},
+	}, {
+		name: "array param used in args",
+		spec: v1beta1.StepActionSpec{
+			Image:   "alpine/git",
+			Command: []string{"git"},
+			Args:    []string{"clone", "$(params.flags[*])"},
+			Params: []v1beta1.ParamSpec{{
+				Name: "flags",
+				Type: v1beta1.ParamTypeArray,
+			}},
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			tt.spec.SetDefaults(ctx)
+			if err := tt.spec.Validate(ctx); err != nil {
+				t.Errorf("StepActionSpec.Validate() = %v", err)
+			}
+		})
+	}
+}
+
+func TestStepActionSpecValidateError(t *testing.T) {
+	tests := []struct {
+		name          string
+		spec          v1beta1.StepActionSpec
+		expectedError apis.FieldError
+	}{{
+		name: "missing image",
+		spec: v1beta1.StepActionSpec{
+			Script: "echo hello",
+		},
+		expectedError: apis.FieldError{
+			Message: `missing field(s)`,
+			Paths:   []string{"image"},
+		},
+	}, {
+		name: "script with command",
+		spec: v1beta1.StepActionSpec{
+			Image:   "alpine/git",
+			Command: []string{"git"},
+			Script:  "git clone",
+		},
+		expectedError: apis.FieldError{
+			Message: `script cannot be used with command`,
+			Paths:   []string{"script"},
+		},
+	}, {
+		name: "undeclared param",
+		spec: v1beta1.StepActionSpec{
+			Image:  "alpine/git",
+			Script: "git clone $(params.url)",
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent variable in "git clone $(params.url)"`,
+			Paths:   []string{"script"},
+		},
+	}, {
+		name: "array param used in script",
+		spec: v1beta1.StepActionSpec{
+			Image:  "alpine/git",
+			Script: "git clone $(params.flags)",
+			Params: []v1beta1.ParamSpec{{
+				Name: "flags",
+				Type: v1beta1.ParamTypeArray,
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `variable type invalid in "git clone $(params.flags)"`,
+			Paths:   []string{"script"},
+		},
+	}, {
+		name: "invalid result name",
+		spec: v1beta1.StepActionSpec{
+			Image: "alpine/git",
+			Results: []v1beta1.TaskResult{{
+				Name: "MY^RESULT",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid key name "MY^RESULT"`,
+			Paths:   []string{"results[0].name"},
+			Details: "Name must consist of alphanumeric characters, '-', '_', and must start and end with an alphanumeric character (e.g. 'MyName',  or 'my-name',  or 'my_name', regex used for validation is '^([A-Za-z0-9][-A-Za-z0-9_.]*)?[A-Za-z0-9]$')",
+		},
+	}}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			tt.spec.SetDefaults(ctx)
+			err := tt.spec.Validate(ctx)
+			if err == nil {
+				t.Fatalf("Expected an error, got nothing for %v", tt.spec)
+			}
+			if d := cmp.Diff(tt.expectedError.Error(), err.Error(), cmpopts.IgnoreUnexported(apis.FieldError{})); d != "" {
+				t.Errorf("StepActionSpec.Validate() errors diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
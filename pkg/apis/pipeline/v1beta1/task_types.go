@@ -30,6 +30,11 @@ const (
 	InternalTektonResultType ResultType = "InternalTektonResult"
 	// UnknownResultType default unknown result type value
 	UnknownResultType ResultType = ""
+
+	// OnFailureStepNamePrefix is the prefix the names of the containers of
+	// the OnFailure steps get, which the names of the Steps of a Task with
+	// OnFailure steps must not start with.
+	OnFailureStepNamePrefix = "on-failure-"
 )
 
 // +genclient
@@ -104,6 +109,18 @@ type TaskSpec struct {
 
 	// Results are values that this Task can output
 	Results []TaskResult `json:"results,omitempty"`
+
+	// ScriptPreamble is prepended to the scripts of the Steps and Sidecars
+	// which don't start with a shebang. It must start with a shebang itself,
+	// and defaults to "#!/bin/sh\nset -xe".
+	// +optional
+	ScriptPreamble string `json:"scriptPreamble,omitempty"`
+
+	// OnFailure are the steps run after the Steps when one of them failed,
+	// for example to collect diagnostics. They are skipped when all the
+	// Steps succeeded.
+	// +optional
+	OnFailure []Step `json:"onFailure,omitempty"`
 }
 
 // TaskResult used to describe the results of a task
@@ -125,11 +142,67 @@ type Step struct {
 	//
 	// If Script is not empty, the Step cannot have an Command or Args.
 	Script string `json:"script,omitempty"`
+	// ScriptLanguage is the language the Script is written in, used to run a
+	// Script which doesn't start with a shebang with the right interpreter.
+	// +optional
+	ScriptLanguage ScriptLanguage `json:"scriptLanguage,omitempty"`
 	// Timeout is the time after which the step times out. Defaults to never.
 	// Refer to Go's ParseDuration documentation for expected format: https://golang.org/pkg/time/#ParseDuration
 	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// OnError defines the exiting behavior of a container on error
+	// can be set to [ continue | stopAndFail ], defaults to stopAndFail
+	// +optional
+	OnError StepOnErrorType `json:"onError,omitempty"`
+	// StdoutConfig is the configuration of the file the stdout of the Step is written to,
+	// in addition to the container log.
+	// +optional
+	StdoutConfig *StepOutputConfig `json:"stdoutConfig,omitempty"`
+	// StderrConfig is the configuration of the file the stderr of the Step is written to,
+	// in addition to the container log.
+	// +optional
+	StderrConfig *StepOutputConfig `json:"stderrConfig,omitempty"`
+	// Ref references a StepAction the Step is resolved from. If Ref is set,
+	// the Step cannot have an Image, Command, Args or Script.
+	// +optional
+	Ref *Ref `json:"ref,omitempty"`
+	// Params are the values bound to the params of the referenced StepAction.
+	// +optional
+	Params []Param `json:"params,omitempty"`
+	// Sidecars are the names of the Task's Sidecars the Step needs. Once any
+	// Step of a Task declares Sidecars, each Step only waits for the Sidecars
+	// it needs to be ready, and a Sidecar is stopped as soon as the last Step
+	// needing it has finished.
+	// +optional
+	Sidecars []string `json:"sidecars,omitempty"`
+}
+
+// StepOutputConfig stores configuration for a step output stream.
+type StepOutputConfig struct {
+	// Path is the path of the file the output stream is written to. To use the output
+	// of the Step as a Task Result, set it to $(results.<name>.path).
+	Path string `json:"path,omitempty"`
 }
 
+// ScriptLanguage defines a list of supported languages of a Step script
+type ScriptLanguage string
+
+const (
+	// ScriptLanguagePython indicates that the script is run with python3
+	ScriptLanguagePython ScriptLanguage = "python"
+	// ScriptLanguageNode indicates that the script is run with node
+	ScriptLanguageNode ScriptLanguage = "node"
+)
+
+// StepOnErrorType defines a list of supported exiting behaviors of a Step on error
+type StepOnErrorType string
+
+const (
+	// StepStopAndFail indicates to exit the taskRun if the container exits with non-zero exit code
+	StepStopAndFail StepOnErrorType = "stopAndFail"
+	// StepContinue indicates to continue executing the rest of the steps irrespective of the container exit code
+	StepContinue StepOnErrorType = "continue"
+)
+
 // Sidecar has nearly the same data structure as Step, consisting of a Container and an optional Script, but does not have the ability to timeout.
 type Sidecar struct {
 	corev1.Container `json:",inline"`
@@ -43,7 +43,10 @@ func (ts *TaskSpec) Validate(ctx context.Context) (errs *apis.FieldError) {
 		errs = errs.Also(apis.ErrMissingField("steps"))
 	}
 	errs = errs.Also(ValidateVolumes(ts.Volumes).ViaField("volumes"))
-	errs = errs.Also(ValidateDeclaredWorkspaces(ts.Workspaces, ts.Steps, ts.StepTemplate).ViaField("workspaces"))
+	// The OnFailure steps are run in the same Pod as the Steps, so they share
+	// the same workspaces and variables.
+	allSteps := append(append([]Step{}, ts.Steps...), ts.OnFailure...)
+	errs = errs.Also(ValidateDeclaredWorkspaces(ts.Workspaces, allSteps, ts.StepTemplate).ViaField("workspaces"))
 	mergedSteps, err := MergeStepsWithStepTemplate(ts.StepTemplate, ts.Steps)
 	if err != nil {
 		errs = errs.Also(&apis.FieldError{
@@ -53,13 +56,54 @@ func (ts *TaskSpec) Validate(ctx context.Context) (errs *apis.FieldError) {
 		})
 	}
 
-	errs = errs.Also(validateSteps(mergedSteps).ViaField("steps"))
+	// The Steps and OnFailure steps share the same names, as their containers
+	// run in the same Pod.
+	stepNames := sets.NewString()
+	errs = errs.Also(validateSteps(mergedSteps, stepNames, len(ts.OnFailure) > 0).ViaField("steps"))
+	errs = errs.Also(validateStepSidecars(ts.Steps, ts.Sidecars))
+	mergedOnFailure, err := MergeStepsWithStepTemplate(ts.StepTemplate, ts.OnFailure)
+	if err != nil {
+		errs = errs.Also(&apis.FieldError{
+			Message: fmt.Sprintf("error merging step template and onFailure steps: %s", err),
+			Paths:   []string{"stepTemplate"},
+			Details: err.Error(),
+		})
+	}
+	errs = errs.Also(validateOnFailureSteps(mergedOnFailure, stepNames).ViaField("onFailure"))
 	errs = errs.Also(ts.Resources.Validate(ctx).ViaField("resources"))
 	errs = errs.Also(ValidateParameterTypes(ts.Params).ViaField("params"))
 	errs = errs.Also(ValidateParameterVariables(ts.Steps, ts.Params))
 	errs = errs.Also(ValidateResourcesVariables(ts.Steps, ts.Resources))
 	errs = errs.Also(validateTaskContextVariables(ts.Steps))
+	errs = errs.Also(validateParameterVariables("onFailure", ts.OnFailure, ts.Params))
+	errs = errs.Also(validateResourcesVariables("onFailure", ts.OnFailure, ts.Resources))
+	errs = errs.Also(validateStepsTaskContextVariables("onFailure", ts.OnFailure))
 	errs = errs.Also(validateResults(ctx, ts.Results).ViaField("results"))
+	if ts.ScriptPreamble != "" && !strings.HasPrefix(ts.ScriptPreamble, "#!") {
+		errs = errs.Also(apis.ErrInvalidValue("scriptPreamble must start with a shebang", "scriptPreamble"))
+	}
+	return errs
+}
+
+// validateStepSidecars validates that the Sidecars needed by the Steps are
+// declared by the Task, and that no Step declares the same Sidecar twice.
+func validateStepSidecars(steps []Step, sidecars []Sidecar) (errs *apis.FieldError) {
+	sidecarNames := sets.NewString()
+	for _, sc := range sidecars {
+		sidecarNames.Insert(sc.Name)
+	}
+	for i, s := range steps {
+		stepSidecarNames := sets.NewString()
+		for _, name := range s.Sidecars {
+			if !sidecarNames.Has(name) {
+				errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%q is not a Sidecar of the Task", name), "sidecars").ViaFieldIndex("steps", i))
+			}
+			if stepSidecarNames.Has(name) {
+				errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("Sidecar %q is declared more than once", name), "sidecars").ViaFieldIndex("steps", i))
+			}
+			stepSidecarNames.Insert(name)
+		}
+	}
 	return errs
 }
 
@@ -123,18 +167,46 @@ func ValidateVolumes(volumes []corev1.Volume) (errs *apis.FieldError) {
 	return errs
 }
 
-func validateSteps(steps []Step) (errs *apis.FieldError) {
+func validateSteps(steps []Step, names sets.String, hasOnFailure bool) (errs *apis.FieldError) {
 	// Task must not have duplicate step names.
-	names := sets.NewString()
 	for idx, s := range steps {
 		errs = errs.Also(validateStep(s, names).ViaIndex(idx))
+		// The containers of the OnFailure steps are named with a prefix, which
+		// the containers of the Steps must not collide with.
+		if hasOnFailure && strings.HasPrefix(s.Name, OnFailureStepNamePrefix) {
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("step name %q must not start with %q", s.Name, OnFailureStepNamePrefix), "name").ViaIndex(idx))
+		}
+	}
+	return errs
+}
+
+// validateOnFailureSteps validates the steps run when a step failed, which
+// can neither reference a StepAction nor wait for Sidecars.
+func validateOnFailureSteps(steps []Step, names sets.String) (errs *apis.FieldError) {
+	for idx, s := range steps {
+		errs = errs.Also(validateStep(s, names).ViaIndex(idx))
+	}
+	for idx, s := range steps {
+		if s.Ref != nil {
+			errs = errs.Also(apis.ErrDisallowedFields("ref").ViaIndex(idx))
+		}
+		if len(s.Sidecars) > 0 {
+			errs = errs.Also(apis.ErrDisallowedFields("sidecars").ViaIndex(idx))
+		}
 	}
 	return errs
 }
 
 func validateStep(s Step, names sets.String) (errs *apis.FieldError) {
-	if s.Image == "" {
-		errs = errs.Also(apis.ErrMissingField("Image"))
+	if s.Ref != nil {
+		errs = errs.Also(validateStepRef(s))
+	} else {
+		if s.Image == "" {
+			errs = errs.Also(apis.ErrMissingField("Image"))
+		}
+		if len(s.Params) > 0 {
+			errs = errs.Also(apis.ErrGeneric("params can only be used with ref", "params"))
+		}
 	}
 
 	if s.Script != "" {
@@ -166,6 +238,32 @@ func validateStep(s Step, names sets.String) (errs *apis.FieldError) {
 		}
 	}
 
+	if s.ScriptLanguage != "" {
+		if s.Script == "" {
+			errs = errs.Also(apis.ErrGeneric("scriptLanguage can only be used with script", "scriptLanguage"))
+		}
+		switch s.ScriptLanguage {
+		case ScriptLanguagePython, ScriptLanguageNode:
+		default:
+			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%q is not a valid scriptLanguage, must be one of %q or %q",
+				s.ScriptLanguage, ScriptLanguagePython, ScriptLanguageNode), "scriptLanguage"))
+		}
+	}
+
+	switch s.OnError {
+	case "", StepStopAndFail, StepContinue:
+	default:
+		errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%q is not a valid onError value, must be one of %q or %q",
+			s.OnError, StepContinue, StepStopAndFail), "onError"))
+	}
+
+	if s.StdoutConfig != nil {
+		errs = errs.Also(validateStepOutputConfig(s.StdoutConfig).ViaField("stdoutConfig"))
+	}
+	if s.StderrConfig != nil {
+		errs = errs.Also(validateStepOutputConfig(s.StderrConfig).ViaField("stderrConfig"))
+	}
+
 	for j, vm := range s.VolumeMounts {
 		if strings.HasPrefix(vm.MountPath, "/tekton/") &&
 			!strings.HasPrefix(vm.MountPath, "/tekton/home") {
@@ -212,6 +310,12 @@ func (p ParamSpec) ValidateType() *apis.FieldError {
 }
 
 func ValidateParameterVariables(steps []Step, params []ParamSpec) *apis.FieldError {
+	return validateParameterVariables("steps", steps, params)
+}
+
+// validateParameterVariables validates the params used by the steps in the
+// given field of the TaskSpec.
+func validateParameterVariables(field string, steps []Step, params []ParamSpec) *apis.FieldError {
 	parameterNames := sets.NewString()
 	arrayParameterNames := sets.NewString()
 
@@ -222,11 +326,15 @@ func ValidateParameterVariables(steps []Step, params []ParamSpec) *apis.FieldErr
 		}
 	}
 
-	errs := validateVariables(steps, "params", parameterNames)
-	return errs.Also(validateArrayUsage(steps, "params", arrayParameterNames))
+	errs := validateVariables(field, steps, "params", parameterNames)
+	return errs.Also(validateArrayUsage(field, steps, "params", arrayParameterNames))
 }
 
 func validateTaskContextVariables(steps []Step) *apis.FieldError {
+	return validateStepsTaskContextVariables("steps", steps)
+}
+
+func validateStepsTaskContextVariables(field string, steps []Step) *apis.FieldError {
 	taskRunContextNames := sets.NewString().Insert(
 		"name",
 		"namespace",
@@ -235,11 +343,17 @@ func validateTaskContextVariables(steps []Step) *apis.FieldError {
 	taskContextNames := sets.NewString().Insert(
 		"name",
 	)
-	errs := validateVariables(steps, "context\\.taskRun", taskRunContextNames)
-	return errs.Also(validateVariables(steps, "context\\.task", taskContextNames))
+	errs := validateVariables(field, steps, "context\\.taskRun", taskRunContextNames)
+	return errs.Also(validateVariables(field, steps, "context\\.task", taskContextNames))
 }
 
 func ValidateResourcesVariables(steps []Step, resources *TaskResources) *apis.FieldError {
+	return validateResourcesVariables("steps", steps, resources)
+}
+
+// validateResourcesVariables validates the resources used by the steps in
+// the given field of the TaskSpec.
+func validateResourcesVariables(field string, steps []Step, resources *TaskResources) *apis.FieldError {
 	if resources == nil {
 		return nil
 	}
@@ -254,12 +368,45 @@ func ValidateResourcesVariables(steps []Step, resources *TaskResources) *apis.Fi
 			resourceNames.Insert(r.Name)
 		}
 	}
-	return validateVariables(steps, "resources.(?:inputs|outputs)", resourceNames)
+	return validateVariables(field, steps, "resources.(?:inputs|outputs)", resourceNames)
 }
 
-func validateArrayUsage(steps []Step, prefix string, vars sets.String) (errs *apis.FieldError) {
+// validateStepRef makes sure a Step referencing a StepAction names it and does not
+// set the fields that the StepAction provides.
+func validateStepRef(s Step) (errs *apis.FieldError) {
+	if s.Ref.Name == "" {
+		errs = errs.Also(apis.ErrMissingField("ref.name"))
+	}
+	if s.Image != "" {
+		errs = errs.Also(apis.ErrGeneric("image cannot be used with ref", "image"))
+	}
+	if len(s.Command) > 0 {
+		errs = errs.Also(apis.ErrGeneric("command cannot be used with ref", "command"))
+	}
+	if len(s.Args) > 0 {
+		errs = errs.Also(apis.ErrGeneric("args cannot be used with ref", "args"))
+	}
+	if s.Script != "" {
+		errs = errs.Also(apis.ErrGeneric("script cannot be used with ref", "script"))
+	}
+	return errs
+}
+
+// validateStepOutputConfig makes sure the output stream of a Step is written to an absolute path,
+// which may be given by a variable such as $(results.<name>.path).
+func validateStepOutputConfig(c *StepOutputConfig) *apis.FieldError {
+	if c.Path == "" {
+		return apis.ErrMissingField("path")
+	}
+	if !strings.HasPrefix(c.Path, "$(") && !filepath.IsAbs(c.Path) {
+		return apis.ErrInvalidValue(fmt.Sprintf("%s must be an absolute path", c.Path), "path")
+	}
+	return nil
+}
+
+func validateArrayUsage(field string, steps []Step, prefix string, vars sets.String) (errs *apis.FieldError) {
 	for idx, step := range steps {
-		errs = errs.Also(validateStepArrayUsage(step, prefix, vars)).ViaFieldIndex("steps", idx)
+		errs = errs.Also(validateStepArrayUsage(step, prefix, vars)).ViaFieldIndex(field, idx)
 	}
 	return errs
 }
@@ -269,6 +416,12 @@ func validateStepArrayUsage(step Step, prefix string, vars sets.String) *apis.Fi
 	errs = errs.Also(validateTaskNoArrayReferenced(step.Image, prefix, vars).ViaField("image"))
 	errs = errs.Also(validateTaskNoArrayReferenced(step.WorkingDir, prefix, vars).ViaField("workingDir"))
 	errs = errs.Also(validateTaskNoArrayReferenced(step.Script, prefix, vars).ViaField("script"))
+	if step.StdoutConfig != nil {
+		errs = errs.Also(validateTaskNoArrayReferenced(step.StdoutConfig.Path, prefix, vars).ViaField("path").ViaField("stdoutConfig"))
+	}
+	if step.StderrConfig != nil {
+		errs = errs.Also(validateTaskNoArrayReferenced(step.StderrConfig.Path, prefix, vars).ViaField("path").ViaField("stderrConfig"))
+	}
 	for i, cmd := range step.Command {
 		errs = errs.Also(validateTaskArraysIsolated(cmd, prefix, vars).ViaFieldIndex("command", i))
 	}
@@ -279,6 +432,15 @@ func validateStepArrayUsage(step Step, prefix string, vars sets.String) *apis.Fi
 	for _, env := range step.Env {
 		errs = errs.Also(validateTaskNoArrayReferenced(env.Value, prefix, vars).ViaFieldKey("env", env.Name))
 	}
+	for _, p := range step.Params {
+		if p.Value.Type == ParamTypeArray {
+			for i, v := range p.Value.ArrayVal {
+				errs = errs.Also(validateTaskArraysIsolated(v, prefix, vars).ViaIndex(i).ViaFieldKey("params", p.Name))
+			}
+		} else {
+			errs = errs.Also(validateTaskNoArrayReferenced(p.Value.StringVal, prefix, vars).ViaFieldKey("params", p.Name))
+		}
+	}
 	for i, v := range step.VolumeMounts {
 		errs = errs.Also(validateTaskNoArrayReferenced(v.Name, prefix, vars).ViaField("name").ViaFieldIndex("volumeMount", i))
 		errs = errs.Also(validateTaskNoArrayReferenced(v.MountPath, prefix, vars).ViaField("mountPath").ViaFieldIndex("volumeMount", i))
@@ -287,9 +449,9 @@ func validateStepArrayUsage(step Step, prefix string, vars sets.String) *apis.Fi
 	return errs
 }
 
-func validateVariables(steps []Step, prefix string, vars sets.String) (errs *apis.FieldError) {
+func validateVariables(field string, steps []Step, prefix string, vars sets.String) (errs *apis.FieldError) {
 	for idx, step := range steps {
-		errs = errs.Also(validateStepVariables(step, prefix, vars).ViaFieldIndex("steps", idx))
+		errs = errs.Also(validateStepVariables(step, prefix, vars).ViaFieldIndex(field, idx))
 	}
 	return errs
 }
@@ -299,6 +461,12 @@ func validateStepVariables(step Step, prefix string, vars sets.String) *apis.Fie
 	errs = errs.Also(validateTaskVariable(step.Image, prefix, vars).ViaField("image"))
 	errs = errs.Also(validateTaskVariable(step.WorkingDir, prefix, vars).ViaField("workingDir"))
 	errs = errs.Also(validateTaskVariable(step.Script, prefix, vars).ViaField("script"))
+	if step.StdoutConfig != nil {
+		errs = errs.Also(validateTaskVariable(step.StdoutConfig.Path, prefix, vars).ViaField("path").ViaField("stdoutConfig"))
+	}
+	if step.StderrConfig != nil {
+		errs = errs.Also(validateTaskVariable(step.StderrConfig.Path, prefix, vars).ViaField("path").ViaField("stderrConfig"))
+	}
 	for i, cmd := range step.Command {
 		errs = errs.Also(validateTaskVariable(cmd, prefix, vars).ViaFieldIndex("command", i))
 	}
@@ -308,6 +476,12 @@ func validateStepVariables(step Step, prefix string, vars sets.String) *apis.Fie
 	for _, env := range step.Env {
 		errs = errs.Also(validateTaskVariable(env.Value, prefix, vars).ViaFieldKey("env", env.Name))
 	}
+	for _, p := range step.Params {
+		for i, v := range p.Value.ArrayVal {
+			errs = errs.Also(validateTaskVariable(v, prefix, vars).ViaIndex(i).ViaFieldKey("params", p.Name))
+		}
+		errs = errs.Also(validateTaskVariable(p.Value.StringVal, prefix, vars).ViaFieldKey("params", p.Name))
+	}
 	for i, v := range step.VolumeMounts {
 		errs = errs.Also(validateTaskVariable(v.Name, prefix, vars).ViaField("name").ViaFieldIndex("volumeMount", i))
 		errs = errs.Also(validateTaskVariable(v.MountPath, prefix, vars).ViaField("MountPath").ViaFieldIndex("volumeMount", i))
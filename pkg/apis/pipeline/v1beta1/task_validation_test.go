@@ -56,12 +56,15 @@ var invalidSteps = []v1beta1.Step{{Container: corev1.Container{
 
 func TestTaskSpecValidate(t *testing.T) {
 	type fields struct {
-		Params       []v1beta1.ParamSpec
-		Resources    *v1beta1.TaskResources
-		Steps        []v1beta1.Step
-		StepTemplate *corev1.Container
-		Workspaces   []v1beta1.WorkspaceDeclaration
-		Results      []v1beta1.TaskResult
+		Params         []v1beta1.ParamSpec
+		Resources      *v1beta1.TaskResources
+		Steps          []v1beta1.Step
+		StepTemplate   *corev1.Container
+		Workspaces     []v1beta1.WorkspaceDeclaration
+		Results        []v1beta1.TaskResult
+		ScriptPreamble string
+		Sidecars       []v1beta1.Sidecar
+		OnFailure      []v1beta1.Step
 	}
 	tests := []struct {
 		name   string
@@ -318,16 +321,126 @@ func TestTaskSpecValidate(t *testing.T) {
 				hello "$(context.taskRun.namespace)"`,
 			}},
 		},
+	}, {
+		name: "step with onError continue",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"arg"},
+				},
+				OnError: v1beta1.StepContinue,
+			}},
+		},
+	}, {
+		name: "step with stdout written to a result",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+					Args:  []string{"arg"},
+				},
+				StdoutConfig: &v1beta1.StepOutputConfig{Path: "$(results.output.path)"},
+				StderrConfig: &v1beta1.StepOutputConfig{Path: "/workspace/stderr"},
+			}},
+			Results: []v1beta1.TaskResult{{Name: "output"}},
+		},
+	}, {
+		name: "script preamble and script language",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+				},
+				Script: "set -o pipefail\nfalse | true",
+			}, {
+				Container: corev1.Container{
+					Image: "python",
+				},
+				Script:         "print(\"hello\")",
+				ScriptLanguage: v1beta1.ScriptLanguagePython,
+			}},
+			ScriptPreamble: "#!/bin/bash\nset -xe\n",
+		},
+	}, {
+		name: "steps needing sidecars",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+				},
+			}, {
+				Container: corev1.Container{
+					Image: "my-test-image",
+				},
+				Sidecars: []string{"database", "cache"},
+			}},
+			Sidecars: []v1beta1.Sidecar{{
+				Container: corev1.Container{Name: "database", Image: "postgres"},
+			}, {
+				Container: corev1.Container{Name: "cache", Image: "redis"},
+			}},
+		},
+	}, {
+		name: "onFailure steps",
+		fields: fields{
+			Params: []v1beta1.ParamSpec{{
+				Name: "logs",
+				Type: v1beta1.ParamTypeString,
+			}},
+			Steps: validSteps,
+			OnFailure: []v1beta1.Step{{
+				Container: corev1.Container{
+					Name:  "collect-logs",
+					Image: "busybox",
+				},
+				Script: "cat $(params.logs)",
+			}},
+		},
+	}, {
+		name: "step with the onFailure step prefix without onFailure steps",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Name:  "on-failure-foo",
+					Image: "busybox",
+				},
+			}},
+		},
+	}, {
+		name: "step referencing a StepAction",
+		fields: fields{
+			Params: []v1beta1.ParamSpec{{
+				Name: "repo",
+				Type: v1beta1.ParamTypeString,
+			}},
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Name: "clone",
+				},
+				Ref: &v1beta1.Ref{Name: "git-clone"},
+				Params: []v1beta1.Param{{
+					Name:  "url",
+					Value: *v1beta1.NewArrayOrString("$(params.repo)"),
+				}},
+			}},
+			StepTemplate: &corev1.Container{
+				Image: "my-image",
+			},
+		},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ts := &v1beta1.TaskSpec{
-				Params:       tt.fields.Params,
-				Resources:    tt.fields.Resources,
-				Steps:        tt.fields.Steps,
-				StepTemplate: tt.fields.StepTemplate,
-				Workspaces:   tt.fields.Workspaces,
-				Results:      tt.fields.Results,
+				Params:         tt.fields.Params,
+				Resources:      tt.fields.Resources,
+				Steps:          tt.fields.Steps,
+				StepTemplate:   tt.fields.StepTemplate,
+				Workspaces:     tt.fields.Workspaces,
+				Results:        tt.fields.Results,
+				ScriptPreamble: tt.fields.ScriptPreamble,
+				Sidecars:       tt.fields.Sidecars,
+				OnFailure:      tt.fields.OnFailure,
 			}
 			ctx := context.Background()
 			ts.SetDefaults(ctx)
@@ -340,13 +453,16 @@ func TestTaskSpecValidate(t *testing.T) {
 
 func TestTaskSpecValidateError(t *testing.T) {
 	type fields struct {
-		Params       []v1beta1.ParamSpec
-		Resources    *v1beta1.TaskResources
-		Steps        []v1beta1.Step
-		Volumes      []corev1.Volume
-		StepTemplate *corev1.Container
-		Workspaces   []v1beta1.WorkspaceDeclaration
-		Results      []v1beta1.TaskResult
+		Params         []v1beta1.ParamSpec
+		Resources      *v1beta1.TaskResources
+		Steps          []v1beta1.Step
+		Volumes        []corev1.Volume
+		StepTemplate   *corev1.Container
+		Workspaces     []v1beta1.WorkspaceDeclaration
+		Results        []v1beta1.TaskResult
+		ScriptPreamble string
+		Sidecars       []v1beta1.Sidecar
+		OnFailure      []v1beta1.Step
 	}
 	tests := []struct {
 		name          string
@@ -943,17 +1059,272 @@ func TestTaskSpecValidateError(t *testing.T) {
 			Message: "invalid value: -10s",
 			Paths:   []string{"steps[0].negative timeout"},
 		},
+	}, {
+		name: "invalid onError value",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+				},
+				OnError: "ignore",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: "ignore" is not a valid onError value, must be one of "continue" or "stopAndFail"`,
+			Paths:   []string{"steps[0].onError"},
+		},
+	}, {
+		name: "stdoutConfig without path",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+				},
+				StdoutConfig: &v1beta1.StepOutputConfig{},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `missing field(s)`,
+			Paths:   []string{"steps[0].stdoutConfig.path"},
+		},
+	}, {
+		name: "stderrConfig with relative path",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+				},
+				StderrConfig: &v1beta1.StepOutputConfig{Path: "stderr.txt"},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: stderr.txt must be an absolute path`,
+			Paths:   []string{"steps[0].stderrConfig.path"},
+		},
+	}, {
+		name: "script preamble without shebang",
+		fields: fields{
+			Steps:          validSteps,
+			ScriptPreamble: "set -xe",
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: scriptPreamble must start with a shebang`,
+			Paths:   []string{"scriptPreamble"},
+		},
+	}, {
+		name: "invalid script language",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "ruby",
+				},
+				Script:         "puts 'hello'",
+				ScriptLanguage: "ruby",
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: "ruby" is not a valid scriptLanguage, must be one of "python" or "node"`,
+			Paths:   []string{"steps[0].scriptLanguage"},
+		},
+	}, {
+		name: "step needing an undeclared sidecar",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+				},
+				Sidecars: []string{"cache"},
+			}},
+			Sidecars: []v1beta1.Sidecar{{
+				Container: corev1.Container{Name: "database", Image: "postgres"},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: "cache" is not a Sidecar of the Task`,
+			Paths:   []string{"steps[0].sidecars"},
+		},
+	}, {
+		name: "step needing the same sidecar twice",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+				},
+				Sidecars: []string{"database", "database"},
+			}},
+			Sidecars: []v1beta1.Sidecar{{
+				Container: corev1.Container{Name: "database", Image: "postgres"},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: Sidecar "database" is declared more than once`,
+			Paths:   []string{"steps[0].sidecars"},
+		},
+	}, {
+		name: "onFailure step without image",
+		fields: fields{
+			Steps: validSteps,
+			OnFailure: []v1beta1.Step{{
+				Container: corev1.Container{
+					Name: "collect-logs",
+				},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `missing field(s)`,
+			Paths:   []string{"onFailure[0].Image"},
+		},
+	}, {
+		name: "onFailure step needing a sidecar",
+		fields: fields{
+			Steps: validSteps,
+			OnFailure: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "busybox",
+				},
+				Sidecars: []string{"database"},
+			}},
+			Sidecars: []v1beta1.Sidecar{{
+				Container: corev1.Container{Name: "database", Image: "postgres"},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `must not set the field(s)`,
+			Paths:   []string{"onFailure[0].sidecars"},
+		},
+	}, {
+		name: "onFailure step using an undeclared param",
+		fields: fields{
+			Steps: validSteps,
+			OnFailure: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "busybox",
+					Args:  []string{"$(params.logs)"},
+				},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent variable in "$(params.logs)"`,
+			Paths:   []string{"onFailure[0].args[0]"},
+		},
+	}, {
+		name: "onFailure step with the name of a step",
+		fields: fields{
+			Steps: validSteps,
+			OnFailure: []v1beta1.Step{{
+				Container: corev1.Container{
+					Name:  "mystep",
+					Image: "busybox",
+				},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: mystep`,
+			Paths:   []string{"onFailure[0].name"},
+		},
+	}, {
+		name: "step with the onFailure step prefix",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Name:  "on-failure-foo",
+					Image: "busybox",
+				},
+			}},
+			OnFailure: []v1beta1.Step{{
+				Container: corev1.Container{
+					Name:  "foo",
+					Image: "busybox",
+				},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `invalid value: step name "on-failure-foo" must not start with "on-failure-"`,
+			Paths:   []string{"steps[0].name"},
+		},
+	}, {
+		name: "script language without script",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "node",
+				},
+				ScriptLanguage: v1beta1.ScriptLanguageNode,
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `scriptLanguage can only be used with script`,
+			Paths:   []string{"steps[0].scriptLanguage"},
+		},
+	}, {
+		name: "step ref without name",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Ref: &v1beta1.Ref{},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `missing field(s)`,
+			Paths:   []string{"steps[0].ref.name"},
+		},
+	}, {
+		name: "step ref with script",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Script: "echo hello",
+				Ref:    &v1beta1.Ref{Name: "git-clone"},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `script cannot be used with ref`,
+			Paths:   []string{"steps[0].script"},
+		},
+	}, {
+		name: "step params without ref",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{
+					Image: "my-image",
+				},
+				Params: []v1beta1.Param{{
+					Name:  "url",
+					Value: *v1beta1.NewArrayOrString("https://example.com"),
+				}},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `params can only be used with ref`,
+			Paths:   []string{"steps[0].params"},
+		},
+	}, {
+		name: "step ref binding an undeclared param",
+		fields: fields{
+			Steps: []v1beta1.Step{{
+				Ref: &v1beta1.Ref{Name: "git-clone"},
+				Params: []v1beta1.Param{{
+					Name:  "url",
+					Value: *v1beta1.NewArrayOrString("$(params.repo)"),
+				}},
+			}},
+		},
+		expectedError: apis.FieldError{
+			Message: `non-existent variable in "$(params.repo)"`,
+			Paths:   []string{"steps[0].params[url]"},
+		},
 	}}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			ts := &v1beta1.TaskSpec{
-				Params:       tt.fields.Params,
-				Resources:    tt.fields.Resources,
-				Steps:        tt.fields.Steps,
-				Volumes:      tt.fields.Volumes,
-				StepTemplate: tt.fields.StepTemplate,
-				Workspaces:   tt.fields.Workspaces,
-				Results:      tt.fields.Results,
+				Params:         tt.fields.Params,
+				Resources:      tt.fields.Resources,
+				Steps:          tt.fields.Steps,
+				Volumes:        tt.fields.Volumes,
+				StepTemplate:   tt.fields.StepTemplate,
+				Workspaces:     tt.fields.Workspaces,
+				Results:        tt.fields.Results,
+				ScriptPreamble: tt.fields.ScriptPreamble,
+				Sidecars:       tt.fields.Sidecars,
+				OnFailure:      tt.fields.OnFailure,
 			}
 			ctx := context.Background()
 			ts.SetDefaults(ctx)
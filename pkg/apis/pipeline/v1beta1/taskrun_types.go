@@ -64,6 +64,101 @@ type TaskRunSpec struct {
 	// Workspaces is a list of WorkspaceBindings from volumes to workspaces.
 	// +optional
 	Workspaces []WorkspaceBinding `json:"workspaces,omitempty"`
+	// Debug holds the debugging configuration of the TaskRun
+	// +optional
+	Debug *TaskRunDebug `json:"debug,omitempty"`
+	// ComputeResources overrides the compute resources of all the Steps
+	// of the Task, it cannot be set together with the resources of
+	// StepOverrides. The limits are only set on the first Step, so that
+	// they are not summed over all the Steps of the Pod.
+	// +optional
+	ComputeResources *corev1.ResourceRequirements `json:"computeResources,omitempty"`
+	// StepOverrides overrides the values of the Steps of the Task, by name.
+	// +optional
+	StepOverrides []TaskRunStepOverride `json:"stepOverrides,omitempty"`
+	// SidecarOverrides overrides the values of the Sidecars of the Task,
+	// by name.
+	// +optional
+	SidecarOverrides []TaskRunSidecarOverride `json:"sidecarOverrides,omitempty"`
+	// StepPods lists the Steps of the Task running in their own Pod. The
+	// Pods run one after the other and hand off files through Workspaces.
+	// +optional
+	StepPods []TaskRunStepPod `json:"stepPods,omitempty"`
+}
+
+// TaskRunStepPod is used to run a Step of the corresponding Task in its own
+// Pod.
+type TaskRunStepPod struct {
+	// Name is the name of the Step running in its own Pod.
+	Name string `json:"name"`
+	// PodTemplate is used for the Pod of the Step instead of the PodTemplate
+	// of the TaskRun.
+	// +optional
+	PodTemplate *PodTemplate `json:"podTemplate,omitempty"`
+}
+
+// TaskRunStepOverride is used to override the values of a Step in the
+// corresponding Task. It is merged with the Step the same way the Step is
+// merged with the StepTemplate.
+type TaskRunStepOverride struct {
+	// Name is the name of the Step to override.
+	Name string `json:"name"`
+	// Resources are merged with the compute resources of the Step.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Image replaces the image of the Step.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Env is merged with the environment variables of the Step, by name.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// SecurityContext is merged with the security context of the Step.
+	// +optional
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+}
+
+// TaskRunSidecarOverride is used to override the values of a Sidecar in
+// the corresponding Task.
+type TaskRunSidecarOverride struct {
+	// Name is the name of the Sidecar to override.
+	Name string `json:"name"`
+	// Resources are merged with the compute resources of the Sidecar.
+	// +optional
+	Resources corev1.ResourceRequirements `json:"resources,omitempty"`
+	// Image replaces the image of the Sidecar.
+	// +optional
+	Image string `json:"image,omitempty"`
+	// Env is merged with the environment variables of the Sidecar, by name.
+	// +optional
+	Env []corev1.EnvVar `json:"env,omitempty"`
+	// SecurityContext is merged with the security context of the Sidecar.
+	// +optional
+	SecurityContext *corev1.SecurityContext `json:"securityContext,omitempty"`
+}
+
+// TaskRunDebug defines the breakpoints of a TaskRun
+type TaskRunDebug struct {
+	// Breakpoint is the list of events at which the TaskRun pauses to let
+	// the user inspect its environment, only "onFailure" is supported.
+	// +optional
+	Breakpoint []string `json:"breakpoint,omitempty"`
+}
+
+// BreakpointOnFailure is the breakpoint which keeps the container of a failed
+// Step running until the user decides to continue or abort the TaskRun.
+const BreakpointOnFailure = "onFailure"
+
+// NeedsBreakpointOnFailure returns true if the TaskRun pauses when one of its Steps fails.
+func (d *TaskRunDebug) NeedsBreakpointOnFailure() bool {
+	if d == nil {
+		return false
+	}
+	for _, b := range d.Breakpoint {
+		if b == BreakpointOnFailure {
+			return true
+		}
+	}
+	return false
 }
 
 // TaskRunSpecStatus defines the taskrun spec status the user can provide
@@ -117,6 +212,30 @@ const (
 	TaskRunReasonCancelled TaskRunReason = "TaskRunCancelled"
 	// TaskRunReasonTimedOut is the reason set when the Taskrun has timed out
 	TaskRunReasonTimedOut TaskRunReason = "TaskRunTimeout"
+	// TaskRunReasonPausedAtBreakpoint is the reason set when a Step of the TaskRun failed
+	// and is waiting at a breakpoint for the user to continue or abort the TaskRun
+	TaskRunReasonPausedAtBreakpoint TaskRunReason = "PausedAtBreakpoint"
+	// TaskRunReasonSidecarFailed is the reason set when a Sidecar of the TaskRun
+	// exited with a non-zero exit code
+	TaskRunReasonSidecarFailed TaskRunReason = "SidecarFailed"
+	// TaskRunReasonImagePullFailed is the reason set when the image of a Step
+	// or Sidecar of the TaskRun could not be pulled
+	TaskRunReasonImagePullFailed TaskRunReason = "ImagePullFailed"
+	// TaskRunReasonOOMKilled is the reason set when a Step of the TaskRun was
+	// killed because it ran out of memory
+	TaskRunReasonOOMKilled TaskRunReason = "OOMKilled"
+	// TaskRunReasonEvicted is the reason set when the Pod of the TaskRun was
+	// evicted from its node
+	TaskRunReasonEvicted TaskRunReason = "Evicted"
+	// TaskRunReasonNodeLost is the reason set when the node running the Pod of
+	// the TaskRun became unreachable
+	TaskRunReasonNodeLost TaskRunReason = "NodeLost"
+	// TaskRunReasonStepTimedOut is the reason set when a Step of the TaskRun
+	// exceeded its own timeout
+	TaskRunReasonStepTimedOut TaskRunReason = "StepTimeout"
+	// TaskRunReasonResultsTooLarge is the reason set when the Results of a Step
+	// of the TaskRun exceeded the size of its termination message
+	TaskRunReasonResultsTooLarge TaskRunReason = "ResultsTooLarge"
 )
 
 func (t TaskRunReason) String() string {
@@ -166,6 +285,12 @@ type TaskRunStatusFields struct {
 	// PodName is the name of the pod responsible for executing this task's steps.
 	PodName string `json:"podName"`
 
+	// PodNames are the names of the pods of the TaskRun, in the order in
+	// which they ran, when some steps run in their own pod. PodName is the
+	// last one.
+	// +optional
+	PodNames []string `json:"podNames,omitempty"`
+
 	// StartTime is the time the build is actually started.
 	// +optional
 	StartTime *metav1.Time `json:"startTime,omitempty"`
@@ -174,12 +299,22 @@ type TaskRunStatusFields struct {
 	// +optional
 	CompletionTime *metav1.Time `json:"completionTime,omitempty"`
 
+	// QueuedTime is the time at which the TaskRun started waiting for a
+	// ResourceQuota to admit its pod.
+	// +optional
+	QueuedTime *metav1.Time `json:"queuedTime,omitempty"`
+
 	// Steps describes the state of each build step container.
 	// +optional
 	Steps []StepState `json:"steps,omitempty"`
 
+	// OnFailureSteps describes the state of each step container run when a
+	// step failed.
+	// +optional
+	OnFailureSteps []StepState `json:"onFailureSteps,omitempty"`
+
 	// CloudEvents describe the state of each cloud event requested via a
-	// CloudEventResource.
+	// CloudEventResource, or sent to the default cloud events sink.
 	// +optional
 	CloudEvents []CloudEventDelivery `json:"cloudEvents,omitempty"`
 
@@ -203,6 +338,46 @@ type TaskRunStatusFields struct {
 
 	// TaskSpec contains the Spec from the dereferenced Task definition used to instantiate this TaskRun.
 	TaskSpec *TaskSpec `json:"taskSpec,omitempty"`
+
+	// Failure classifies why the TaskRun failed, it is only set once the
+	// TaskRun failed.
+	// +optional
+	Failure *TaskRunFailure `json:"failure,omitempty"`
+
+	// Provenance records where the signed provenance of the TaskRun was
+	// stored, or why it could not be, once the TaskRun succeeded.
+	// +optional
+	Provenance *TaskRunProvenance `json:"provenance,omitempty"`
+}
+
+// TaskRunProvenance records the outcome of storing the signed provenance of a
+// TaskRun, so that it is stored only once.
+type TaskRunProvenance struct {
+	// Location is the annotation of the TaskRun holding the provenance, or the
+	// reference by digest of the image the provenance was pushed to.
+	// +optional
+	Location string `json:"location,omitempty"`
+	// Error is why the provenance could not be stored, it is not attempted
+	// again.
+	// +optional
+	Error string `json:"error,omitempty"`
+}
+
+// TaskRunFailure is the machine-readable classification of the failure of a
+// TaskRun, so that automation can decide whether to retry it.
+type TaskRunFailure struct {
+	// Reason is the stable code of the failure, the same as the reason of
+	// the Succeeded condition.
+	Reason TaskRunReason `json:"reason"`
+	// Step is the name of the Step the failure originates from, if any.
+	// +optional
+	Step string `json:"step,omitempty"`
+	// Sidecar is the name of the Sidecar the failure originates from, if any.
+	// +optional
+	Sidecar string `json:"sidecar,omitempty"`
+	// ExitCode is the exit code of the failed Step or Sidecar.
+	// +optional
+	ExitCode int32 `json:"exitCode,omitempty"`
 }
 
 // TaskRunResult used to describe the results of a task
@@ -261,6 +436,10 @@ type StepState struct {
 	Name                  string `json:"name,omitempty"`
 	ContainerName         string `json:"container,omitempty"`
 	ImageID               string `json:"imageID,omitempty"`
+	// LogLocation is where the logs of the step were archived, if log
+	// archiving is configured.
+	// +optional
+	LogLocation string `json:"logLocation,omitempty"`
 }
 
 // SidecarState reports the results of running a sidecar in a Task.
@@ -275,10 +454,42 @@ type SidecarState struct {
 // delivery.
 type CloudEventDelivery struct {
 	// Target points to an addressable
-	Target string                  `json:"target,omitempty"`
+	Target string `json:"target,omitempty"`
+	// Type is the type of the cloud event sent to the default cloud events
+	// sink. It is empty for the cloud events requested via a
+	// CloudEventResource, which are sent once the TaskRun is done.
+	// +optional
+	Type string `json:"type,omitempty"`
+	// ID is the ID of the cloud event, kept when its delivery is retried so
+	// that the sink can tell the duplicates apart.
+	// +optional
+	ID string `json:"id,omitempty"`
+	// PipelineTask identifies the PipelineTask of the PipelineRun the cloud
+	// event is about, for the events about a PipelineTask.
+	// +optional
+	PipelineTask *CloudEventPipelineTask `json:"pipelineTask,omitempty"`
+	// Data is the JSON data of the cloud event, as it was when the event was
+	// queued. It is cleared once the event is delivered, or once its
+	// delivery is not retried anymore.
+	// +optional
+	Data   string                  `json:"data,omitempty"`
 	Status CloudEventDeliveryState `json:"status,omitempty"`
 }
 
+// CloudEventPipelineTask identifies the PipelineTask of a PipelineRun a cloud
+// event is about.
+type CloudEventPipelineTask struct {
+	// Name is the name of the PipelineTask.
+	Name string `json:"name"`
+	// TaskRunName is the name of the TaskRun of the PipelineTask, if any.
+	// +optional
+	TaskRunName string `json:"taskRunName,omitempty"`
+	// RetryCount is the number of times the TaskRun of the PipelineTask was
+	// retried.
+	// +optional
+	RetryCount int `json:"retryCount,omitempty"`
+}
+
 // CloudEventCondition is a string that represents the condition of the event.
 type CloudEventCondition string
 
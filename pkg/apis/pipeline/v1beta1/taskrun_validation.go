@@ -22,6 +22,7 @@ import (
 	"strings"
 
 	"github.com/tektoncd/pipeline/pkg/apis/validate"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/apis"
 )
@@ -66,7 +67,79 @@ func (ts *TaskRunSpec) Validate(ctx context.Context) (errs *apis.FieldError) {
 			errs = errs.Also(apis.ErrInvalidValue(fmt.Sprintf("%s should be >= 0", ts.Timeout.Duration.String()), "timeout"))
 		}
 	}
+	if ts.Debug != nil {
+		errs = errs.Also(validateDebug(ts.Debug).ViaField("debug"))
+	}
+	errs = errs.Also(validateOverrides(ts.ComputeResources, ts.StepOverrides, ts.SidecarOverrides))
+	errs = errs.Also(validateStepPods(ts.StepPods, ts.Workspaces))
+
+	return errs
+}
+
+// validateStepPods makes sure Steps run in their own Pod once, and that the
+// Workspaces can be shared by the Pods of the TaskRun.
+func validateStepPods(stepPods []TaskRunStepPod, wb []WorkspaceBinding) (errs *apis.FieldError) {
+	if len(stepPods) == 0 {
+		return nil
+	}
+	seen := sets.NewString()
+	for idx, sp := range stepPods {
+		if sp.Name == "" {
+			errs = errs.Also(apis.ErrMissingField("name").ViaFieldIndex("stepPods", idx))
+			continue
+		}
+		if seen.Has(sp.Name) {
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("Step %q runs in its own Pod more than once", sp.Name), "name").ViaFieldIndex("stepPods", idx))
+		}
+		seen.Insert(sp.Name)
+	}
+	for idx, w := range wb {
+		if w.EmptyDir != nil {
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("workspace %q cannot be shared by the Pods of the Steps", w.Name), "emptyDir").ViaFieldIndex("workspaces", idx))
+		}
+	}
+	return errs
+}
+
+// validateOverrides makes sure the compute resources are overridden either
+// for the whole Task or for Steps, and that Steps and Sidecars are overridden
+// once.
+func validateOverrides(computeResources *corev1.ResourceRequirements, stepOverrides []TaskRunStepOverride, sidecarOverrides []TaskRunSidecarOverride) (errs *apis.FieldError) {
+	seen := sets.NewString()
+	for idx, o := range stepOverrides {
+		if computeResources != nil && (o.Resources.Requests != nil || o.Resources.Limits != nil) {
+			errs = errs.Also(apis.ErrMultipleOneOf("computeResources", fmt.Sprintf("stepOverrides[%d].resources", idx)))
+		}
+		if o.Name == "" {
+			errs = errs.Also(apis.ErrMissingField("name").ViaFieldIndex("stepOverrides", idx))
+			continue
+		}
+		if seen.Has(o.Name) {
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("Step %q is overridden more than once", o.Name), "name").ViaFieldIndex("stepOverrides", idx))
+		}
+		seen.Insert(o.Name)
+	}
+	seen = sets.NewString()
+	for idx, o := range sidecarOverrides {
+		if o.Name == "" {
+			errs = errs.Also(apis.ErrMissingField("name").ViaFieldIndex("sidecarOverrides", idx))
+			continue
+		}
+		if seen.Has(o.Name) {
+			errs = errs.Also(apis.ErrGeneric(fmt.Sprintf("Sidecar %q is overridden more than once", o.Name), "name").ViaFieldIndex("sidecarOverrides", idx))
+		}
+		seen.Insert(o.Name)
+	}
+	return errs
+}
 
+// validateDebug makes sure only supported breakpoints are requested.
+func validateDebug(db *TaskRunDebug) (errs *apis.FieldError) {
+	for idx, b := range db.Breakpoint {
+		if b != BreakpointOnFailure {
+			errs = errs.Also(apis.ErrInvalidArrayValue(fmt.Sprintf("%s is not a valid breakpoint, must be %s", b, BreakpointOnFailure), "breakpoint", idx))
+		}
+	}
 	return errs
 }
 
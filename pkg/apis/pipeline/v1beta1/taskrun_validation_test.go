@@ -26,6 +26,7 @@ import (
 	resource "github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1"
 	"github.com/tektoncd/pipeline/test/diff"
 	corev1 "k8s.io/api/core/v1"
+	k8sresource "k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
 )
@@ -201,6 +202,90 @@ func TestTaskRunSpec_Invalidate(t *testing.T) {
 			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
 		},
 		wantErr: apis.ErrMultipleOneOf("params[myname].name"),
+	}, {
+		name: "invalid breakpoint",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			Debug: &v1beta1.TaskRunDebug{
+				Breakpoint: []string{"onSuccess"},
+			},
+		},
+		wantErr: apis.ErrInvalidArrayValue("onSuccess is not a valid breakpoint, must be onFailure", "debug.breakpoint", 0),
+	}, {
+		name: "computeResources and stepOverrides together",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			ComputeResources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: k8sresource.MustParse("1")},
+			},
+			StepOverrides: []v1beta1.TaskRunStepOverride{{
+				Name: "build",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: k8sresource.MustParse("8")},
+				},
+			}},
+		},
+		wantErr: apis.ErrMultipleOneOf("computeResources", "stepOverrides[0].resources"),
+	}, {
+		name: "stepOverrides without a name",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef:       &v1beta1.TaskRef{Name: "mytask"},
+			StepOverrides: []v1beta1.TaskRunStepOverride{{}},
+		},
+		wantErr: apis.ErrMissingField("stepOverrides[0].name"),
+	}, {
+		name: "step overridden more than once",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			StepOverrides: []v1beta1.TaskRunStepOverride{{
+				Name: "build",
+			}, {
+				Name: "build",
+			}},
+		},
+		wantErr: apis.ErrGeneric(`Step "build" is overridden more than once`, "stepOverrides[1].name"),
+	}, {
+		name: "sidecarOverrides without a name",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef:          &v1beta1.TaskRef{Name: "mytask"},
+			SidecarOverrides: []v1beta1.TaskRunSidecarOverride{{}},
+		},
+		wantErr: apis.ErrMissingField("sidecarOverrides[0].name"),
+	}, {
+		name: "sidecar overridden more than once",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			SidecarOverrides: []v1beta1.TaskRunSidecarOverride{{
+				Name: "registry",
+			}, {
+				Name: "registry",
+			}},
+		},
+		wantErr: apis.ErrGeneric(`Sidecar "registry" is overridden more than once`, "sidecarOverrides[1].name"),
+	}, {
+		name: "step running in its own pod more than once",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			StepPods: []v1beta1.TaskRunStepPod{{
+				Name: "test",
+			}, {
+				Name: "test",
+			}},
+		},
+		wantErr: apis.ErrGeneric(`Step "test" runs in its own Pod more than once`, "stepPods[1].name"),
+	}, {
+		name: "stepPods with an emptyDir workspace",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			StepPods: []v1beta1.TaskRunStepPod{{
+				Name: "test",
+			}},
+			Workspaces: []v1beta1.WorkspaceBinding{{
+				Name:     "source",
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			}},
+		},
+		wantErr: apis.ErrGeneric(`workspace "source" cannot be shared by the Pods of the Steps`, "workspaces[0].emptyDir"),
 	}}
 	for _, ts := range tests {
 		t.Run(ts.name, func(t *testing.T) {
@@ -265,6 +350,71 @@ func TestTaskRunSpec_Validate(t *testing.T) {
 				}},
 			},
 		},
+	}, {
+		name: "breakpoint on failure",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			Debug: &v1beta1.TaskRunDebug{
+				Breakpoint: []string{v1beta1.BreakpointOnFailure},
+			},
+		},
+	}, {
+		name: "compute resources",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			ComputeResources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: k8sresource.MustParse("500m")},
+			},
+		},
+	}, {
+		name: "compute resources and step overrides without resources",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			ComputeResources: &corev1.ResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceCPU: k8sresource.MustParse("500m")},
+			},
+			StepOverrides: []v1beta1.TaskRunStepOverride{{
+				Name:  "build",
+				Image: "golang:1.16",
+			}},
+		},
+	}, {
+		name: "sidecar overrides",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			SidecarOverrides: []v1beta1.TaskRunSidecarOverride{{
+				Name: "registry",
+				Env:  []corev1.EnvVar{{Name: "REGISTRY_LOG_LEVEL", Value: "debug"}},
+			}},
+		},
+	}, {
+		name: "step pods",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			StepPods: []v1beta1.TaskRunStepPod{{
+				Name: "test",
+				PodTemplate: &v1beta1.PodTemplate{
+					NodeSelector: map[string]string{"accelerator": "nvidia-tesla-t4"},
+				},
+			}},
+			Workspaces: []v1beta1.WorkspaceBinding{{
+				Name: "source",
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: "source",
+				},
+			}},
+		},
+	}, {
+		name: "step overrides",
+		spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "mytask"},
+			StepOverrides: []v1beta1.TaskRunStepOverride{{
+				Name: "build",
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{corev1.ResourceCPU: k8sresource.MustParse("8")},
+				},
+			}},
+		},
 	}}
 	for _, ts := range tests {
 		t.Run(ts.name, func(t *testing.T) {
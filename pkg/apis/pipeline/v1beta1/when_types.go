@@ -93,6 +93,17 @@ func (wes WhenExpressions) AllowsExecution() bool {
 	return true
 }
 
+// FirstFalse returns the first When Expression that evaluates to False, which prevents the
+// guarded Task from being executed, or nil if they all evaluate to True.
+func (wes WhenExpressions) FirstFalse() *WhenExpression {
+	for i := range wes {
+		if !wes[i].isTrue() {
+			return &wes[i]
+		}
+	}
+	return nil
+}
+
 // HaveVariables indicates whether When Expressions contains variables, such as Parameters
 // or Results in the Inputs or Values.
 func (wes WhenExpressions) HaveVariables() bool {
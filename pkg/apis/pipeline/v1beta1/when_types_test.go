@@ -88,6 +88,54 @@ func TestAllowsExecution(t *testing.T) {
 	}
 }
 
+func TestFirstFalse(t *testing.T) {
+	tests := []struct {
+		name            string
+		whenExpressions WhenExpressions
+		expected        *WhenExpression
+	}{{
+		name: "all true",
+		whenExpressions: WhenExpressions{
+			{
+				Input:    "foo",
+				Operator: selection.In,
+				Values:   []string{"foo", "bar"},
+			},
+		},
+		expected: nil,
+	}, {
+		name: "multiple expressions - false",
+		whenExpressions: WhenExpressions{
+			{
+				Input:    "foobar",
+				Operator: selection.In,
+				Values:   []string{"foobar"},
+			}, {
+				Input:    "foo",
+				Operator: selection.In,
+				Values:   []string{"bar"},
+			}, {
+				Input:    "foo",
+				Operator: selection.NotIn,
+				Values:   []string{"foo"},
+			},
+		},
+		expected: &WhenExpression{
+			Input:    "foo",
+			Operator: selection.In,
+			Values:   []string{"bar"},
+		},
+	}}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.whenExpressions.FirstFalse()
+			if d := cmp.Diff(tc.expected, got); d != "" {
+				t.Errorf("Error evaluating FirstFalse() for When Expressions in test case %s: %s", tc.name, diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
 func TestHaveVariables(t *testing.T) {
 	tests := []struct {
 		name            string
@@ -68,6 +68,11 @@ func (in *CannotConvertError) DeepCopy() *CannotConvertError {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *CloudEventDelivery) DeepCopyInto(out *CloudEventDelivery) {
 	*out = *in
+	if in.PipelineTask != nil {
+		in, out := &in.PipelineTask, &out.PipelineTask
+		*out = new(CloudEventPipelineTask)
+		**out = **in
+	}
 	in.Status.DeepCopyInto(&out.Status)
 	return
 }
@@ -102,6 +107,22 @@ func (in *CloudEventDeliveryState) DeepCopy() *CloudEventDeliveryState {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CloudEventPipelineTask) DeepCopyInto(out *CloudEventPipelineTask) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CloudEventPipelineTask.
+func (in *CloudEventPipelineTask) DeepCopy() *CloudEventPipelineTask {
+	if in == nil {
+		return nil
+	}
+	out := new(CloudEventPipelineTask)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterTask) DeepCopyInto(out *ClusterTask) {
 	*out = *in
@@ -701,6 +722,15 @@ func (in *PipelineRunStatusFields) DeepCopyInto(out *PipelineRunStatusFields) {
 		in, out := &in.CompletionTime, &out.CompletionTime
 		*out = (*in).DeepCopy()
 	}
+	if in.PausedTime != nil {
+		in, out := &in.PausedTime, &out.PausedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.PausedDuration != nil {
+		in, out := &in.PausedDuration, &out.PausedDuration
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.TaskRuns != nil {
 		in, out := &in.TaskRuns, &out.TaskRuns
 		*out = make(map[string]*PipelineRunTaskRunStatus, len(*in))
@@ -733,6 +763,13 @@ func (in *PipelineRunStatusFields) DeepCopyInto(out *PipelineRunStatusFields) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.CloudEvents != nil {
+		in, out := &in.CloudEvents, &out.CloudEvents
+		*out = make([]CloudEventDelivery, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1095,6 +1132,25 @@ func (in *PipelineTaskRunSpec) DeepCopyInto(out *PipelineTaskRunSpec) {
 		*out = new(pod.Template)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ComputeResources != nil {
+		in, out := &in.ComputeResources, &out.ComputeResources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StepOverrides != nil {
+		in, out := &in.StepOverrides, &out.StepOverrides
+		*out = make([]TaskRunStepOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SidecarOverrides != nil {
+		in, out := &in.SidecarOverrides, &out.SidecarOverrides
+		*out = make([]TaskRunSidecarOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1124,6 +1180,22 @@ func (in *PipelineWorkspaceDeclaration) DeepCopy() *PipelineWorkspaceDeclaration
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Ref) DeepCopyInto(out *Ref) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Ref.
+func (in *Ref) DeepCopy() *Ref {
+	if in == nil {
+		return nil
+	}
+	out := new(Ref)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResultRef) DeepCopyInto(out *ResultRef) {
 	*out = *in
@@ -1206,6 +1278,33 @@ func (in *Step) DeepCopyInto(out *Step) {
 		*out = new(metav1.Duration)
 		**out = **in
 	}
+	if in.StdoutConfig != nil {
+		in, out := &in.StdoutConfig, &out.StdoutConfig
+		*out = new(StepOutputConfig)
+		**out = **in
+	}
+	if in.StderrConfig != nil {
+		in, out := &in.StderrConfig, &out.StderrConfig
+		*out = new(StepOutputConfig)
+		**out = **in
+	}
+	if in.Ref != nil {
+		in, out := &in.Ref, &out.Ref
+		*out = new(Ref)
+		**out = **in
+	}
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make([]Param, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Sidecars != nil {
+		in, out := &in.Sidecars, &out.Sidecars
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	return
 }
 
@@ -1219,6 +1318,127 @@ func (in *Step) DeepCopy() *Step {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepAction) DeepCopyInto(out *StepAction) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepAction.
+func (in *StepAction) DeepCopy() *StepAction {
+	if in == nil {
+		return nil
+	}
+	out := new(StepAction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StepAction) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepActionList) DeepCopyInto(out *StepActionList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]StepAction, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepActionList.
+func (in *StepActionList) DeepCopy() *StepActionList {
+	if in == nil {
+		return nil
+	}
+	out := new(StepActionList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *StepActionList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepActionSpec) DeepCopyInto(out *StepActionSpec) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Params != nil {
+		in, out := &in.Params, &out.Params
+		*out = make([]ParamSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Results != nil {
+		in, out := &in.Results, &out.Results
+		*out = make([]TaskResult, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepActionSpec.
+func (in *StepActionSpec) DeepCopy() *StepActionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(StepActionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StepOutputConfig) DeepCopyInto(out *StepOutputConfig) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StepOutputConfig.
+func (in *StepOutputConfig) DeepCopy() *StepOutputConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(StepOutputConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *StepState) DeepCopyInto(out *StepState) {
 	*out = *in
@@ -1421,6 +1641,43 @@ func (in *TaskRun) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskRunDebug) DeepCopyInto(out *TaskRunDebug) {
+	*out = *in
+	if in.Breakpoint != nil {
+		in, out := &in.Breakpoint, &out.Breakpoint
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskRunDebug.
+func (in *TaskRunDebug) DeepCopy() *TaskRunDebug {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskRunDebug)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskRunFailure) DeepCopyInto(out *TaskRunFailure) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskRunFailure.
+func (in *TaskRunFailure) DeepCopy() *TaskRunFailure {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskRunFailure)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaskRunInputs) DeepCopyInto(out *TaskRunInputs) {
 	*out = *in
@@ -1507,6 +1764,22 @@ func (in *TaskRunOutputs) DeepCopy() *TaskRunOutputs {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskRunProvenance) DeepCopyInto(out *TaskRunProvenance) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskRunProvenance.
+func (in *TaskRunProvenance) DeepCopy() *TaskRunProvenance {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskRunProvenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaskRunResources) DeepCopyInto(out *TaskRunResources) {
 	*out = *in
@@ -1553,6 +1826,35 @@ func (in *TaskRunResult) DeepCopy() *TaskRunResult {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskRunSidecarOverride) DeepCopyInto(out *TaskRunSidecarOverride) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(v1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskRunSidecarOverride.
+func (in *TaskRunSidecarOverride) DeepCopy() *TaskRunSidecarOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskRunSidecarOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaskRunSpec) DeepCopyInto(out *TaskRunSpec) {
 	*out = *in
@@ -1595,6 +1897,37 @@ func (in *TaskRunSpec) DeepCopyInto(out *TaskRunSpec) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Debug != nil {
+		in, out := &in.Debug, &out.Debug
+		*out = new(TaskRunDebug)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ComputeResources != nil {
+		in, out := &in.ComputeResources, &out.ComputeResources
+		*out = new(v1.ResourceRequirements)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.StepOverrides != nil {
+		in, out := &in.StepOverrides, &out.StepOverrides
+		*out = make([]TaskRunStepOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SidecarOverrides != nil {
+		in, out := &in.SidecarOverrides, &out.SidecarOverrides
+		*out = make([]TaskRunSidecarOverride, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.StepPods != nil {
+		in, out := &in.StepPods, &out.StepPods
+		*out = make([]TaskRunStepPod, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
@@ -1629,6 +1962,11 @@ func (in *TaskRunStatus) DeepCopy() *TaskRunStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaskRunStatusFields) DeepCopyInto(out *TaskRunStatusFields) {
 	*out = *in
+	if in.PodNames != nil {
+		in, out := &in.PodNames, &out.PodNames
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.StartTime != nil {
 		in, out := &in.StartTime, &out.StartTime
 		*out = (*in).DeepCopy()
@@ -1637,6 +1975,10 @@ func (in *TaskRunStatusFields) DeepCopyInto(out *TaskRunStatusFields) {
 		in, out := &in.CompletionTime, &out.CompletionTime
 		*out = (*in).DeepCopy()
 	}
+	if in.QueuedTime != nil {
+		in, out := &in.QueuedTime, &out.QueuedTime
+		*out = (*in).DeepCopy()
+	}
 	if in.Steps != nil {
 		in, out := &in.Steps, &out.Steps
 		*out = make([]StepState, len(*in))
@@ -1644,6 +1986,13 @@ func (in *TaskRunStatusFields) DeepCopyInto(out *TaskRunStatusFields) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.OnFailureSteps != nil {
+		in, out := &in.OnFailureSteps, &out.OnFailureSteps
+		*out = make([]StepState, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.CloudEvents != nil {
 		in, out := &in.CloudEvents, &out.CloudEvents
 		*out = make([]CloudEventDelivery, len(*in))
@@ -1682,6 +2031,16 @@ func (in *TaskRunStatusFields) DeepCopyInto(out *TaskRunStatusFields) {
 		*out = new(TaskSpec)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Failure != nil {
+		in, out := &in.Failure, &out.Failure
+		*out = new(TaskRunFailure)
+		**out = **in
+	}
+	if in.Provenance != nil {
+		in, out := &in.Provenance, &out.Provenance
+		*out = new(TaskRunProvenance)
+		**out = **in
+	}
 	return
 }
 
@@ -1695,6 +2054,56 @@ func (in *TaskRunStatusFields) DeepCopy() *TaskRunStatusFields {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskRunStepOverride) DeepCopyInto(out *TaskRunStepOverride) {
+	*out = *in
+	in.Resources.DeepCopyInto(&out.Resources)
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make([]v1.EnvVar, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SecurityContext != nil {
+		in, out := &in.SecurityContext, &out.SecurityContext
+		*out = new(v1.SecurityContext)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskRunStepOverride.
+func (in *TaskRunStepOverride) DeepCopy() *TaskRunStepOverride {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskRunStepOverride)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TaskRunStepPod) DeepCopyInto(out *TaskRunStepPod) {
+	*out = *in
+	if in.PodTemplate != nil {
+		in, out := &in.PodTemplate, &out.PodTemplate
+		*out = new(pod.Template)
+		(*in).DeepCopyInto(*out)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TaskRunStepPod.
+func (in *TaskRunStepPod) DeepCopy() *TaskRunStepPod {
+	if in == nil {
+		return nil
+	}
+	out := new(TaskRunStepPod)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TaskSpec) DeepCopyInto(out *TaskSpec) {
 	*out = *in
@@ -1746,6 +2155,13 @@ func (in *TaskSpec) DeepCopyInto(out *TaskSpec) {
 		*out = make([]TaskResult, len(*in))
 		copy(*out, *in)
 	}
+	if in.OnFailure != nil {
+		in, out := &in.OnFailure, &out.OnFailure
+		*out = make([]Step, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	return
 }
 
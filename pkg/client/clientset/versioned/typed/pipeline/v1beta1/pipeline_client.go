@@ -29,6 +29,7 @@ type TektonV1beta1Interface interface {
 	ClusterTasksGetter
 	PipelinesGetter
 	PipelineRunsGetter
+	StepActionsGetter
 	TasksGetter
 	TaskRunsGetter
 }
@@ -50,6 +51,10 @@ func (c *TektonV1beta1Client) PipelineRuns(namespace string) PipelineRunInterfac
 	return newPipelineRuns(c, namespace)
 }
 
+func (c *TektonV1beta1Client) StepActions(namespace string) StepActionInterface {
+	return newStepActions(c, namespace)
+}
+
 func (c *TektonV1beta1Client) Tasks(namespace string) TaskInterface {
 	return newTasks(c, namespace)
 }
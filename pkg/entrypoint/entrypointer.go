@@ -18,10 +18,13 @@ package entrypoint
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"strconv"
 	"time"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
@@ -30,11 +33,27 @@ import (
 	"go.uber.org/zap"
 )
 
-//RFC3339 with millisecond
+// RFC3339 with millisecond
 const (
 	timeFormat = "2006-01-02T15:04:05.000Z07:00"
 )
 
+const (
+	// ContinueOnError indicates to continue executing the rest of the steps irrespective of the step exit code
+	ContinueOnError = "continue"
+	// FailOnError indicates to stop executing the rest of the steps if the step exits with non-zero exit code
+	FailOnError = "stopAndFail"
+)
+
+const (
+	// BreakpointSuffix is the extension of the file written next to the post file
+	// while a failed step is waiting at a breakpoint
+	BreakpointSuffix = ".breakpoint"
+	// BreakpointExitSuffix is the extension of the file which ends the wait at a breakpoint,
+	// the execution continues if it exists and is aborted if it exists with a ".err" extension
+	BreakpointExitSuffix = ".breakpointexit"
+)
+
 // Entrypointer holds fields for running commands with redirected
 // entrypoints.
 type Entrypointer struct {
@@ -48,6 +67,14 @@ type Entrypointer struct {
 	// WaitFileContent indicates the WaitFile should have non-zero size
 	// before continuing with execution.
 	WaitFileContent bool
+	// OnFailureFile is the post file of the last step. If specified, the
+	// step only runs once OnFailureFile.err is present, that is when a step
+	// failed, and is skipped successfully once OnFailureFile is present.
+	OnFailureFile string
+	// SidecarReadyFiles is the set of files signalling that the Sidecars
+	// needed by the step are ready. Execution begins once they all have
+	// content, after the WaitFiles are present.
+	SidecarReadyFiles []string
 	// PostFile is the file to write when complete. If not specified, no
 	// file is written.
 	PostFile string
@@ -61,11 +88,22 @@ type Entrypointer struct {
 	Runner Runner
 	// PostWriter encapsulates writing files when complete.
 	PostWriter PostWriter
+	// LogArchiver encapsulates archiving the output of the step once it
+	// completes. If not specified, the output is not archived.
+	LogArchiver LogArchiver
 
 	// Results is the set of files that might contain task results
 	Results []string
 	// Timeout is an optional user-specified duration within which the Step must complete
 	Timeout *time.Duration
+	// OnError defines exiting behavior of the entrypoint
+	// set it to "stopAndFail" to indicate the entrypoint to exit the taskRun if the container exits with non zero exit code
+	// set it to "continue" to indicate the entrypoint to continue executing the rest of the steps irrespective of the container exit code
+	OnError string
+	// BreakpointOnFailure indicates the entrypoint to wait at a breakpoint instead of
+	// exiting when the container exits with a non zero exit code, so that the user
+	// can inspect the environment of the step before continuing or aborting the execution
+	BreakpointOnFailure bool
 }
 
 // Waiter encapsulates waiting for files to exist.
@@ -85,6 +123,12 @@ type PostWriter interface {
 	Write(file string)
 }
 
+// LogArchiver encapsulates archiving the output of a step.
+type LogArchiver interface {
+	// Archive stores the output of the step, and returns its location.
+	Archive() (string, error)
+}
+
 // Go optionally waits for a file, runs the command, and writes a
 // post file.
 func (e Entrypointer) Go() error {
@@ -99,8 +143,8 @@ func (e Entrypointer) Go() error {
 		_ = logger.Sync()
 	}()
 
-	for _, f := range e.WaitFiles {
-		if err := e.Waiter.Wait(f, e.WaitFileContent); err != nil {
+	wait := func(f string, expectContent bool) error {
+		if err := e.Waiter.Wait(f, expectContent); err != nil {
 			// An error happened while waiting, so we bail
 			// *but* we write postfile to make next steps bail too.
 			e.WritePostFile(e.PostFile, err)
@@ -111,6 +155,31 @@ func (e Entrypointer) Go() error {
 			})
 			return err
 		}
+		return nil
+	}
+	for _, f := range e.WaitFiles {
+		if err := wait(f, e.WaitFileContent); err != nil {
+			return err
+		}
+	}
+	for _, f := range e.SidecarReadyFiles {
+		if err := wait(f, true); err != nil {
+			return err
+		}
+	}
+
+	if e.OnFailureFile != "" {
+		if err := e.Waiter.Wait(e.OnFailureFile, false); err == nil {
+			// All the steps succeeded, so there is nothing to do.
+			logger.Infof("Skipping step because no previous step failed")
+			e.WritePostFile(e.PostFile, nil)
+			output = append(output, v1beta1.PipelineResourceResult{
+				Key:        "StartedAt",
+				Value:      time.Now().Format(timeFormat),
+				ResultType: v1beta1.InternalTektonResultType,
+			})
+			return nil
+		}
 	}
 
 	if e.Entrypoint != "" {
@@ -145,13 +214,57 @@ func (e Entrypointer) Go() error {
 		}
 	}
 
+	var ee *exec.ExitError
+	if err != nil && e.OnError == ContinueOnError && errors.As(err, &ee) {
+		// The step is allowed to fail: record its exit code and let the
+		// following steps run as if it had succeeded.
+		logger.Infof("Ignoring step error because onError is set to %q: %v", ContinueOnError, err)
+		output = append(output, v1beta1.PipelineResourceResult{
+			Key:        "ExitCode",
+			Value:      strconv.Itoa(ee.ExitCode()),
+			ResultType: v1beta1.InternalTektonResultType,
+		})
+		err = nil
+	}
+
+	if err != nil && e.BreakpointOnFailure {
+		err = e.waitAtBreakpoint(logger, err)
+	}
+
 	// Write the post file *no matter what*
 	e.WritePostFile(e.PostFile, err)
 
+	// Archive the output of the step once the next step can start, failing
+	// to archive it does not fail the step
+	if e.LogArchiver != nil {
+		if location, aErr := e.LogArchiver.Archive(); aErr != nil {
+			logger.Errorf("Error archiving the logs of the step: %v", aErr)
+		} else {
+			output = append(output, v1beta1.PipelineResourceResult{
+				Key:        "LogLocation",
+				Value:      location,
+				ResultType: v1beta1.InternalTektonResultType,
+			})
+		}
+	}
+
 	// strings.Split(..) with an empty string returns an array that contains one element, an empty string.
 	// This creates an error when trying to open the result folder as a file.
 	if len(e.Results) >= 1 && e.Results[0] != "" {
 		if err := e.readResultsFromDisk(); err != nil {
+			var lErr termination.MessageLengthError
+			if errors.As(err, &lErr) {
+				// Record why the step failed, the deferred write is
+				// skipped when exiting.
+				output = append(output, v1beta1.PipelineResourceResult{
+					Key:        "Reason",
+					Value:      "ResultsTooLarge",
+					ResultType: v1beta1.InternalTektonResultType,
+				})
+				if wErr := termination.WriteMessage(e.TerminationPath, output); wErr != nil {
+					logger.Errorf("Error while writing message: %s", wErr)
+				}
+			}
 			logger.Fatalf("Error while handling results: %s", err)
 		}
 	}
@@ -187,6 +300,37 @@ func (e Entrypointer) readResultsFromDisk() error {
 	return nil
 }
 
+// waitAtBreakpoint blocks the failed step until the breakpoint exit file is written. The
+// step is then considered successful, unless the file has a ".err" extension in which
+// case the original error is returned and the execution of the following steps is aborted.
+func (e Entrypointer) waitAtBreakpoint(logger *zap.SugaredLogger, err error) error {
+	if e.PostFile == "" {
+		return err
+	}
+	breakpointExitFile := e.PostFile + BreakpointExitSuffix
+	e.PostWriter.Write(e.PostFile + BreakpointSuffix)
+	logger.Infof("Step failed with %v, waiting at breakpoint until %s or %s.err is written", err, breakpointExitFile, breakpointExitFile)
+	if wErr := e.Waiter.Wait(breakpointExitFile, false); wErr != nil {
+		logger.Infof("Aborting execution at breakpoint: %v", wErr)
+		return err
+	}
+	logger.Info("Continuing execution after breakpoint")
+	return nil
+}
+
+// IsAtBreakpoint returns true if the step writing the given post file is waiting at a breakpoint.
+func IsAtBreakpoint(postFile string) bool {
+	if _, err := os.Stat(postFile + BreakpointSuffix); err != nil {
+		return false
+	}
+	for _, exitFile := range []string{postFile + BreakpointExitSuffix, postFile + BreakpointExitSuffix + ".err"} {
+		if _, err := os.Stat(exitFile); err == nil {
+			return false
+		}
+	}
+	return true
+}
+
 // WritePostFile write the postfile
 func (e Entrypointer) WritePostFile(postFile string, err error) {
 	if err != nil && postFile != "" {
@@ -20,9 +20,13 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"io/ioutil"
 	"os"
+	"os/exec"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 
@@ -225,6 +229,289 @@ func TestEntrypointer(t *testing.T) {
 	}
 }
 
+func TestEntrypointer_OnError(t *testing.T) {
+	for _, c := range []struct {
+		desc, onError, expectedError, expectedPostFile, expectedExitCode string
+		runner                                                           Runner
+	}{{
+		desc:             "continue ignores the exit code of the step",
+		onError:          ContinueOnError,
+		runner:           &fakeExitErrorRunner{exitCode: 3},
+		expectedPostFile: "writeme",
+		expectedExitCode: "3",
+	}, {
+		desc:             "stopAndFail fails the step",
+		onError:          FailOnError,
+		runner:           &fakeExitErrorRunner{exitCode: 3},
+		expectedError:    "exit status 3",
+		expectedPostFile: "writeme.err",
+	}, {
+		desc:             "continue does not ignore errors other than the exit code of the step",
+		onError:          ContinueOnError,
+		runner:           &fakeErrorRunner{},
+		expectedError:    "runner failed",
+		expectedPostFile: "writeme.err",
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			fpw := &fakePostWriter{}
+			timeout := time.Duration(0)
+			err := Entrypointer{
+				Entrypoint:      "echo",
+				PostFile:        "writeme",
+				Waiter:          &fakeWaiter{},
+				Runner:          c.runner,
+				PostWriter:      fpw,
+				TerminationPath: "termination",
+				Timeout:         &timeout,
+				OnError:         c.onError,
+			}.Go()
+			defer os.Remove("termination")
+
+			if c.expectedError == "" && err != nil {
+				t.Fatalf("Entrypointer failed: %v", err)
+			}
+			if c.expectedError != "" {
+				if err == nil {
+					t.Fatalf("Entrypointer didn't fail")
+				}
+				if d := cmp.Diff(c.expectedError, err.Error()); d != "" {
+					t.Errorf("Entrypointer error diff %s", diff.PrintWantGot(d))
+				}
+			}
+			if fpw.wrote == nil {
+				t.Fatal("Wanted post file written, got nil")
+			}
+			if *fpw.wrote != c.expectedPostFile {
+				t.Errorf("Wrote post file %q, want %q", *fpw.wrote, c.expectedPostFile)
+			}
+
+			fileContents, err := ioutil.ReadFile("termination")
+			if err != nil {
+				t.Fatalf("Wanted termination file written, got %v", err)
+			}
+			var entries []v1alpha1.PipelineResourceResult
+			if err := json.Unmarshal(fileContents, &entries); err != nil {
+				t.Fatalf("Error unmarshalling termination message: %v", err)
+			}
+			exitCode := ""
+			for _, result := range entries {
+				if result.Key == "ExitCode" {
+					exitCode = result.Value
+				}
+			}
+			if exitCode != c.expectedExitCode {
+				t.Errorf("Recorded exit code %q, want %q", exitCode, c.expectedExitCode)
+			}
+		})
+	}
+}
+
+func TestEntrypointer_LogArchiver(t *testing.T) {
+	for _, c := range []struct {
+		desc                string
+		runner              Runner
+		archiver            *fakeLogArchiver
+		expectedLogLocation string
+	}{{
+		desc:                "logs archived after success",
+		runner:              &fakeRunner{},
+		archiver:            &fakeLogArchiver{location: "pvc://logs/ns/taskrun/build.log"},
+		expectedLogLocation: "pvc://logs/ns/taskrun/build.log",
+	}, {
+		desc:                "logs archived after failure",
+		runner:              &fakeErrorRunner{},
+		archiver:            &fakeLogArchiver{location: "pvc://logs/ns/taskrun/build.log"},
+		expectedLogLocation: "pvc://logs/ns/taskrun/build.log",
+	}, {
+		desc:     "logs not archived",
+		runner:   &fakeRunner{},
+		archiver: &fakeLogArchiver{err: errors.New("bucket not found")},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			fpw := &fakePostWriter{}
+			_ = Entrypointer{
+				Entrypoint:      "echo",
+				PostFile:        "writeme",
+				Waiter:          &fakeWaiter{},
+				Runner:          c.runner,
+				PostWriter:      fpw,
+				LogArchiver:     c.archiver,
+				TerminationPath: "termination",
+			}.Go()
+			defer os.Remove("termination")
+
+			if !c.archiver.archived {
+				t.Error("Expected the logs to be archived")
+			}
+			if fpw.wrote == nil {
+				t.Error("Wanted post file written, got nil")
+			}
+
+			fileContents, err := ioutil.ReadFile("termination")
+			if err != nil {
+				t.Fatalf("Wanted termination file written, got %v", err)
+			}
+			var entries []v1alpha1.PipelineResourceResult
+			if err := json.Unmarshal(fileContents, &entries); err != nil {
+				t.Fatalf("Error unmarshalling termination message: %v", err)
+			}
+			logLocation := ""
+			for _, result := range entries {
+				if result.Key == "LogLocation" {
+					logLocation = result.Value
+				}
+			}
+			if logLocation != c.expectedLogLocation {
+				t.Errorf("Recorded log location %q, want %q", logLocation, c.expectedLogLocation)
+			}
+		})
+	}
+}
+
+func TestEntrypointer_BreakpointOnFailure(t *testing.T) {
+	for _, c := range []struct {
+		desc, expectedError, expectedPostFile string
+		runner                                Runner
+		abort                                 bool
+		expectedWaited                        []string
+	}{{
+		desc:             "continue after breakpoint",
+		runner:           &fakeExitErrorRunner{exitCode: 3},
+		expectedPostFile: "writeme",
+		expectedWaited:   []string{"waitforme", "writeme.breakpointexit"},
+	}, {
+		desc:             "abort at breakpoint",
+		runner:           &fakeExitErrorRunner{exitCode: 3},
+		abort:            true,
+		expectedError:    "exit status 3",
+		expectedPostFile: "writeme.err",
+		expectedWaited:   []string{"waitforme", "writeme.breakpointexit"},
+	}, {
+		desc:             "no breakpoint when the step succeeds",
+		runner:           &fakeRunner{},
+		expectedPostFile: "writeme",
+		expectedWaited:   []string{"waitforme"},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			fw := &fakeBreakpointWaiter{abort: c.abort}
+			fpw := &fakePostWriter{}
+			err := Entrypointer{
+				Entrypoint:          "echo",
+				WaitFiles:           []string{"waitforme"},
+				PostFile:            "writeme",
+				Waiter:              fw,
+				Runner:              c.runner,
+				PostWriter:          fpw,
+				TerminationPath:     "termination",
+				BreakpointOnFailure: true,
+			}.Go()
+			defer os.Remove("termination")
+
+			if c.expectedError == "" && err != nil {
+				t.Fatalf("Entrypointer failed: %v", err)
+			}
+			if c.expectedError != "" && (err == nil || err.Error() != c.expectedError) {
+				t.Fatalf("Entrypointer error %v, want %q", err, c.expectedError)
+			}
+			if d := cmp.Diff(c.expectedWaited, fw.waited); d != "" {
+				t.Errorf("Entrypointer waited for unexpected files %s", diff.PrintWantGot(d))
+			}
+			if fpw.wrote == nil || *fpw.wrote != c.expectedPostFile {
+				t.Errorf("Wrote post file %v, want %q", fpw.wrote, c.expectedPostFile)
+			}
+		})
+	}
+}
+
+func TestEntrypointer_SidecarReadyFiles(t *testing.T) {
+	fw := &fakeContentWaiter{}
+	err := Entrypointer{
+		Entrypoint:        "echo",
+		WaitFiles:         []string{"waitforme"},
+		SidecarReadyFiles: []string{"sidecar-database", "sidecar-cache"},
+		PostFile:          "writeme",
+		Waiter:            fw,
+		Runner:            &fakeRunner{},
+		PostWriter:        &fakePostWriter{},
+		TerminationPath:   "termination",
+	}.Go()
+	defer os.Remove("termination")
+	if err != nil {
+		t.Fatalf("Entrypointer failed: %v", err)
+	}
+
+	want := []string{"waitforme", "sidecar-database (content)", "sidecar-cache (content)"}
+	if d := cmp.Diff(want, fw.waited); d != "" {
+		t.Errorf("Entrypointer waited for unexpected files %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestEntrypointer_OnFailureFile(t *testing.T) {
+	for _, c := range []struct {
+		desc    string
+		waiter  Waiter
+		wantRun bool
+	}{{
+		desc:   "skipped when the steps succeeded",
+		waiter: &fakeWaiter{},
+	}, {
+		desc:    "run when a step failed",
+		waiter:  &fakeErrorWaiter{},
+		wantRun: true,
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			fr := &fakeRunner{}
+			fpw := &fakePostWriter{}
+			err := Entrypointer{
+				Entrypoint:      "echo",
+				OnFailureFile:   "laststep",
+				PostFile:        "writeme",
+				Waiter:          c.waiter,
+				Runner:          fr,
+				PostWriter:      fpw,
+				TerminationPath: "termination",
+			}.Go()
+			defer os.Remove("termination")
+			if err != nil {
+				t.Fatalf("Entrypointer failed: %v", err)
+			}
+
+			if ran := fr.args != nil; ran != c.wantRun {
+				t.Errorf("Entrypointer ran the step: %t, want %t", ran, c.wantRun)
+			}
+			if fpw.wrote == nil || *fpw.wrote != "writeme" {
+				t.Errorf("Wrote post file %v, want %q", fpw.wrote, "writeme")
+			}
+		})
+	}
+}
+
+func TestIsAtBreakpoint(t *testing.T) {
+	dir, err := ioutil.TempDir("", "breakpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	postFile := filepath.Join(dir, "0")
+
+	write := func(file string) {
+		if err := ioutil.WriteFile(file, nil, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if IsAtBreakpoint(postFile) {
+		t.Error("Expected step not to be at a breakpoint before it failed")
+	}
+	write(postFile + BreakpointSuffix)
+	if !IsAtBreakpoint(postFile) {
+		t.Error("Expected step to be at a breakpoint after it failed")
+	}
+	write(postFile + BreakpointExitSuffix + ".err")
+	if IsAtBreakpoint(postFile) {
+		t.Error("Expected step not to be at a breakpoint after the execution was aborted")
+	}
+}
+
 type fakeWaiter struct{ waited []string }
 
 func (f *fakeWaiter) Wait(file string, _ bool) error {
@@ -232,6 +519,31 @@ func (f *fakeWaiter) Wait(file string, _ bool) error {
 	return nil
 }
 
+// fakeContentWaiter records the waited files, along with whether they were
+// expected to have content.
+type fakeContentWaiter struct{ waited []string }
+
+func (f *fakeContentWaiter) Wait(file string, expectContent bool) error {
+	if expectContent {
+		file += " (content)"
+	}
+	f.waited = append(f.waited, file)
+	return nil
+}
+
+type fakeBreakpointWaiter struct {
+	waited []string
+	abort  bool
+}
+
+func (f *fakeBreakpointWaiter) Wait(file string, _ bool) error {
+	f.waited = append(f.waited, file)
+	if f.abort && strings.HasSuffix(file, BreakpointExitSuffix) {
+		return errors.New("execution aborted at breakpoint")
+	}
+	return nil
+}
+
 type fakeRunner struct{ args *[]string }
 
 func (f *fakeRunner) Run(ctx context.Context, args ...string) error {
@@ -243,6 +555,17 @@ type fakePostWriter struct{ wrote *string }
 
 func (f *fakePostWriter) Write(file string) { f.wrote = &file }
 
+type fakeLogArchiver struct {
+	location string
+	err      error
+	archived bool
+}
+
+func (f *fakeLogArchiver) Archive() (string, error) {
+	f.archived = true
+	return f.location, f.err
+}
+
 type fakeErrorWaiter struct{ waited *string }
 
 func (f *fakeErrorWaiter) Wait(file string, expectContent bool) error {
@@ -276,3 +599,9 @@ func (f *fakeTimeoutRunner) Run(ctx context.Context, args ...string) error {
 	}
 	return errors.New("runner failed")
 }
+
+type fakeExitErrorRunner struct{ exitCode int }
+
+func (f *fakeExitErrorRunner) Run(ctx context.Context, args ...string) error {
+	return exec.Command("sh", "-c", fmt.Sprintf("exit %d", f.exitCode)).Run()
+}
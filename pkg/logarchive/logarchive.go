@@ -0,0 +1,265 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package logarchive stores the logs of the steps, once they complete, to an
+// S3-compatible bucket, a PersistentVolumeClaim or an HTTP endpoint.
+//
+// The credentials of the archive are only known to the controller, which
+// gives the entrypoint of each step a URL to upload its log to, pre-signed for
+// this log only.
+package logarchive
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+)
+
+const (
+	// PVCMountPath is where the directory of the PersistentVolumeClaim the
+	// logs of a TaskRun are archived to is mounted in its steps.
+	PVCMountPath = "/tekton/log-archive"
+
+	// ExpiresParam and SignatureParam are the query parameters of an upload
+	// URL of an HTTP endpoint, holding when the URL expires, in seconds since
+	// the epoch, and its signature.
+	ExpiresParam   = "expires"
+	SignatureParam = "signature"
+
+	// MaxUploadURLExpiry is the longest an upload URL can be valid for, which
+	// is the longest allowed for an S3 pre-signed URL.
+	MaxUploadURLExpiry = 7 * 24 * time.Hour
+
+	defaultS3Region = "us-east-1"
+)
+
+// Credentials are the credentials of the log archive. The AccessKeyID and
+// SecretAccessKey are used for an S3 bucket, and the Token signs the upload
+// URLs of an HTTP endpoint.
+type Credentials struct {
+	AccessKeyID     string
+	SecretAccessKey string
+	Token           string
+}
+
+// Sink stores the log of a step.
+type Sink interface {
+	// Archive stores the given log, read from its start.
+	Archive(ctx context.Context, log io.ReadSeeker) error
+}
+
+// StepLocation returns the location the log of the given step of a TaskRun is
+// archived to, under the configured base location.
+func StepLocation(base, namespace, taskRun, step string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	u.Path = path.Join("/", u.Path, namespace, taskRun, step+".log")
+	return u.String(), nil
+}
+
+// UploadURL returns the URL the log archived to the given location of a step
+// is uploaded to, signed with the credentials so that it is only valid for
+// this location, until expiry. The location of a PersistentVolumeClaim is
+// returned as is.
+func UploadURL(location string, creds Credentials, expiry time.Duration, now time.Time) (string, error) {
+	u, err := url.Parse(location)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse log archive location %q: %w", location, err)
+	}
+	if expiry > MaxUploadURLExpiry {
+		expiry = MaxUploadURLExpiry
+	}
+	switch u.Scheme {
+	case "pvc":
+		return location, nil
+	case "http", "https":
+		if creds.Token == "" {
+			return location, nil
+		}
+		expires := strconv.FormatInt(now.Add(expiry).Unix(), 10)
+		q := u.Query()
+		q.Set(ExpiresParam, expires)
+		q.Set(SignatureParam, signature(creds.Token, u.EscapedPath(), expires))
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	case "s3":
+		req, err := http.NewRequest(http.MethodPut, s3ObjectURL(u), nil)
+		if err != nil {
+			return "", err
+		}
+		signer := v4.NewSigner(credentials.NewStaticCredentials(creds.AccessKeyID, creds.SecretAccessKey, ""))
+		if _, err := signer.Presign(req, nil, "s3", s3Region(u), expiry, now); err != nil {
+			return "", fmt.Errorf("failed to pre-sign the upload URL of %s: %w", location, err)
+		}
+		return req.URL.String(), nil
+	default:
+		return "", fmt.Errorf("unsupported log archive location %q", location)
+	}
+}
+
+// VerifyUploadRequest checks that the request uploading a log to an HTTP
+// endpoint was made to an upload URL signed with the token, which has not
+// expired yet. It is meant to be used by the endpoints receiving the logs.
+func VerifyUploadRequest(token string, r *http.Request, now time.Time) error {
+	q := r.URL.Query()
+	expires, sig := q.Get(ExpiresParam), q.Get(SignatureParam)
+	if expires == "" || sig == "" {
+		return errors.New("the upload URL is not signed")
+	}
+	if !hmac.Equal([]byte(sig), []byte(signature(token, r.URL.EscapedPath(), expires))) {
+		return errors.New("the signature of the upload URL does not match")
+	}
+	seconds, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid expiry of the upload URL: %w", err)
+	}
+	if now.After(time.Unix(seconds, 0)) {
+		return errors.New("the upload URL has expired")
+	}
+	return nil
+}
+
+// signature returns the signature of the upload URL with the given path and
+// expiry, as the hex-encoded HMAC-SHA256 of the method, the path and the
+// expiry, separated by newlines.
+func signature(token, escapedPath, expires string) string {
+	mac := hmac.New(sha256.New, []byte(token))
+	fmt.Fprintf(mac, "%s\n%s\n%s", http.MethodPut, escapedPath, expires)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewSink returns the Sink storing a log to the given upload URL, returned by
+// UploadURL.
+func NewSink(uploadURL string) (Sink, error) {
+	u, err := url.Parse(uploadURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse log archive upload URL: %w", err)
+	}
+	switch u.Scheme {
+	case "pvc":
+		// Only the directory of the TaskRun is mounted in its steps.
+		return &pvcSink{path: filepath.Join(PVCMountPath, path.Base(u.Path))}, nil
+	case "http", "https":
+		return &httpSink{url: uploadURL, client: http.DefaultClient}, nil
+	default:
+		return nil, fmt.Errorf("unsupported log archive upload URL scheme %q", u.Scheme)
+	}
+}
+
+// pvcSink writes the log to a file of the directory of the
+// PersistentVolumeClaim mounted at PVCMountPath.
+type pvcSink struct {
+	path string
+}
+
+func (s *pvcSink) Archive(_ context.Context, log io.ReadSeeker) error {
+	if _, err := log.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	f, err := os.Create(s.path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, log); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// httpSink sends the log with a PUT request to the pre-signed upload URL of an
+// HTTP endpoint or an S3-compatible bucket.
+type httpSink struct {
+	url    string
+	client *http.Client
+}
+
+func (s *httpSink) Archive(ctx context.Context, log io.ReadSeeker) error {
+	size, err := contentLength(log)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPut, s.url, ioutil.NopCloser(log))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.ContentLength = size
+	req.Header.Set("Content-Type", "text/plain; charset=utf-8")
+	return do(s.client, req)
+}
+
+// s3ObjectURL returns the path-style URL of the object of an S3-compatible
+// bucket, given its location of the form
+// s3://bucket/key?endpoint=<url>&region=<region>. Path-style URLs are
+// supported by all the S3-compatible stores.
+func s3ObjectURL(u *url.URL) string {
+	endpoint := u.Query().Get("endpoint")
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", s3Region(u))
+	}
+	return fmt.Sprintf("%s/%s%s", endpoint, u.Host, u.EscapedPath())
+}
+
+func s3Region(u *url.URL) string {
+	if region := u.Query().Get("region"); region != "" {
+		return region
+	}
+	return defaultS3Region
+}
+
+// contentLength returns the size of the log, which is read from its start.
+func contentLength(log io.ReadSeeker) (int64, error) {
+	size, err := log.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if _, err := log.Seek(0, io.SeekStart); err != nil {
+		return 0, err
+	}
+	return size, nil
+}
+
+func do(client *http.Client, req *http.Request) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		// The query of the URL holds its signature.
+		return fmt.Errorf("failed to archive the log to %s://%s%s: %s %s", req.URL.Scheme, req.URL.Host, req.URL.Path, resp.Status, body)
+	}
+	return nil
+}
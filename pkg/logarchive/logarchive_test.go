@@ -0,0 +1,269 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package logarchive
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const stepLog = "Cloning into 'source'...\nBuild succeeded\n"
+
+func TestStepLocation(t *testing.T) {
+	for _, tc := range []struct {
+		base string
+		want string
+	}{{
+		base: "pvc://logs-claim",
+		want: "pvc://logs-claim/ns/taskrun/build.log",
+	}, {
+		base: "https://logs.example.com/upload/",
+		want: "https://logs.example.com/upload/ns/taskrun/build.log",
+	}, {
+		base: "s3://bucket/archive?endpoint=http://minio:9000&region=eu-west-1",
+		want: "s3://bucket/archive/ns/taskrun/build.log?endpoint=http://minio:9000&region=eu-west-1",
+	}} {
+		t.Run(tc.base, func(t *testing.T) {
+			got, err := StepLocation(tc.base, "ns", "taskrun", "build")
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tc.want {
+				t.Errorf("StepLocation(%q) = %q, want %q", tc.base, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestNewSink_Unsupported(t *testing.T) {
+	if _, err := NewSink("gs://bucket/ns/taskrun/build.log"); err == nil {
+		t.Error("Expected an error for an unsupported upload URL, got nil")
+	}
+}
+
+func TestNewSink_PVC(t *testing.T) {
+	sink, err := NewSink("pvc://logs-claim/archive/ns/taskrun/build.log")
+	if err != nil {
+		t.Fatalf("Unexpected error creating the sink: %v", err)
+	}
+	// Only the directory of the TaskRun is mounted in its steps.
+	if want := filepath.Join(PVCMountPath, "build.log"); sink.(*pvcSink).path != want {
+		t.Errorf("Expected the log to be written to %s, got %s", want, sink.(*pvcSink).path)
+	}
+}
+
+func TestPVCSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log-archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "build.log")
+	sink := &pvcSink{path: path}
+	if err := sink.Archive(context.Background(), strings.NewReader(stepLog)); err != nil {
+		t.Fatalf("Unexpected error archiving the log: %v", err)
+	}
+	got, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Error reading the archived log: %v", err)
+	}
+	if string(got) != stepLog {
+		t.Errorf("Archived log = %q, want %q", got, stepLog)
+	}
+}
+
+func TestUploadURL_Unsigned(t *testing.T) {
+	for _, location := range []string{
+		"pvc://logs-claim/ns/taskrun/build.log",
+		"https://logs.example.com/upload/ns/taskrun/build.log",
+	} {
+		got, err := UploadURL(location, Credentials{}, time.Hour, time.Now())
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if got != location {
+			t.Errorf("UploadURL(%q) = %q, want the location", location, got)
+		}
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	var gotMethod, gotPath, gotAuthorization, gotBody string
+	var verifyErr error
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		gotMethod, gotPath, gotAuthorization, gotBody = r.Method, r.URL.Path, r.Header.Get("Authorization"), string(body)
+		verifyErr = VerifyUploadRequest("secret-token", r, time.Now())
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	uploadURL, err := UploadURL(server.URL+"/upload/ns/taskrun/build.log", Credentials{Token: "secret-token"}, time.Hour, time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error signing the upload URL: %v", err)
+	}
+	if strings.Contains(uploadURL, "secret-token") {
+		t.Errorf("Expected the token to be kept out of the upload URL, got %s", uploadURL)
+	}
+	sink, err := NewSink(uploadURL)
+	if err != nil {
+		t.Fatalf("Unexpected error creating the sink: %v", err)
+	}
+	if err := sink.Archive(context.Background(), strings.NewReader(stepLog)); err != nil {
+		t.Fatalf("Unexpected error archiving the log: %v", err)
+	}
+	if gotMethod != http.MethodPut {
+		t.Errorf("Expected a PUT request, got %s", gotMethod)
+	}
+	if gotPath != "/upload/ns/taskrun/build.log" {
+		t.Errorf("Expected the log to be sent to /upload/ns/taskrun/build.log, got %s", gotPath)
+	}
+	if gotAuthorization != "" {
+		t.Errorf("Expected no credentials to be sent, got %q", gotAuthorization)
+	}
+	if verifyErr != nil {
+		t.Errorf("Expected the upload request to be verified, got %v", verifyErr)
+	}
+	if gotBody != stepLog {
+		t.Errorf("Sent log = %q, want %q", gotBody, stepLog)
+	}
+}
+
+func TestVerifyUploadRequest(t *testing.T) {
+	now := time.Now()
+	location := "https://logs.example.com/upload/ns/taskrun/build.log"
+	uploadURL, err := UploadURL(location, Credentials{Token: "secret-token"}, time.Hour, now)
+	if err != nil {
+		t.Fatalf("Unexpected error signing the upload URL: %v", err)
+	}
+	for _, tc := range []struct {
+		desc    string
+		url     string
+		token   string
+		now     time.Time
+		wantErr bool
+	}{{
+		desc:  "signed",
+		url:   uploadURL,
+		token: "secret-token",
+		now:   now,
+	}, {
+		desc:    "expired",
+		url:     uploadURL,
+		token:   "secret-token",
+		now:     now.Add(2 * time.Hour),
+		wantErr: true,
+	}, {
+		desc:    "signed with another token",
+		url:     uploadURL,
+		token:   "other-token",
+		now:     now,
+		wantErr: true,
+	}, {
+		desc:    "signed for the log of another step",
+		url:     strings.Replace(uploadURL, "build.log", "test.log", 1),
+		token:   "secret-token",
+		now:     now,
+		wantErr: true,
+	}, {
+		desc:    "not signed",
+		url:     location,
+		token:   "secret-token",
+		now:     now,
+		wantErr: true,
+	}} {
+		t.Run(tc.desc, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, tc.url, nil)
+			if err := VerifyUploadRequest(tc.token, req, tc.now); (err != nil) != tc.wantErr {
+				t.Errorf("VerifyUploadRequest() = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPSink_Error(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "quota exceeded", http.StatusInsufficientStorage)
+	}))
+	defer server.Close()
+
+	sink, err := NewSink(server.URL + "/ns/taskrun/build.log?signature=secret")
+	if err != nil {
+		t.Fatalf("Unexpected error creating the sink: %v", err)
+	}
+	err = sink.Archive(context.Background(), strings.NewReader(stepLog))
+	if err == nil || !strings.Contains(err.Error(), "quota exceeded") {
+		t.Errorf("Expected the error of the endpoint, got %v", err)
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("Expected the signature to be kept out of the error, got %v", err)
+	}
+}
+
+func TestS3UploadURL(t *testing.T) {
+	var gotPath, gotAuthorization, gotBody string
+	var gotQuery url.Values
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		gotPath, gotQuery, gotBody = r.URL.Path, r.URL.Query(), string(body)
+		gotAuthorization = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	location := "s3://tekton-logs/ns/taskrun/build.log?endpoint=" + server.URL + "&region=eu-west-1"
+	uploadURL, err := UploadURL(location, Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, 30*24*time.Hour, time.Now())
+	if err != nil {
+		t.Fatalf("Unexpected error pre-signing the upload URL: %v", err)
+	}
+	if strings.Contains(uploadURL, "SECRET") {
+		t.Errorf("Expected the secret access key to be kept out of the upload URL, got %s", uploadURL)
+	}
+	sink, err := NewSink(uploadURL)
+	if err != nil {
+		t.Fatalf("Unexpected error creating the sink: %v", err)
+	}
+	if err := sink.Archive(context.Background(), strings.NewReader(stepLog)); err != nil {
+		t.Fatalf("Unexpected error archiving the log: %v", err)
+	}
+	if gotPath != "/tekton-logs/ns/taskrun/build.log" {
+		t.Errorf("Expected the log to be put to /tekton-logs/ns/taskrun/build.log, got %s", gotPath)
+	}
+	if c := gotQuery.Get("X-Amz-Credential"); !strings.HasPrefix(c, "AKID/") || !strings.HasSuffix(c, "/eu-west-1/s3/aws4_request") {
+		t.Errorf("Expected the URL to be pre-signed for the s3 service in eu-west-1, got credential %q", c)
+	}
+	if gotQuery.Get("X-Amz-Signature") == "" {
+		t.Error("Expected the URL to be pre-signed, got no signature")
+	}
+	if e := gotQuery.Get("X-Amz-Expires"); e != "604800" {
+		t.Errorf("Expected the expiry to be capped to 7 days, got %s seconds", e)
+	}
+	if gotAuthorization != "" {
+		t.Errorf("Expected no credentials to be sent, got %q", gotAuthorization)
+	}
+	if gotBody != stepLog {
+		t.Errorf("Sent log = %q, want %q", gotBody, stepLog)
+	}
+}
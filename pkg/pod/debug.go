@@ -0,0 +1,118 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/entrypoint"
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	breakpointProbeMode = "breakpoint-probe"
+	debugContinueScript = mountPoint + "/debug-continue"
+	debugAbortScript    = mountPoint + "/debug-abort"
+
+	// debugScriptTemplate ends the wait of the Step waiting at a breakpoint by writing
+	// its breakpoint exit file, with the extension given as parameter.
+	debugScriptTemplate = `#!/bin/sh
+found=""
+for marker in %[1]s/*%[2]s; do
+  [ -e "${marker}" ] || continue
+  exitfile="${marker%%%[2]s}%[3]s"
+  if [ ! -e "${exitfile}" ] && [ ! -e "${exitfile}.err" ]; then
+    touch "${exitfile}%[4]s"
+    found="${marker}"
+  fi
+done
+if [ -z "${found}" ]; then
+  echo "No step is waiting at a breakpoint" >&2
+  exit 1
+fi
+echo "%[5]s"
+`
+)
+
+// breakpointProbe returns the readiness probe of a Step in debug mode, the Step
+// container is ready only while it is waiting at a breakpoint.
+func breakpointProbe(postFile string) *corev1.Probe {
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			Exec: &corev1.ExecAction{
+				Command: []string{entrypointBinary, breakpointProbeMode, postFile},
+			},
+		},
+		PeriodSeconds: 1,
+	}
+}
+
+// debugScriptsInit returns the init container placing the scripts used to continue
+// or abort the execution of a Step waiting at a breakpoint in the tools volume.
+// The scripts are written by a shell from the shellImage, or by the entrypoint
+// binary from the entrypointImage if placeWithEntrypoint is set.
+func debugScriptsInit(shellImage, entrypointImage string, placeWithEntrypoint bool) corev1.Container {
+	c := corev1.Container{
+		Name:         "place-debug-scripts",
+		Image:        shellImage,
+		Command:      []string{"sh"},
+		Args:         []string{"-c", ""},
+		VolumeMounts: []corev1.VolumeMount{toolsMount},
+	}
+	if placeWithEntrypoint {
+		c.Image = entrypointImage
+		// Invoke the entrypoint binary in "cp mode" to copy the scripts
+		// from environment variables into the tools volume.
+		c.Command = []string{"/ko-app/entrypoint", "cp"}
+		c.Args = nil
+	}
+	for _, s := range []struct{ path, env, extension, message string }{
+		{debugContinueScript, "TEKTON_DEBUG_CONTINUE_SCRIPT", "", "Continuing the execution of the TaskRun"},
+		{debugAbortScript, "TEKTON_DEBUG_ABORT_SCRIPT", ".err", "Aborting the execution of the TaskRun"},
+	} {
+		content := fmt.Sprintf(debugScriptTemplate, mountPoint, entrypoint.BreakpointSuffix, entrypoint.BreakpointExitSuffix, s.extension, s.message)
+		if placeWithEntrypoint {
+			c.Env = append(c.Env, corev1.EnvVar{Name: s.env, Value: escapeEnvValue(content)})
+			c.Command = append(c.Command, "env:"+s.env, s.path)
+			continue
+		}
+		c.Args[1] += fmt.Sprintf(`tmpfile="%s"
+touch ${tmpfile} && chmod +x ${tmpfile}
+cat > ${tmpfile} << 'debug-script-heredoc'
+%s
+debug-script-heredoc
+`, s.path, content)
+	}
+	return c
+}
+
+// stepAtBreakpoint returns the name of the Step container of the Pod which is waiting
+// at a breakpoint, if any.
+func stepAtBreakpoint(pod *corev1.Pod) (string, bool) {
+	debugged := map[string]bool{}
+	for _, c := range pod.Spec.Containers {
+		if p := c.ReadinessProbe; p != nil && p.Exec != nil && len(p.Exec.Command) > 1 && p.Exec.Command[1] == breakpointProbeMode {
+			debugged[c.Name] = true
+		}
+	}
+	for _, s := range pod.Status.ContainerStatuses {
+		if IsContainerStep(s.Name) && debugged[s.Name] && s.State.Running != nil && s.Ready {
+			return s.Name, true
+		}
+	}
+	return "", false
+}
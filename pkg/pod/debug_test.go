@@ -0,0 +1,60 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/test/diff"
+)
+
+func TestDebugScriptsInit(t *testing.T) {
+	shell := debugScriptsInit(images.ShellImage, images.EntrypointImage, false)
+	if shell.Image != images.ShellImage || shell.Command[0] != "sh" {
+		t.Errorf("Expected the scripts to be placed by a shell from %s, got %v from %s", images.ShellImage, shell.Command, shell.Image)
+	}
+	for _, path := range []string{debugContinueScript, debugAbortScript} {
+		if !strings.Contains(shell.Args[1], `tmpfile="`+path+`"`) {
+			t.Errorf("Expected the shell script to place %s, got %s", path, shell.Args[1])
+		}
+	}
+
+	ep := debugScriptsInit(images.ShellImage, images.EntrypointImage, true)
+	if ep.Image != images.EntrypointImage {
+		t.Errorf("Expected the scripts to be placed from %s, got %s", images.EntrypointImage, ep.Image)
+	}
+	wantCommand := []string{"/ko-app/entrypoint", "cp",
+		"env:TEKTON_DEBUG_CONTINUE_SCRIPT", debugContinueScript,
+		"env:TEKTON_DEBUG_ABORT_SCRIPT", debugAbortScript,
+	}
+	if d := cmp.Diff(wantCommand, ep.Command); d != "" {
+		t.Errorf("Unexpected command %s", diff.PrintWantGot(d))
+	}
+	if len(ep.Args) != 0 {
+		t.Errorf("Expected no args, got %v", ep.Args)
+	}
+	if len(ep.Env) != 2 || !strings.Contains(ep.Env[0].Value, "Continuing") || !strings.Contains(ep.Env[1].Value, "Aborting") {
+		t.Errorf("Expected the continue and abort scripts in the environment, got %v", ep.Env)
+	}
+	// The kubelet expands the references to variables in the value of an
+	// environment variable, which turns $$ back into $.
+	if !strings.Contains(ep.Env[0].Value, `[ -z "$${found}" ]`) {
+		t.Errorf("Expected the variables of the script to be escaped, got %s", ep.Env[0].Value)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"strings"
 
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/names"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
@@ -41,8 +42,20 @@ const (
 	readyAnnotation        = "tekton.dev/ready"
 	readyAnnotationValue   = "READY"
 
-	stepPrefix    = "step-"
-	sidecarPrefix = "sidecar-"
+	// sidecarReadyAnnotationPrefix prefixes the annotations signalling
+	// that a Sidecar needed by some Steps is ready, keyed by the name of the
+	// Sidecar container.
+	sidecarReadyAnnotationPrefix = "sidecar-ready.tekton.dev/"
+	// sidecarStepsAnnotationPrefix prefixes the annotations listing the
+	// Step containers needing a Sidecar, keyed by the name of the Sidecar
+	// container.
+	sidecarStepsAnnotationPrefix = "sidecar-steps.tekton.dev/"
+	// onFailureStepsAnnotation lists the containers of the OnFailure steps.
+	onFailureStepsAnnotation = "tekton.dev/on-failure-steps"
+
+	stepPrefix          = "step-"
+	onFailureStepPrefix = stepPrefix + v1beta1.OnFailureStepNamePrefix
+	sidecarPrefix       = "sidecar-"
 )
 
 var (
@@ -85,8 +98,9 @@ var (
 // Containers must have Command specified; if the user didn't specify a
 // command, we must have fetched the image's ENTRYPOINT before calling this
 // method, using entrypoint_lookup.go.
-// Additionally, Step timeouts are added as entrypoint flag.
-func orderContainers(entrypointImage string, commonExtraEntrypointArgs []string, steps []corev1.Container, taskSpec *v1beta1.TaskSpec) (corev1.Container, []corev1.Container, error) {
+// Additionally, Step timeouts, onError behaviors and output files are added as entrypoint flags,
+// and the breakpoints of the TaskRun are set up when it is debugged.
+func orderContainers(entrypointImage string, commonExtraEntrypointArgs []string, steps []corev1.Container, taskSpec *v1beta1.TaskSpec, breakpointConfig *v1beta1.TaskRunDebug) (corev1.Container, []corev1.Container, error) {
 	initContainer := corev1.Container{
 		Name:  "place-tools",
 		Image: entrypointImage,
@@ -100,10 +114,32 @@ func orderContainers(entrypointImage string, commonExtraEntrypointArgs []string,
 		return corev1.Container{}, nil, errors.New("No steps specified")
 	}
 
+	// The OnFailure steps of the Task follow its Steps, and only run when one
+	// of them failed.
+	var specs []v1beta1.Step
+	firstOnFailure := len(steps)
+	if taskSpec != nil {
+		specs = append(append(specs, taskSpec.Steps...), taskSpec.OnFailure...)
+		if len(taskSpec.OnFailure) > 0 {
+			firstOnFailure = len(taskSpec.Steps)
+		}
+	}
+
 	for i, s := range steps {
 		var argsForEntrypoint []string
-		switch i {
-		case 0:
+		switch {
+		case i >= firstOnFailure:
+			// OnFailure steps wait for the previous OnFailure step, and
+			// check whether the last step failed.
+			if i > firstOnFailure {
+				argsForEntrypoint = []string{"-wait_file", filepath.Join(mountPoint, fmt.Sprintf("%d", i-1))}
+			}
+			argsForEntrypoint = append(argsForEntrypoint,
+				"-on_failure_file", filepath.Join(mountPoint, fmt.Sprintf("%d", firstOnFailure-1)),
+				"-post_file", filepath.Join(mountPoint, fmt.Sprintf("%d", i)),
+				"-termination_path", terminationPath,
+			)
+		case i == 0:
 			argsForEntrypoint = []string{
 				// First step waits for the Downward volume file.
 				"-wait_file", filepath.Join(downwardMountPoint, downwardMountReadyFile),
@@ -122,11 +158,28 @@ func orderContainers(entrypointImage string, commonExtraEntrypointArgs []string,
 		}
 		argsForEntrypoint = append(argsForEntrypoint, commonExtraEntrypointArgs...)
 		if taskSpec != nil {
-			if taskSpec.Steps != nil && len(taskSpec.Steps) >= i+1 && taskSpec.Steps[i].Timeout != nil {
-				argsForEntrypoint = append(argsForEntrypoint, "-timeout", taskSpec.Steps[i].Timeout.Duration.String())
+			if len(specs) >= i+1 && specs[i].Timeout != nil {
+				argsForEntrypoint = append(argsForEntrypoint, "-timeout", specs[i].Timeout.Duration.String())
+			}
+			if len(specs) >= i+1 && specs[i].OnError != "" {
+				argsForEntrypoint = append(argsForEntrypoint, "-on_error", string(specs[i].OnError))
+			}
+			if len(specs) >= i+1 && specs[i].StdoutConfig != nil {
+				argsForEntrypoint = append(argsForEntrypoint, "-stdout_path", specs[i].StdoutConfig.Path)
+			}
+			if len(specs) >= i+1 && specs[i].StderrConfig != nil {
+				argsForEntrypoint = append(argsForEntrypoint, "-stderr_path", specs[i].StderrConfig.Path)
 			}
 			argsForEntrypoint = append(argsForEntrypoint, resultArgument(steps, taskSpec.Results)...)
 		}
+		var needsSidecars bool
+		if len(specs) >= i+1 && len(specs[i].Sidecars) > 0 {
+			needsSidecars = true
+			argsForEntrypoint = append(argsForEntrypoint, "-sidecar_ready_files", sidecarReadyFiles(specs[i].Sidecars))
+		}
+		if breakpointConfig.NeedsBreakpointOnFailure() {
+			argsForEntrypoint = append(argsForEntrypoint, "-breakpoint_on_failure")
+		}
 
 		cmd, args := s.Command, s.Args
 		if len(cmd) == 0 {
@@ -143,6 +196,14 @@ func orderContainers(entrypointImage string, commonExtraEntrypointArgs []string,
 		steps[i].Args = argsForEntrypoint
 		steps[i].VolumeMounts = append(steps[i].VolumeMounts, toolsMount)
 		steps[i].TerminationMessagePath = terminationPath
+		// Mount the Downward volume to wait for the Sidecars the step needs,
+		// the first step always has it mounted.
+		if needsSidecars && i > 0 {
+			steps[i].VolumeMounts = append(steps[i].VolumeMounts, downwardMount)
+		}
+		if breakpointConfig.NeedsBreakpointOnFailure() {
+			steps[i].ReadinessProbe = breakpointProbe(filepath.Join(mountPoint, fmt.Sprintf("%d", i)))
+		}
 	}
 	// Mount the Downward volume into the first step container.
 	steps[0].VolumeMounts = append(steps[0].VolumeMounts, downwardMount)
@@ -150,6 +211,56 @@ func orderContainers(entrypointImage string, commonExtraEntrypointArgs []string,
 	return initContainer, steps, nil
 }
 
+// sidecarReadyFiles returns the paths of the Downward volume files signalling
+// that the named Sidecars are ready.
+func sidecarReadyFiles(sidecars []string) string {
+	var files []string
+	for _, name := range sidecars {
+		files = append(files, filepath.Join(downwardMountPoint, sidecarContainerName(name)))
+	}
+	return strings.Join(files, ",")
+}
+
+// sidecarDownwardVolume returns the Downward volume, also projecting the
+// ready annotation of each of the named Sidecars.
+func sidecarDownwardVolume(sidecars []string) corev1.Volume {
+	if len(sidecars) == 0 {
+		return downwardVolume
+	}
+	items := append([]corev1.DownwardAPIVolumeFile{}, downwardVolume.DownwardAPI.Items...)
+	for _, name := range sidecars {
+		containerName := sidecarContainerName(name)
+		items = append(items, corev1.DownwardAPIVolumeFile{
+			Path: containerName,
+			FieldRef: &corev1.ObjectFieldSelector{
+				FieldPath: fmt.Sprintf("metadata.annotations['%s%s']", sidecarReadyAnnotationPrefix, containerName),
+			},
+		})
+	}
+	return corev1.Volume{
+		Name: downwardVolumeName,
+		VolumeSource: corev1.VolumeSource{
+			DownwardAPI: &corev1.DownwardAPIVolumeSource{Items: items},
+		},
+	}
+}
+
+// neededSidecars returns the names of the Sidecars needed by any of the
+// steps, in the order they are first declared.
+func neededSidecars(steps []v1beta1.Step) []string {
+	var names []string
+	seen := map[string]bool{}
+	for _, s := range steps {
+		for _, name := range s.Sidecars {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
+
 func resultArgument(steps []corev1.Container, results []v1beta1.TaskResult) []string {
 	if len(results) == 0 {
 		return nil
@@ -220,6 +331,99 @@ func StopSidecars(ctx context.Context, nopImage string, kubeclient kubernetes.In
 	return nil
 }
 
+// UpdateSidecarsReady updates the Pod's annotations to signal the steps
+// waiting for the Sidecars they need that those are ready, by projecting a
+// ready annotation per Sidecar via the Downward API.
+func UpdateSidecarsReady(ctx context.Context, kubeclient kubernetes.Interface, pod corev1.Pod) error {
+	if !hasStepSidecars(pod) {
+		return nil
+	}
+	newPod, err := kubeclient.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting Pod %q when updating sidecar ready annotations: %w", pod.Name, err)
+	}
+
+	updated := false
+	for _, s := range newPod.Status.ContainerStatuses {
+		if _, ok := newPod.ObjectMeta.Annotations[sidecarStepsAnnotationPrefix+s.Name]; !ok {
+			continue
+		}
+		if (s.State.Running != nil && s.Ready) || s.State.Terminated != nil {
+			key := sidecarReadyAnnotationPrefix + s.Name
+			if newPod.ObjectMeta.Annotations[key] != readyAnnotationValue {
+				newPod.ObjectMeta.Annotations[key] = readyAnnotationValue
+				updated = true
+			}
+		}
+	}
+	if updated {
+		if _, err := kubeclient.CoreV1().Pods(newPod.Namespace).Update(ctx, newPod, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("error adding sidecar ready annotations to Pod %q: %w", pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// StopUnneededSidecars updates the Sidecar containers in the Pod whose
+// steps needing them have all terminated to a nop image, which exits
+// successfully immediately.
+func StopUnneededSidecars(ctx context.Context, nopImage string, kubeclient kubernetes.Interface, pod corev1.Pod) error {
+	if !hasStepSidecars(pod) {
+		return nil
+	}
+	newPod, err := kubeclient.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+	if err != nil {
+		return fmt.Errorf("error getting Pod %q when stopping unneeded sidecars: %w", pod.Name, err)
+	}
+	if newPod.Status.Phase != corev1.PodRunning {
+		return nil
+	}
+
+	terminated := map[string]bool{}
+	for _, s := range newPod.Status.ContainerStatuses {
+		terminated[s.Name] = s.State.Terminated != nil
+	}
+	updated := false
+	for _, s := range newPod.Status.ContainerStatuses {
+		steps, ok := newPod.ObjectMeta.Annotations[sidecarStepsAnnotationPrefix+s.Name]
+		if !ok || s.State.Running == nil {
+			continue
+		}
+		needed := false
+		for _, step := range strings.Split(steps, ",") {
+			if !terminated[step] {
+				needed = true
+			}
+		}
+		if needed {
+			continue
+		}
+		for j, c := range newPod.Spec.Containers {
+			if c.Name == s.Name && c.Image != nopImage {
+				updated = true
+				newPod.Spec.Containers[j].Image = nopImage
+			}
+		}
+	}
+	if updated {
+		if _, err := kubeclient.CoreV1().Pods(newPod.Namespace).Update(ctx, newPod, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("error stopping unneeded sidecars of Pod %q: %w", pod.Name, err)
+		}
+	}
+	return nil
+}
+
+// hasStepSidecars returns true if any step of the Pod declared the Sidecars
+// it needs.
+func hasStepSidecars(pod corev1.Pod) bool {
+	for key := range pod.ObjectMeta.Annotations {
+		if strings.HasPrefix(key, sidecarStepsAnnotationPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
 // IsSidecarStatusRunning determines if any SidecarStatus on a TaskRun
 // is still running.
 func IsSidecarStatusRunning(tr *v1beta1.TaskRun) bool {
@@ -236,6 +440,21 @@ func IsSidecarStatusRunning(tr *v1beta1.TaskRun) bool {
 // represents a step.
 func IsContainerStep(name string) bool { return strings.HasPrefix(name, stepPrefix) }
 
+// isContainerOnFailureStep returns true if the annotations of the Pod list
+// the named container as a step run when a step failed.
+func isContainerOnFailureStep(pod *corev1.Pod, name string) bool {
+	containers, ok := pod.Annotations[onFailureStepsAnnotation]
+	if !ok {
+		return false
+	}
+	for _, c := range strings.Split(containers, ",") {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
+
 // isContainerSidecar returns true if the container name indicates that it
 // represents a sidecar.
 func isContainerSidecar(name string) bool { return strings.HasPrefix(name, sidecarPrefix) }
@@ -243,6 +462,18 @@ func isContainerSidecar(name string) bool { return strings.HasPrefix(name, sidec
 // trimStepPrefix returns the container name, stripped of its step prefix.
 func trimStepPrefix(name string) string { return strings.TrimPrefix(name, stepPrefix) }
 
+// sidecarContainerName returns the name of the container of the named
+// Sidecar.
+func sidecarContainerName(name string) string {
+	return names.SimpleNameGenerator.RestrictLength(fmt.Sprintf("%v%v", sidecarPrefix, name))
+}
+
+// trimOnFailureStepPrefix returns the container name, stripped of its
+// OnFailure step prefix.
+func trimOnFailureStepPrefix(name string) string {
+	return strings.TrimPrefix(name, onFailureStepPrefix)
+}
+
 // TrimSidecarPrefix returns the container name, stripped of its sidecar
 // prefix.
 func TrimSidecarPrefix(name string) string { return strings.TrimPrefix(name, sidecarPrefix) }
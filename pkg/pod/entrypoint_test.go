@@ -88,7 +88,7 @@ func TestOrderContainers(t *testing.T) {
 		VolumeMounts:           []corev1.VolumeMount{toolsMount},
 		TerminationMessagePath: "/tekton/termination",
 	}}
-	gotInit, got, err := orderContainers(images.EntrypointImage, []string{}, steps, nil)
+	gotInit, got, err := orderContainers(images.EntrypointImage, []string{}, steps, nil, nil)
 	if err != nil {
 		t.Fatalf("orderContainers: %v", err)
 	}
@@ -174,7 +174,7 @@ func TestEntryPointResults(t *testing.T) {
 		VolumeMounts:           []corev1.VolumeMount{toolsMount},
 		TerminationMessagePath: "/tekton/termination",
 	}}
-	_, got, err := orderContainers(images.EntrypointImage, []string{}, steps, &taskSpec)
+	_, got, err := orderContainers(images.EntrypointImage, []string{}, steps, &taskSpec, nil)
 	if err != nil {
 		t.Fatalf("orderContainers: %v", err)
 	}
@@ -214,7 +214,7 @@ func TestEntryPointResultsSingleStep(t *testing.T) {
 		VolumeMounts:           []corev1.VolumeMount{toolsMount, downwardMount},
 		TerminationMessagePath: "/tekton/termination",
 	}}
-	_, got, err := orderContainers(images.EntrypointImage, []string{}, steps, &taskSpec)
+	_, got, err := orderContainers(images.EntrypointImage, []string{}, steps, &taskSpec, nil)
 	if err != nil {
 		t.Fatalf("orderContainers: %v", err)
 	}
@@ -250,7 +250,7 @@ func TestEntryPointSingleResultsSingleStep(t *testing.T) {
 		VolumeMounts:           []corev1.VolumeMount{toolsMount, downwardMount},
 		TerminationMessagePath: "/tekton/termination",
 	}}
-	_, got, err := orderContainers(images.EntrypointImage, []string{}, steps, &taskSpec)
+	_, got, err := orderContainers(images.EntrypointImage, []string{}, steps, &taskSpec, nil)
 	if err != nil {
 		t.Fatalf("orderContainers: %v", err)
 	}
@@ -258,6 +258,289 @@ func TestEntryPointSingleResultsSingleStep(t *testing.T) {
 		t.Errorf("Diff %s", diff.PrintWantGot(d))
 	}
 }
+func TestEntryPointOnError(t *testing.T) {
+	taskSpec := v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{Name: "failing-step"},
+			OnError:   v1beta1.StepContinue,
+		}, {
+			Container: corev1.Container{Name: "passing-step"},
+			OnError:   v1beta1.StepStopAndFail,
+		}},
+	}
+
+	steps := []corev1.Container{{
+		Name:    "failing-step",
+		Image:   "step-1",
+		Command: []string{"cmd"},
+	}, {
+		Name:    "passing-step",
+		Image:   "step-2",
+		Command: []string{"cmd"},
+	}}
+	want := []corev1.Container{{
+		Name:    "failing-step",
+		Image:   "step-1",
+		Command: []string{entrypointBinary},
+		Args: []string{
+			"-wait_file", "/tekton/downward/ready",
+			"-wait_file_content",
+			"-post_file", "/tekton/tools/0",
+			"-termination_path", "/tekton/termination",
+			"-on_error", "continue",
+			"-entrypoint", "cmd", "--",
+		},
+		VolumeMounts:           []corev1.VolumeMount{toolsMount, downwardMount},
+		TerminationMessagePath: "/tekton/termination",
+	}, {
+		Name:    "passing-step",
+		Image:   "step-2",
+		Command: []string{entrypointBinary},
+		Args: []string{
+			"-wait_file", "/tekton/tools/0",
+			"-post_file", "/tekton/tools/1",
+			"-termination_path", "/tekton/termination",
+			"-on_error", "stopAndFail",
+			"-entrypoint", "cmd", "--",
+		},
+		VolumeMounts:           []corev1.VolumeMount{toolsMount},
+		TerminationMessagePath: "/tekton/termination",
+	}}
+	_, got, err := orderContainers(images.EntrypointImage, []string{}, steps, &taskSpec, nil)
+	if err != nil {
+		t.Fatalf("orderContainers: %v", err)
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestEntryPointOnFailureSteps(t *testing.T) {
+	taskSpec := v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{Name: "build"},
+		}, {
+			Container: corev1.Container{Name: "test"},
+		}},
+		OnFailure: []v1beta1.Step{{
+			Container: corev1.Container{Name: "collect"},
+		}, {
+			Container: corev1.Container{Name: "notify"},
+			OnError:   v1beta1.StepContinue,
+		}},
+	}
+
+	steps := []corev1.Container{{
+		Name:    "build",
+		Image:   "step-1",
+		Command: []string{"cmd"},
+	}, {
+		Name:    "test",
+		Image:   "step-2",
+		Command: []string{"cmd"},
+	}, {
+		Name:    "collect",
+		Image:   "step-3",
+		Command: []string{"cmd"},
+	}, {
+		Name:    "notify",
+		Image:   "step-4",
+		Command: []string{"cmd"},
+	}}
+	want := []corev1.Container{{
+		Name:    "build",
+		Image:   "step-1",
+		Command: []string{entrypointBinary},
+		Args: []string{
+			"-wait_file", "/tekton/downward/ready",
+			"-wait_file_content",
+			"-post_file", "/tekton/tools/0",
+			"-termination_path", "/tekton/termination",
+			"-entrypoint", "cmd", "--",
+		},
+		VolumeMounts:           []corev1.VolumeMount{toolsMount, downwardMount},
+		TerminationMessagePath: "/tekton/termination",
+	}, {
+		Name:    "test",
+		Image:   "step-2",
+		Command: []string{entrypointBinary},
+		Args: []string{
+			"-wait_file", "/tekton/tools/0",
+			"-post_file", "/tekton/tools/1",
+			"-termination_path", "/tekton/termination",
+			"-entrypoint", "cmd", "--",
+		},
+		VolumeMounts:           []corev1.VolumeMount{toolsMount},
+		TerminationMessagePath: "/tekton/termination",
+	}, {
+		Name:    "collect",
+		Image:   "step-3",
+		Command: []string{entrypointBinary},
+		Args: []string{
+			"-on_failure_file", "/tekton/tools/1",
+			"-post_file", "/tekton/tools/2",
+			"-termination_path", "/tekton/termination",
+			"-entrypoint", "cmd", "--",
+		},
+		VolumeMounts:           []corev1.VolumeMount{toolsMount},
+		TerminationMessagePath: "/tekton/termination",
+	}, {
+		Name:    "notify",
+		Image:   "step-4",
+		Command: []string{entrypointBinary},
+		Args: []string{
+			"-wait_file", "/tekton/tools/2",
+			"-on_failure_file", "/tekton/tools/1",
+			"-post_file", "/tekton/tools/3",
+			"-termination_path", "/tekton/termination",
+			"-on_error", "continue",
+			"-entrypoint", "cmd", "--",
+		},
+		VolumeMounts:           []corev1.VolumeMount{toolsMount},
+		TerminationMessagePath: "/tekton/termination",
+	}}
+	_, got, err := orderContainers(images.EntrypointImage, []string{}, steps, &taskSpec, nil)
+	if err != nil {
+		t.Fatalf("orderContainers: %v", err)
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestEntryPointStepOutputConfigs(t *testing.T) {
+	taskSpec := v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container:    corev1.Container{Name: "step-with-output"},
+			StdoutConfig: &v1beta1.StepOutputConfig{Path: "/tekton/results/out"},
+			StderrConfig: &v1beta1.StepOutputConfig{Path: "/workspace/logs/stderr"},
+		}},
+	}
+
+	steps := []corev1.Container{{
+		Name:    "step-with-output",
+		Image:   "step-1",
+		Command: []string{"cmd"},
+	}}
+	want := []corev1.Container{{
+		Name:    "step-with-output",
+		Image:   "step-1",
+		Command: []string{entrypointBinary},
+		Args: []string{
+			"-wait_file", "/tekton/downward/ready",
+			"-wait_file_content",
+			"-post_file", "/tekton/tools/0",
+			"-termination_path", "/tekton/termination",
+			"-stdout_path", "/tekton/results/out",
+			"-stderr_path", "/workspace/logs/stderr",
+			"-entrypoint", "cmd", "--",
+		},
+		VolumeMounts:           []corev1.VolumeMount{toolsMount, downwardMount},
+		TerminationMessagePath: "/tekton/termination",
+	}}
+	_, got, err := orderContainers(images.EntrypointImage, []string{}, steps, &taskSpec, nil)
+	if err != nil {
+		t.Fatalf("orderContainers: %v", err)
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestEntryPointBreakpointOnFailure(t *testing.T) {
+	steps := []corev1.Container{{
+		Name:    "failing-step",
+		Image:   "step-1",
+		Command: []string{"cmd"},
+	}}
+	want := []corev1.Container{{
+		Name:    "failing-step",
+		Image:   "step-1",
+		Command: []string{entrypointBinary},
+		Args: []string{
+			"-wait_file", "/tekton/downward/ready",
+			"-wait_file_content",
+			"-post_file", "/tekton/tools/0",
+			"-termination_path", "/tekton/termination",
+			"-breakpoint_on_failure",
+			"-entrypoint", "cmd", "--",
+		},
+		VolumeMounts:           []corev1.VolumeMount{toolsMount, downwardMount},
+		TerminationMessagePath: "/tekton/termination",
+		ReadinessProbe: &corev1.Probe{
+			Handler: corev1.Handler{
+				Exec: &corev1.ExecAction{
+					Command: []string{entrypointBinary, "breakpoint-probe", "/tekton/tools/0"},
+				},
+			},
+			PeriodSeconds: 1,
+		},
+	}}
+	_, got, err := orderContainers(images.EntrypointImage, []string{}, steps, &v1beta1.TaskSpec{}, &v1beta1.TaskRunDebug{
+		Breakpoint: []string{v1beta1.BreakpointOnFailure},
+	})
+	if err != nil {
+		t.Fatalf("orderContainers: %v", err)
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestEntryPointStepSidecars(t *testing.T) {
+	taskSpec := v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{Name: "build"},
+		}, {
+			Container: corev1.Container{Name: "test"},
+			Sidecars:  []string{"database", "cache"},
+		}},
+	}
+	steps := []corev1.Container{{
+		Name:    "build",
+		Image:   "step-1",
+		Command: []string{"cmd"},
+	}, {
+		Name:    "test",
+		Image:   "step-2",
+		Command: []string{"cmd"},
+	}}
+	want := []corev1.Container{{
+		Name:    "build",
+		Image:   "step-1",
+		Command: []string{entrypointBinary},
+		Args: []string{
+			"-wait_file", "/tekton/downward/ready",
+			"-wait_file_content",
+			"-post_file", "/tekton/tools/0",
+			"-termination_path", "/tekton/termination",
+			"-entrypoint", "cmd", "--",
+		},
+		VolumeMounts:           []corev1.VolumeMount{toolsMount, downwardMount},
+		TerminationMessagePath: "/tekton/termination",
+	}, {
+		Name:    "test",
+		Image:   "step-2",
+		Command: []string{entrypointBinary},
+		Args: []string{
+			"-wait_file", "/tekton/tools/0",
+			"-post_file", "/tekton/tools/1",
+			"-termination_path", "/tekton/termination",
+			"-sidecar_ready_files", "/tekton/downward/sidecar-database,/tekton/downward/sidecar-cache",
+			"-entrypoint", "cmd", "--",
+		},
+		VolumeMounts:           []corev1.VolumeMount{toolsMount, downwardMount},
+		TerminationMessagePath: "/tekton/termination",
+	}}
+	_, got, err := orderContainers(images.EntrypointImage, []string{}, steps, &taskSpec, nil)
+	if err != nil {
+		t.Fatalf("orderContainers: %v", err)
+	}
+	if d := cmp.Diff(want, got); d != "" {
+		t.Errorf("Diff %s", diff.PrintWantGot(d))
+	}
+}
+
 func TestUpdateReady(t *testing.T) {
 	for _, c := range []struct {
 		desc            string
@@ -438,3 +721,155 @@ func TestStopSidecars(t *testing.T) {
 		})
 	}
 }
+
+func TestUpdateSidecarsReady(t *testing.T) {
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(time.Now())}}
+	for _, c := range []struct {
+		desc            string
+		pod             corev1.Pod
+		wantAnnotations map[string]string
+	}{{
+		desc: "ready and terminated sidecars needed by steps are signalled ready",
+		pod: corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pod",
+				Annotations: map[string]string{
+					sidecarStepsAnnotationPrefix + "sidecar-database": "step-test",
+					sidecarStepsAnnotationPrefix + "sidecar-cache":    "step-test",
+					sidecarStepsAnnotationPrefix + "sidecar-queue":    "step-test",
+				},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "sidecar-database",
+					State: running,
+					Ready: true,
+				}, {
+					Name:  "sidecar-cache",
+					State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}},
+				}, {
+					Name:  "sidecar-queue",
+					State: running,
+				}, {
+					Name:  "sidecar-ungated",
+					State: running,
+					Ready: true,
+				}},
+			},
+		},
+		wantAnnotations: map[string]string{
+			sidecarStepsAnnotationPrefix + "sidecar-database": "step-test",
+			sidecarStepsAnnotationPrefix + "sidecar-cache":    "step-test",
+			sidecarStepsAnnotationPrefix + "sidecar-queue":    "step-test",
+			sidecarReadyAnnotationPrefix + "sidecar-database": readyAnnotationValue,
+			sidecarReadyAnnotationPrefix + "sidecar-cache":    readyAnnotationValue,
+		},
+	}, {
+		desc: "Pod without sidecars needed by steps is not updated",
+		pod: corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "pod",
+				Annotations: map[string]string{"something": "else"},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "sidecar-database",
+					State: running,
+					Ready: true,
+				}},
+			},
+		},
+		wantAnnotations: map[string]string{"something": "else"},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			kubeclient := fakek8s.NewSimpleClientset(&c.pod)
+			if err := UpdateSidecarsReady(ctx, kubeclient, c.pod); err != nil {
+				t.Errorf("UpdateSidecarsReady: %v", err)
+			}
+
+			got, err := kubeclient.CoreV1().Pods(c.pod.Namespace).Get(ctx, c.pod.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Errorf("Getting pod %q after update: %v", c.pod.Name, err)
+			} else if d := cmp.Diff(c.wantAnnotations, got.Annotations); d != "" {
+				t.Errorf("Annotations Diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+// TestStopUnneededSidecars tests stopping the sidecars whose steps needing
+// them have all terminated.
+func TestStopUnneededSidecars(t *testing.T) {
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(time.Now())}}
+	terminated := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}
+	containers := []corev1.Container{
+		{Name: "step-build", Image: "builder"},
+		{Name: "step-test", Image: "tester"},
+		{Name: "sidecar-database", Image: "postgres"},
+		{Name: "sidecar-cache", Image: "redis"},
+		{Name: "sidecar-ungated", Image: "proxy"},
+	}
+	annotations := map[string]string{
+		sidecarStepsAnnotationPrefix + "sidecar-database": "step-build",
+		sidecarStepsAnnotationPrefix + "sidecar-cache":    "step-build,step-test",
+	}
+	for _, c := range []struct {
+		desc           string
+		statuses       []corev1.ContainerStatus
+		wantContainers []corev1.Container
+	}{{
+		desc: "sidecars needed by running steps are not stopped",
+		statuses: []corev1.ContainerStatus{
+			{Name: "step-build", State: running},
+			{Name: "step-test", State: running},
+			{Name: "sidecar-database", State: running},
+			{Name: "sidecar-cache", State: running},
+			{Name: "sidecar-ungated", State: running},
+		},
+		wantContainers: containers,
+	}, {
+		desc: "sidecars whose steps have all terminated are stopped",
+		statuses: []corev1.ContainerStatus{
+			{Name: "step-build", State: terminated},
+			{Name: "step-test", State: running},
+			{Name: "sidecar-database", State: running},
+			{Name: "sidecar-cache", State: running},
+			{Name: "sidecar-ungated", State: running},
+		},
+		wantContainers: []corev1.Container{
+			{Name: "step-build", Image: "builder"},
+			{Name: "step-test", Image: "tester"},
+			{Name: "sidecar-database", Image: nopImage},
+			{Name: "sidecar-cache", Image: "redis"},
+			{Name: "sidecar-ungated", Image: "proxy"},
+		},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			pod := corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-pod", Annotations: annotations},
+				Spec:       corev1.PodSpec{Containers: append([]corev1.Container{}, containers...)},
+				Status: corev1.PodStatus{
+					Phase:             corev1.PodRunning,
+					ContainerStatuses: c.statuses,
+				},
+			}
+			kubeclient := fakek8s.NewSimpleClientset(&pod)
+			if err := StopUnneededSidecars(ctx, nopImage, kubeclient, pod); err != nil {
+				t.Errorf("error stopping unneeded sidecars: %v", err)
+			}
+
+			got, err := kubeclient.CoreV1().Pods(pod.Namespace).Get(ctx, pod.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Errorf("Getting pod %q after update: %v", pod.Name, err)
+			} else if d := cmp.Diff(c.wantContainers, got.Spec.Containers); d != "" {
+				t.Errorf("Containers Diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/logarchive"
+	"github.com/tektoncd/pipeline/pkg/system"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const logArchiveVolumeName = "tekton-internal-log-archive"
+
+// logArchiveUploadURLMargin is added to the timeout of the TaskRun for the
+// upload URLs of the logs of its steps to expire, leaving time for its Pod to
+// start and for the logs to be uploaded.
+const logArchiveUploadURLMargin = time.Hour
+
+// logArchiveCredentials reads the credentials of the log archive from the
+// configured Secret, in the namespace of the controller.
+func logArchiveCredentials(ctx context.Context, kubeclient kubernetes.Interface, cfg *config.LogArchive) (logarchive.Credentials, error) {
+	if cfg == nil || cfg.Location == "" || cfg.SecretName == "" {
+		return logarchive.Credentials{}, nil
+	}
+	secret, err := kubeclient.CoreV1().Secrets(system.GetNamespace()).Get(ctx, cfg.SecretName, metav1.GetOptions{})
+	if err != nil {
+		return logarchive.Credentials{}, fmt.Errorf("failed to get log archive secret %s: %w", cfg.SecretName, err)
+	}
+	return logarchive.Credentials{
+		AccessKeyID:     string(secret.Data["access-key-id"]),
+		SecretAccessKey: string(secret.Data["secret-access-key"]),
+		Token:           string(secret.Data["token"]),
+	}, nil
+}
+
+// archiveStepLogs makes the entrypoint of each step archive its logs to the
+// configured log archive, under the namespace and name of the TaskRun. The
+// steps are only given a URL to upload their own log to, signed with the
+// credentials of the archive until expiry. It returns the volumes needed by
+// the steps to reach the archive.
+func archiveStepLogs(cfg *config.LogArchive, creds logarchive.Credentials, expiry time.Duration, namespace, taskRunName string, steps []corev1.Container) ([]corev1.Container, []corev1.Volume, error) {
+	if cfg == nil || cfg.Location == "" {
+		return steps, nil, nil
+	}
+	u, err := url.Parse(cfg.Location)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse log archive location %q: %w", cfg.Location, err)
+	}
+
+	now := time.Now()
+	var volumes []corev1.Volume
+	if u.Scheme == "pvc" {
+		volumes = append(volumes, corev1.Volume{
+			Name: logArchiveVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: u.Host},
+			},
+		})
+	}
+
+	for i, s := range steps {
+		location, err := logarchive.StepLocation(cfg.Location, namespace, taskRunName, trimStepPrefix(s.Name))
+		if err != nil {
+			return nil, nil, err
+		}
+		uploadURL, err := logarchive.UploadURL(location, creds, expiry, now)
+		if err != nil {
+			return nil, nil, err
+		}
+		// The entrypoint flags come first in the args of the steps.
+		steps[i].Args = append([]string{"-log_archive_location", location, "-log_archive_upload_url", uploadURL}, s.Args...)
+		if len(volumes) > 0 {
+			// Only the directory of the logs of the TaskRun is mounted, so
+			// that the steps cannot reach the logs of the other TaskRuns.
+			locationURL, err := url.Parse(location)
+			if err != nil {
+				return nil, nil, err
+			}
+			steps[i].VolumeMounts = append(steps[i].VolumeMounts, corev1.VolumeMount{
+				Name:      logArchiveVolumeName,
+				MountPath: logarchive.PVCMountPath,
+				SubPath:   strings.TrimPrefix(path.Dir(locationURL.Path), "/"),
+			})
+		}
+	}
+	return steps, volumes, nil
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pod
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/logarchive"
+	"github.com/tektoncd/pipeline/pkg/system"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakek8s "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLogArchiveCredentials(t *testing.T) {
+	kubeclient := fakek8s.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "log-archive-creds", Namespace: system.GetNamespace()},
+		Data: map[string][]byte{
+			"access-key-id":     []byte("AKID"),
+			"secret-access-key": []byte("SECRET"),
+		},
+	})
+	got, err := logArchiveCredentials(context.Background(), kubeclient, &config.LogArchive{Location: "s3://logs", SecretName: "log-archive-creds"})
+	if err != nil {
+		t.Fatalf("logArchiveCredentials: %v", err)
+	}
+	if d := cmp.Diff(logarchive.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"}, got); d != "" {
+		t.Errorf("Diff credentials %s", diff.PrintWantGot(d))
+	}
+
+	if _, err := logArchiveCredentials(context.Background(), kubeclient, &config.LogArchive{Location: "s3://logs", SecretName: "missing"}); err == nil {
+		t.Error("Expected an error for a missing secret, got nil")
+	}
+}
+
+func TestArchiveStepLogs(t *testing.T) {
+	steps := func() []corev1.Container {
+		return []corev1.Container{{
+			Name: "step-build",
+			Args: []string{"-post_file", "/tekton/tools/0", "-entrypoint", "make", "--"},
+		}, {
+			Name: "step-on-failure-notify",
+			Args: []string{"-post_file", "/tekton/tools/1", "-entrypoint", "notify", "--"},
+		}}
+	}
+	// withArgs returns the steps with the given entrypoint flags of the log
+	// archive, the upload URL being compared separately.
+	withArgs := func(steps []corev1.Container, locations ...string) []corev1.Container {
+		for i := range steps {
+			steps[i].Args = append([]string{"-log_archive_location", locations[i], "-log_archive_upload_url", ""}, steps[i].Args...)
+		}
+		return steps
+	}
+
+	for _, c := range []struct {
+		desc           string
+		cfg            *config.LogArchive
+		creds          logarchive.Credentials
+		wantSteps      []corev1.Container
+		wantUploadURLs []string
+		wantVolumes    []corev1.Volume
+	}{{
+		desc:      "no log archive",
+		cfg:       &config.LogArchive{},
+		wantSteps: steps(),
+	}, {
+		desc:  "s3 bucket with credentials",
+		cfg:   &config.LogArchive{Location: "s3://logs/tekton?region=eu-west-1", SecretName: "log-archive-creds"},
+		creds: logarchive.Credentials{AccessKeyID: "AKID", SecretAccessKey: "SECRET"},
+		wantSteps: withArgs(steps(),
+			"s3://logs/tekton/ns/tr/build.log?region=eu-west-1",
+			"s3://logs/tekton/ns/tr/on-failure-notify.log?region=eu-west-1"),
+		wantUploadURLs: []string{
+			"https://s3.eu-west-1.amazonaws.com/logs/tekton/ns/tr/build.log?",
+			"https://s3.eu-west-1.amazonaws.com/logs/tekton/ns/tr/on-failure-notify.log?",
+		},
+	}, {
+		desc: "persistent volume claim",
+		cfg:  &config.LogArchive{Location: "pvc://logs-claim/archive"},
+		wantSteps: func() []corev1.Container {
+			s := withArgs(steps(),
+				"pvc://logs-claim/archive/ns/tr/build.log",
+				"pvc://logs-claim/archive/ns/tr/on-failure-notify.log")
+			for i := range s {
+				s[i].VolumeMounts = []corev1.VolumeMount{{Name: logArchiveVolumeName, MountPath: logarchive.PVCMountPath, SubPath: "archive/ns/tr"}}
+			}
+			return s
+		}(),
+		wantUploadURLs: []string{
+			"pvc://logs-claim/archive/ns/tr/build.log",
+			"pvc://logs-claim/archive/ns/tr/on-failure-notify.log",
+		},
+		wantVolumes: []corev1.Volume{{
+			Name: logArchiveVolumeName,
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{ClaimName: "logs-claim"},
+			},
+		}},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			gotSteps, gotVolumes, err := archiveStepLogs(c.cfg, c.creds, time.Hour, "ns", "tr", steps())
+			if err != nil {
+				t.Fatalf("archiveStepLogs: %v", err)
+			}
+			for i, uploadURL := range c.wantUploadURLs {
+				got := gotSteps[i].Args[3]
+				if !strings.HasPrefix(got, uploadURL) {
+					t.Errorf("Expected the upload URL of step %d to start with %s, got %s", i, uploadURL, got)
+				}
+				if strings.Contains(got, "SECRET") {
+					t.Errorf("Expected the secret access key to be kept out of the upload URL, got %s", got)
+				}
+				gotSteps[i].Args[3] = ""
+			}
+			if d := cmp.Diff(c.wantSteps, gotSteps); d != "" {
+				t.Errorf("Diff steps %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff(c.wantVolumes, gotVolumes); d != "" {
+				t.Errorf("Diff volumes %s", diff.PrintWantGot(d))
+			}
+			for _, s := range gotSteps {
+				if len(s.Env) != 0 {
+					t.Errorf("Expected no credentials in the environment of %s, got %v", s.Name, s.Env)
+				}
+			}
+		})
+	}
+}
@@ -20,11 +20,14 @@ import (
 	"context"
 	"fmt"
 	"path/filepath"
+	"strings"
 
 	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/logarchive"
 	"github.com/tektoncd/pipeline/pkg/names"
+	"github.com/tektoncd/pipeline/pkg/tracing"
 	"github.com/tektoncd/pipeline/pkg/version"
 	"github.com/tektoncd/pipeline/pkg/workspace"
 	corev1 "k8s.io/api/core/v1"
@@ -102,6 +105,14 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 		})
 	}
 
+	// Let the Steps join the trace of the TaskRun.
+	if traceParent := tracing.TraceParent(ctx); traceParent != "" {
+		implicitEnvVars = append(implicitEnvVars, corev1.EnvVar{
+			Name:  tracing.TraceParentEnvVar,
+			Value: traceParent,
+		})
+	}
+
 	// Create Volumes and VolumeMounts for any credentials found in annotated
 	// Secrets, along with any arguments needed by Step entrypoints to process
 	// those secrets.
@@ -118,10 +129,28 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 	if err != nil {
 		return nil, err
 	}
+	// The OnFailure steps run in the same Pod, after the Steps.
+	onFailureSteps, err := v1beta1.MergeStepsWithStepTemplate(taskSpec.StepTemplate, taskSpec.OnFailure)
+	if err != nil {
+		return nil, err
+	}
+	if len(onFailureSteps) > 0 {
+		steps = append(append([]v1beta1.Step{}, steps...), onFailureSteps...)
+	}
+	// Merge the overrides of the TaskRun with steps and sidecars.
+	steps, err = v1beta1.MergeStepsWithOverrides(steps, taskRun.Spec.StepOverrides)
+	if err != nil {
+		return nil, err
+	}
+	sidecars, err := v1beta1.MergeSidecarsWithOverrides(taskSpec.Sidecars, taskRun.Spec.SidecarOverrides)
+	if err != nil {
+		return nil, err
+	}
+	steps = applyComputeResources(steps, taskRun.Spec.ComputeResources)
 
 	// Convert any steps with Script to command+args.
 	// If any are found, append an init container to initialize scripts.
-	scriptsInit, stepContainers, sidecarContainers := convertScripts(b.Images.ShellImage, steps, taskSpec.Sidecars)
+	scriptsInit, stepContainers, sidecarContainers := convertScripts(b.Images.ShellImage, b.Images.EntrypointImage, shouldPlaceScriptsWithEntrypoint(ctx), taskSpec.ScriptPreamble, steps, sidecars)
 	if scriptsInit != nil {
 		initContainers = append(initContainers, *scriptsInit)
 		volumes = append(volumes, scriptsVolume)
@@ -141,12 +170,19 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 	// Rewrite steps with entrypoint binary. Append the entrypoint init
 	// container to place the entrypoint binary. Also add timeout flags
 	// to entrypoint binary.
-	entrypointInit, stepContainers, err := orderContainers(b.Images.EntrypointImage, credEntrypointArgs, stepContainers, &taskSpec)
+	entrypointInit, stepContainers, err := orderContainers(b.Images.EntrypointImage, credEntrypointArgs, stepContainers, &taskSpec, taskRun.Spec.Debug)
 	if err != nil {
 		return nil, err
 	}
 	initContainers = append(initContainers, entrypointInit)
-	volumes = append(volumes, toolsVolume, downwardVolume)
+	stepSidecars := neededSidecars(taskSpec.Steps)
+	volumes = append(volumes, toolsVolume, sidecarDownwardVolume(stepSidecars))
+
+	// Place the scripts to continue or abort the execution of a Step
+	// waiting at a breakpoint when the TaskRun is debugged.
+	if taskRun.Spec.Debug.NeedsBreakpointOnFailure() {
+		initContainers = append(initContainers, debugScriptsInit(b.Images.ShellImage, b.Images.EntrypointImage, shouldPlaceScriptsWithEntrypoint(ctx)))
+	}
 
 	limitRangeMin, err := getLimitRangeMinimum(ctx, taskRun.Namespace, b.KubeClient)
 	if err != nil {
@@ -190,20 +226,46 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 
 	// This loop:
 	// - defaults workingDir to /workspace
-	// - sets container name to add "step-" prefix or "step-unnamed-#" if not specified.
+	// - sets container name to add "step-" prefix or "step-unnamed-#" if not specified,
+	//   and "step-on-failure-" prefix or "step-on-failure-unnamed-#" for OnFailure steps.
 	// TODO(#1605): Remove this loop and make each transformation in
 	// isolation.
 	shouldOverrideWorkingDir := shouldOverrideWorkingDir(ctx)
+	var onFailureContainers []string
 	for i, s := range stepContainers {
 		if s.WorkingDir == "" && shouldOverrideWorkingDir {
 			stepContainers[i].WorkingDir = pipeline.WorkspaceDir
 		}
+		prefix, index := stepPrefix, i
+		onFailure := len(taskSpec.OnFailure) > 0 && i >= len(taskSpec.Steps)
+		if onFailure {
+			prefix, index = onFailureStepPrefix, i-len(taskSpec.Steps)
+		}
 		if s.Name == "" {
-			stepContainers[i].Name = names.SimpleNameGenerator.RestrictLength(fmt.Sprintf("%sunnamed-%d", stepPrefix, i))
+			stepContainers[i].Name = names.SimpleNameGenerator.RestrictLength(fmt.Sprintf("%sunnamed-%d", prefix, index))
 		} else {
-			stepContainers[i].Name = names.SimpleNameGenerator.RestrictLength(fmt.Sprintf("%s%s", stepPrefix, s.Name))
+			stepContainers[i].Name = names.SimpleNameGenerator.RestrictLength(fmt.Sprintf("%s%s", prefix, s.Name))
 		}
+		if onFailure {
+			onFailureContainers = append(onFailureContainers, stepContainers[i].Name)
+		}
+	}
+
+	// Archive the logs of the steps, now that their names are known.
+	logArchiveCfg := config.FromContextOrDefaults(ctx).LogArchive
+	logArchiveCreds, err := logArchiveCredentials(ctx, b.KubeClient, logArchiveCfg)
+	if err != nil {
+		return nil, err
+	}
+	logArchiveExpiry := logarchive.MaxUploadURLExpiry
+	if taskRun.Spec.Timeout != nil && taskRun.Spec.Timeout.Duration > 0 {
+		logArchiveExpiry = taskRun.Spec.Timeout.Duration + logArchiveUploadURLMargin
+	}
+	stepContainers, logArchiveVolumes, err := archiveStepLogs(logArchiveCfg, logArchiveCreds, logArchiveExpiry, taskRun.Namespace, taskRun.Name, stepContainers)
+	if err != nil {
+		return nil, err
 	}
+	volumes = append(volumes, logArchiveVolumes...)
 
 	// By default, use an empty pod template and take the one defined in the task run spec if any
 	podTemplate := v1beta1.PodTemplate{}
@@ -235,7 +297,12 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 
 	// Merge sidecar containers with step containers.
 	for _, sc := range sidecarContainers {
-		sc.Name = names.SimpleNameGenerator.RestrictLength(fmt.Sprintf("%v%v", sidecarPrefix, sc.Name))
+		sc.Name = sidecarContainerName(sc.Name)
+		// Capture the end of the logs of a failed sidecar in its termination
+		// message, so that the failure can be surfaced in the TaskRun status.
+		if sc.TerminationMessagePolicy == "" {
+			sc.TerminationMessagePolicy = corev1.TerminationMessageFallbackToLogsOnError
+		}
 		mergedPodContainers = append(mergedPodContainers, sc)
 	}
 
@@ -252,7 +319,33 @@ func (b *Builder) Build(ctx context.Context, taskRun *v1beta1.TaskRun, taskSpec
 	podAnnotations := taskRun.Annotations
 	podAnnotations[ReleaseAnnotation] = version.PipelineVersion
 
-	if shouldAddReadyAnnotationOnPodCreate(ctx, taskSpec.Sidecars) {
+	// Record the steps needing each Sidecar, so that the Sidecar is signalled
+	// ready to them and stopped once they have all finished. The Sidecars needed
+	// by steps don't hold back the other steps.
+	var ungatedSidecars []v1beta1.Sidecar
+	for _, sc := range taskSpec.Sidecars {
+		var stepNames []string
+		for i, s := range taskSpec.Steps {
+			for _, name := range s.Sidecars {
+				if name == sc.Name {
+					stepNames = append(stepNames, stepContainers[i].Name)
+				}
+			}
+		}
+		if len(stepNames) == 0 {
+			ungatedSidecars = append(ungatedSidecars, sc)
+			continue
+		}
+		podAnnotations[sidecarStepsAnnotationPrefix+sidecarContainerName(sc.Name)] = strings.Join(stepNames, ",")
+	}
+
+	// Record the containers of the OnFailure steps, to tell them apart from
+	// the Steps in the status of the Pod.
+	if len(onFailureContainers) > 0 {
+		podAnnotations[onFailureStepsAnnotation] = strings.Join(onFailureContainers, ",")
+	}
+
+	if shouldAddReadyAnnotationOnPodCreate(ctx, ungatedSidecars) {
 		podAnnotations[readyAnnotation] = readyAnnotationValue
 	}
 
@@ -384,6 +477,14 @@ func shouldOverrideWorkingDir(ctx context.Context) bool {
 	return !cfg.FeatureFlags.DisableWorkingDirOverwrite
 }
 
+// shouldPlaceScriptsWithEntrypoint returns a bool indicating whether the scripts
+// of the steps and sidecars should be written by the entrypoint binary instead
+// of a shell, so that no image containing a shell is needed.
+func shouldPlaceScriptsWithEntrypoint(ctx context.Context) bool {
+	cfg := config.FromContextOrDefaults(ctx)
+	return cfg.FeatureFlags.PlaceScriptsWithEntrypoint
+}
+
 // shouldAddReadyAnnotationonPodCreate returns a bool indicating whether the
 // controller should add the `Ready` annotation when creating the Pod. We cannot
 // add the annotation if Tekton is running in a cluster with injected sidecars
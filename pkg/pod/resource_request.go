@@ -17,6 +17,7 @@ limitations under the License.
 package pod
 
 import (
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 )
@@ -78,3 +79,24 @@ func resolveResourceRequests(containers []corev1.Container, limitRangeMin corev1
 
 	return containers
 }
+
+// applyComputeResources overrides the compute resources of the steps with
+// the ones requested by the TaskRun for the whole Task.
+func applyComputeResources(steps []v1beta1.Step, computeResources *corev1.ResourceRequirements) []v1beta1.Step {
+	if computeResources == nil {
+		return steps
+	}
+	// Copy the steps so that the overrides don't leak into the TaskSpec.
+	steps = append([]v1beta1.Step{}, steps...)
+	for i := range steps {
+		// Every step requests the same resources, only the maximum requests
+		// are kept once they are resolved so the Pod requests what the
+		// TaskRun asked for. Each container is limited on its own, so the
+		// limits apply to every step.
+		steps[i].Resources = corev1.ResourceRequirements{
+			Requests: computeResources.Requests.DeepCopy(),
+			Limits:   computeResources.Limits.DeepCopy(),
+		}
+	}
+	return steps
+}
@@ -20,6 +20,7 @@ import (
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/test/diff"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -344,3 +345,61 @@ func TestResolveResourceRequests_LimitRange(t *testing.T) {
 		})
 	}
 }
+
+func TestApplyComputeResources(t *testing.T) {
+	small := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m")},
+	}
+	large := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8")},
+		Limits:   corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("16Gi")},
+	}
+	limitsOnly := corev1.ResourceRequirements{
+		Limits: corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("2Gi")},
+	}
+	steps := []v1beta1.Step{{
+		Container: corev1.Container{Name: "clone", Resources: small},
+	}, {
+		Container: corev1.Container{Name: "build", Resources: small},
+	}, {
+		Container: corev1.Container{Name: "push"},
+	}}
+	for _, c := range []struct {
+		desc             string
+		computeResources *corev1.ResourceRequirements
+		want             []v1beta1.Step
+	}{{
+		desc: "no overrides",
+		want: steps,
+	}, {
+		desc:             "compute resources of the task",
+		computeResources: &large,
+		want: []v1beta1.Step{{
+			Container: corev1.Container{Name: "clone", Resources: large},
+		}, {
+			Container: corev1.Container{Name: "build", Resources: large},
+		}, {
+			Container: corev1.Container{Name: "push", Resources: large},
+		}},
+	}, {
+		desc:             "limits only",
+		computeResources: &limitsOnly,
+		want: []v1beta1.Step{{
+			Container: corev1.Container{Name: "clone", Resources: limitsOnly},
+		}, {
+			Container: corev1.Container{Name: "build", Resources: limitsOnly},
+		}, {
+			Container: corev1.Container{Name: "push", Resources: limitsOnly},
+		}},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			got := applyComputeResources(steps, c.computeResources)
+			if d := cmp.Diff(c.want, got, resourceQuantityCmp); d != "" {
+				t.Errorf("Diff %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff(small, steps[1].Resources, resourceQuantityCmp); d != "" {
+				t.Errorf("Steps of the Task changed %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
@@ -44,14 +44,24 @@ var (
 		Name:      scriptsVolumeName,
 		MountPath: scriptsDir,
 	}
+
+	// scriptLanguageShebangs are the shebangs added to the scripts which
+	// don't start with one, according to their scriptLanguage.
+	scriptLanguageShebangs = map[v1beta1.ScriptLanguage]string{
+		v1beta1.ScriptLanguagePython: "#!/usr/bin/env python3\n",
+		v1beta1.ScriptLanguageNode:   "#!/usr/bin/env node\n",
+	}
 )
 
 // convertScripts converts any steps and sidecars that specify a Script field into a normal Container.
 //
 // It does this by prepending a container that writes specified Script bodies
 // to executable files in a shared volumeMount, then produces Containers that
-// simply run those executable files.
-func convertScripts(shellImage string, steps []v1beta1.Step, sidecars []v1beta1.Sidecar) (*corev1.Container, []corev1.Container, []corev1.Container) {
+// simply run those executable files. The scripts are written by a shell from
+// the shellImage, or by the entrypoint binary from the entrypointImage if
+// placeWithEntrypoint is set. Scripts without a shebang are prefixed with the
+// preamble, which defaults to defaultScriptPreamble.
+func convertScripts(shellImage, entrypointImage string, placeWithEntrypoint bool, preamble string, steps []v1beta1.Step, sidecars []v1beta1.Sidecar) (*corev1.Container, []corev1.Container, []corev1.Container) {
 	placeScripts := false
 	placeScriptsInit := corev1.Container{
 		Name:         "place-scripts",
@@ -60,8 +70,23 @@ func convertScripts(shellImage string, steps []v1beta1.Step, sidecars []v1beta1.
 		Args:         []string{"-c", ""},
 		VolumeMounts: []corev1.VolumeMount{scriptsVolumeMount},
 	}
+	if placeWithEntrypoint {
+		placeScriptsInit = corev1.Container{
+			Name:  "place-scripts",
+			Image: entrypointImage,
+			// Invoke the entrypoint binary in "cp mode" to copy the scripts
+			// from environment variables into the scripts volume.
+			Command:      []string{"/ko-app/entrypoint", "cp"},
+			VolumeMounts: []corev1.VolumeMount{scriptsVolumeMount},
+		}
+	}
+	if preamble == "" {
+		preamble = defaultScriptPreamble
+	} else if !strings.HasSuffix(preamble, "\n") {
+		preamble += "\n"
+	}
 
-	convertedStepContainers := convertListOfSteps(steps, &placeScriptsInit, &placeScripts, "script")
+	convertedStepContainers := convertListOfSteps(steps, &placeScriptsInit, &placeScripts, placeWithEntrypoint, preamble, "script")
 
 	sideCarSteps := []v1beta1.Step{}
 	for _, step := range sidecars {
@@ -72,7 +97,7 @@ func convertScripts(shellImage string, steps []v1beta1.Step, sidecars []v1beta1.
 		}
 		sideCarSteps = append(sideCarSteps, sidecarStep)
 	}
-	sidecarContainers := convertListOfSteps(sideCarSteps, &placeScriptsInit, &placeScripts, "sidecar-script")
+	sidecarContainers := convertListOfSteps(sideCarSteps, &placeScriptsInit, &placeScripts, placeWithEntrypoint, preamble, "sidecar-script")
 
 	if placeScripts {
 		return &placeScriptsInit, convertedStepContainers, sidecarContainers
@@ -80,11 +105,20 @@ func convertScripts(shellImage string, steps []v1beta1.Step, sidecars []v1beta1.
 	return nil, convertedStepContainers, sidecarContainers
 }
 
+// escapeEnvValue escapes the references to variables, such as $(VAR), which
+// the kubelet expands in the value of an environment variable, so that the
+// value is passed to the container as is.
+func escapeEnvValue(value string) string {
+	return strings.ReplaceAll(value, "$", "$$")
+}
+
 // convertListOfSteps does the heavy lifting for convertScripts.
 //
 // It iterates through the list of steps (or sidecars), generates the script file name and heredoc termination string,
 // adds an entry to the init container args, sets up the step container to run the script, and sets the volume mounts.
-func convertListOfSteps(steps []v1beta1.Step, initContainer *corev1.Container, placeScripts *bool, namePrefix string) []corev1.Container {
+// If placeWithEntrypoint is set, the script is instead passed to the init container in an environment variable,
+// and the init container command copies it to the script file.
+func convertListOfSteps(steps []v1beta1.Step, initContainer *corev1.Container, placeScripts *bool, placeWithEntrypoint bool, preamble, namePrefix string) []corev1.Container {
 	containers := []corev1.Container{}
 	for i, s := range steps {
 		if s.Script == "" {
@@ -93,15 +127,7 @@ func convertListOfSteps(steps []v1beta1.Step, initContainer *corev1.Container, p
 			continue
 		}
 
-		// Check for a shebang, and add a default if it's not set.
-		// The shebang must be the first non-empty line.
-		cleaned := strings.TrimSpace(s.Script)
-		hasShebang := strings.HasPrefix(cleaned, "#!")
-
-		script := s.Script
-		if !hasShebang {
-			script = defaultScriptPreamble + s.Script
-		}
+		script := scriptWithShebang(s, preamble)
 
 		// At least one step uses a script, so we should return a
 		// non-nil init container.
@@ -110,20 +136,26 @@ func convertListOfSteps(steps []v1beta1.Step, initContainer *corev1.Container, p
 		// Append to the place-scripts script to place the
 		// script file in a known location in the scripts volume.
 		tmpFile := filepath.Join(scriptsDir, names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(fmt.Sprintf("%s-%d", namePrefix, i)))
-		// heredoc is the "here document" placeholder string
-		// used to cat script contents into the file. Typically
-		// this is the string "EOF" but if this value were
-		// "EOF" it would prevent users from including the
-		// string "EOF" in their own scripts. Instead we
-		// randomly generate a string to (hopefully) prevent
-		// collisions.
-		heredoc := names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(fmt.Sprintf("%s-heredoc-randomly-generated", namePrefix))
-		initContainer.Args[1] += fmt.Sprintf(`tmpfile="%s"
+		if placeWithEntrypoint {
+			env := fmt.Sprintf("TEKTON_%s_%d", strings.ToUpper(strings.ReplaceAll(namePrefix, "-", "_")), i)
+			initContainer.Env = append(initContainer.Env, corev1.EnvVar{Name: env, Value: escapeEnvValue(script)})
+			initContainer.Command = append(initContainer.Command, "env:"+env, tmpFile)
+		} else {
+			// heredoc is the "here document" placeholder string
+			// used to cat script contents into the file. Typically
+			// this is the string "EOF" but if this value were
+			// "EOF" it would prevent users from including the
+			// string "EOF" in their own scripts. Instead we
+			// randomly generate a string to (hopefully) prevent
+			// collisions.
+			heredoc := names.SimpleNameGenerator.RestrictLengthWithRandomSuffix(fmt.Sprintf("%s-heredoc-randomly-generated", namePrefix))
+			initContainer.Args[1] += fmt.Sprintf(`tmpfile="%s"
 touch ${tmpfile} && chmod +x ${tmpfile}
 cat > ${tmpfile} << '%s'
 %s
 %s
 `, tmpFile, heredoc, script, heredoc)
+		}
 
 		// Set the command to execute the correct script in the mounted
 		// volume.
@@ -136,3 +168,27 @@ cat > ${tmpfile} << '%s'
 	}
 	return containers
 }
+
+// scriptWithShebang returns the script of the step, making sure it starts with
+// a shebang: scripts without one are prefixed with the shebang of their
+// scriptLanguage if any, or with the preamble otherwise.
+func scriptWithShebang(s v1beta1.Step, preamble string) string {
+	script := s.Script
+	// Check for a shebang, and add a default if it's not set.
+	// The shebang must be the first non-empty line.
+	cleaned := strings.TrimSpace(script)
+
+	// Scripts written with Windows line endings would run an interpreter whose
+	// name ends with a carriage return, so convert them to Unix line endings.
+	if strings.Contains(script, "\r\n") {
+		script = strings.ReplaceAll(script, "\r\n", "\n")
+	}
+
+	if strings.HasPrefix(cleaned, "#!") {
+		return script
+	}
+	if shebang, ok := scriptLanguageShebangs[s.ScriptLanguage]; ok {
+		return shebang + script
+	}
+	return preamble + script
+}
@@ -27,7 +27,7 @@ import (
 )
 
 func TestConvertScripts_NothingToConvert_EmptySidecars(t *testing.T) {
-	gotInit, gotScripts, gotSidecars := convertScripts(images.ShellImage, []v1beta1.Step{{
+	gotInit, gotScripts, gotSidecars := convertScripts(images.ShellImage, images.EntrypointImage, false, "", []v1beta1.Step{{
 		Container: corev1.Container{
 			Image: "step-1",
 		},
@@ -54,7 +54,7 @@ func TestConvertScripts_NothingToConvert_EmptySidecars(t *testing.T) {
 }
 
 func TestConvertScripts_NothingToConvert_NilSidecars(t *testing.T) {
-	gotInit, gotScripts, gotSidecars := convertScripts(images.ShellImage, []v1beta1.Step{{
+	gotInit, gotScripts, gotSidecars := convertScripts(images.ShellImage, images.EntrypointImage, false, "", []v1beta1.Step{{
 		Container: corev1.Container{
 			Image: "step-1",
 		},
@@ -81,7 +81,7 @@ func TestConvertScripts_NothingToConvert_NilSidecars(t *testing.T) {
 }
 
 func TestConvertScripts_NothingToConvert_WithSidecar(t *testing.T) {
-	gotInit, gotScripts, gotSidecars := convertScripts(images.ShellImage, []v1beta1.Step{{
+	gotInit, gotScripts, gotSidecars := convertScripts(images.ShellImage, images.EntrypointImage, false, "", []v1beta1.Step{{
 		Container: corev1.Container{
 			Image: "step-1",
 		},
@@ -130,7 +130,7 @@ func TestConvertScripts(t *testing.T) {
 		MountPath: "/another/one",
 	}}
 
-	gotInit, gotSteps, gotSidecars := convertScripts(images.ShellImage, []v1beta1.Step{{
+	gotInit, gotSteps, gotSidecars := convertScripts(images.ShellImage, images.EntrypointImage, false, "", []v1beta1.Step{{
 		Script: `#!/bin/sh
 script-1`,
 		Container: corev1.Container{Image: "step-1"},
@@ -225,7 +225,7 @@ func TestConvertScripts_WithSidecar(t *testing.T) {
 		MountPath: "/another/one",
 	}}
 
-	gotInit, gotSteps, gotSidecars := convertScripts(images.ShellImage, []v1beta1.Step{{
+	gotInit, gotSteps, gotSidecars := convertScripts(images.ShellImage, images.EntrypointImage, false, "", []v1beta1.Step{{
 		Script: `#!/bin/sh
 script-1`,
 		Container: corev1.Container{Image: "step-1"},
@@ -307,3 +307,147 @@ sidecar-script-heredoc-randomly-generated-j2tds
 	}
 
 }
+
+func TestConvertScripts_WithEntrypoint(t *testing.T) {
+	names.TestingSeed()
+
+	gotInit, gotSteps, gotSidecars := convertScripts(images.ShellImage, images.EntrypointImage, true, "", []v1beta1.Step{{
+		Script: `#!/bin/sh
+script-1`,
+		Container: corev1.Container{Image: "step-1"},
+	}, {
+		// No script to convert here.
+		Container: corev1.Container{Image: "step-2"},
+	}, {
+		Script:    `no-shebang`,
+		Container: corev1.Container{Image: "step-3"},
+	}}, []v1beta1.Sidecar{{
+		Script: `#!/bin/sh
+sidecar-1`,
+		Container: corev1.Container{Image: "sidecar-1"},
+	}})
+	wantInit := &corev1.Container{
+		Name:  "place-scripts",
+		Image: images.EntrypointImage,
+		Command: []string{"/ko-app/entrypoint", "cp",
+			"env:TEKTON_SCRIPT_0", "/tekton/scripts/script-0-9l9zj",
+			"env:TEKTON_SCRIPT_2", "/tekton/scripts/script-2-mz4c7",
+			"env:TEKTON_SIDECAR_SCRIPT_0", "/tekton/scripts/sidecar-script-0-mssqb",
+		},
+		Env: []corev1.EnvVar{{
+			Name:  "TEKTON_SCRIPT_0",
+			Value: "#!/bin/sh\nscript-1",
+		}, {
+			Name:  "TEKTON_SCRIPT_2",
+			Value: "#!/bin/sh\nset -xe\nno-shebang",
+		}, {
+			Name:  "TEKTON_SIDECAR_SCRIPT_0",
+			Value: "#!/bin/sh\nsidecar-1",
+		}},
+		VolumeMounts: []corev1.VolumeMount{scriptsVolumeMount},
+	}
+	want := []corev1.Container{{
+		Image:        "step-1",
+		Command:      []string{"/tekton/scripts/script-0-9l9zj"},
+		VolumeMounts: []corev1.VolumeMount{scriptsVolumeMount},
+	}, {
+		Image: "step-2",
+	}, {
+		Image:        "step-3",
+		Command:      []string{"/tekton/scripts/script-2-mz4c7"},
+		VolumeMounts: []corev1.VolumeMount{scriptsVolumeMount},
+	}}
+	wantSidecars := []corev1.Container{{
+		Image:        "sidecar-1",
+		Command:      []string{"/tekton/scripts/sidecar-script-0-mssqb"},
+		VolumeMounts: []corev1.VolumeMount{scriptsVolumeMount},
+	}}
+	if d := cmp.Diff(wantInit, gotInit); d != "" {
+		t.Errorf("Init Container Diff %s", diff.PrintWantGot(d))
+	}
+	if d := cmp.Diff(want, gotSteps); d != "" {
+		t.Errorf("Step Containers Diff %s", diff.PrintWantGot(d))
+	}
+	if d := cmp.Diff(wantSidecars, gotSidecars); d != "" {
+		t.Errorf("Sidecar Containers Diff %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestConvertScripts_WithEntrypointEscapesVariables(t *testing.T) {
+	names.TestingSeed()
+
+	gotInit, _, _ := convertScripts(images.ShellImage, images.EntrypointImage, true, "", []v1beta1.Step{{
+		Script:    "#!/bin/sh\necho $(HOME) $$ ${USER}",
+		Container: corev1.Container{Image: "step-1"},
+	}}, nil)
+	// The kubelet expands $(HOME) and turns $$ into $ in the value of an
+	// environment variable, so every $ is escaped for the script to be
+	// placed as is.
+	want := []corev1.EnvVar{{
+		Name:  "TEKTON_SCRIPT_0",
+		Value: "#!/bin/sh\necho $$(HOME) $$$$ $${USER}",
+	}}
+	if d := cmp.Diff(want, gotInit.Env); d != "" {
+		t.Errorf("Init Container Env Diff %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestScriptWithShebang(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		step     v1beta1.Step
+		preamble string
+		want     string
+	}{{
+		name:     "shebang",
+		step:     v1beta1.Step{Script: "#!/bin/bash\necho hello"},
+		preamble: defaultScriptPreamble,
+		want:     "#!/bin/bash\necho hello",
+	}, {
+		name:     "shebang after empty lines",
+		step:     v1beta1.Step{Script: "\n\n#!/bin/bash\necho hello"},
+		preamble: defaultScriptPreamble,
+		want:     "\n\n#!/bin/bash\necho hello",
+	}, {
+		name:     "default preamble",
+		step:     v1beta1.Step{Script: "echo hello"},
+		preamble: defaultScriptPreamble,
+		want:     "#!/bin/sh\nset -xe\necho hello",
+	}, {
+		name:     "task preamble",
+		step:     v1beta1.Step{Script: "false | true"},
+		preamble: "#!/bin/bash\nset -xeo pipefail\n",
+		want:     "#!/bin/bash\nset -xeo pipefail\nfalse | true",
+	}, {
+		name:     "python script language",
+		step:     v1beta1.Step{Script: "print(\"hello\")", ScriptLanguage: v1beta1.ScriptLanguagePython},
+		preamble: defaultScriptPreamble,
+		want:     "#!/usr/bin/env python3\nprint(\"hello\")",
+	}, {
+		name:     "node script language",
+		step:     v1beta1.Step{Script: "console.log(\"hello\")", ScriptLanguage: v1beta1.ScriptLanguageNode},
+		preamble: defaultScriptPreamble,
+		want:     "#!/usr/bin/env node\nconsole.log(\"hello\")",
+	}, {
+		name:     "shebang takes precedence over script language",
+		step:     v1beta1.Step{Script: "#!/usr/bin/python2\nprint \"hello\"", ScriptLanguage: v1beta1.ScriptLanguagePython},
+		preamble: defaultScriptPreamble,
+		want:     "#!/usr/bin/python2\nprint \"hello\"",
+	}, {
+		name:     "windows line endings",
+		step:     v1beta1.Step{Script: "#!/bin/sh\r\necho hello\r\necho world\r\n"},
+		preamble: defaultScriptPreamble,
+		want:     "#!/bin/sh\necho hello\necho world\n",
+	}, {
+		name:     "windows line endings without shebang",
+		step:     v1beta1.Step{Script: "echo hello\r\n"},
+		preamble: defaultScriptPreamble,
+		want:     "#!/bin/sh\nset -xe\necho hello\n",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if d := cmp.Diff(tc.want, scriptWithShebang(tc.step, tc.preamble)); d != "" {
+				t.Errorf("Script Diff %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
@@ -20,6 +20,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"sort"
+	"strconv"
 	"strings"
 	"time"
 
@@ -49,6 +50,10 @@ const (
 	// a ResourceQuota in the namespace
 	ReasonExceededResourceQuota = "ExceededResourceQuota"
 
+	// ReasonResourceQuotaQueued indicates that the TaskRun is queued, waiting for a
+	// ResourceQuota in the namespace to admit its pod
+	ReasonResourceQuotaQueued = "ResourceQuotaQueued"
+
 	// ReasonExceededNodeResources indicates that the TaskRun's pod has failed to start due
 	// to resource constraints on the node
 	ReasonExceededNodeResources = "ExceededNodeResources"
@@ -69,11 +74,44 @@ const (
 	timeFormat = "2006-01-02T15:04:05.000Z07:00"
 )
 
-const oomKilled = "OOMKilled"
+const (
+	oomKilled = "OOMKilled"
+
+	// resultsTooLarge is the internal reason recorded by the entrypoint when
+	// the Results of a step exceed the size of its termination message.
+	resultsTooLarge = "ResultsTooLarge"
+
+	podReasonEvicted  = "Evicted"
+	podReasonNodeLost = "NodeLost"
+)
+
+// imagePullFailureReasons are the reasons of a waiting container whose image
+// cannot be pulled, mapped to whether the failure is permanent. The kubelet
+// keeps retrying the other ones, which may still succeed.
+var imagePullFailureReasons = map[string]bool{
+	"ErrImagePull":      false,
+	"ImagePullBackOff":  false,
+	"InvalidImageName":  true,
+	"ErrImageNeverPull": true,
+}
 
 // SidecarsReady returns true if all of the Pod's sidecars are Ready or
 // Terminated.
 func SidecarsReady(podStatus corev1.PodStatus) bool {
+	return sidecarsReady(podStatus, nil)
+}
+
+// UngatedSidecarsReady returns true if all of the Pod's sidecars that no
+// step declared it needs are Ready or Terminated. The steps needing a
+// sidecar wait for it separately, see UpdateSidecarsReady.
+func UngatedSidecarsReady(pod corev1.Pod) bool {
+	return sidecarsReady(pod.Status, func(name string) bool {
+		_, ok := pod.ObjectMeta.Annotations[sidecarStepsAnnotationPrefix+name]
+		return ok
+	})
+}
+
+func sidecarsReady(podStatus corev1.PodStatus, skip func(name string) bool) bool {
 	if podStatus.Phase != corev1.PodRunning {
 		return false
 	}
@@ -85,6 +123,9 @@ func SidecarsReady(podStatus corev1.PodStatus) bool {
 		if IsContainerStep(s.Name) {
 			continue
 		}
+		if skip != nil && skip(s.Name) {
+			continue
+		}
 		if s.State.Running != nil && s.Ready {
 			continue
 		}
@@ -116,6 +157,7 @@ func MakeTaskRunStatus(logger *zap.SugaredLogger, tr v1beta1.TaskRun, pod *corev
 
 	trs.PodName = pod.Name
 	trs.Steps = []v1beta1.StepState{}
+	trs.OnFailureSteps = nil
 	trs.Sidecars = []v1beta1.SidecarState{}
 
 	var stepStatuses []corev1.ContainerStatus
@@ -129,7 +171,7 @@ func MakeTaskRunStatus(logger *zap.SugaredLogger, tr v1beta1.TaskRun, pod *corev
 	}
 
 	var merr *multierror.Error
-	if err := setTaskRunStatusBasedOnStepStatus(logger, stepStatuses, &tr); err != nil {
+	if err := setTaskRunStatusBasedOnStepStatus(logger, stepStatuses, &tr, pod); err != nil {
 		merr = multierror.Append(merr, err)
 	}
 
@@ -140,11 +182,15 @@ func MakeTaskRunStatus(logger *zap.SugaredLogger, tr v1beta1.TaskRun, pod *corev
 	return *trs, merr.ErrorOrNil()
 }
 
-func setTaskRunStatusBasedOnStepStatus(logger *zap.SugaredLogger, stepStatuses []corev1.ContainerStatus, tr *v1beta1.TaskRun) *multierror.Error {
+func setTaskRunStatusBasedOnStepStatus(logger *zap.SugaredLogger, stepStatuses []corev1.ContainerStatus, tr *v1beta1.TaskRun, pod *corev1.Pod) *multierror.Error {
 	trs := &tr.Status
 	var merr *multierror.Error
 
 	for _, s := range stepStatuses {
+		// exitCode is the exit code of a step which was allowed to fail (onError: continue),
+		// the step container itself exits successfully so that the TaskRun does not fail.
+		var exitCode *int32
+		var logLocation string
 		if s.State.Terminated != nil && len(s.State.Terminated.Message) != 0 {
 			msg := s.State.Terminated.Message
 
@@ -158,6 +204,12 @@ func setTaskRunStatusBasedOnStepStatus(logger *zap.SugaredLogger, stepStatuses [
 					logger.Errorf("error setting the start time of step %q in taskrun %q: %v", s.Name, tr.Name, err)
 					merr = multierror.Append(merr, err)
 				}
+				logLocation = extractLogLocationFromResults(results)
+				exitCode, err = extractExitCodeFromResults(results)
+				if err != nil {
+					logger.Errorf("error extracting the exit code of step %q in taskrun %q: %v", s.Name, tr.Name, err)
+					merr = multierror.Append(merr, err)
+				}
 				taskResults, pipelineResourceResults, filteredResults := filterResultsAndResources(results)
 				if tr.IsSuccessful() {
 					trs.TaskRunResults = append(trs.TaskRunResults, taskResults...)
@@ -175,12 +227,22 @@ func setTaskRunStatusBasedOnStepStatus(logger *zap.SugaredLogger, stepStatuses [
 				}
 			}
 		}
-		trs.Steps = append(trs.Steps, v1beta1.StepState{
+		stepState := v1beta1.StepState{
 			ContainerState: *s.State.DeepCopy(),
 			Name:           trimStepPrefix(s.Name),
 			ContainerName:  s.Name,
 			ImageID:        s.ImageID,
-		})
+			LogLocation:    logLocation,
+		}
+		if exitCode != nil && stepState.Terminated != nil {
+			stepState.Terminated.ExitCode = *exitCode
+		}
+		if isContainerOnFailureStep(pod, s.Name) {
+			stepState.Name = trimOnFailureStepPrefix(s.Name)
+			trs.OnFailureSteps = append(trs.OnFailureSteps, stepState)
+			continue
+		}
+		trs.Steps = append(trs.Steps, stepState)
 	}
 
 	return merr
@@ -269,10 +331,42 @@ func extractStartedAtTimeFromResults(results []v1beta1.PipelineResourceResult) (
 	return nil, nil
 }
 
+func extractExitCodeFromResults(results []v1beta1.PipelineResourceResult) (*int32, error) {
+	for _, result := range results {
+		if result.Key == "ExitCode" {
+			i, err := strconv.ParseInt(result.Value, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse int value %q in ExitCode field: %w", result.Value, err)
+			}
+			exitCode := int32(i)
+			return &exitCode, nil
+		}
+	}
+	return nil, nil
+}
+
+func extractLogLocationFromResults(results []v1beta1.PipelineResourceResult) string {
+	for _, result := range results {
+		if result.ResultType == v1beta1.InternalTektonResultType && result.Key == "LogLocation" {
+			return result.Value
+		}
+	}
+	return ""
+}
+
 func updateCompletedTaskRunStatus(logger *zap.SugaredLogger, trs *v1beta1.TaskRunStatus, pod *corev1.Pod) {
 	if DidTaskRunFail(pod) {
 		msg := getFailureMessage(logger, pod)
-		MarkStatusFailure(trs, msg)
+		failure := classifyFailure(logger, pod)
+		if failure.Reason == v1beta1.TaskRunReasonSidecarFailed {
+			// The Steps most likely failed because of the Sidecar they relied on.
+			for _, s := range pod.Status.ContainerStatuses {
+				if s.Name == sidecarContainerName(failure.Sidecar) {
+					msg = sidecarFailureMessage(pod, s) + msg
+				}
+			}
+		}
+		markStatusClassifiedFailure(trs, failure, msg)
 	} else {
 		MarkStatusSuccess(trs)
 	}
@@ -284,6 +378,16 @@ func updateCompletedTaskRunStatus(logger *zap.SugaredLogger, trs *v1beta1.TaskRu
 func updateIncompleteTaskRunStatus(trs *v1beta1.TaskRunStatus, pod *corev1.Pod) {
 	switch pod.Status.Phase {
 	case corev1.PodRunning:
+		if step, ok := stepAtBreakpoint(pod); ok {
+			MarkStatusRunning(trs, v1beta1.TaskRunReasonPausedAtBreakpoint.String(),
+				fmt.Sprintf("Step %q failed and is waiting at a breakpoint, run %s or %s in its container to continue or abort the execution",
+					trimStepPrefix(step), debugContinueScript, debugAbortScript))
+			return
+		}
+		if sidecar, ok := failedSidecar(pod); ok {
+			MarkStatusRunning(trs, v1beta1.TaskRunReasonSidecarFailed.String(), sidecarFailureMessage(pod, sidecar))
+			return
+		}
 		MarkStatusRunning(trs, v1beta1.TaskRunReasonRunning.String(), "Not all Steps in the Task have finished executing")
 	case corev1.PodPending:
 		var reason, msg string
@@ -315,6 +419,114 @@ func DidTaskRunFail(pod *corev1.Pod) bool {
 	return f
 }
 
+// failedSidecar returns the status of the first Sidecar of the Pod which
+// exited with a non-zero exit code.
+func failedSidecar(pod *corev1.Pod) (corev1.ContainerStatus, bool) {
+	for _, s := range pod.Status.ContainerStatuses {
+		if isContainerSidecar(s.Name) && s.State.Terminated != nil && s.State.Terminated.ExitCode != 0 {
+			return s, true
+		}
+	}
+	return corev1.ContainerStatus{}, false
+}
+
+// failedSidecarOfStep returns the status of the first Sidecar needed by the
+// given step container which exited with a non-zero exit code.
+func failedSidecarOfStep(pod *corev1.Pod, stepContainer string) (corev1.ContainerStatus, bool) {
+	for _, s := range pod.Status.ContainerStatuses {
+		steps, ok := pod.ObjectMeta.Annotations[sidecarStepsAnnotationPrefix+s.Name]
+		if !ok || s.State.Terminated == nil || s.State.Terminated.ExitCode == 0 {
+			continue
+		}
+		for _, step := range strings.Split(steps, ",") {
+			if step == stepContainer {
+				return s, true
+			}
+		}
+	}
+	return corev1.ContainerStatus{}, false
+}
+
+// RequiredSidecarFailure returns the classification of the failure of a
+// Sidecar needed by a step of the Pod which hasn't finished yet, which
+// would otherwise wait for it forever, along with a message describing it.
+func RequiredSidecarFailure(pod *corev1.Pod) (*v1beta1.TaskRunFailure, string) {
+	terminated := map[string]bool{}
+	for _, s := range pod.Status.ContainerStatuses {
+		terminated[s.Name] = s.State.Terminated != nil
+	}
+	for _, s := range pod.Status.ContainerStatuses {
+		steps, ok := pod.ObjectMeta.Annotations[sidecarStepsAnnotationPrefix+s.Name]
+		if !ok || s.State.Terminated == nil || s.State.Terminated.ExitCode == 0 {
+			continue
+		}
+		for _, step := range strings.Split(steps, ",") {
+			if !terminated[step] {
+				return sidecarFailure(s), sidecarFailureMessage(pod, s)
+			}
+		}
+	}
+	return nil, ""
+}
+
+// PermanentImagePullFailure returns the classification of the failure of a
+// Pod whose Step or Sidecar image can never be pulled, e.g. because its name
+// is invalid, which would otherwise stay pending until the TaskRun times out,
+// along with a message describing it. Images which the kubelet is still
+// trying to pull, e.g. on ImagePullBackOff, are not reported.
+func PermanentImagePullFailure(pod *corev1.Pod) (*v1beta1.TaskRunFailure, string) {
+	for _, s := range pod.Status.ContainerStatuses {
+		if s.State.Waiting == nil || !imagePullFailureReasons[s.State.Waiting.Reason] {
+			continue
+		}
+		failure := &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonImagePullFailed}
+		if IsContainerStep(s.Name) {
+			failure.Step = stepName(pod, s.Name)
+		} else {
+			failure.Sidecar = TrimSidecarPrefix(s.Name)
+		}
+		return failure, fmt.Sprintf("the image %q of %q cannot be pulled: %s", s.Image, s.Name, s.State.Waiting.Message)
+	}
+	return nil, ""
+}
+
+// ImagePullFailure returns the classification of the failure of a TaskRun
+// which failed, e.g. timed out, while one of its Steps or Sidecars was still
+// waiting for its image to be pulled, along with a message describing it.
+func ImagePullFailure(status v1beta1.TaskRunStatus) (*v1beta1.TaskRunFailure, string) {
+	for _, s := range status.Steps {
+		if isWaitingForImage(s.ContainerState) {
+			return &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonImagePullFailed, Step: s.Name},
+				fmt.Sprintf("the image of %q could not be pulled: %s", s.ContainerName, s.Waiting.Message)
+		}
+	}
+	for _, s := range status.Sidecars {
+		if isWaitingForImage(s.ContainerState) {
+			return &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonImagePullFailed, Sidecar: s.Name},
+				fmt.Sprintf("the image of %q could not be pulled: %s", s.ContainerName, s.Waiting.Message)
+		}
+	}
+	return nil, ""
+}
+
+func isWaitingForImage(state corev1.ContainerState) bool {
+	if state.Waiting == nil {
+		return false
+	}
+	_, ok := imagePullFailureReasons[state.Waiting.Reason]
+	return ok
+}
+
+func sidecarFailureMessage(pod *corev1.Pod, s corev1.ContainerStatus) string {
+	term := s.State.Terminated
+	msg := fmt.Sprintf("sidecar %q exited with code %d (image: %q)", s.Name, term.ExitCode, s.ImageID)
+	if m := strings.TrimSpace(term.Message); m != "" {
+		msg += ": " + m
+	}
+	// Newline required at end to prevent yaml parser from breaking the log help text at 80 chars
+	return fmt.Sprintf("%s; for logs run: kubectl -n %s logs %s -c %s\n", msg, pod.Namespace, pod.Name, s.Name)
+}
+
 func areStepsComplete(pod *corev1.Pod) bool {
 	stepsComplete := len(pod.Status.ContainerStatuses) > 0 && pod.Status.Phase == corev1.PodRunning
 	for _, s := range pod.Status.ContainerStatuses {
@@ -341,6 +553,12 @@ func getFailureMessage(logger *zap.SugaredLogger, pod *corev1.Pod) string {
 						status.Name,
 						pod.Namespace, pod.Name, status.Name)
 				}
+				if result.ResultType == v1beta1.InternalTektonResultType && result.Key == "Reason" && result.Value == resultsTooLarge {
+					// Newline required at end to prevent yaml parser from breaking the log help text at 80 chars
+					return fmt.Sprintf("%q exited because its results exceeded the maximum size of %d bytes; for logs run: kubectl -n %s logs %s -c %s\n",
+						status.Name, termination.MaxContainerTerminationMessageLength,
+						pod.Namespace, pod.Name, status.Name)
+				}
 			}
 			if term.ExitCode != 0 {
 				// Newline required at end to prevent yaml parser from breaking the log help text at 80 chars
@@ -369,6 +587,78 @@ func getFailureMessage(logger *zap.SugaredLogger, pod *corev1.Pod) string {
 	return "build failed for unspecified reasons."
 }
 
+// classifyFailure returns the classification of the failure of a completed Pod.
+// The failure of a Sidecar is only reported when no step failed on its own, or
+// when the failing step needed that Sidecar.
+func classifyFailure(logger *zap.SugaredLogger, pod *corev1.Pod) v1beta1.TaskRunFailure {
+	switch pod.Status.Reason {
+	case podReasonEvicted:
+		return v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonEvicted}
+	case podReasonNodeLost:
+		return v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonNodeLost}
+	}
+	if failure, step, ok := stepFailure(logger, pod); ok {
+		if sidecar, ok := failedSidecarOfStep(pod, step.Name); ok {
+			return *sidecarFailure(sidecar)
+		}
+		return failure
+	}
+	if sidecar, ok := failedSidecar(pod); ok {
+		return *sidecarFailure(sidecar)
+	}
+	return v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonFailed}
+}
+
+// stepFailure returns the classification of the failure of the first step of
+// the Pod which failed, along with the status of its container.
+func stepFailure(logger *zap.SugaredLogger, pod *corev1.Pod) (v1beta1.TaskRunFailure, corev1.ContainerStatus, bool) {
+	for _, s := range pod.Status.ContainerStatuses {
+		term := s.State.Terminated
+		if !IsContainerStep(s.Name) || term == nil {
+			continue
+		}
+		failure := v1beta1.TaskRunFailure{Step: stepName(pod, s.Name), ExitCode: term.ExitCode}
+		r, _ := termination.ParseMessage(logger, term.Message)
+		for _, result := range r {
+			if result.ResultType == v1beta1.InternalTektonResultType && result.Key == "Reason" {
+				switch result.Value {
+				case "TimeoutExceeded":
+					failure.Reason = v1beta1.TaskRunReasonStepTimedOut
+				case resultsTooLarge:
+					failure.Reason = v1beta1.TaskRunReasonResultsTooLarge
+				}
+			}
+		}
+		switch {
+		case failure.Reason != "":
+			return failure, s, true
+		case isOOMKilled(s):
+			failure.Reason = v1beta1.TaskRunReasonOOMKilled
+			return failure, s, true
+		case term.ExitCode != 0:
+			failure.Reason = v1beta1.TaskRunReasonFailed
+			return failure, s, true
+		}
+	}
+	return v1beta1.TaskRunFailure{}, corev1.ContainerStatus{}, false
+}
+
+func sidecarFailure(s corev1.ContainerStatus) *v1beta1.TaskRunFailure {
+	return &v1beta1.TaskRunFailure{
+		Reason:   v1beta1.TaskRunReasonSidecarFailed,
+		Sidecar:  TrimSidecarPrefix(s.Name),
+		ExitCode: s.State.Terminated.ExitCode,
+	}
+}
+
+// stepName returns the name of the step run in the given container.
+func stepName(pod *corev1.Pod, containerName string) string {
+	if isContainerOnFailureStep(pod, containerName) {
+		return trimOnFailureStepPrefix(containerName)
+	}
+	return trimStepPrefix(containerName)
+}
+
 // IsPodExceedingNodeResources returns true if the Pod's status indicates there
 // are insufficient resources to schedule the Pod.
 func IsPodExceedingNodeResources(pod *corev1.Pod) bool {
@@ -437,6 +727,18 @@ func MarkStatusFailure(trs *v1beta1.TaskRunStatus, message string) {
 	})
 }
 
+// markStatusClassifiedFailure sets taskrun status to failure, with the reason
+// of its classification
+func markStatusClassifiedFailure(trs *v1beta1.TaskRunStatus, failure v1beta1.TaskRunFailure, message string) {
+	trs.SetCondition(&apis.Condition{
+		Type:    apis.ConditionSucceeded,
+		Status:  corev1.ConditionFalse,
+		Reason:  failure.Reason.String(),
+		Message: message,
+	})
+	trs.Failure = &failure
+}
+
 // MarkStatusSuccess sets taskrun status to success
 func MarkStatusSuccess(trs *v1beta1.TaskRunStatus) {
 	trs.SetCondition(&apis.Condition{
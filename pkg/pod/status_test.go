@@ -173,6 +173,91 @@ func TestMakeTaskRunStatus(t *testing.T) {
 				CompletionTime: &metav1.Time{Time: time.Now()},
 			},
 		},
+	}, {
+		desc: "success with a step allowed to fail",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "step-lint",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 0,
+						Message:  `[{"key":"ExitCode","value":"11","type":"InternalTektonResult"}]`,
+					},
+				},
+				ImageID: "image-id",
+			}, {
+				Name: "step-push",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 0,
+					},
+				},
+				ImageID: "image-id",
+			}},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{conditionSucceeded},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 11,
+						}},
+					Name:          "lint",
+					ContainerName: "step-lint",
+					ImageID:       "image-id",
+				}, {
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 0,
+						}},
+					Name:          "push",
+					ContainerName: "step-push",
+					ImageID:       "image-id",
+				}},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+			},
+		},
+	}, {
+		desc: "success with an archived step log",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "step-build",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 0,
+						Message:  `[{"key":"LogLocation","value":"s3://bucket/ns/tr/build.log","type":"InternalTektonResult"}]`,
+					},
+				},
+				ImageID: "image-id",
+			}},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{conditionSucceeded},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 0,
+						}},
+					Name:          "build",
+					ContainerName: "step-build",
+					ImageID:       "image-id",
+					LogLocation:   "s3://bucket/ns/tr/build.log",
+				}},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+			},
+		},
 	}, {
 		desc: "running",
 		podStatus: corev1.PodStatus{
@@ -240,6 +325,108 @@ func TestMakeTaskRunStatus(t *testing.T) {
 				Sidecars: []v1beta1.SidecarState{},
 				// We don't actually care about the time, just that it's not nil
 				CompletionTime: &metav1.Time{Time: time.Now()},
+				Failure:        &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonFailed, Step: "failure", ExitCode: 123},
+			},
+		},
+	}, {
+		desc: "failure-terminated-with-onFailure-step",
+		pod: corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "pod",
+				Namespace:   "foo",
+				Annotations: map[string]string{onFailureStepsAnnotation: "step-on-failure-collect"},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodFailed,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:    "step-failure",
+					ImageID: "image-id",
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 123,
+						},
+					},
+				}, {
+					Name:    "step-on-failure-collect",
+					ImageID: "image-id",
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 0,
+						},
+					},
+				}},
+			},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  v1beta1.TaskRunReasonFailed.String(),
+					Message: "\"step-failure\" exited with code 123 (image: \"image-id\"); for logs run: kubectl -n foo logs pod -c step-failure\n",
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 123,
+						}},
+					Name:          "failure",
+					ContainerName: "step-failure",
+					ImageID:       "image-id",
+				}},
+				OnFailureSteps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 0,
+						}},
+					Name:          "collect",
+					ContainerName: "step-on-failure-collect",
+					ImageID:       "image-id",
+				}},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+				Failure:        &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonFailed, Step: "failure", ExitCode: 123},
+			},
+		},
+	}, {
+		desc: "step-named-with-onFailure-prefix",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:    "step-on-failure-report",
+				ImageID: "image-id",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 0,
+					},
+				},
+			}},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionTrue,
+					Reason:  v1beta1.TaskRunReasonSuccessful.String(),
+					Message: "All Steps have completed executing",
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 0,
+						}},
+					Name:          "on-failure-report",
+					ContainerName: "step-on-failure-report",
+					ImageID:       "image-id",
+				}},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
 			},
 		},
 	}, {
@@ -262,6 +449,7 @@ func TestMakeTaskRunStatus(t *testing.T) {
 				Sidecars: []v1beta1.SidecarState{},
 				// We don't actually care about the time, just that it's not nil
 				CompletionTime: &metav1.Time{Time: time.Now()},
+				Failure:        &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonFailed},
 			},
 		},
 	}, {
@@ -284,7 +472,7 @@ func TestMakeTaskRunStatus(t *testing.T) {
 				Conditions: []apis.Condition{{
 					Type:    apis.ConditionSucceeded,
 					Status:  corev1.ConditionFalse,
-					Reason:  v1beta1.TaskRunReasonFailed.String(),
+					Reason:  v1beta1.TaskRunReasonOOMKilled.String(),
 					Message: "OOMKilled",
 				}},
 			},
@@ -302,6 +490,7 @@ func TestMakeTaskRunStatus(t *testing.T) {
 				Sidecars: []v1beta1.SidecarState{},
 				// We don't actually care about the time, just that it's not nil
 				CompletionTime: &metav1.Time{Time: time.Now()},
+				Failure:        &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonOOMKilled, Step: "step-push"},
 			},
 		},
 	}, {
@@ -321,6 +510,135 @@ func TestMakeTaskRunStatus(t *testing.T) {
 				Sidecars: []v1beta1.SidecarState{},
 				// We don't actually care about the time, just that it's not nil
 				CompletionTime: &metav1.Time{Time: time.Now()},
+				Failure:        &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonFailed},
+			},
+		},
+	}, {
+		desc: "evicted",
+		podStatus: corev1.PodStatus{
+			Phase:   corev1.PodFailed,
+			Reason:  "Evicted",
+			Message: "The node was low on resource: ephemeral-storage.",
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  v1beta1.TaskRunReasonEvicted.String(),
+					Message: "The node was low on resource: ephemeral-storage.",
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps:    []v1beta1.StepState{},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+				Failure:        &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonEvicted},
+			},
+		},
+	}, {
+		desc: "node lost",
+		podStatus: corev1.PodStatus{
+			Phase:   corev1.PodFailed,
+			Reason:  "NodeLost",
+			Message: "Node node-1 which was running pod pod is unresponsive",
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  v1beta1.TaskRunReasonNodeLost.String(),
+					Message: "Node node-1 which was running pod pod is unresponsive",
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps:    []v1beta1.StepState{},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+				Failure:        &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonNodeLost},
+			},
+		},
+	}, {
+		desc: "step timeout",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:    "step-slow",
+				ImageID: "image-id",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+						Message:  `[{"key":"Reason","value":"TimeoutExceeded","type":"InternalTektonResult"}]`,
+					},
+				},
+			}},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  v1beta1.TaskRunReasonStepTimedOut.String(),
+					Message: "\"step-slow\" exited because the step exceeded the specified timeout limit; for logs run: kubectl -n foo logs pod -c step-slow\n",
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 1,
+						}},
+					Name:          "slow",
+					ContainerName: "step-slow",
+					ImageID:       "image-id",
+				}},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+				Failure:        &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonStepTimedOut, Step: "slow", ExitCode: 1},
+			},
+		},
+	}, {
+		desc: "results too large",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:    "step-report",
+				ImageID: "image-id",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+						Message:  `[{"key":"Reason","value":"ResultsTooLarge","type":"InternalTektonResult"}]`,
+					},
+				},
+			}},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  v1beta1.TaskRunReasonResultsTooLarge.String(),
+					Message: "\"step-report\" exited because its results exceeded the maximum size of 4096 bytes; for logs run: kubectl -n foo logs pod -c step-report\n",
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 1,
+						}},
+					Name:          "report",
+					ContainerName: "step-report",
+					ImageID:       "image-id",
+				}},
+				Sidecars: []v1beta1.SidecarState{},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+				Failure:        &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonResultsTooLarge, Step: "report", ExitCode: 1},
 			},
 		},
 	}, {
@@ -587,7 +905,12 @@ func TestMakeTaskRunStatus(t *testing.T) {
 		},
 		want: v1beta1.TaskRunStatus{
 			Status: duckv1beta1.Status{
-				Conditions: []apis.Condition{conditionRunning},
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionUnknown,
+					Reason:  v1beta1.TaskRunReasonSidecarFailed.String(),
+					Message: "sidecar \"sidecar-error\" exited with code 1 (image: \"image-id\"): Error; for logs run: kubectl -n foo logs pod -c sidecar-error\n",
+				}},
 			},
 			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
 				Steps: []v1beta1.StepState{{
@@ -611,6 +934,132 @@ func TestMakeTaskRunStatus(t *testing.T) {
 				}},
 			},
 		},
+	}, {
+		desc: "with-sidecar-failed-and-step-failed",
+		podStatus: corev1.PodStatus{
+			Phase: corev1.PodFailed,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:    "step-failed-step",
+				ImageID: "step-image-id",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 1,
+					},
+				},
+			}, {
+				Name:    "sidecar-dind",
+				ImageID: "image-id",
+				State: corev1.ContainerState{
+					Terminated: &corev1.ContainerStateTerminated{
+						ExitCode: 2,
+						Message:  "failed to start daemon\n",
+					},
+				},
+			}},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionFalse,
+					Reason:  v1beta1.TaskRunReasonFailed.String(),
+					Message: "\"step-failed-step\" exited with code 1 (image: \"step-image-id\"); for logs run: kubectl -n foo logs pod -c step-failed-step\n",
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 1,
+						},
+					},
+					Name:          "failed-step",
+					ImageID:       "step-image-id",
+					ContainerName: "step-failed-step",
+				}},
+				Sidecars: []v1beta1.SidecarState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 2,
+							Message:  "failed to start daemon\n",
+						},
+					},
+					Name:          "dind",
+					ImageID:       "image-id",
+					ContainerName: "sidecar-dind",
+				}},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+				Failure:        &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonFailed, Step: "failed-step", ExitCode: 1},
+			},
+		},
+	}, {
+		desc: "with-sidecar-failed-and-step-needing-it-failed",
+		pod: corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "pod",
+				Namespace:   "foo",
+				Annotations: map[string]string{sidecarStepsAnnotationPrefix + "sidecar-dind": "step-failed-step"},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodFailed,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:    "step-failed-step",
+					ImageID: "step-image-id",
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 1,
+						},
+					},
+				}, {
+					Name:    "sidecar-dind",
+					ImageID: "image-id",
+					State: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 2,
+							Message:  "failed to start daemon\n",
+						},
+					},
+				}},
+			},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionFalse,
+					Reason: v1beta1.TaskRunReasonSidecarFailed.String(),
+					Message: "sidecar \"sidecar-dind\" exited with code 2 (image: \"image-id\"): failed to start daemon; for logs run: kubectl -n foo logs pod -c sidecar-dind\n" +
+						"\"step-failed-step\" exited with code 1 (image: \"step-image-id\"); for logs run: kubectl -n foo logs pod -c step-failed-step\n",
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 1,
+						},
+					},
+					Name:          "failed-step",
+					ImageID:       "step-image-id",
+					ContainerName: "step-failed-step",
+				}},
+				Sidecars: []v1beta1.SidecarState{{
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							ExitCode: 2,
+							Message:  "failed to start daemon\n",
+						},
+					},
+					Name:          "dind",
+					ImageID:       "image-id",
+					ContainerName: "sidecar-dind",
+				}},
+				// We don't actually care about the time, just that it's not nil
+				CompletionTime: &metav1.Time{Time: time.Now()},
+				Failure:        &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonSidecarFailed, Sidecar: "dind", ExitCode: 2},
+			},
+		},
 	}, {
 		desc: "image resource updated",
 		podStatus: corev1.PodStatus{
@@ -807,6 +1256,7 @@ func TestMakeTaskRunStatus(t *testing.T) {
 				Sidecars: []v1beta1.SidecarState{},
 				// We don't actually care about the time, just that it's not nil
 				CompletionTime: &metav1.Time{Time: time.Now()},
+				Failure:        &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonFailed},
 			},
 		},
 	}, {
@@ -878,6 +1328,86 @@ func TestMakeTaskRunStatus(t *testing.T) {
 				CompletionTime: &metav1.Time{Time: time.Now()},
 			},
 		},
+	}, {
+		desc: "step waiting at a breakpoint",
+		pod: corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pod",
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name:           "step-failing",
+					ReadinessProbe: breakpointProbe("/tekton/tools/0"),
+				}},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "step-failing",
+					Ready: true,
+					State: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{},
+					},
+				}},
+			},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{{
+					Type:    apis.ConditionSucceeded,
+					Status:  corev1.ConditionUnknown,
+					Reason:  v1beta1.TaskRunReasonPausedAtBreakpoint.String(),
+					Message: `Step "failing" failed and is waiting at a breakpoint, run /tekton/tools/debug-continue or /tekton/tools/debug-abort in its container to continue or abort the execution`,
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{},
+					},
+					Name:          "failing",
+					ContainerName: "step-failing",
+				}},
+				Sidecars: []v1beta1.SidecarState{},
+			},
+		},
+	}, {
+		desc: "running step without breakpoint",
+		pod: corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pod",
+			},
+			Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{
+					Name: "step-running",
+				}},
+			},
+			Status: corev1.PodStatus{
+				Phase: corev1.PodRunning,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "step-running",
+					Ready: true,
+					State: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{},
+					},
+				}},
+			},
+		},
+		want: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: []apis.Condition{conditionRunning},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					ContainerState: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{},
+					},
+					Name:          "running",
+					ContainerName: "step-running",
+				}},
+				Sidecars: []v1beta1.SidecarState{},
+			},
+		},
 	}, {
 		desc: "correct TaskRun status step order regardless of pod container status order",
 		pod: corev1.Pod{
@@ -1109,6 +1639,7 @@ func TestMakeRunStatusJSONError(t *testing.T) {
 			Sidecars: []v1beta1.SidecarState{},
 			// We don't actually care about the time, just that it's not nil
 			CompletionTime: &metav1.Time{Time: time.Now()},
+			Failure:        &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonFailed, Step: "non-json", ExitCode: 1},
 		},
 	}
 	tr := v1beta1.TaskRun{
@@ -1226,6 +1757,217 @@ func TestSidecarsReady(t *testing.T) {
 	}
 }
 
+func TestUngatedSidecarsReady(t *testing.T) {
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				sidecarStepsAnnotationPrefix + "sidecar-database": "step-test",
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodRunning,
+			ContainerStatuses: []corev1.ContainerStatus{
+				{Name: "step-test"},
+				{
+					Name: "sidecar-database",
+					State: corev1.ContainerState{
+						Waiting: &corev1.ContainerStateWaiting{},
+					},
+				},
+				{
+					Name: "sidecar-ungated",
+					State: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{},
+					},
+					Ready: true,
+				},
+			},
+		},
+	}
+	if !UngatedSidecarsReady(pod) {
+		t.Errorf("UngatedSidecarsReady got false, want true")
+	}
+	if SidecarsReady(pod.Status) {
+		t.Errorf("SidecarsReady got true, want false")
+	}
+}
+
+func TestRequiredSidecarFailure(t *testing.T) {
+	failed := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{ExitCode: 1, Message: "connection refused"}}
+	running := corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}
+	for _, c := range []struct {
+		desc     string
+		statuses []corev1.ContainerStatus
+		wantMsg  string
+		want     *v1beta1.TaskRunFailure
+	}{{
+		desc: "failed sidecar needed by a running step",
+		statuses: []corev1.ContainerStatus{
+			{Name: "step-test", State: running},
+			{Name: "sidecar-database", State: failed, ImageID: "postgres"},
+		},
+		wantMsg: "sidecar \"sidecar-database\" exited with code 1 (image: \"postgres\"): connection refused; for logs run: kubectl -n foo logs pod -c sidecar-database\n",
+		want:    &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonSidecarFailed, Sidecar: "database", ExitCode: 1},
+	}, {
+		desc: "failed sidecar whose steps have finished",
+		statuses: []corev1.ContainerStatus{
+			{Name: "step-test", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}},
+			{Name: "sidecar-database", State: failed},
+		},
+	}, {
+		desc: "failed sidecar not needed by a step",
+		statuses: []corev1.ContainerStatus{
+			{Name: "step-test", State: running},
+			{Name: "sidecar-ungated", State: failed},
+		},
+	}, {
+		desc: "sidecar needed by a step stopped successfully",
+		statuses: []corev1.ContainerStatus{
+			{Name: "step-test", State: running},
+			{Name: "sidecar-database", State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}}},
+		},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "pod",
+					Namespace: "foo",
+					Annotations: map[string]string{
+						sidecarStepsAnnotationPrefix + "sidecar-database": "step-test",
+					},
+				},
+				Status: corev1.PodStatus{
+					Phase:             corev1.PodRunning,
+					ContainerStatuses: c.statuses,
+				},
+			}
+			got, gotMsg := RequiredSidecarFailure(pod)
+			if d := cmp.Diff(c.want, got); d != "" {
+				t.Errorf("RequiredSidecarFailure failure %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff(c.wantMsg, gotMsg); d != "" {
+				t.Errorf("RequiredSidecarFailure message %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestPermanentImagePullFailure(t *testing.T) {
+	for _, c := range []struct {
+		desc     string
+		statuses []corev1.ContainerStatus
+		wantMsg  string
+		want     *v1beta1.TaskRunFailure
+	}{{
+		desc: "step image that is never pulled",
+		statuses: []corev1.ContainerStatus{{
+			Name:  "step-build",
+			Image: "registry.example.com/builder",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImageNeverPull", Message: "Container image is not present with pull policy of Never"}},
+		}},
+		wantMsg: `the image "registry.example.com/builder" of "step-build" cannot be pulled: Container image is not present with pull policy of Never`,
+		want:    &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonImagePullFailed, Step: "build"},
+	}, {
+		desc: "sidecar with an invalid image name",
+		statuses: []corev1.ContainerStatus{{
+			Name:  "step-build",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}},
+		}, {
+			Name:  "sidecar-database",
+			Image: "Postgres",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "InvalidImageName", Message: "invalid reference format"}},
+		}},
+		wantMsg: `the image "Postgres" of "sidecar-database" cannot be pulled: invalid reference format`,
+		want:    &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonImagePullFailed, Sidecar: "database"},
+	}, {
+		desc: "step image pull backing off",
+		statuses: []corev1.ContainerStatus{{
+			Name:  "step-build",
+			Image: "registry.example.com/builder",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}},
+		}},
+	}, {
+		desc: "first image pull attempt failed",
+		statuses: []corev1.ContainerStatus{{
+			Name:  "step-build",
+			State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ErrImagePull"}},
+		}},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "foo"},
+				Status: corev1.PodStatus{
+					Phase:             corev1.PodPending,
+					ContainerStatuses: c.statuses,
+				},
+			}
+			got, gotMsg := PermanentImagePullFailure(pod)
+			if d := cmp.Diff(c.want, got); d != "" {
+				t.Errorf("PermanentImagePullFailure failure %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff(c.wantMsg, gotMsg); d != "" {
+				t.Errorf("PermanentImagePullFailure message %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestImagePullFailure(t *testing.T) {
+	backOff := corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"}}
+	for _, c := range []struct {
+		desc    string
+		status  v1beta1.TaskRunStatus
+		wantMsg string
+		want    *v1beta1.TaskRunFailure
+	}{{
+		desc: "step image pull backing off",
+		status: v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+			Steps: []v1beta1.StepState{{
+				Name:           "build",
+				ContainerName:  "step-build",
+				ContainerState: backOff,
+			}},
+		}},
+		wantMsg: `the image of "step-build" could not be pulled: Back-off pulling image`,
+		want:    &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonImagePullFailed, Step: "build"},
+	}, {
+		desc: "sidecar image pull backing off",
+		status: v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+			Steps: []v1beta1.StepState{{
+				Name:           "build",
+				ContainerName:  "step-build",
+				ContainerState: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "PodInitializing"}},
+			}},
+			Sidecars: []v1beta1.SidecarState{{
+				Name:           "database",
+				ContainerName:  "sidecar-database",
+				ContainerState: backOff,
+			}},
+		}},
+		wantMsg: `the image of "sidecar-database" could not be pulled: Back-off pulling image`,
+		want:    &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonImagePullFailed, Sidecar: "database"},
+	}, {
+		desc: "step still running",
+		status: v1beta1.TaskRunStatus{TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+			Steps: []v1beta1.StepState{{
+				Name:           "build",
+				ContainerName:  "step-build",
+				ContainerState: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}},
+		}},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			got, gotMsg := ImagePullFailure(c.status)
+			if d := cmp.Diff(c.want, got); d != "" {
+				t.Errorf("ImagePullFailure failure %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff(c.wantMsg, gotMsg); d != "" {
+				t.Errorf("ImagePullFailure message %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
 func TestMarkStatusRunning(t *testing.T) {
 	trs := v1beta1.TaskRunStatus{}
 	MarkStatusRunning(&trs, v1beta1.TaskRunReasonRunning.String(), "Not all Steps in the Task have finished executing")
@@ -0,0 +1,68 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/tarball"
+)
+
+// Push pushes the envelope, as the single layer of an image, to the given
+// repository under the given tag. It returns the reference of the image by
+// digest.
+func Push(env *Envelope, repository, tag string, keychain authn.Keychain) (string, error) {
+	ref, err := name.NewTag(repository + ":" + tag)
+	if err != nil {
+		return "", fmt.Errorf("invalid provenance image reference: %w", err)
+	}
+
+	data, err := json.Marshal(env)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize provenance: %w", err)
+	}
+	layer, err := tarball.LayerFromReader(bytes.NewReader(data))
+	if err != nil {
+		return "", fmt.Errorf("failed to create provenance layer: %w", err)
+	}
+	img, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer: layer,
+		Annotations: map[string]string{
+			"org.opencontainers.image.title": "provenance.json",
+			"dev.tekton.provenance.type":     env.PayloadType,
+		},
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to create provenance image: %w", err)
+	}
+
+	if err := remote.Write(ref, img, remote.WithAuthFromKeychain(keychain)); err != nil {
+		return "", fmt.Errorf("failed to push provenance to %s: %w", ref, err)
+	}
+	digest, err := img.Digest()
+	if err != nil {
+		return "", fmt.Errorf("failed to read provenance image digest: %w", err)
+	}
+	return ref.Context().Digest(digest.String()).String(), nil
+}
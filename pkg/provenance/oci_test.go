@@ -0,0 +1,94 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/tektoncd/pipeline/test/diff"
+)
+
+func TestPush(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repository := u.Host + "/tekton/provenance"
+
+	env, err := Sign(&Statement{Type: StatementType, PredicateType: PredicateType}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Push(env, repository, "taskrun-1234", authn.DefaultKeychain)
+	if err != nil {
+		t.Fatalf("Unexpected error pushing the provenance: %v", err)
+	}
+	if !strings.HasPrefix(got, repository+"@sha256:") {
+		t.Errorf("Expected a reference by digest in %s, got %s", repository, got)
+	}
+
+	ref, err := name.ParseReference(got)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := remote.Image(ref)
+	if err != nil {
+		t.Fatalf("Unexpected error pulling the provenance: %v", err)
+	}
+	layers, err := img.Layers()
+	if err != nil || len(layers) != 1 {
+		t.Fatalf("Expected a single layer, got %d: %v", len(layers), err)
+	}
+	rc, err := layers[0].Uncompressed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	data, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var pushed Envelope
+	if err := json.Unmarshal(data, &pushed); err != nil {
+		t.Fatalf("Unexpected error reading the pushed provenance: %v", err)
+	}
+	if d := cmp.Diff(env, &pushed); d != "" {
+		t.Errorf("Unexpected pushed provenance %s", diff.PrintWantGot(d))
+	}
+
+	if _, err := remote.Image(ref.Context().Tag("taskrun-1234")); err != nil {
+		t.Errorf("Expected the provenance to be tagged: %v", err)
+	}
+}
+
+func TestPush_InvalidRepository(t *testing.T) {
+	if _, err := Push(&Envelope{}, "gcr.io/Invalid", "tag", authn.DefaultKeychain); err == nil {
+		t.Error("Expected an error pushing to an invalid repository")
+	}
+}
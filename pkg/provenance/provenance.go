@@ -0,0 +1,250 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package provenance generates in-toto attestations of the SLSA provenance of
+// the TaskRuns, signs them and stores them in an OCI registry.
+package provenance
+
+import (
+	"strings"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/version"
+)
+
+const (
+	// StatementType is the type of the in-toto statements.
+	StatementType = "https://in-toto.io/Statement/v0.1"
+	// PredicateType is the type of the SLSA provenance predicates.
+	PredicateType = "https://slsa.dev/provenance/v0.1"
+	// RecipeType is the type of the recipe of the TaskRuns.
+	RecipeType = "https://tekton.dev/attestations/v1beta1/TaskRun"
+	// BuilderID identifies the Tekton Pipelines controller as the builder,
+	// it is suffixed with the version of the controller.
+	BuilderID = "https://tekton.dev/pipeline"
+
+	// Annotation is the annotation of the TaskRuns holding their signed
+	// provenance, when it is stored in an annotation.
+	Annotation = "tekton.dev/provenance"
+	// ImageAnnotation is the annotation of the TaskRuns holding the
+	// reference of the image their signed provenance was pushed to, when it
+	// is stored in an OCI registry.
+	ImageAnnotation = "tekton.dev/provenance-image"
+
+	// imageURLResult and imageDigestResult are the names of the Task results
+	// declaring an image built by the Task.
+	imageURLResult    = "IMAGE_URL"
+	imageDigestResult = "IMAGE_DIGEST"
+
+	// digestResourceKey and urlResourceKey are the keys of the results
+	// written by the image digest exporter for the image output resources.
+	digestResourceKey = "digest"
+	urlResourceKey    = "url"
+
+	dockerPullablePrefix = "docker-pullable://"
+)
+
+// Statement is an in-toto statement about the subjects built by a TaskRun.
+type Statement struct {
+	Type          string    `json:"_type"`
+	Subject       []Subject `json:"subject"`
+	PredicateType string    `json:"predicateType"`
+	Predicate     Predicate `json:"predicate"`
+}
+
+// Subject is an artifact built by a TaskRun.
+type Subject struct {
+	Name   string    `json:"name"`
+	Digest DigestSet `json:"digest"`
+}
+
+// DigestSet maps a digest algorithm to the hex-encoded value of a digest.
+type DigestSet map[string]string
+
+// Predicate is the SLSA provenance of a TaskRun.
+type Predicate struct {
+	Builder   Builder    `json:"builder"`
+	Recipe    Recipe     `json:"recipe"`
+	Metadata  Metadata   `json:"metadata"`
+	Materials []Material `json:"materials,omitempty"`
+}
+
+// Builder identifies what ran the TaskRun.
+type Builder struct {
+	ID string `json:"id"`
+}
+
+// Recipe describes how the subjects were built.
+type Recipe struct {
+	Type        string            `json:"type"`
+	EntryPoint  string            `json:"entryPoint,omitempty"`
+	Arguments   []v1beta1.Param   `json:"arguments,omitempty"`
+	Environment RecipeEnvironment `json:"environment"`
+}
+
+// RecipeEnvironment holds the spec the TaskRun ran and the results it
+// produced.
+type RecipeEnvironment struct {
+	TaskSpec  *v1beta1.TaskSpec                `json:"taskSpec,omitempty"`
+	Results   []v1beta1.TaskRunResult          `json:"results,omitempty"`
+	Resources []v1beta1.PipelineResourceResult `json:"resourcesResult,omitempty"`
+}
+
+// Metadata holds when the TaskRun ran.
+type Metadata struct {
+	BuildStartedOn  *time.Time `json:"buildStartedOn,omitempty"`
+	BuildFinishedOn *time.Time `json:"buildFinishedOn,omitempty"`
+}
+
+// Material is an image the steps or sidecars of a TaskRun ran.
+type Material struct {
+	URI    string    `json:"uri"`
+	Digest DigestSet `json:"digest"`
+}
+
+// Generate returns the provenance of the given TaskRun, built from the spec it
+// ran, the images of its steps and sidecars, its params and its results.
+func Generate(tr *v1beta1.TaskRun) *Statement {
+	s := &Statement{
+		Type:          StatementType,
+		Subject:       subjects(tr),
+		PredicateType: PredicateType,
+		Predicate: Predicate{
+			Builder: Builder{ID: builderID()},
+			Recipe: Recipe{
+				Type:      RecipeType,
+				Arguments: tr.Spec.Params,
+				Environment: RecipeEnvironment{
+					TaskSpec:  tr.Status.TaskSpec,
+					Results:   tr.Status.TaskRunResults,
+					Resources: tr.Status.ResourcesResult,
+				},
+			},
+			Materials: materials(tr),
+		},
+	}
+	if tr.Spec.TaskRef != nil {
+		s.Predicate.Recipe.EntryPoint = tr.Spec.TaskRef.Name
+	}
+	if tr.Status.StartTime != nil {
+		t := tr.Status.StartTime.Time.UTC()
+		s.Predicate.Metadata.BuildStartedOn = &t
+	}
+	if tr.Status.CompletionTime != nil {
+		t := tr.Status.CompletionTime.Time.UTC()
+		s.Predicate.Metadata.BuildFinishedOn = &t
+	}
+	return s
+}
+
+func builderID() string {
+	if version.PipelineVersion == "" {
+		return BuilderID
+	}
+	return BuilderID + "@" + version.PipelineVersion
+}
+
+// subjects returns the images built by the TaskRun, as declared by the
+// IMAGE_URL and IMAGE_DIGEST results of its Task or exported for its image
+// output resources.
+func subjects(tr *v1beta1.TaskRun) []Subject {
+	subjects := []Subject{}
+
+	var url, digest string
+	for _, r := range tr.Status.TaskRunResults {
+		switch r.Name {
+		case imageURLResult:
+			url = strings.TrimSpace(r.Value)
+		case imageDigestResult:
+			digest = strings.TrimSpace(r.Value)
+		}
+	}
+	if s, ok := subject(url, digest); ok {
+		subjects = append(subjects, s)
+	}
+
+	urls := map[string]string{}
+	for _, r := range tr.Status.ResourcesResult {
+		if r.Key == urlResourceKey {
+			urls[r.ResourceName] = r.Value
+		}
+	}
+	for _, r := range tr.Status.ResourcesResult {
+		if r.Key != digestResourceKey {
+			continue
+		}
+		name := urls[r.ResourceName]
+		if name == "" {
+			name = r.ResourceName
+		}
+		if s, ok := subject(name, r.Value); ok {
+			subjects = append(subjects, s)
+		}
+	}
+	return subjects
+}
+
+func subject(name, digest string) (Subject, bool) {
+	if name == "" {
+		return Subject{}, false
+	}
+	d, ok := digestSet(digest)
+	if !ok {
+		return Subject{}, false
+	}
+	return Subject{Name: name, Digest: d}, true
+}
+
+// materials returns the images, by digest, the steps and sidecars of the
+// TaskRun ran.
+func materials(tr *v1beta1.TaskRun) []Material {
+	var imageIDs []string
+	for _, s := range tr.Status.Steps {
+		imageIDs = append(imageIDs, s.ImageID)
+	}
+	for _, s := range tr.Status.Sidecars {
+		imageIDs = append(imageIDs, s.ImageID)
+	}
+
+	var materials []Material
+	seen := map[string]bool{}
+	for _, id := range imageIDs {
+		// The image ID of a container is reported by the runtime as
+		// [docker-pullable://]<repository>@<algorithm>:<hex>.
+		id = strings.TrimPrefix(id, dockerPullablePrefix)
+		parts := strings.SplitN(id, "@", 2)
+		if len(parts) != 2 || seen[id] {
+			continue
+		}
+		d, ok := digestSet(parts[1])
+		if !ok {
+			continue
+		}
+		seen[id] = true
+		materials = append(materials, Material{URI: parts[0], Digest: d})
+	}
+	return materials
+}
+
+// digestSet parses a digest of the form <algorithm>:<hex>.
+func digestSet(digest string) (DigestSet, bool) {
+	parts := strings.SplitN(digest, ":", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, false
+	}
+	return DigestSet{parts[0]: parts[1]}, true
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	imageDigest = "sha256:05f95b26ed10668b7183c1e2da98610e91372fa9f510046d4ce5812addad86b5"
+	stepDigest  = "sha256:2d2f1fb47ab1c2c3b61a9e1ec8b0c6b0c4d7b3f0b7c6b83b1ef0d5ec6b1ed1c2"
+)
+
+func TestGenerate(t *testing.T) {
+	startTime := time.Date(2020, 11, 3, 10, 0, 0, 0, time.UTC)
+	completionTime := startTime.Add(2 * time.Minute)
+	taskSpec := &v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{Container: corev1.Container{Name: "build", Image: "gcr.io/kaniko-project/executor"}}},
+	}
+	params := []v1beta1.Param{{Name: "IMAGE", Value: *v1beta1.NewArrayOrString("gcr.io/foo/bar")}}
+	results := []v1beta1.TaskRunResult{
+		{Name: "IMAGE_URL", Value: "gcr.io/foo/bar\n"},
+		{Name: "IMAGE_DIGEST", Value: imageDigest},
+	}
+	resources := []v1beta1.PipelineResourceResult{
+		{Key: "digest", Value: imageDigest, ResourceName: "built-image"},
+		{Key: "url", Value: "gcr.io/foo/baz", ResourceName: "built-image"},
+		{Key: "digest", Value: imageDigest, ResourceName: "other-image"},
+		{Key: "digest", Value: "invalid", ResourceName: "invalid-image"},
+	}
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "build", Namespace: "foo"},
+		Spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "kaniko"},
+			Params:  params,
+		},
+		Status: v1beta1.TaskRunStatus{
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				StartTime:       &metav1.Time{Time: startTime},
+				CompletionTime:  &metav1.Time{Time: completionTime},
+				TaskSpec:        taskSpec,
+				TaskRunResults:  results,
+				ResourcesResult: resources,
+				Steps: []v1beta1.StepState{{
+					Name:    "build",
+					ImageID: "docker-pullable://gcr.io/kaniko-project/executor@" + stepDigest,
+				}, {
+					Name:    "push",
+					ImageID: "gcr.io/kaniko-project/executor@" + stepDigest,
+				}, {
+					Name:    "unknown",
+					ImageID: stepDigest,
+				}},
+				Sidecars: []v1beta1.SidecarState{{
+					Name:    "registry",
+					ImageID: "docker-pullable://registry@" + imageDigest,
+				}},
+			},
+		},
+	}
+
+	want := &Statement{
+		Type: StatementType,
+		Subject: []Subject{{
+			Name:   "gcr.io/foo/bar",
+			Digest: DigestSet{"sha256": "05f95b26ed10668b7183c1e2da98610e91372fa9f510046d4ce5812addad86b5"},
+		}, {
+			Name:   "gcr.io/foo/baz",
+			Digest: DigestSet{"sha256": "05f95b26ed10668b7183c1e2da98610e91372fa9f510046d4ce5812addad86b5"},
+		}, {
+			Name:   "other-image",
+			Digest: DigestSet{"sha256": "05f95b26ed10668b7183c1e2da98610e91372fa9f510046d4ce5812addad86b5"},
+		}},
+		PredicateType: PredicateType,
+		Predicate: Predicate{
+			Builder: Builder{ID: BuilderID},
+			Recipe: Recipe{
+				Type:       RecipeType,
+				EntryPoint: "kaniko",
+				Arguments:  params,
+				Environment: RecipeEnvironment{
+					TaskSpec:  taskSpec,
+					Results:   results,
+					Resources: resources,
+				},
+			},
+			Metadata: Metadata{
+				BuildStartedOn:  &startTime,
+				BuildFinishedOn: &completionTime,
+			},
+			Materials: []Material{{
+				URI:    "gcr.io/kaniko-project/executor",
+				Digest: DigestSet{"sha256": "2d2f1fb47ab1c2c3b61a9e1ec8b0c6b0c4d7b3f0b7c6b83b1ef0d5ec6b1ed1c2"},
+			}, {
+				URI:    "registry",
+				Digest: DigestSet{"sha256": "05f95b26ed10668b7183c1e2da98610e91372fa9f510046d4ce5812addad86b5"},
+			}},
+		},
+	}
+	if d := cmp.Diff(want, Generate(tr)); d != "" {
+		t.Errorf("Unexpected provenance %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestGenerate_NoSubjects(t *testing.T) {
+	got := Generate(&v1beta1.TaskRun{})
+	if len(got.Subject) != 0 {
+		t.Errorf("Expected no subjects, got %v", got.Subject)
+	}
+	if got.Predicate.Metadata.BuildStartedOn != nil || got.Predicate.Materials != nil {
+		t.Errorf("Expected no metadata nor materials, got %v", got.Predicate)
+	}
+}
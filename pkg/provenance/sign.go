@@ -0,0 +1,131 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// PayloadType is the type of the payload of the envelopes, the in-toto
+// statements.
+const PayloadType = "application/vnd.in-toto+json"
+
+// Envelope is a DSSE envelope holding a signed statement.
+type Envelope struct {
+	PayloadType string      `json:"payloadType"`
+	Payload     []byte      `json:"payload"`
+	Signatures  []Signature `json:"signatures"`
+}
+
+// Signature is the signature of the payload of an Envelope.
+type Signature struct {
+	KeyID string `json:"keyid,omitempty"`
+	Sig   []byte `json:"sig"`
+}
+
+// LoadSigner parses a PEM-encoded PKCS#8, PKCS#1 or SEC 1 private key.
+// ECDSA, Ed25519 and RSA keys are supported.
+func LoadSigner(data []byte) (crypto.Signer, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("no PEM-encoded private key found")
+	}
+	var (
+		key interface{}
+		err error
+	)
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	switch k := key.(type) {
+	case *ecdsa.PrivateKey, *rsa.PrivateKey, ed25519.PrivateKey:
+		return k.(crypto.Signer), nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
+
+// Sign serializes the statement in an Envelope, signed with the given signer.
+// The Envelope holds no signature when the signer is nil.
+func Sign(s *Statement, signer crypto.Signer) (*Envelope, error) {
+	payload, err := json.Marshal(s)
+	if err != nil {
+		return nil, fmt.Errorf("failed to serialize provenance: %w", err)
+	}
+	env := &Envelope{
+		PayloadType: PayloadType,
+		Payload:     payload,
+		Signatures:  []Signature{},
+	}
+	if signer == nil {
+		return env, nil
+	}
+
+	message := PAE(env.PayloadType, env.Payload)
+	var sig []byte
+	if _, ok := signer.(ed25519.PrivateKey); ok {
+		sig, err = signer.Sign(rand.Reader, message, crypto.Hash(0))
+	} else {
+		digest := sha256.Sum256(message)
+		sig, err = signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign provenance: %w", err)
+	}
+	keyID, err := KeyID(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	env.Signatures = append(env.Signatures, Signature{KeyID: keyID, Sig: sig})
+	return env, nil
+}
+
+// PAE returns the pre-authentication encoding of the payload, which is what
+// the envelopes sign.
+func PAE(payloadType string, payload []byte) []byte {
+	return []byte(fmt.Sprintf("DSSEv1 %d %s %d %s", len(payloadType), payloadType, len(payload), payload))
+}
+
+// KeyID returns the hex-encoded SHA-256 digest of the PKIX encoding of the
+// public key.
+func KeyID(pub crypto.PublicKey) (string, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return "", fmt.Errorf("failed to serialize public key: %w", err)
+	}
+	digest := sha256.Sum256(der)
+	return hex.EncodeToString(digest[:]), nil
+}
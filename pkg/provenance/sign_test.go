@@ -0,0 +1,158 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edDER, err := x509.MarshalPKCS8PrivateKey(edKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		key    *pem.Block
+		verify func(pub crypto.PublicKey, message, sig []byte) bool
+	}{{
+		name: "ecdsa",
+		key:  &pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER},
+		verify: func(pub crypto.PublicKey, message, sig []byte) bool {
+			var s struct{ R, S *big.Int }
+			if _, err := asn1.Unmarshal(sig, &s); err != nil {
+				return false
+			}
+			digest := sha256.Sum256(message)
+			return ecdsa.Verify(pub.(*ecdsa.PublicKey), digest[:], s.R, s.S)
+		},
+	}, {
+		name: "ed25519",
+		key:  &pem.Block{Type: "PRIVATE KEY", Bytes: edDER},
+		verify: func(pub crypto.PublicKey, message, sig []byte) bool {
+			return ed25519.Verify(pub.(ed25519.PublicKey), message, sig)
+		},
+	}, {
+		name: "rsa",
+		key:  &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)},
+		verify: func(pub crypto.PublicKey, message, sig []byte) bool {
+			digest := sha256.Sum256(message)
+			return rsa.VerifyPKCS1v15(pub.(*rsa.PublicKey), crypto.SHA256, digest[:], sig) == nil
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			signer, err := LoadSigner(pem.EncodeToMemory(tc.key))
+			if err != nil {
+				t.Fatalf("Unexpected error loading the key: %v", err)
+			}
+			s := &Statement{Type: StatementType, PredicateType: PredicateType}
+			env, err := Sign(s, signer)
+			if err != nil {
+				t.Fatalf("Unexpected error signing: %v", err)
+			}
+			if len(env.Signatures) != 1 {
+				t.Fatalf("Expected one signature, got %d", len(env.Signatures))
+			}
+			if !tc.verify(signer.Public(), PAE(env.PayloadType, env.Payload), env.Signatures[0].Sig) {
+				t.Errorf("Expected a valid signature of the payload")
+			}
+			keyID, err := KeyID(signer.Public())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if env.Signatures[0].KeyID != keyID {
+				t.Errorf("Expected key ID %s, got %s", keyID, env.Signatures[0].KeyID)
+			}
+			var got Statement
+			if err := json.Unmarshal(env.Payload, &got); err != nil {
+				t.Fatalf("Unexpected error reading the payload: %v", err)
+			}
+			if got.Type != StatementType {
+				t.Errorf("Expected statement type %q, got %q", StatementType, got.Type)
+			}
+		})
+	}
+}
+
+func TestSign_Unsigned(t *testing.T) {
+	env, err := Sign(&Statement{}, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(env.Signatures) != 0 {
+		t.Errorf("Expected no signatures, got %v", env.Signatures)
+	}
+	if env.PayloadType != PayloadType {
+		t.Errorf("Expected payload type %q, got %q", PayloadType, env.PayloadType)
+	}
+}
+
+func TestLoadSigner_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data []byte
+	}{{
+		name: "not pem",
+		data: []byte("not a key"),
+	}, {
+		name: "invalid key",
+		data: pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: []byte("invalid")}),
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := LoadSigner(tc.data); err == nil {
+				t.Errorf("Expected an error loading %q", tc.data)
+			}
+		})
+	}
+}
+
+func TestPAE(t *testing.T) {
+	got := string(PAE("http://example.com/HelloWorld", []byte("hello world")))
+	want := "DSSEv1 29 http://example.com/HelloWorld 11 hello world"
+	if got != want {
+		t.Errorf("PAE() = %q, want %q", got, want)
+	}
+}
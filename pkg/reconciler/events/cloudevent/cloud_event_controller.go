@@ -19,9 +19,11 @@ package cloudevent
 import (
 	"context"
 	"errors"
+	"fmt"
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/google/uuid"
 	"github.com/hashicorp/go-multierror"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	resource "github.com/tektoncd/pipeline/pkg/apis/resource/v1alpha1"
@@ -30,6 +32,7 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	controller "knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
 )
@@ -87,6 +90,10 @@ func SendCloudEvents(tr *v1beta1.TaskRun, ceclient CEClient, logger *zap.Sugared
 	var merr *multierror.Error
 	for idx, cloudEventDelivery := range tr.Status.CloudEvents {
 		eventStatus := &(tr.Status.CloudEvents[idx].Status)
+		// Skip the events sent to the default sink, they are sent by SendQueuedCloudEvents
+		if cloudEventDelivery.Type != "" {
+			continue
+		}
 		// Skip events that have already been sent (successfully or unsuccessfully)
 		// Ensure we try to send all events once (possibly through different reconcile calls)
 		if eventStatus.Condition != v1beta1.CloudEventConditionUnknown || eventStatus.RetryCount > 0 {
@@ -114,42 +121,290 @@ func SendCloudEvents(tr *v1beta1.TaskRun, ceclient CEClient, logger *zap.Sugared
 	return merr.ErrorOrNil()
 }
 
-// SendCloudEventWithRetries sends a cloud event for the specified resource.
-// It does not block and it perform retries with backoff using the cloudevents
-// sdk-go capabilities.
-// It accepts a runtime.Object to avoid making objectWithCondition public since
-// it's only used within the events/cloudevents packages.
-func SendCloudEventWithRetries(ctx context.Context, object runtime.Object) error {
-	var (
-		o  objectWithCondition
-		ok bool
-	)
-	if o, ok = object.(objectWithCondition); !ok {
+// QueueCloudEvent records in the status of the TaskRun or PipelineRun the
+// delivery to target of the cloud event about its current condition, unless
+// the target does not accept events of its type. The event is sent by
+// SendQueuedCloudEvents, and sent again by the next reconciles of the run
+// until it is delivered, so that it is not lost when the sink is down or when
+// the controller restarts.
+func QueueCloudEvent(object runtime.Object, target Target) error {
+	o, ok := object.(objectWithCondition)
+	if !ok {
 		return errors.New("Input object does not satisfy objectWithCondition")
 	}
-	logger := logging.FromContext(ctx)
+	eventType, err := getEventType(o)
+	if err != nil {
+		return err
+	}
+	if eventType == nil {
+		return errors.New("No matching event type found")
+	}
+	return QueueCloudEventOfType(object, target, *eventType, nil)
+}
+
+// QueueCloudEventOfType records in the status of the TaskRun or PipelineRun
+// the delivery to target of a cloud event of the given type, like
+// QueueCloudEvent does for the events about the condition of the run.
+// pipelineTask identifies the PipelineTask of the PipelineRun the event is
+// about, and is nil for the events about the run itself.
+func QueueCloudEventOfType(object runtime.Object, target Target, eventType TektonEventType, pipelineTask *v1beta1.CloudEventPipelineTask) error {
+	deliveries := cloudEventDeliveries(object)
+	if deliveries == nil {
+		return fmt.Errorf("cannot record the delivery of cloud events in the status of %T", object)
+	}
+	if pipelineTask != nil {
+		if _, ok := object.(*v1beta1.PipelineRun); !ok {
+			return fmt.Errorf("cannot send cloud events about a PipelineTask of %T", object)
+		}
+	}
+	if !target.Accepts(eventType) {
+		return nil
+	}
+	delivery := v1beta1.CloudEventDelivery{
+		Target:       target.Sink,
+		Type:         eventType.String(),
+		ID:           uuid.New().String(),
+		PipelineTask: pipelineTask,
+		Status: v1beta1.CloudEventDeliveryState{
+			Condition: v1beta1.CloudEventConditionUnknown,
+		},
+	}
+	// The event is built now, so that its retries carry the state of the
+	// run at the time the event happened.
+	run, ok := withoutCloudEventDeliveries(object)
+	if !ok {
+		return errors.New("Input object does not satisfy objectWithCondition")
+	}
+	event, err := eventForDelivery(run, delivery)
+	if err != nil {
+		return err
+	}
+	delivery.Data = string(event.Data())
+	*deliveries = append(*deliveries, delivery)
+	return nil
+}
+
+// SendQueuedCloudEvents records in the status of the TaskRun or PipelineRun
+// the outcome of the attempts to deliver the cloud events queued by
+// QueueCloudEvent that completed since the previous call, and attempts the
+// delivery of the events that are not delivered yet. The attempts run in the
+// background: the run is reconciled again once they complete, so that their
+// outcome is recorded. A failed delivery is attempted again once its backoff
+// expired, up to maxRetries times. The events are sent as they were when they
+// were queued. The oldest deliveries that are done are dropped from the status
+// once there are more than maxCloudEventDeliveries of them.
+func SendQueuedCloudEvents(ctx context.Context, object runtime.Object, maxRetries int) error {
+	o, ok := object.(objectWithCondition)
+	if !ok {
+		return errors.New("Input object does not satisfy objectWithCondition")
+	}
+	deliveries := cloudEventDeliveries(object)
+	if deliveries == nil {
+		return nil
+	}
+	defer func() {
+		*deliveries = pruneCloudEventDeliveries(*deliveries, maxRetries)
+	}()
+	if !hasPendingCloudEvents(*deliveries, maxRetries) {
+		return nil
+	}
 	ceClient := Get(ctx)
 	if ceClient == nil {
 		return errors.New("No cloud events client found in the context")
 	}
-	event, err := EventForObjectWithCondition(o)
+	tracker := getDeliveryTracker(ctx)
+	logger := logging.FromContext(ctx)
+	meta := o.GetObjectMeta()
+	key := types.NamespacedName{Namespace: meta.GetNamespace(), Name: meta.GetName()}
+
+	for idx := range *deliveries {
+		delivery := &(*deliveries)[idx]
+		if !isPendingCloudEvent(*delivery, maxRetries) {
+			continue
+		}
+		if attempt, ok := tracker.outcome(delivery.ID); ok {
+			if !attempt.done {
+				// The run is reconciled again once the attempt completed
+				continue
+			}
+			delivery.Status.SentAt = &metav1.Time{Time: attempt.sentAt}
+			delivery.Status.RetryCount++
+			if attempt.result == nil {
+				logger.Debugf("Sent cloudevent of type %q to %s", delivery.Type, delivery.Target)
+				delivery.Status.Condition = v1beta1.CloudEventConditionSent
+				delivery.Status.Error = ""
+				delivery.Data = ""
+				continue
+			}
+			logger.Warnf("Failed to send cloudevent of type %q to %s: %s", delivery.Type, delivery.Target, attempt.result.Error())
+			delivery.Status.Condition = v1beta1.CloudEventConditionFailed
+			delivery.Status.Error = attempt.result.Error()
+			if !isPendingCloudEvent(*delivery, maxRetries) {
+				delivery.Data = ""
+				if recorder := controller.GetEventRecorder(ctx); recorder != nil {
+					recorder.Event(object, corev1.EventTypeWarning, "Cloud Event Failure", attempt.result.Error())
+				}
+				continue
+			}
+		}
+		if delivery.Status.SentAt != nil {
+			if wait := time.Until(delivery.Status.SentAt.Add(cloudEventBackoff(delivery.Status.RetryCount))); wait > 0 {
+				tracker.retryAfter(key, wait)
+				continue
+			}
+		}
+		event, err := queuedEvent(o, *delivery)
+		if err != nil {
+			return err
+		}
+		tracker.send(ceClient, delivery.Target, *event, key)
+	}
+	return nil
+}
+
+// HasPendingCloudEvents returns true while some cloud events queued in the
+// status of the TaskRun or PipelineRun by QueueCloudEvent are not delivered
+// yet and may still be retried, up to maxRetries times.
+func HasPendingCloudEvents(object runtime.Object, maxRetries int) bool {
+	deliveries := cloudEventDeliveries(object)
+	return deliveries != nil && hasPendingCloudEvents(*deliveries, maxRetries)
+}
+
+// queuedEvent returns the cloud event of the delivery, with the data it had
+// when it was queued. The data is built from the current state of the run for
+// the deliveries queued without it.
+func queuedEvent(runObject objectWithCondition, delivery v1beta1.CloudEventDelivery) (*cloudevents.Event, error) {
+	event, err := eventForDelivery(runObject, delivery)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	event.SetID(delivery.ID)
+	if delivery.Data != "" {
+		if err := event.SetData(cloudevents.ApplicationJSON, []byte(delivery.Data)); err != nil {
+			return nil, err
+		}
+	}
+	return event, nil
+}
+
+// withoutCloudEventDeliveries returns a copy of the TaskRun or PipelineRun
+// without the deliveries of cloud events in its status, which are not part of
+// the cloud events about the run.
+func withoutCloudEventDeliveries(object runtime.Object) (objectWithCondition, bool) {
+	switch v := object.(type) {
+	case *v1beta1.TaskRun:
+		run := v.DeepCopy()
+		run.Status.CloudEvents = nil
+		return run, true
+	case *v1beta1.PipelineRun:
+		run := v.DeepCopy()
+		run.Status.CloudEvents = nil
+		return run, true
 	}
+	return nil, false
+}
 
-	wasIn := make(chan error)
-	go func() {
-		wasIn <- nil
-		logger.Debugf("Sending cloudevent of type %q", event.Type())
-		if result := ceClient.Send(cloudevents.ContextWithRetriesExponentialBackoff(ctx, 10*time.Millisecond, 10), *event); !cloudevents.IsACK(result) {
-			logger.Warnf("Failed to send cloudevent: %s", result.Error())
-			recorder := controller.GetEventRecorder(ctx)
-			if recorder == nil {
-				logger.Warnf("No recorder in context, cannot emit error event")
+// eventForDelivery creates the cloud event of the delivery, about the run or
+// about one of its PipelineTasks.
+func eventForDelivery(runObject objectWithCondition, delivery v1beta1.CloudEventDelivery) (*cloudevents.Event, error) {
+	eventType := TektonEventType(delivery.Type)
+	if delivery.PipelineTask == nil {
+		return eventForRun(runObject, eventType)
+	}
+	pipelineRun, ok := runObject.(*v1beta1.PipelineRun)
+	if !ok {
+		return nil, fmt.Errorf("cannot send cloud events about a PipelineTask of %T", runObject)
+	}
+	data := PipelineTaskEventData{
+		Name:        delivery.PipelineTask.Name,
+		TaskRunName: delivery.PipelineTask.TaskRunName,
+		RetryCount:  delivery.PipelineTask.RetryCount,
+	}
+	if eventType == PipelineTaskSkippedEventV1 {
+		for _, skippedTask := range pipelineRun.Status.SkippedTasks {
+			if whenExpressions := v1beta1.WhenExpressions(skippedTask.WhenExpressions); skippedTask.Name == data.Name && !whenExpressions.HaveVariables() {
+				data.WhenExpression = whenExpressions.FirstFalse()
 			}
-			recorder.Event(object, corev1.EventTypeWarning, "Cloud Event Failure", result.Error())
 		}
-	}()
+	}
+	return EventForPipelineTask(eventType, pipelineRun, data)
+}
 
-	return <-wasIn
+const (
+	// cloudEventSendTimeout bounds each attempt to deliver a cloud event, so
+	// that a sink that does not answer does not hold a goroutine forever.
+	cloudEventSendTimeout = 10 * time.Second
+	// maxCloudEventBackoff bounds the time between two attempts to deliver a
+	// cloud event.
+	maxCloudEventBackoff = 5 * time.Minute
+	// maxCloudEventDeliveries bounds the number of deliveries to the default
+	// sink kept in the status of a run. The oldest delivered ones are dropped
+	// first.
+	maxCloudEventDeliveries = 20
+)
+
+// cloudEventBackoff returns the time to wait before attempting again the
+// delivery of a cloud event after the given number of attempts.
+func cloudEventBackoff(attempts int32) time.Duration {
+	backoff := time.Second
+	for i := int32(1); i < attempts && backoff < maxCloudEventBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxCloudEventBackoff {
+		return maxCloudEventBackoff
+	}
+	return backoff
+}
+
+// cloudEventDeliveries returns the deliveries of cloud events in the status
+// of the TaskRun or PipelineRun, or nil for other objects.
+func cloudEventDeliveries(object runtime.Object) *[]v1beta1.CloudEventDelivery {
+	switch v := object.(type) {
+	case *v1beta1.TaskRun:
+		return &v.Status.CloudEvents
+	case *v1beta1.PipelineRun:
+		return &v.Status.CloudEvents
+	}
+	return nil
+}
+
+// isPendingCloudEvent returns true if the cloud event of the delivery was
+// queued by QueueCloudEvent, is not delivered yet and may still be retried.
+func isPendingCloudEvent(delivery v1beta1.CloudEventDelivery, maxRetries int) bool {
+	return delivery.Type != "" &&
+		delivery.Status.Condition != v1beta1.CloudEventConditionSent &&
+		int(delivery.Status.RetryCount) <= maxRetries
+}
+
+func hasPendingCloudEvents(deliveries []v1beta1.CloudEventDelivery, maxRetries int) bool {
+	for _, delivery := range deliveries {
+		if isPendingCloudEvent(delivery, maxRetries) {
+			return true
+		}
+	}
+	return false
+}
+
+// pruneCloudEventDeliveries drops the oldest deliveries to the default sink
+// that are done, because they were delivered or are not retried anymore, while
+// there are more than maxCloudEventDeliveries of them.
+func pruneCloudEventDeliveries(deliveries []v1beta1.CloudEventDelivery, maxRetries int) []v1beta1.CloudEventDelivery {
+	count := 0
+	for _, delivery := range deliveries {
+		if delivery.Type != "" {
+			count++
+		}
+	}
+	if count <= maxCloudEventDeliveries {
+		return deliveries
+	}
+	pruned := make([]v1beta1.CloudEventDelivery, 0, len(deliveries))
+	for _, delivery := range deliveries {
+		if count > maxCloudEventDeliveries && delivery.Type != "" && !isPendingCloudEvent(delivery, maxRetries) {
+			count--
+			continue
+		}
+		pruned = append(pruned, delivery)
+	}
+	return pruned
 }
@@ -30,6 +30,9 @@ import (
 	"github.com/tektoncd/pipeline/test/diff"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/client-go/tools/record"
 	"knative.dev/pkg/apis"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
@@ -293,127 +296,375 @@ func TestInitializeCloudEvents(t *testing.T) {
 	}
 }
 
-func TestSendCloudEventWithRetries(t *testing.T) {
-
-	objectStatus := duckv1beta1.Status{
-		Conditions: []apis.Condition{{
-			Type:   apis.ConditionSucceeded,
-			Status: corev1.ConditionTrue,
+func TestQueueCloudEvent(t *testing.T) {
+	taskRun := &v1beta1.TaskRun{
+		Status: v1beta1.TaskRunStatus{Status: duckv1beta1.Status{
+			Conditions: []apis.Condition{{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionUnknown,
+				Reason: v1beta1.TaskRunReasonRunning.String(),
+			}},
 		}},
 	}
+	if err := QueueCloudEvent(taskRun, Target{Sink: "http://sink"}); err != nil {
+		t.Fatalf("Unexpected error queuing cloud event: %v", err)
+	}
+	if len(taskRun.Status.CloudEvents) != 1 {
+		t.Fatalf("Expected one cloud event delivery, got %v", taskRun.Status.CloudEvents)
+	}
+	delivery := taskRun.Status.CloudEvents[0]
+	if delivery.ID == "" {
+		t.Error("Expected the cloud event delivery to have an ID")
+	}
+	// The data of the event is snapshotted when the event is queued
+	if !strings.Contains(delivery.Data, `"reason":"Running"`) {
+		t.Errorf("Expected the data of the cloud event to be snapshotted, got %q", delivery.Data)
+	}
+	want := v1beta1.CloudEventDelivery{
+		Target: "http://sink",
+		Type:   TaskRunRunningEventV1.String(),
+		ID:     delivery.ID,
+		Data:   delivery.Data,
+		Status: v1beta1.CloudEventDeliveryState{Condition: v1beta1.CloudEventConditionUnknown},
+	}
+	if d := cmp.Diff(want, delivery); d != "" {
+		t.Errorf("Wrong cloud event delivery %s", diff.PrintWantGot(d))
+	}
 
-	tests := []struct {
-		name            string
-		clientBehaviour FakeClientBehaviour
-		object          objectWithCondition
-		wantCEvent      string
-		wantEvent       string
-	}{{
-		name: "test-send-cloud-event-taskrun",
-		clientBehaviour: FakeClientBehaviour{
-			SendSuccessfully: true,
-		},
-		object: &v1beta1.TaskRun{
-			ObjectMeta: metav1.ObjectMeta{
-				SelfLink: "/taskruns/test1",
-			},
-			Status: v1beta1.TaskRunStatus{Status: objectStatus},
-		},
-		wantCEvent: "Validation: valid",
-		wantEvent:  "",
-	}, {
-		name: "test-send-cloud-event-pipelinerun",
-		clientBehaviour: FakeClientBehaviour{
-			SendSuccessfully: true,
-		},
-		object: &v1beta1.PipelineRun{
-			ObjectMeta: metav1.ObjectMeta{
-				SelfLink: "/pipelineruns/test1",
-			},
-			Status: v1beta1.PipelineRunStatus{Status: objectStatus},
-		},
-		wantCEvent: "Validation: valid",
-		wantEvent:  "",
-	}, {
-		name: "test-send-cloud-event-failed",
-		clientBehaviour: FakeClientBehaviour{
-			SendSuccessfully: false,
-		},
-		object: &v1beta1.PipelineRun{
-			Status: v1beta1.PipelineRunStatus{Status: objectStatus},
-		},
-		wantCEvent: "",
-		wantEvent:  "Warning Cloud Event Failure",
-	}}
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			ctx := setupFakeContext(t, tc.clientBehaviour, true)
-			ctx, cancel := context.WithCancel(ctx)
-			defer cancel()
-			err := SendCloudEventWithRetries(ctx, tc.object)
-			if err != nil {
-				t.Fatalf("Unexpected error sending cloud events: %v", err)
-			}
-			ceClient := Get(ctx).(FakeClient)
-			err = checkCloudEvents(t, &ceClient, tc.name, tc.wantCEvent)
-			if err != nil {
-				t.Fatalf(err.Error())
-			}
-			recorder := controller.GetEventRecorder(ctx).(*record.FakeRecorder)
-			err = checkEvents(t, recorder, tc.name, tc.wantEvent)
-			if err != nil {
-				t.Fatalf(err.Error())
-			}
+	if err := QueueCloudEvent(&v1beta1.TaskRun{}, Target{Sink: "http://sink"}); err == nil {
+		t.Error("Expected an error queuing a cloud event for a TaskRun without condition")
+	}
+}
+
+func TestPruneCloudEventDeliveries(t *testing.T) {
+	var deliveries []v1beta1.CloudEventDelivery
+	for i := 0; i < maxCloudEventDeliveries+2; i++ {
+		status := v1beta1.CloudEventDeliveryState{Condition: v1beta1.CloudEventConditionSent, RetryCount: 1}
+		switch i {
+		case 0:
+			// Failed, but still retried
+			status = v1beta1.CloudEventDeliveryState{Condition: v1beta1.CloudEventConditionFailed, RetryCount: 1}
+		case 1:
+			// Failed, and not retried anymore
+			status = v1beta1.CloudEventDeliveryState{Condition: v1beta1.CloudEventConditionFailed, RetryCount: 4}
+		}
+		deliveries = append(deliveries, v1beta1.CloudEventDelivery{
+			Target: "http://sink",
+			Type:   PipelineRunUnknownEventV1.String(),
+			ID:     fmt.Sprintf("event-%d", i),
+			Status: status,
 		})
 	}
+	// The deliveries requested via a CloudEventResource are kept
+	deliveries = append(deliveries, v1beta1.CloudEventDelivery{Target: "http://resource-sink"})
+
+	pruned := pruneCloudEventDeliveries(deliveries, 3)
+	if len(pruned) != maxCloudEventDeliveries+1 {
+		t.Fatalf("Expected %d cloud event deliveries, got %d", maxCloudEventDeliveries+1, len(pruned))
+	}
+	// The oldest deliveries that are done are dropped, the one still
+	// retried is kept
+	if pruned[0].ID != "event-0" || pruned[1].ID != "event-3" {
+		t.Errorf("Expected the oldest deliveries that are done to be dropped, got %v", pruned[:2])
+	}
+	if last := pruned[len(pruned)-1]; last.Target != "http://resource-sink" {
+		t.Errorf("Expected the delivery requested via a CloudEventResource to be kept, got %v", last)
+	}
+
+	if got := pruneCloudEventDeliveries(deliveries[:maxCloudEventDeliveries], 3); len(got) != maxCloudEventDeliveries {
+		t.Errorf("Expected no delivery to be dropped below the maximum, got %d", len(got))
+	}
 }
 
-func TestSendCloudEventWithRetriesInvalid(t *testing.T) {
+func TestQueueCloudEventOfType(t *testing.T) {
+	pipelineRun := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pipelinerun", Namespace: "foo"},
+	}
+	pipelineTask := &v1beta1.CloudEventPipelineTask{Name: "test-task", TaskRunName: "test-pipelinerun-test-task", RetryCount: 1}
+	if err := QueueCloudEventOfType(pipelineRun, Target{Sink: "http://sink"}, PipelineTaskRetryStartedEventV1, pipelineTask); err != nil {
+		t.Fatalf("Unexpected error queuing cloud event: %v", err)
+	}
+	// The events of the types the target does not accept are not queued
+	target := Target{Sink: "http://sink", EventTypes: sets.NewString(PipelineRunFinallyStartedEventV1.String())}
+	if err := QueueCloudEventOfType(pipelineRun, target, PipelineTaskSkippedEventV1, &v1beta1.CloudEventPipelineTask{Name: "test-task"}); err != nil {
+		t.Fatalf("Unexpected error queuing cloud event: %v", err)
+	}
+	if len(pipelineRun.Status.CloudEvents) != 1 {
+		t.Fatalf("Expected one cloud event delivery, got %v", pipelineRun.Status.CloudEvents)
+	}
+	delivery := pipelineRun.Status.CloudEvents[0]
+	want := v1beta1.CloudEventDelivery{
+		Target:       "http://sink",
+		Type:         PipelineTaskRetryStartedEventV1.String(),
+		ID:           delivery.ID,
+		PipelineTask: pipelineTask,
+		Data:         `{"parentPipelineRun":{"name":"test-pipelinerun","namespace":"foo"},"pipelineTask":{"name":"test-task","taskRunName":"test-pipelinerun-test-task","retryCount":1}}`,
+		Status:       v1beta1.CloudEventDeliveryState{Condition: v1beta1.CloudEventConditionUnknown},
+	}
+	if d := cmp.Diff(want, delivery); d != "" {
+		t.Errorf("Wrong cloud event delivery %s", diff.PrintWantGot(d))
+	}
+
+	if err := QueueCloudEventOfType(&v1beta1.TaskRun{}, Target{Sink: "http://sink"}, PipelineTaskSkippedEventV1, pipelineTask); err == nil {
+		t.Error("Expected an error queuing a cloud event about a PipelineTask of a TaskRun")
+	}
+}
 
-	tests := []struct {
-		name       string
-		object     objectWithCondition
-		wantCEvent string
-		wantEvent  string
-	}{{
-		name: "test-send-cloud-event-invalid-taskrun",
-		object: &v1beta1.TaskRun{
-			Status: v1beta1.TaskRunStatus{},
+func TestEventForDelivery(t *testing.T) {
+	whenExpression := v1beta1.WhenExpression{Input: "foo", Operator: selection.In, Values: []string{"bar"}}
+	pipelineRun := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-pipelinerun", Namespace: "foo", SelfLink: "/pipelineruns/test-pipelinerun"},
+		Status: v1beta1.PipelineRunStatus{
+			Status: duckv1beta1.Status{Conditions: []apis.Condition{{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionUnknown,
+			}}},
+			PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+				SkippedTasks: []v1beta1.SkippedTask{{Name: "test-task", WhenExpressions: []v1beta1.WhenExpression{whenExpression}}},
+			},
 		},
-		wantCEvent: "Validation: valid",
-		wantEvent:  "",
-	}, {
-		name: "test-send-cloud-event-pipelinerun",
-		object: &v1beta1.PipelineRun{
-			Status: v1beta1.PipelineRunStatus{},
+	}
+
+	event, err := eventForDelivery(pipelineRun, v1beta1.CloudEventDelivery{
+		Type:         PipelineTaskSkippedEventV1.String(),
+		PipelineTask: &v1beta1.CloudEventPipelineTask{Name: "test-task"},
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error creating the cloud event: %v", err)
+	}
+	if event.Type() != PipelineTaskSkippedEventV1.String() || event.Subject() != "foo/test-pipelinerun/test-task" {
+		t.Errorf("Unexpected cloud event %v", event)
+	}
+	data := TektonCloudEventData{}
+	if err := event.DataAs(&data); err != nil {
+		t.Fatalf("Unexpected error reading the payload: %v", err)
+	}
+	if data.PipelineTask == nil || data.PipelineTask.Name != "test-task" || data.PipelineTask.WhenExpression == nil || data.PipelineTask.WhenExpression.Input != "foo" {
+		t.Errorf("Expected the payload to describe the skipped PipelineTask, got %v", data.PipelineTask)
+	}
+
+	event, err = eventForDelivery(pipelineRun, v1beta1.CloudEventDelivery{Type: PipelineRunFinallyStartedEventV1.String()})
+	if err != nil {
+		t.Fatalf("Unexpected error creating the cloud event: %v", err)
+	}
+	if event.Type() != PipelineRunFinallyStartedEventV1.String() || event.Subject() != "test-pipelinerun" {
+		t.Errorf("Unexpected cloud event %v", event)
+	}
+}
+
+func TestSendQueuedCloudEvents(t *testing.T) {
+	behaviour := FakeClientBehaviour{SendSuccessfully: false}
+	ctx, _ := rtesting.SetupFakeContext(t)
+	ctx = WithClient(ctx, &behaviour)
+	ceClient := Get(ctx).(FakeClient)
+	tracker := NewDeliveryTracker()
+	enqueued := make(chan time.Duration, 10)
+	tracker.SetCallbackFunc(func(key types.NamespacedName, delay time.Duration) {
+		if key.Namespace != "foo" || key.Name != "test-pipelinerun" {
+			t.Errorf("Unexpected key %s", key)
+		}
+		enqueued <- delay
+	})
+	ctx = WithDeliveryTracker(ctx, tracker)
+
+	pipelineRun := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "test-pipelinerun",
+			Namespace: "foo",
+			SelfLink:  "/pipelineruns/test-pipelinerun",
 		},
-		wantCEvent: "Validation: valid",
-		wantEvent:  "",
-	}}
-	for _, tc := range tests {
-		t.Run(tc.name, func(t *testing.T) {
-			ctx := setupFakeContext(t, FakeClientBehaviour{
-				SendSuccessfully: true,
-			}, true)
-			ctx, cancel := context.WithCancel(ctx)
-			defer cancel()
-			err := SendCloudEventWithRetries(ctx, tc.object)
-			if err == nil {
-				t.Fatalf("Expected an error sending cloud events for invalid object, got none")
-			}
-		})
+		Status: v1beta1.PipelineRunStatus{Status: duckv1beta1.Status{
+			Conditions: []apis.Condition{{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionTrue,
+			}},
+		}},
+	}
+	if err := QueueCloudEvent(pipelineRun, Target{Sink: "http://sink"}); err != nil {
+		t.Fatalf("Unexpected error queuing cloud event: %v", err)
+	}
+
+	// The first attempt runs in the background, and the run is reconciled
+	// again once it completed
+	if err := SendQueuedCloudEvents(ctx, pipelineRun, 3); err != nil {
+		t.Fatalf("Unexpected error sending cloud events: %v", err)
+	}
+	tracker.Wait()
+	if delay := <-enqueued; delay != 0 {
+		t.Errorf("Expected the run to be reconciled once the attempt completed, got a delay of %s", delay)
+	}
+	if status := pipelineRun.Status.CloudEvents[0].Status; status.RetryCount != 0 {
+		t.Fatalf("Expected the outcome of the attempt to be recorded by the next call, got %v", status)
+	}
+
+	// The outcome of the attempt is recorded, and the run is reconciled again
+	// once the backoff expired
+	if err := SendQueuedCloudEvents(ctx, pipelineRun, 3); err != nil {
+		t.Fatalf("Unexpected error sending cloud events: %v", err)
+	}
+	status := pipelineRun.Status.CloudEvents[0].Status
+	if status.Condition != v1beta1.CloudEventConditionFailed || status.RetryCount != 1 || status.SentAt == nil || status.Error == "" {
+		t.Fatalf("Expected a failed delivery after one attempt, got %v", status)
+	}
+	if delay := <-enqueued; delay <= 0 || delay > cloudEventBackoff(1) {
+		t.Errorf("Expected the run to be reconciled again once the backoff expired, got a delay of %s", delay)
+	}
+
+	// Once the backoff expired the event is sent again, with the same ID
+	pipelineRun.Status.CloudEvents[0].Status.SentAt = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+	behaviour.SendSuccessfully = true
+	if err := SendQueuedCloudEvents(ctx, pipelineRun, 3); err != nil {
+		t.Fatalf("Unexpected error sending cloud events: %v", err)
+	}
+	tracker.Wait()
+	<-enqueued
+	if err := checkCloudEvents(t, &ceClient, "resend", "Validation: valid"); err != nil {
+		t.Fatal(err)
+	}
+	if !HasPendingCloudEvents(pipelineRun, 3) {
+		t.Error("Expected the delivery to be pending until its outcome is recorded")
+	}
+	if err := SendQueuedCloudEvents(ctx, pipelineRun, 3); err != nil {
+		t.Fatalf("Unexpected error sending cloud events: %v", err)
+	}
+	delivery := pipelineRun.Status.CloudEvents[0]
+	if delivery.Status.Condition != v1beta1.CloudEventConditionSent || delivery.Status.RetryCount != 2 || delivery.Status.Error != "" {
+		t.Errorf("Expected a successful delivery after two attempts, got %v", delivery.Status)
+	}
+	if delivery.Data != "" {
+		t.Errorf("Expected the data of the delivered event to be dropped, got %q", delivery.Data)
+	}
+	if HasPendingCloudEvents(pipelineRun, 3) {
+		t.Error("Expected no pending delivery once the event is delivered")
+	}
+
+	// Delivered events are not sent again
+	if err := SendQueuedCloudEvents(ctx, pipelineRun, 3); err != nil {
+		t.Fatalf("Unexpected error sending cloud events: %v", err)
+	}
+	tracker.Wait()
+	if err := checkCloudEvents(t, &ceClient, "delivered", ""); err != nil {
+		t.Fatal(err)
 	}
 }
 
-func TestSendCloudEventWithRetriesNoClient(t *testing.T) {
+func TestQueuedEvent(t *testing.T) {
+	taskRun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun", Namespace: "foo", SelfLink: "/taskruns/test-taskrun"},
+		Status: v1beta1.TaskRunStatus{Status: duckv1beta1.Status{
+			Conditions: []apis.Condition{{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionUnknown,
+				Reason: v1beta1.TaskRunReasonRunning.String(),
+			}},
+		}},
+	}
+	if err := QueueCloudEvent(taskRun, Target{Sink: "http://sink"}); err != nil {
+		t.Fatalf("Unexpected error queuing cloud event: %v", err)
+	}
+	// The run completes before the event is sent
+	taskRun.Status.Conditions[0].Status = corev1.ConditionTrue
+	taskRun.Status.Conditions[0].Reason = v1beta1.TaskRunReasonSuccessful.String()
+
+	delivery := taskRun.Status.CloudEvents[0]
+	event, err := queuedEvent(taskRun, delivery)
+	if err != nil {
+		t.Fatalf("Unexpected error getting the queued event: %v", err)
+	}
+	if event.ID() != delivery.ID || event.Type() != TaskRunRunningEventV1.String() {
+		t.Errorf("Unexpected cloud event %v", event)
+	}
+	data := TektonCloudEventData{}
+	if err := event.DataAs(&data); err != nil {
+		t.Fatalf("Unexpected error decoding the data of the event: %v", err)
+	}
+	if reason := data.TaskRun.Status.GetCondition(apis.ConditionSucceeded).Reason; reason != v1beta1.TaskRunReasonRunning.String() {
+		t.Errorf("Expected the event to carry the state of the run when it was queued, got reason %q", reason)
+	}
+	if len(data.TaskRun.Status.CloudEvents) != 0 {
+		t.Errorf("Expected the event not to carry the cloud event deliveries, got %v", data.TaskRun.Status.CloudEvents)
+	}
+}
+
+func TestSendQueuedCloudEvents_InFlight(t *testing.T) {
+	ctx := setupFakeContext(t, FakeClientBehaviour{SendSuccessfully: true}, true)
+	tracker := NewDeliveryTracker()
+	ctx = WithDeliveryTracker(ctx, tracker)
+	taskRun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun", Namespace: "foo", SelfLink: "/taskruns/test-taskrun"},
+		Status: v1beta1.TaskRunStatus{Status: duckv1beta1.Status{
+			Conditions: []apis.Condition{{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionTrue,
+			}},
+		}},
+	}
+	if err := QueueCloudEvent(taskRun, Target{Sink: "http://sink"}); err != nil {
+		t.Fatalf("Unexpected error queuing cloud event: %v", err)
+	}
+	// An attempt that did not complete yet is not started again
+	tracker.attempts[taskRun.Status.CloudEvents[0].ID] = &deliveryAttempt{sentAt: time.Now()}
+	if err := SendQueuedCloudEvents(ctx, taskRun, 3); err != nil {
+		t.Fatalf("Unexpected error sending cloud events: %v", err)
+	}
+	tracker.Wait()
+	ceClient := Get(ctx).(FakeClient)
+	if err := checkCloudEvents(t, &ceClient, "in flight", ""); err != nil {
+		t.Fatal(err)
+	}
+	if status := taskRun.Status.CloudEvents[0].Status; status.RetryCount != 0 || status.Condition != v1beta1.CloudEventConditionUnknown {
+		t.Errorf("Expected the delivery to be in flight, got %v", status)
+	}
+}
 
-	ctx := setupFakeContext(t, FakeClientBehaviour{}, false)
-	err := SendCloudEventWithRetries(ctx, &v1beta1.TaskRun{Status: v1beta1.TaskRunStatus{}})
-	if err == nil {
-		t.Fatalf("Expected an error sending cloud events with no client in the context, got none")
+func TestSendQueuedCloudEvents_RetriesExhausted(t *testing.T) {
+	ctx := setupFakeContext(t, FakeClientBehaviour{SendSuccessfully: false}, true)
+	tracker := NewDeliveryTracker()
+	ctx = WithDeliveryTracker(ctx, tracker)
+	taskRun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{SelfLink: "/taskruns/test1"},
+		Status: v1beta1.TaskRunStatus{Status: duckv1beta1.Status{
+			Conditions: []apis.Condition{{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionFalse,
+			}},
+		}},
+	}
+	if err := QueueCloudEvent(taskRun, Target{Sink: "http://sink"}); err != nil {
+		t.Fatalf("Unexpected error queuing cloud event: %v", err)
+	}
+	if err := SendQueuedCloudEvents(ctx, taskRun, 0); err != nil {
+		t.Fatalf("Unexpected error sending cloud events: %v", err)
 	}
-	if d := cmp.Diff("No cloud events client found in the context", err.Error()); d != "" {
-		t.Fatalf("Unexpected error message %s", diff.PrintWantGot(d))
+	tracker.Wait()
+	if err := SendQueuedCloudEvents(ctx, taskRun, 0); err != nil {
+		t.Fatalf("Expected no error once the retries are exhausted, got %v", err)
+	}
+	if got := taskRun.Status.CloudEvents[0].Status.Condition; got != v1beta1.CloudEventConditionFailed {
+		t.Errorf("Expected the delivery to have failed, got %s", got)
+	}
+	if HasPendingCloudEvents(taskRun, 0) {
+		t.Error("Expected no pending delivery once the retries are exhausted")
+	}
+	recorder := controller.GetEventRecorder(ctx).(*record.FakeRecorder)
+	if err := checkEvents(t, recorder, "retries exhausted", "Warning Cloud Event Failure"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCloudEventBackoff(t *testing.T) {
+	for _, tc := range []struct {
+		attempts int32
+		want     time.Duration
+	}{
+		{attempts: 1, want: time.Second},
+		{attempts: 2, want: 2 * time.Second},
+		{attempts: 4, want: 8 * time.Second},
+		{attempts: 20, want: maxCloudEventBackoff},
+	} {
+		if got := cloudEventBackoff(tc.attempts); got != tc.want {
+			t.Errorf("cloudEventBackoff(%d) = %s, want %s", tc.attempts, got, tc.want)
+		}
 	}
 }
 
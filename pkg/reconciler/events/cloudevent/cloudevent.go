@@ -28,7 +28,9 @@ import (
 	"github.com/google/go-cmp/cmp/cmpopts"
 	"knative.dev/pkg/apis"
 
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"k8s.io/apimachinery/pkg/types"
 )
 
 // TektonEventType holds the types of cloud events sent by Tekton
@@ -48,6 +50,9 @@ const (
 	TaskRunSuccessfulEventV1 TektonEventType = "dev.tekton.event.taskrun.successful.v1"
 	// TaskRunFailedEventV1 is sent for TaskRuns with "ConditionSucceeded" "False"
 	TaskRunFailedEventV1 TektonEventType = "dev.tekton.event.taskrun.failed.v1"
+	// TaskRunCancelledEventV1 is sent for TaskRuns with "ConditionSucceeded" "False"
+	// that were cancelled
+	TaskRunCancelledEventV1 TektonEventType = "dev.tekton.event.taskrun.cancelled.v1"
 	// PipelineRunStartedEventV1 is sent for PipelineRuns with "ConditionSucceeded" "Unknown"
 	// the first time they are picked up by the reconciler
 	PipelineRunStartedEventV1 TektonEventType = "dev.tekton.event.pipelinerun.started.v1"
@@ -61,6 +66,33 @@ const (
 	PipelineRunSuccessfulEventV1 TektonEventType = "dev.tekton.event.pipelinerun.successful.v1"
 	// PipelineRunFailedEventV1 is sent for PipelineRuns with "ConditionSucceeded" "False"
 	PipelineRunFailedEventV1 TektonEventType = "dev.tekton.event.pipelinerun.failed.v1"
+	// PipelineRunCancelledEventV1 is sent for PipelineRuns with "ConditionSucceeded" "False"
+	// that were cancelled
+	PipelineRunCancelledEventV1 TektonEventType = "dev.tekton.event.pipelinerun.cancelled.v1"
+	// PipelineRunFinallyStartedEventV1 is sent when the TaskRuns of the finally
+	// tasks of a PipelineRun are first created
+	PipelineRunFinallyStartedEventV1 TektonEventType = "dev.tekton.event.pipelinerun.finallystarted.v1"
+	// PipelineTaskSkippedEventV1 is sent when a PipelineTask of a PipelineRun is
+	// skipped
+	PipelineTaskSkippedEventV1 TektonEventType = "dev.tekton.event.pipelinetask.skipped.v1"
+	// PipelineTaskRetryStartedEventV1 is sent when the TaskRun of a PipelineTask
+	// of a PipelineRun is retried
+	PipelineTaskRetryStartedEventV1 TektonEventType = "dev.tekton.event.pipelinetask.retrystarted.v1"
+)
+
+// Extension attributes set on the Tekton cloud events, so that they can be
+// routed without decoding their payload. CloudEvents attribute names only
+// allow lower-case letters and digits, hence the "devtekton" prefix.
+const (
+	// NamespaceExtension holds the namespace of the run
+	NamespaceExtension = "devtektonnamespace"
+	// PipelineRunExtension holds the name of the PipelineRun, or of the parent
+	// PipelineRun of the TaskRun or of the PipelineTask
+	PipelineRunExtension = "devtektonpipelinerun"
+	// PipelineTaskExtension holds the name of the PipelineTask
+	PipelineTaskExtension = "devtektonpipelinetask"
+	// TaskRunExtension holds the name of the TaskRun
+	TaskRunExtension = "devtektontaskrun"
 )
 
 func (t TektonEventType) String() string {
@@ -71,10 +103,40 @@ func (t TektonEventType) String() string {
 type CEClient cloudevents.Client
 
 // TektonCloudEventData type is used to marshal and unmarshal the payload of
-// a Tekton cloud event. It can include a PipelineRun or a PipelineRun
+// a Tekton cloud event. It can include a TaskRun or a PipelineRun, or the
+// PipelineTask the event is about.
 type TektonCloudEventData struct {
 	TaskRun     *v1beta1.TaskRun     `json:"taskRun,omitempty"`
 	PipelineRun *v1beta1.PipelineRun `json:"pipelineRun,omitempty"`
+	// ParentPipelineRun identifies the PipelineRun the TaskRun or the
+	// PipelineTask belongs to.
+	ParentPipelineRun *PipelineRunReference `json:"parentPipelineRun,omitempty"`
+	// PipelineTask describes the PipelineTask the event is about.
+	PipelineTask *PipelineTaskEventData `json:"pipelineTask,omitempty"`
+	// Results holds the results of the TaskRun or of the PipelineRun, by name.
+	Results map[string]string `json:"results,omitempty"`
+}
+
+// PipelineRunReference identifies a PipelineRun.
+type PipelineRunReference struct {
+	Name      string    `json:"name"`
+	Namespace string    `json:"namespace"`
+	UID       types.UID `json:"uid,omitempty"`
+}
+
+// PipelineTaskEventData describes the PipelineTask of a PipelineRun an event
+// is about.
+type PipelineTaskEventData struct {
+	// Name is the name of the PipelineTask.
+	Name string `json:"name"`
+	// TaskRunName is the name of the TaskRun of the PipelineTask, if any.
+	TaskRunName string `json:"taskRunName,omitempty"`
+	// RetryCount is the number of times the TaskRun of the PipelineTask was
+	// retried.
+	RetryCount int `json:"retryCount,omitempty"`
+	// WhenExpression is the WhenExpression that caused the PipelineTask to be
+	// skipped, if any.
+	WhenExpression *v1beta1.WhenExpression `json:"whenExpression,omitempty"`
 }
 
 // NewTektonCloudEventData returns a new instance of NewTektonCloudEventData
@@ -83,19 +145,43 @@ func NewTektonCloudEventData(runObject objectWithCondition) TektonCloudEventData
 	switch v := runObject.(type) {
 	case *v1beta1.TaskRun:
 		tektonCloudEventData.TaskRun = v
+		tektonCloudEventData.ParentPipelineRun = parentPipelineRun(v)
+		for _, result := range v.Status.TaskRunResults {
+			tektonCloudEventData.addResult(result.Name, result.Value)
+		}
 	case *v1beta1.PipelineRun:
 		tektonCloudEventData.PipelineRun = v
+		for _, result := range v.Status.PipelineResults {
+			tektonCloudEventData.addResult(result.Name, result.Value)
+		}
 	}
 	return tektonCloudEventData
 }
 
+func (d *TektonCloudEventData) addResult(name, value string) {
+	if d.Results == nil {
+		d.Results = map[string]string{}
+	}
+	d.Results[name] = value
+}
+
+// parentPipelineRun returns the PipelineRun owning the TaskRun, or nil for
+// a standalone TaskRun.
+func parentPipelineRun(tr *v1beta1.TaskRun) *PipelineRunReference {
+	for _, ref := range tr.OwnerReferences {
+		if ref.Kind == pipeline.PipelineRunControllerName {
+			return &PipelineRunReference{Name: ref.Name, Namespace: tr.Namespace, UID: ref.UID}
+		}
+	}
+	if name, ok := tr.Labels[pipeline.GroupName+pipeline.PipelineRunLabelKey]; ok {
+		return &PipelineRunReference{Name: name, Namespace: tr.Namespace}
+	}
+	return nil
+}
+
 // EventForObjectWithCondition creates a new event based for a objectWithCondition,
 // or return an error if not possible.
 func EventForObjectWithCondition(runObject objectWithCondition) (*cloudevents.Event, error) {
-	event := cloudevents.NewEvent()
-	event.SetID(uuid.New().String())
-	event.SetSubject(runObject.GetObjectMeta().GetName())
-	event.SetSource(runObject.GetObjectMeta().GetSelfLink()) // TODO: SelfLink is deprecated https://github.com/tektoncd/pipeline/issues/2676
 	eventType, err := getEventType(runObject)
 	if err != nil {
 		return nil, err
@@ -103,14 +189,68 @@ func EventForObjectWithCondition(runObject objectWithCondition) (*cloudevents.Ev
 	if eventType == nil {
 		return nil, errors.New("No matching event type found")
 	}
-	event.SetType(eventType.String())
+	return eventForRun(runObject, *eventType)
+}
+
+// eventForRun creates a new event of the given type with the runObject as
+// payload.
+func eventForRun(runObject objectWithCondition, eventType TektonEventType) (*cloudevents.Event, error) {
+	meta := runObject.GetObjectMeta()
+	event := newEvent(eventType, meta.GetName(), meta.GetSelfLink()) // TODO: SelfLink is deprecated https://github.com/tektoncd/pipeline/issues/2676
+	event.SetExtension(NamespaceExtension, meta.GetNamespace())
+	data := NewTektonCloudEventData(runObject)
+	switch runObject.(type) {
+	case *v1beta1.TaskRun:
+		event.SetExtension(TaskRunExtension, meta.GetName())
+		if data.ParentPipelineRun != nil {
+			event.SetExtension(PipelineRunExtension, data.ParentPipelineRun.Name)
+		}
+		if pipelineTask, ok := meta.GetLabels()[pipeline.GroupName+pipeline.PipelineTaskLabelKey]; ok {
+			event.SetExtension(PipelineTaskExtension, pipelineTask)
+		}
+	case *v1beta1.PipelineRun:
+		event.SetExtension(PipelineRunExtension, meta.GetName())
+	}
 
-	if err := event.SetData(cloudevents.ApplicationJSON, NewTektonCloudEventData(runObject)); err != nil {
+	if err := event.SetData(cloudevents.ApplicationJSON, data); err != nil {
 		return nil, err
 	}
 	return &event, nil
 }
 
+// EventForPipelineTask creates a new event of the given type about a
+// PipelineTask of a PipelineRun, or return an error if not possible.
+func EventForPipelineTask(eventType TektonEventType, pipelineRun *v1beta1.PipelineRun, pipelineTask PipelineTaskEventData) (*cloudevents.Event, error) {
+	if pipelineRun == nil {
+		return nil, errors.New("Cannot send an event for a PipelineTask of an empty PipelineRun")
+	}
+	event := newEvent(eventType, pipelineRun.Namespace+"/"+pipelineRun.Name+"/"+pipelineTask.Name, pipelineRun.SelfLink)
+	event.SetExtension(NamespaceExtension, pipelineRun.Namespace)
+	event.SetExtension(PipelineRunExtension, pipelineRun.Name)
+	event.SetExtension(PipelineTaskExtension, pipelineTask.Name)
+	if pipelineTask.TaskRunName != "" {
+		event.SetExtension(TaskRunExtension, pipelineTask.TaskRunName)
+	}
+
+	data := TektonCloudEventData{
+		ParentPipelineRun: &PipelineRunReference{Name: pipelineRun.Name, Namespace: pipelineRun.Namespace, UID: pipelineRun.UID},
+		PipelineTask:      &pipelineTask,
+	}
+	if err := event.SetData(cloudevents.ApplicationJSON, data); err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func newEvent(eventType TektonEventType, subject, source string) cloudevents.Event {
+	event := cloudevents.NewEvent()
+	event.SetID(uuid.New().String())
+	event.SetType(eventType.String())
+	event.SetSubject(subject)
+	event.SetSource(source)
+	return event
+}
+
 // EventForTaskRun will create a new event based on a TaskRun,
 // or return an error if not possible.
 func EventForTaskRun(taskRun *v1beta1.TaskRun) (*cloudevents.Event, error) {
@@ -131,6 +271,16 @@ func EventForPipelineRun(pipelineRun *v1beta1.PipelineRun) (*cloudevents.Event,
 	return EventForObjectWithCondition(pipelineRun)
 }
 
+// EventForPipelineRunOfType will create a new event of the given type based
+// on a PipelineRun, regardless of its condition, or return an error if not
+// possible.
+func EventForPipelineRunOfType(eventType TektonEventType, pipelineRun *v1beta1.PipelineRun) (*cloudevents.Event, error) {
+	if pipelineRun == nil {
+		return nil, errors.New("Cannot send an event for an empty PipelineRun")
+	}
+	return eventForRun(pipelineRun, eventType)
+}
+
 func getEventType(runObject objectWithCondition) (*TektonEventType, error) {
 	c := runObject.GetStatusCondition().GetCondition(apis.ConditionSucceeded)
 	if c == nil {
@@ -160,11 +310,19 @@ func getEventType(runObject objectWithCondition) (*TektonEventType, error) {
 			}
 		}
 	case c.IsFalse():
-		switch runObject.(type) {
+		switch v := runObject.(type) {
 		case *v1beta1.TaskRun:
-			eventType = TaskRunFailedEventV1
+			if v.IsCancelled() || c.Reason == v1beta1.TaskRunReasonCancelled.String() {
+				eventType = TaskRunCancelledEventV1
+			} else {
+				eventType = TaskRunFailedEventV1
+			}
 		case *v1beta1.PipelineRun:
-			eventType = PipelineRunFailedEventV1
+			if v.IsCancelled() || c.Reason == v1beta1.PipelineRunReasonCancelled.String() {
+				eventType = PipelineRunCancelledEventV1
+			} else {
+				eventType = PipelineRunFailedEventV1
+			}
 		}
 	case c.IsTrue():
 		switch runObject.(type) {
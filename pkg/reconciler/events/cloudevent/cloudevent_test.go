@@ -25,6 +25,7 @@ import (
 	"github.com/tektoncd/pipeline/test/names"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/selection"
 	"knative.dev/pkg/apis"
 	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 )
@@ -104,6 +105,10 @@ func TestEventForTaskRun(t *testing.T) {
 		desc:          "send a cloud event with failed status taskrun",
 		taskRun:       getTaskRunByCondition(corev1.ConditionFalse, "meh"),
 		wantEventType: TaskRunFailedEventV1,
+	}, {
+		desc:          "send a cloud event with cancelled taskrun",
+		taskRun:       getTaskRunByCondition(corev1.ConditionFalse, v1beta1.TaskRunReasonCancelled.String()),
+		wantEventType: TaskRunCancelledEventV1,
 	}, {
 		desc:          "send a cloud event with successful status taskrun",
 		taskRun:       getTaskRunByCondition(corev1.ConditionTrue, "yay"),
@@ -165,6 +170,10 @@ func TestEventForPipelineRun(t *testing.T) {
 		desc:          "send a cloud event with unknown status pipelinerun",
 		pipelineRun:   getPipelineRunByCondition(corev1.ConditionFalse, "meh"),
 		wantEventType: PipelineRunFailedEventV1,
+	}, {
+		desc:          "send a cloud event with cancelled pipelinerun",
+		pipelineRun:   getPipelineRunByCondition(corev1.ConditionFalse, v1beta1.PipelineRunReasonCancelled.String()),
+		wantEventType: PipelineRunCancelledEventV1,
 	}} {
 		t.Run(c.desc, func(t *testing.T) {
 			names.TestingSeed()
@@ -196,3 +205,120 @@ func TestEventForPipelineRun(t *testing.T) {
 		})
 	}
 }
+
+func TestEventForTaskRun_PipelineTask(t *testing.T) {
+	taskRun := getTaskRunByCondition(corev1.ConditionTrue, "yay")
+	taskRun.OwnerReferences = []metav1.OwnerReference{{
+		Kind: "PipelineRun",
+		Name: pipelineRunName,
+		UID:  "1234",
+	}}
+	taskRun.Labels = map[string]string{
+		"tekton.dev/pipelineRun":  pipelineRunName,
+		"tekton.dev/pipelineTask": "build",
+	}
+	taskRun.Status.TaskRunResults = []v1beta1.TaskRunResult{{Name: "digest", Value: "sha256:abcd"}}
+
+	got, err := EventForTaskRun(taskRun)
+	if err != nil {
+		t.Fatalf("I did not expect an error but I got %s", err)
+	}
+	wantExtensions := map[string]interface{}{
+		NamespaceExtension:    "marshmallow",
+		PipelineRunExtension:  pipelineRunName,
+		PipelineTaskExtension: "build",
+		TaskRunExtension:      taskRunName,
+	}
+	if d := cmp.Diff(wantExtensions, got.Extensions()); d != "" {
+		t.Errorf("Wrong Event extensions %s", diff.PrintWantGot(d))
+	}
+	gotData := TektonCloudEventData{}
+	if err := got.DataAs(&gotData); err != nil {
+		t.Errorf("Unexpected error from DataAsl; %s", err)
+	}
+	wantParent := &PipelineRunReference{Name: pipelineRunName, Namespace: "marshmallow", UID: "1234"}
+	if d := cmp.Diff(wantParent, gotData.ParentPipelineRun); d != "" {
+		t.Errorf("Wrong parent PipelineRun %s", diff.PrintWantGot(d))
+	}
+	if d := cmp.Diff(map[string]string{"digest": "sha256:abcd"}, gotData.Results); d != "" {
+		t.Errorf("Wrong results %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestEventForPipelineRunOfType(t *testing.T) {
+	pipelineRun := getPipelineRunByCondition(corev1.ConditionUnknown, v1beta1.PipelineRunReasonRunning.String())
+	got, err := EventForPipelineRunOfType(PipelineRunFinallyStartedEventV1, pipelineRun)
+	if err != nil {
+		t.Fatalf("I did not expect an error but I got %s", err)
+	}
+	if d := cmp.Diff(string(PipelineRunFinallyStartedEventV1), got.Type()); d != "" {
+		t.Errorf("Wrong Event Type %s", diff.PrintWantGot(d))
+	}
+	if d := cmp.Diff(pipelineRunName, got.Subject()); d != "" {
+		t.Errorf("Wrong Event subject %s", diff.PrintWantGot(d))
+	}
+	if err := got.Validate(); err != nil {
+		t.Errorf("Expected event to be valid; %s", err)
+	}
+}
+
+func TestEventForPipelineTask(t *testing.T) {
+	pipelineRun := getPipelineRunByCondition(corev1.ConditionUnknown, v1beta1.PipelineRunReasonRunning.String())
+	pipelineRun.UID = "1234"
+	whenExpression := &v1beta1.WhenExpression{Input: "foo", Operator: selection.In, Values: []string{"bar"}}
+	for _, c := range []struct {
+		desc           string
+		eventType      TektonEventType
+		pipelineTask   PipelineTaskEventData
+		wantExtensions map[string]interface{}
+	}{{
+		desc:         "skipped pipeline task",
+		eventType:    PipelineTaskSkippedEventV1,
+		pipelineTask: PipelineTaskEventData{Name: "deploy", WhenExpression: whenExpression},
+		wantExtensions: map[string]interface{}{
+			NamespaceExtension:    "marshmallow",
+			PipelineRunExtension:  pipelineRunName,
+			PipelineTaskExtension: "deploy",
+		},
+	}, {
+		desc:         "retried pipeline task",
+		eventType:    PipelineTaskRetryStartedEventV1,
+		pipelineTask: PipelineTaskEventData{Name: "deploy", TaskRunName: taskRunName, RetryCount: 2},
+		wantExtensions: map[string]interface{}{
+			NamespaceExtension:    "marshmallow",
+			PipelineRunExtension:  pipelineRunName,
+			PipelineTaskExtension: "deploy",
+			TaskRunExtension:      taskRunName,
+		},
+	}} {
+		t.Run(c.desc, func(t *testing.T) {
+			got, err := EventForPipelineTask(c.eventType, pipelineRun, c.pipelineTask)
+			if err != nil {
+				t.Fatalf("I did not expect an error but I got %s", err)
+			}
+			if d := cmp.Diff("marshmallow/"+pipelineRunName+"/deploy", got.Subject()); d != "" {
+				t.Errorf("Wrong Event subject %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff(string(c.eventType), got.Type()); d != "" {
+				t.Errorf("Wrong Event Type %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff(c.wantExtensions, got.Extensions()); d != "" {
+				t.Errorf("Wrong Event extensions %s", diff.PrintWantGot(d))
+			}
+			wantData := TektonCloudEventData{
+				ParentPipelineRun: &PipelineRunReference{Name: pipelineRunName, Namespace: "marshmallow", UID: "1234"},
+				PipelineTask:      &c.pipelineTask,
+			}
+			gotData := TektonCloudEventData{}
+			if err := got.DataAs(&gotData); err != nil {
+				t.Errorf("Unexpected error from DataAsl; %s", err)
+			}
+			if d := cmp.Diff(wantData, gotData); d != "" {
+				t.Errorf("Wrong Event data %s", diff.PrintWantGot(d))
+			}
+			if err := got.Validate(); err != nil {
+				t.Errorf("Expected event to be valid; %s", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudevent
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// staleDeliveryAttempt is how long the outcome of an attempt to deliver a
+// cloud event is kept when it is not recorded by a reconcile of its run, e.g.
+// because the run was deleted in the meantime.
+const staleDeliveryAttempt = time.Hour
+
+// DeliveryTracker delivers in the background the cloud events queued in the
+// status of the runs, so that a sink that is down does not block the
+// reconcilers. It keeps the outcome of each attempt until the next reconcile
+// of the run records it in its status.
+type DeliveryTracker struct {
+	mu       sync.Mutex
+	attempts map[string]*deliveryAttempt
+	// enqueueAfter reconciles a run again after the given delay
+	enqueueAfter func(types.NamespacedName, time.Duration)
+	inFlight     sync.WaitGroup
+}
+
+type deliveryAttempt struct {
+	done   bool
+	result error
+	sentAt time.Time
+}
+
+// NewDeliveryTracker returns a DeliveryTracker with no attempt in flight.
+func NewDeliveryTracker() *DeliveryTracker {
+	return &DeliveryTracker{
+		attempts: map[string]*deliveryAttempt{},
+	}
+}
+
+// SetCallbackFunc sets the function called to reconcile a run again, once an
+// attempt to deliver one of its cloud events completed or when the next
+// attempt is due.
+func (t *DeliveryTracker) SetCallbackFunc(f func(types.NamespacedName, time.Duration)) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.enqueueAfter = f
+}
+
+// Wait blocks until the attempts in flight complete.
+func (t *DeliveryTracker) Wait() {
+	t.inFlight.Wait()
+}
+
+type deliveryTrackerKey struct{}
+
+// defaultDeliveryTracker is used when there is no DeliveryTracker in the
+// context. The runs are not reconciled again when its attempts complete.
+var defaultDeliveryTracker = NewDeliveryTracker()
+
+// WithDeliveryTracker adds the DeliveryTracker to the context.
+func WithDeliveryTracker(ctx context.Context, t *DeliveryTracker) context.Context {
+	return context.WithValue(ctx, deliveryTrackerKey{}, t)
+}
+
+// DeliveryTrackerFromContext returns the DeliveryTracker added to the
+// context by WithDeliveryTracker, or a new one when there is none.
+func DeliveryTrackerFromContext(ctx context.Context) *DeliveryTracker {
+	if t, ok := ctx.Value(deliveryTrackerKey{}).(*DeliveryTracker); ok && t != nil {
+		return t
+	}
+	return NewDeliveryTracker()
+}
+
+func getDeliveryTracker(ctx context.Context) *DeliveryTracker {
+	if t, ok := ctx.Value(deliveryTrackerKey{}).(*DeliveryTracker); ok && t != nil {
+		return t
+	}
+	return defaultDeliveryTracker
+}
+
+// send delivers the event to target in the background. The run identified
+// by key is reconciled again once the attempt completed.
+func (t *DeliveryTracker) send(ceClient CEClient, target string, event cloudevents.Event, key types.NamespacedName) {
+	t.mu.Lock()
+	t.pruneStaleAttempts()
+	attempt := &deliveryAttempt{sentAt: time.Now()}
+	t.attempts[event.ID()] = attempt
+	t.mu.Unlock()
+
+	t.inFlight.Add(1)
+	go func() {
+		defer t.inFlight.Done()
+		ctx, cancel := context.WithTimeout(cloudevents.ContextWithTarget(context.Background(), target), cloudEventSendTimeout)
+		result := ceClient.Send(ctx, event)
+		cancel()
+
+		t.mu.Lock()
+		attempt.done = true
+		if !cloudevents.IsACK(result) {
+			attempt.result = result
+		}
+		enqueueAfter := t.enqueueAfter
+		t.mu.Unlock()
+		if enqueueAfter != nil {
+			enqueueAfter(key, 0)
+		}
+	}()
+}
+
+// outcome returns the attempt to deliver the cloud event with the given ID,
+// and false if there is none. The attempts that completed are forgotten once
+// their outcome is returned.
+func (t *DeliveryTracker) outcome(id string) (deliveryAttempt, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	attempt, ok := t.attempts[id]
+	if !ok {
+		return deliveryAttempt{}, false
+	}
+	if attempt.done {
+		delete(t.attempts, id)
+	}
+	return *attempt, true
+}
+
+// retryAfter reconciles the run identified by key again after delay, for the
+// next attempt to deliver one of its cloud events.
+func (t *DeliveryTracker) retryAfter(key types.NamespacedName, delay time.Duration) {
+	t.mu.Lock()
+	enqueueAfter := t.enqueueAfter
+	t.mu.Unlock()
+	if enqueueAfter != nil {
+		enqueueAfter(key, delay)
+	}
+}
+
+func (t *DeliveryTracker) pruneStaleAttempts() {
+	for id, attempt := range t.attempts {
+		if attempt.done && time.Since(attempt.sentAt) > staleDeliveryAttempt {
+			delete(t.attempts, id)
+		}
+	}
+}
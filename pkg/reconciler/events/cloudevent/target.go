@@ -0,0 +1,132 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudevent
+
+import (
+	"context"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// SinkAnnotation can be set on a TaskRun, a PipelineRun or a Namespace
+	// to send the cloud events about the runs to another sink than the
+	// default one. An empty value disables the cloud events.
+	SinkAnnotation = pipeline.GroupName + "/cloud-events-sink"
+	// EventTypesAnnotation can be set on a TaskRun, a PipelineRun or a
+	// Namespace to only send the cloud events of the given comma separated
+	// types. All the types are sent when it is not set or empty.
+	EventTypesAnnotation = pipeline.GroupName + "/cloud-events-types"
+)
+
+// Target describes where the cloud events about a run are sent.
+type Target struct {
+	// Sink is the URL the events are sent to, no event is sent when empty.
+	Sink string
+	// EventTypes are the types of the events sent, all of them when empty.
+	EventTypes sets.String
+}
+
+// Accepts returns true if the events of the given type are sent to the
+// target.
+func (t Target) Accepts(eventType TektonEventType) bool {
+	if t.Sink == "" {
+		return false
+	}
+	return t.EventTypes.Len() == 0 || t.EventTypes.Has(eventType.String())
+}
+
+type namespaceListerKey struct{}
+
+// WithNamespaceLister adds to the context the lister used by TargetFor to
+// read the cloud events configuration of the Namespaces of the runs.
+func WithNamespaceLister(ctx context.Context, namespaces corev1listers.NamespaceLister) context.Context {
+	return context.WithValue(ctx, namespaceListerKey{}, namespaces)
+}
+
+func namespaceAnnotations(namespaces corev1listers.NamespaceLister, name string) (map[string]string, error) {
+	ns, err := namespaces.Get(name)
+	if k8serrors.IsNotFound(err) {
+		// A Namespace that cannot be found has no configuration
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ns.Annotations, nil
+}
+
+// TargetFor returns where the cloud events about the given run are sent. The
+// annotations of the run take precedence over the ones of its Namespace,
+// which take precedence over the default sink. The Namespace is read when the
+// context holds a NamespaceLister. A run can only send its events to one of
+// the allowedSinks, or disable them with an empty sink: any other sink set on
+// the run is ignored.
+func TargetFor(ctx context.Context, run metav1.Object, defaultSink string, allowedSinks []string) (Target, error) {
+	sink, hasSink := run.GetAnnotations()[SinkAnnotation]
+	eventTypes, hasEventTypes := run.GetAnnotations()[EventTypesAnnotation]
+	if hasSink && !isAllowedSink(sink, allowedSinks) {
+		logging.FromContext(ctx).Warnf("Ignoring the cloud events sink %q of %s/%s: it is not one of the allowed sinks", sink, run.GetNamespace(), run.GetName())
+		hasSink = false
+	}
+	if namespaces, ok := ctx.Value(namespaceListerKey{}).(corev1listers.NamespaceLister); ok && (!hasSink || !hasEventTypes) {
+		annotations, err := namespaceAnnotations(namespaces, run.GetNamespace())
+		if err != nil {
+			return Target{}, err
+		}
+		if !hasSink {
+			sink, hasSink = annotations[SinkAnnotation]
+		}
+		if !hasEventTypes {
+			eventTypes = annotations[EventTypesAnnotation]
+		}
+	}
+	if !hasSink {
+		sink = defaultSink
+	}
+	return Target{Sink: strings.TrimSpace(sink), EventTypes: parseEventTypes(eventTypes)}, nil
+}
+
+// isAllowedSink returns true if a run can send its cloud events to sink.
+func isAllowedSink(sink string, allowedSinks []string) bool {
+	sink = strings.TrimSpace(sink)
+	if sink == "" {
+		return true
+	}
+	for _, allowed := range allowedSinks {
+		if sink == allowed {
+			return true
+		}
+	}
+	return false
+}
+
+func parseEventTypes(value string) sets.String {
+	eventTypes := sets.NewString()
+	for _, eventType := range strings.Split(value, ",") {
+		if eventType = strings.TrimSpace(eventType); eventType != "" {
+			eventTypes.Insert(eventType)
+		}
+	}
+	return eventTypes
+}
@@ -0,0 +1,177 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudevent
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+	corev1listers "k8s.io/client-go/listers/core/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestTargetFor(t *testing.T) {
+	namespaces := []*corev1.Namespace{{
+		ObjectMeta: metav1.ObjectMeta{Name: "plain"},
+	}, {
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "team",
+			Annotations: map[string]string{
+				SinkAnnotation:       "http://team-sink",
+				EventTypesAnnotation: "dev.tekton.event.taskrun.failed.v1, dev.tekton.event.pipelinerun.failed.v1",
+			},
+		},
+	}}
+	for _, tc := range []struct {
+		name         string
+		namespace    string
+		annotations  map[string]string
+		defaultSink  string
+		allowedSinks []string
+		want         Target
+	}{{
+		name:        "default sink",
+		namespace:   "plain",
+		defaultSink: "http://default-sink",
+		want:        Target{Sink: "http://default-sink", EventTypes: sets.NewString()},
+	}, {
+		name:        "namespace not found",
+		namespace:   "missing",
+		defaultSink: "http://default-sink",
+		want:        Target{Sink: "http://default-sink", EventTypes: sets.NewString()},
+	}, {
+		name:        "namespace sink and event types",
+		namespace:   "team",
+		defaultSink: "http://default-sink",
+		want: Target{
+			Sink:       "http://team-sink",
+			EventTypes: sets.NewString("dev.tekton.event.taskrun.failed.v1", "dev.tekton.event.pipelinerun.failed.v1"),
+		},
+	}, {
+		name:      "namespace sink without default sink",
+		namespace: "team",
+		want: Target{
+			Sink:       "http://team-sink",
+			EventTypes: sets.NewString("dev.tekton.event.taskrun.failed.v1", "dev.tekton.event.pipelinerun.failed.v1"),
+		},
+	}, {
+		name:      "no sink",
+		namespace: "plain",
+		want:      Target{Sink: "", EventTypes: sets.NewString()},
+	}, {
+		name:         "run sink",
+		namespace:    "team",
+		annotations:  map[string]string{SinkAnnotation: "http://run-sink"},
+		allowedSinks: []string{"http://other-sink", "http://run-sink"},
+		want: Target{
+			Sink:       "http://run-sink",
+			EventTypes: sets.NewString("dev.tekton.event.taskrun.failed.v1", "dev.tekton.event.pipelinerun.failed.v1"),
+		},
+	}, {
+		name:        "run sink not allowed",
+		namespace:   "plain",
+		annotations: map[string]string{SinkAnnotation: "http://kubernetes.default.svc"},
+		defaultSink: "http://default-sink",
+		want:        Target{Sink: "http://default-sink", EventTypes: sets.NewString()},
+	}, {
+		name:        "run event types",
+		namespace:   "team",
+		annotations: map[string]string{EventTypesAnnotation: ""},
+		defaultSink: "http://default-sink",
+		want:        Target{Sink: "http://team-sink", EventTypes: sets.NewString()},
+	}, {
+		name:        "run disables cloud events",
+		namespace:   "plain",
+		annotations: map[string]string{SinkAnnotation: ""},
+		defaultSink: "http://default-sink",
+		want:        Target{Sink: "", EventTypes: sets.NewString()},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := WithNamespaceLister(context.Background(), namespaceLister(t, namespaces...))
+			run := &metav1.ObjectMeta{Name: "run", Namespace: tc.namespace, Annotations: tc.annotations}
+			got, err := TargetFor(ctx, run, tc.defaultSink, tc.allowedSinks)
+			if err != nil {
+				t.Fatalf("Unexpected error getting the target: %v", err)
+			}
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("Wrong target %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestTargetFor_WithoutNamespaceLister(t *testing.T) {
+	run := &metav1.ObjectMeta{Name: "run", Namespace: "foo"}
+	got, err := TargetFor(context.Background(), run, "http://default-sink", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error getting the target: %v", err)
+	}
+	if got.Sink != "http://default-sink" {
+		t.Errorf("Expected the default sink, got %q", got.Sink)
+	}
+}
+
+func TestTargetAccepts(t *testing.T) {
+	for _, tc := range []struct {
+		name      string
+		target    Target
+		eventType TektonEventType
+		want      bool
+	}{{
+		name:      "no sink",
+		target:    Target{},
+		eventType: TaskRunFailedEventV1,
+		want:      false,
+	}, {
+		name:      "all event types",
+		target:    Target{Sink: "http://sink"},
+		eventType: TaskRunFailedEventV1,
+		want:      true,
+	}, {
+		name:      "event type sent",
+		target:    Target{Sink: "http://sink", EventTypes: sets.NewString(TaskRunFailedEventV1.String())},
+		eventType: TaskRunFailedEventV1,
+		want:      true,
+	}, {
+		name:      "event type filtered out",
+		target:    Target{Sink: "http://sink", EventTypes: sets.NewString(TaskRunFailedEventV1.String())},
+		eventType: TaskRunStartedEventV1,
+		want:      false,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.target.Accepts(tc.eventType); got != tc.want {
+				t.Errorf("Accepts(%s) = %t, want %t", tc.eventType, got, tc.want)
+			}
+		})
+	}
+}
+
+func namespaceLister(t *testing.T, namespaces ...*corev1.Namespace) corev1listers.NamespaceLister {
+	t.Helper()
+	indexer := cache.NewIndexer(cache.MetaNamespaceKeyFunc, cache.Indexers{})
+	for _, ns := range namespaces {
+		if err := indexer.Add(ns); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return corev1listers.NewNamespaceLister(indexer)
+}
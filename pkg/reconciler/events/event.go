@@ -18,11 +18,12 @@ package events
 import (
 	"context"
 
-	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/reconciler/events/cloudevent"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/tools/record"
 	"knative.dev/pkg/apis"
@@ -45,27 +46,81 @@ const (
 // Two types of events are supported, k8s and cloud events.
 //
 // k8s events are always sent if afterCondition is different from beforeCondition
-// Cloud events are always sent if enabled, i.e. if a sink is available
+// Cloud events are always sent if enabled, i.e. if a sink is available for
+// object, and if their type is not filtered out. Their delivery is recorded in
+// the status of object, and the ones that could not be delivered are sent
+// again by SendQueuedCloudEvents.
 func Emit(ctx context.Context, beforeCondition *apis.Condition, afterCondition *apis.Condition, object runtime.Object) {
 	recorder := controller.GetEventRecorder(ctx)
 	logger := logging.FromContext(ctx)
 	configs := config.FromContextOrDefaults(ctx)
-	sendCloudEvents := (configs.Defaults.DefaultCloudEventsSink != "")
-	if sendCloudEvents {
-		ctx = cloudevents.ContextWithTarget(ctx, configs.Defaults.DefaultCloudEventsSink)
-	}
 
 	sendKubernetesEvents(recorder, beforeCondition, afterCondition, object)
 
-	if sendCloudEvents {
-		// Only send events if the new condition represents a change
-		if !equality.Semantic.DeepEqual(beforeCondition, afterCondition) {
-			err := cloudevent.SendCloudEventWithRetries(ctx, object)
-			if err != nil {
-				logger.Warnf("Failed to emit cloud events %v", err.Error())
-			}
-		}
+	// Only send events if the new condition represents a change
+	if equality.Semantic.DeepEqual(beforeCondition, afterCondition) {
+		return
+	}
+	target, ok := cloudEventsTarget(ctx, object)
+	if !ok {
+		return
+	}
+	if err := cloudevent.QueueCloudEvent(object, target); err != nil {
+		logger.Warnf("Failed to emit cloud events %v", err.Error())
+		return
+	}
+	if err := cloudevent.SendQueuedCloudEvents(ctx, object, configs.Defaults.DefaultCloudEventsMaxRetries); err != nil {
+		logger.Warnf("Failed to send cloud events %v", err.Error())
+	}
+}
+
+// SendQueuedCloudEvents records the outcome of the deliveries of the cloud
+// events about object that completed, and sends the ones that are not
+// delivered yet, within the retry budget of the configuration. The deliveries
+// run in the background and object is reconciled again once they complete.
+func SendQueuedCloudEvents(ctx context.Context, object runtime.Object) error {
+	configs := config.FromContextOrDefaults(ctx)
+	return cloudevent.SendQueuedCloudEvents(ctx, object, configs.Defaults.DefaultCloudEventsMaxRetries)
+}
+
+// HasPendingCloudEvents returns true while some cloud events about object
+// are not delivered yet, within the retry budget of the configuration.
+func HasPendingCloudEvents(ctx context.Context, object runtime.Object) bool {
+	configs := config.FromContextOrDefaults(ctx)
+	return cloudevent.HasPendingCloudEvents(object, configs.Defaults.DefaultCloudEventsMaxRetries)
+}
+
+// EmitCloudEvent records the delivery of a cloud event of the given type
+// about object, when cloud events are enabled for object and of that type.
+// Unlike Emit, it is used for the events that are not about a change of the
+// condition of object. pipelineTask identifies the PipelineTask of the
+// PipelineRun the event is about, if any. The event is sent by
+// SendQueuedCloudEvents.
+func EmitCloudEvent(ctx context.Context, eventType cloudevent.TektonEventType, object runtime.Object, pipelineTask *v1beta1.CloudEventPipelineTask) {
+	target, ok := cloudEventsTarget(ctx, object)
+	if !ok {
+		return
+	}
+	if err := cloudevent.QueueCloudEventOfType(object, target, eventType, pipelineTask); err != nil {
+		logging.FromContext(ctx).Warnf("Failed to emit cloud events %v", err.Error())
+	}
+}
+
+// cloudEventsTarget returns where the cloud events about object are sent, and
+// false if they are not sent at all.
+func cloudEventsTarget(ctx context.Context, object runtime.Object) (cloudevent.Target, bool) {
+	configs := config.FromContextOrDefaults(ctx)
+	meta, ok := object.(metav1.Object)
+	if !ok {
+		target := cloudevent.Target{Sink: configs.Defaults.DefaultCloudEventsSink}
+		return target, target.Sink != ""
+	}
+	target, err := cloudevent.TargetFor(ctx, meta, configs.Defaults.DefaultCloudEventsSink, configs.Defaults.DefaultCloudEventsAllowedSinks)
+	if err != nil {
+		logging.FromContext(ctx).Warnf("Failed to get the cloud events sink of %s/%s: %v", meta.GetNamespace(), meta.GetName(), err)
+		return target, false
 	}
+	return target, target.Sink != ""
 }
 
 func sendKubernetesEvents(c record.EventRecorder, beforeCondition *apis.Condition, afterCondition *apis.Condition, object runtime.Object) {
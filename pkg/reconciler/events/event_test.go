@@ -245,6 +245,70 @@ func TestEmit(t *testing.T) {
 	}
 }
 
+func TestEmitCloudEvent(t *testing.T) {
+	testcases := []struct {
+		name           string
+		data           map[string]string
+		annotations    map[string]string
+		wantCloudEvent string
+	}{{
+		name:           "without sink",
+		data:           map[string]string{},
+		wantCloudEvent: "",
+	}, {
+		name:           "with sink",
+		data:           map[string]string{"default-cloud-events-sink": "http://mysink"},
+		wantCloudEvent: `(?s)dev.tekton.event.pipelinerun.finallystarted.v1.*test1`,
+	}, {
+		name:           "with allowed run sink",
+		data:           map[string]string{"default-cloud-events-allowed-sinks": "http://runsink"},
+		annotations:    map[string]string{cloudevent.SinkAnnotation: "http://runsink"},
+		wantCloudEvent: `(?s)dev.tekton.event.pipelinerun.finallystarted.v1.*test1`,
+	}, {
+		name:           "with run sink not allowed",
+		data:           map[string]string{},
+		annotations:    map[string]string{cloudevent.SinkAnnotation: "http://runsink"},
+		wantCloudEvent: "",
+	}, {
+		name:           "with event type filtered out",
+		data:           map[string]string{"default-cloud-events-sink": "http://mysink"},
+		annotations:    map[string]string{cloudevent.EventTypesAnnotation: "dev.tekton.event.pipelinerun.failed.v1"},
+		wantCloudEvent: "",
+	}}
+
+	for _, tc := range testcases {
+		object := &v1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test1",
+				Namespace:   "foo",
+				Annotations: tc.annotations,
+			},
+		}
+		ctx, _ := rtesting.SetupFakeContext(t)
+		ctx = cloudevent.WithClient(ctx, &cloudevent.FakeClientBehaviour{SendSuccessfully: true})
+		fakeClient := cloudevent.Get(ctx).(cloudevent.FakeClient)
+
+		defaults, _ := config.NewDefaultsFromMap(tc.data)
+		featureFlags, _ := config.NewFeatureFlagsFromMap(map[string]string{})
+		ctx = config.ToContext(ctx, &config.Config{
+			Defaults:     defaults,
+			FeatureFlags: featureFlags,
+		})
+
+		EmitCloudEvent(ctx, cloudevent.PipelineRunFinallyStartedEventV1, object, nil)
+		// The event is queued in the status of the run, and sent by SendQueuedCloudEvents
+		if queued := len(object.Status.CloudEvents) == 1; queued != (tc.wantCloudEvent != "") {
+			t.Fatalf("Unexpected cloud event deliveries %v", object.Status.CloudEvents)
+		}
+		if err := SendQueuedCloudEvents(ctx, object); err != nil {
+			t.Fatalf("Unexpected error sending cloud events: %v", err)
+		}
+		if err := checkCloudEvents(t, &fakeClient, tc.name, tc.wantCloudEvent); err != nil {
+			t.Fatalf(err.Error())
+		}
+	}
+}
+
 func eventFromChannel(c chan string, testName string, wantEvent string) error {
 	timer := time.NewTimer(1 * time.Second)
 	select {
@@ -54,7 +54,11 @@ func cancelPipelineRun(ctx context.Context, logger *zap.SugaredLogger, pr *v1bet
 
 	// Loop over the TaskRuns in the PipelineRun status.
 	// If a TaskRun is not in the status yet we should not cancel it anyways.
-	for taskRunName := range pr.Status.TaskRuns {
+	for taskRunName, prtrs := range pr.Status.TaskRuns {
+		// Completed TaskRuns, such as the ones reused from a previous PipelineRun, have nothing to cancel.
+		if prtrs.Status != nil && !prtrs.Status.GetCondition(apis.ConditionSucceeded).IsUnknown() {
+			continue
+		}
 		logger.Infof("cancelling TaskRun %s", taskRunName)
 
 		if _, err := clientSet.TektonV1beta1().TaskRuns(pr.Namespace).Patch(ctx, taskRunName, types.JSONPatchType, cancelPatchBytes, metav1.PatchOptions{}, ""); err != nil {
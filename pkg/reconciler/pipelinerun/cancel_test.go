@@ -23,8 +23,10 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
 	"github.com/tektoncd/pipeline/test"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"knative.dev/pkg/apis"
+	duckv1beta1 "knative.dev/pkg/apis/duck/v1beta1"
 	logtesting "knative.dev/pkg/logging/testing"
 )
 
@@ -75,6 +77,29 @@ func TestCancelPipelineRun(t *testing.T) {
 			{ObjectMeta: metav1.ObjectMeta{Name: "t1"}},
 			{ObjectMeta: metav1.ObjectMeta{Name: "t2"}},
 		},
+	}, {
+		name: "completed-taskrun-reused-from-previous-pipelinerun",
+		pipelineRun: &v1beta1.PipelineRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-pipeline-run-cancelled"},
+			Spec: v1beta1.PipelineRunSpec{
+				Status:  v1beta1.PipelineRunSpecStatusCancelled,
+				RerunOf: "test-pipeline-run-failed",
+			},
+			Status: v1beta1.PipelineRunStatus{PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+				TaskRuns: map[string]*v1beta1.PipelineRunTaskRunStatus{
+					"t1": {
+						PipelineTaskName: "task-1",
+						Status: &v1beta1.TaskRunStatus{Status: duckv1beta1.Status{
+							Conditions: duckv1beta1.Conditions{{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue}},
+						}},
+					},
+					"t2": {PipelineTaskName: "task-2"},
+				},
+			}},
+		},
+		taskRuns: []*v1beta1.TaskRun{
+			{ObjectMeta: metav1.ObjectMeta{Name: "t2"}},
+		},
 	}}
 	for _, tc := range testCases {
 		tc := tc
@@ -34,8 +34,10 @@ import (
 	cloudeventclient "github.com/tektoncd/pipeline/pkg/reconciler/events/cloudevent"
 	"github.com/tektoncd/pipeline/pkg/reconciler/volumeclaim"
 	"github.com/tektoncd/pipeline/pkg/timeout"
+	"github.com/tektoncd/pipeline/pkg/tracing"
 	"k8s.io/client-go/tools/cache"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	namespaceinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/namespace"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
@@ -54,32 +56,38 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 		pipelineRunInformer := pipelineruninformer.Get(ctx)
 		pipelineInformer := pipelineinformer.Get(ctx)
 		resourceInformer := resourceinformer.Get(ctx)
+		namespaceInformer := namespaceinformer.Get(ctx)
 		conditionInformer := conditioninformer.Get(ctx)
 		timeoutHandler := timeout.NewHandler(ctx.Done(), logger)
+		cloudEventDeliveries := cloudeventclient.DeliveryTrackerFromContext(ctx)
 		metrics, err := NewRecorder()
 		if err != nil {
 			logger.Errorf("Failed to create pipelinerun metrics recorder %v", err)
 		}
 
 		c := &Reconciler{
-			KubeClientSet:     kubeclientset,
-			PipelineClientSet: pipelineclientset,
-			Images:            images,
-			pipelineRunLister: pipelineRunInformer.Lister(),
-			pipelineLister:    pipelineInformer.Lister(),
-			taskLister:        taskInformer.Lister(),
-			clusterTaskLister: clusterTaskInformer.Lister(),
-			taskRunLister:     taskRunInformer.Lister(),
-			resourceLister:    resourceInformer.Lister(),
-			conditionLister:   conditionInformer.Lister(),
-			timeoutHandler:    timeoutHandler,
-			cloudEventClient:  cloudeventclient.Get(ctx),
-			metrics:           metrics,
-			pvcHandler:        volumeclaim.NewPVCHandler(kubeclientset, logger),
+			KubeClientSet:        kubeclientset,
+			PipelineClientSet:    pipelineclientset,
+			Images:               images,
+			pipelineRunLister:    pipelineRunInformer.Lister(),
+			pipelineLister:       pipelineInformer.Lister(),
+			taskLister:           taskInformer.Lister(),
+			clusterTaskLister:    clusterTaskInformer.Lister(),
+			taskRunLister:        taskRunInformer.Lister(),
+			resourceLister:       resourceInformer.Lister(),
+			namespaceLister:      namespaceInformer.Lister(),
+			conditionLister:      conditionInformer.Lister(),
+			timeoutHandler:       timeoutHandler,
+			cloudEventClient:     cloudeventclient.Get(ctx),
+			cloudEventDeliveries: cloudEventDeliveries,
+			metrics:              metrics,
+			pvcHandler:           volumeclaim.NewPVCHandler(kubeclientset, logger),
 		}
 		impl := pipelinerunreconciler.NewImpl(ctx, c, func(impl *controller.Impl) controller.Options {
 			configStore := config.NewStore(logger.Named("config-store"))
 			configStore.WatchConfigs(cmw)
+			tracing.WatchConfig(cmw, logger)
+			metrics.WatchConfig(cmw, logger)
 			return controller.Options{
 				AgentName:   pipeline.PipelineRunControllerName,
 				ConfigStore: configStore,
@@ -87,6 +95,7 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 		})
 
 		timeoutHandler.SetCallbackFunc(impl.EnqueueKey)
+		cloudEventDeliveries.SetCallbackFunc(impl.EnqueueKeyAfter)
 		timeoutHandler.CheckTimeouts(ctx, namespace, kubeclientset, pipelineclientset)
 
 		logger.Info("Setting up event handlers")
@@ -22,13 +22,16 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
+	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/metrics"
 )
@@ -58,6 +61,10 @@ type Recorder struct {
 	namespace   tag.Key
 	status      tag.Key
 
+	// views are the views of the metrics, registered for the metrics
+	// configuration
+	views *config.MetricsViews
+
 	ReportingPeriod time.Duration
 }
 
@@ -95,26 +102,9 @@ func NewRecorder() (*Recorder, error) {
 	}
 	r.status = status
 
-	err = view.Register(
-		&view.View{
-			Description: prDuration.Description(),
-			Measure:     prDuration,
-			Aggregation: prDistributions,
-			TagKeys:     []tag.Key{r.pipeline, r.pipelineRun, r.namespace, r.status},
-		},
-		&view.View{
-			Description: prCount.Description(),
-			Measure:     prCount,
-			Aggregation: view.Count(),
-			TagKeys:     []tag.Key{r.status},
-		},
-		&view.View{
-			Description: runningPRsCount.Description(),
-			Measure:     runningPRsCount,
-			Aggregation: view.LastValue(),
-		},
-	)
-
+	r.views = config.NewMetricsViews(r.buildViews)
+	cfg, _ := config.NewMetricsFromMap(map[string]string{})
+	err = r.views.Register(cfg)
 	if err != nil {
 		r.initialized = false
 		return r, err
@@ -123,6 +113,57 @@ func NewRecorder() (*Recorder, error) {
 	return r, nil
 }
 
+// buildViews returns the views of the metrics, tagged at the level of the
+// given configuration and with the tags of the run labels.
+func (r *Recorder) buildViews(cfg *config.Metrics, labelTags []tag.Key) []*view.View {
+	// The tags identifying the PipelineRuns, depending on the level the
+	// metrics are aggregated at
+	var pipelineRunTags []tag.Key
+	switch cfg.PipelineRunLevel {
+	case config.PipelineRunLevelAtPipelineRun:
+		pipelineRunTags = []tag.Key{r.pipeline, r.pipelineRun}
+	case config.PipelineRunLevelAtPipeline:
+		pipelineRunTags = []tag.Key{r.pipeline}
+	}
+
+	return []*view.View{{
+		Description: prDuration.Description(),
+		Measure:     prDuration,
+		Aggregation: prDistributions,
+		TagKeys:     append(append(pipelineRunTags, r.namespace, r.status), labelTags...),
+	}, {
+		Description: prCount.Description(),
+		Measure:     prCount,
+		Aggregation: view.Count(),
+		TagKeys:     append([]tag.Key{r.status}, labelTags...),
+	}, {
+		Description: runningPRsCount.Description(),
+		Measure:     runningPRsCount,
+		Aggregation: view.LastValue(),
+	}}
+}
+
+// WatchConfig updates the tags of the metrics when the configuration of the
+// metrics in the config-observability ConfigMap changes. The data aggregated
+// so far is dropped when the tags change.
+func (r *Recorder) WatchConfig(cmw configmap.Watcher, logger *zap.SugaredLogger) {
+	config.WatchMetricsConfig(cmw, logger, r.updateConfig)
+}
+
+func (r *Recorder) updateConfig(cfg *config.Metrics) error {
+	if !r.initialized {
+		return errors.New("ignoring the metrics configuration, failed to initialize the metrics recorder")
+	}
+
+	return r.views.Register(cfg)
+}
+
+// runLabelsContext returns a context tagged with the values of the labels of
+// the run configured to be copied to the metrics.
+func (r *Recorder) runLabelsContext(labels map[string]string) context.Context {
+	return r.views.RunLabelsContext(labels)
+}
+
 // DurationAndCount logs the duration of PipelineRun execution and
 // count for number of PipelineRuns succeed or failed
 // returns an error if its failed to log the metrics
@@ -146,7 +187,7 @@ func (r *Recorder) DurationAndCount(pr *v1beta1.PipelineRun) error {
 		pipelineName = pr.Spec.PipelineRef.Name
 	}
 	ctx, err := tag.New(
-		context.Background(),
+		r.runLabelsContext(pr.Labels),
 		tag.Insert(r.pipeline, pipelineName),
 		tag.Insert(r.pipelineRun, pr.Name),
 		tag.Insert(r.namespace, pr.Namespace),
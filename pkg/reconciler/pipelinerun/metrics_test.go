@@ -20,6 +20,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	fakepipelineruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1beta1/pipelinerun/fake"
 	"github.com/tektoncd/pipeline/pkg/names"
@@ -137,6 +138,86 @@ func TestRecordPipelineRunDurationCount(t *testing.T) {
 	}
 }
 
+func TestRecordPipelineRunDurationCountWithMetricsConfig(t *testing.T) {
+	pipelineRun := &v1beta1.PipelineRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pipelinerun-1",
+			Namespace: "ns",
+			Labels:    map[string]string{"team": "team-1"},
+		},
+		Spec: v1beta1.PipelineRunSpec{
+			PipelineRef: &v1beta1.PipelineRef{Name: "pipeline-1"},
+		},
+		Status: v1beta1.PipelineRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: duckv1beta1.Conditions{{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionTrue,
+				}},
+			},
+			PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+				StartTime:      &startTime,
+				CompletionTime: &completionTime,
+			},
+		},
+	}
+	for _, test := range []struct {
+		name              string
+		cfg               *config.Metrics
+		expectedTags      map[string]string
+		expectedCountTags map[string]string
+	}{{
+		name: "at pipeline level",
+		cfg: &config.Metrics{
+			TaskRunLevel:     config.TaskRunLevelAtTaskRun,
+			PipelineRunLevel: config.PipelineRunLevelAtPipeline,
+		},
+		expectedTags: map[string]string{
+			"pipeline":  "pipeline-1",
+			"namespace": "ns",
+			"status":    "success",
+		},
+		expectedCountTags: map[string]string{
+			"status": "success",
+		},
+	}, {
+		name: "at namespace level with run labels",
+		cfg: &config.Metrics{
+			TaskRunLevel:     config.TaskRunLevelAtTaskRun,
+			PipelineRunLevel: config.PipelineRunLevelAtNS,
+			RunLabels:        []string{"team"},
+		},
+		expectedTags: map[string]string{
+			"namespace": "ns",
+			"status":    "success",
+			"team":      "team-1",
+		},
+		expectedCountTags: map[string]string{
+			"status": "success",
+			"team":   "team-1",
+		},
+	}} {
+		t.Run(test.name, func(t *testing.T) {
+			unregisterMetrics()
+
+			metrics, err := NewRecorder()
+			if err != nil {
+				t.Fatalf("NewRecorder: %v", err)
+			}
+			if err := metrics.updateConfig(test.cfg); err != nil {
+				t.Fatalf("updateConfig: %v", err)
+			}
+			defer unregisterMetrics()
+
+			if err := metrics.DurationAndCount(pipelineRun); err != nil {
+				t.Errorf("DurationAndCount: %v", err)
+			}
+			metricstest.CheckDistributionData(t, "pipelinerun_duration_seconds", test.expectedTags, 1, 60, 60)
+			metricstest.CheckCountData(t, "pipelinerun_count", test.expectedCountTags, 1)
+		})
+	}
+}
+
 func TestRecordRunningPipelineRunsCount(t *testing.T) {
 	unregisterMetrics()
 
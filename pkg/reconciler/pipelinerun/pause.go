@@ -0,0 +1,38 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// resumePipelineRun adds the duration of the ongoing pause of the PipelineRun to
+// its total paused duration and clears its paused time.
+func resumePipelineRun(pr *v1beta1.PipelineRun) {
+	if pr.Status.PausedTime == nil {
+		return
+	}
+	paused := time.Since(pr.Status.PausedTime.Time)
+	if pr.Status.PausedDuration != nil {
+		paused += pr.Status.PausedDuration.Duration
+	}
+	pr.Status.PausedDuration = &metav1.Duration{Duration: paused}
+	pr.Status.PausedTime = nil
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"testing"
+	"time"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestResumePipelineRun(t *testing.T) {
+	testCases := []struct {
+		name           string
+		pausedTime     *metav1.Time
+		pausedDuration *metav1.Duration
+		minDuration    time.Duration
+	}{{
+		name:        "first pause",
+		pausedTime:  &metav1.Time{Time: time.Now().Add(-10 * time.Minute)},
+		minDuration: 10 * time.Minute,
+	}, {
+		name:           "paused before",
+		pausedTime:     &metav1.Time{Time: time.Now().Add(-10 * time.Minute)},
+		pausedDuration: &metav1.Duration{Duration: 5 * time.Minute},
+		minDuration:    15 * time.Minute,
+	}, {
+		name:           "not paused",
+		pausedDuration: &metav1.Duration{Duration: 5 * time.Minute},
+		minDuration:    5 * time.Minute,
+	}}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := &v1beta1.PipelineRun{
+				Status: v1beta1.PipelineRunStatus{PipelineRunStatusFields: v1beta1.PipelineRunStatusFields{
+					PausedTime:     tc.pausedTime,
+					PausedDuration: tc.pausedDuration,
+				}},
+			}
+			resumePipelineRun(pr)
+			if pr.Status.PausedTime != nil {
+				t.Errorf("Expected paused time to be cleared, got %v", pr.Status.PausedTime)
+			}
+			if pr.Status.PausedDuration == nil {
+				t.Fatal("Expected paused duration to be set")
+			}
+			if d := pr.Status.PausedDuration.Duration; d < tc.minDuration || d > tc.minDuration+time.Minute {
+				t.Errorf("Expected paused duration to be about %s, got %s", tc.minDuration, d)
+			}
+		})
+	}
+}
@@ -45,13 +45,16 @@ import (
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun"
 	"github.com/tektoncd/pipeline/pkg/reconciler/volumeclaim"
 	"github.com/tektoncd/pipeline/pkg/timeout"
+	"github.com/tektoncd/pipeline/pkg/tracing"
 	"github.com/tektoncd/pipeline/pkg/workspace"
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
@@ -98,6 +101,12 @@ const (
 	// ReasonCouldntCancel indicates that a PipelineRun was cancelled but attempting to update
 	// all of the running TaskRuns as cancelled failed.
 	ReasonCouldntCancel = "PipelineRunCouldntCancel"
+	// ReasonCouldntGetPreviousPipelineRun indicates that the reason for the failure status is that the
+	// PipelineRun this PipelineRun is a rerun of couldn't be retrieved
+	ReasonCouldntGetPreviousPipelineRun = "CouldntGetPreviousPipelineRun"
+	// ReasonPreviousPipelineRunNotDone indicates that the reason for the failure status is that the
+	// PipelineRun this PipelineRun is a rerun of has not completed yet
+	ReasonPreviousPipelineRunNotDone = "PreviousPipelineRunNotDone"
 )
 
 // Reconciler implements controller.Reconciler for Configuration resources.
@@ -113,12 +122,15 @@ type Reconciler struct {
 	taskLister        listers.TaskLister
 	clusterTaskLister listers.ClusterTaskLister
 	resourceLister    resourcelisters.PipelineResourceLister
+	namespaceLister   corev1listers.NamespaceLister
 	conditionLister   listersv1alpha1.ConditionLister
 	cloudEventClient  cloudevent.CEClient
-	tracker           tracker.Interface
-	timeoutHandler    *timeout.Handler
-	metrics           *Recorder
-	pvcHandler        volumeclaim.PvcHandler
+	// cloudEventDeliveries delivers the queued cloud events in the background
+	cloudEventDeliveries *cloudevent.DeliveryTracker
+	tracker              tracker.Interface
+	timeoutHandler       *timeout.Handler
+	metrics              *Recorder
+	pvcHandler           volumeclaim.PvcHandler
 }
 
 var (
@@ -132,6 +144,10 @@ var (
 func (c *Reconciler) ReconcileKind(ctx context.Context, pr *v1beta1.PipelineRun) pkgreconciler.Event {
 	logger := logging.FromContext(ctx)
 	ctx = cloudevent.ToContext(ctx, c.cloudEventClient)
+	ctx = cloudevent.WithDeliveryTracker(ctx, c.cloudEventDeliveries)
+	ctx = cloudevent.WithNamespaceLister(ctx, c.namespaceLister)
+	ctx, span := tracing.StartRunSpan(ctx, "PipelineRun.Reconcile", pr)
+	defer span.End()
 
 	// Read the initial condition
 	before := pr.Status.GetCondition(apis.ConditionSucceeded)
@@ -177,12 +193,17 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, pr *v1beta1.PipelineRun)
 			logger.Errorf("Failed to update TaskRun status for PipelineRun %s: %v", pr.Name, err)
 			return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
 		}
-		go func(metrics *Recorder) {
-			err := metrics.DurationAndCount(pr)
-			if err != nil {
-				logger.Warnf("Failed to log the metrics : %v", err)
-			}
-		}(c.metrics)
+		// The metrics are recorded once the cloud events are delivered, so that
+		// they are not recorded again by the reconciles that record the outcome
+		// of their delivery
+		if !events.HasPendingCloudEvents(ctx, pr) {
+			go func(metrics *Recorder) {
+				err := metrics.DurationAndCount(pr)
+				if err != nil {
+					logger.Warnf("Failed to log the metrics : %v", err)
+				}
+			}(c.metrics)
+		}
 		return c.finishReconcileUpdateEmitEvents(ctx, pr, before, nil)
 	}
 
@@ -192,6 +213,20 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, pr *v1beta1.PipelineRun)
 		return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
 	}
 
+	switch {
+	case pr.IsPaused() && pr.Status.PausedTime == nil:
+		// The pipelinerun was just paused: stop tracking its timeout until it is resumed
+		logger.Infof("PipelineRun %s has been paused", pr.GetNamespacedName())
+		pr.Status.PausedTime = &metav1.Time{Time: time.Now()}
+		c.timeoutHandler.Release(pr.GetNamespacedName())
+	case !pr.IsPaused() && pr.Status.PausedTime != nil:
+		// The pipelinerun was just resumed: record the time spent paused and track
+		// its timeout again, shifted by the total paused duration
+		resumePipelineRun(pr)
+		logger.Infof("PipelineRun %s has been resumed after being paused for %s", pr.GetNamespacedName(), pr.Status.PausedDuration.Duration)
+		go c.timeoutHandler.Wait(pr.GetNamespacedName(), pr.GetTimeoutStartTime(), getPipelineRunTimeout(ctx, pr))
+	}
+
 	if err := c.tracker.Track(pr.GetTaskRunRef(), pr); err != nil {
 		logger.Errorf("Failed to create tracker for TaskRuns for PipelineRun %s: %v", pr.Name, err)
 		return c.finishReconcileUpdateEmitEvents(ctx, pr, before, err)
@@ -219,13 +254,15 @@ func (c *Reconciler) finishReconcileUpdateEmitEvents(ctx context.Context, pr *v1
 
 	afterCondition := pr.Status.GetCondition(apis.ConditionSucceeded)
 	events.Emit(ctx, beforeCondition, afterCondition, pr)
+	// Send again the cloud events that could not be delivered so far
+	cloudEventErr := events.SendQueuedCloudEvents(ctx, pr)
 	_, err := c.updateLabelsAndAnnotations(ctx, pr)
 	if err != nil {
 		logger.Warn("Failed to update PipelineRun labels/annotations", zap.Error(err))
 		events.EmitError(controller.GetEventRecorder(ctx), err, pr)
 	}
 
-	merr := multierror.Append(previousError, err).ErrorOrNil()
+	merr := multierror.Append(previousError, err, cloudEventErr).ErrorOrNil()
 	if controller.IsPermanentError(previousError) {
 		return controller.NewPermanentError(merr)
 	}
@@ -392,7 +429,8 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 	// pipelineRunState holds a list of pipeline tasks after resolving conditions and pipeline resources
 	// pipelineRunState also holds a taskRun for each pipeline task after the taskRun is created
 	// pipelineRunState is instantiated and updated on every reconcile cycle
-	pipelineRunState, err := resources.ResolvePipelineRun(ctx,
+	resolveCtx, span := trace.StartSpan(ctx, "ResolvePipelineRun")
+	pipelineRunState, err := resources.ResolvePipelineRun(resolveCtx,
 		*pr,
 		func(ctx context.Context, name string) (v1beta1.TaskInterface, error) {
 			return c.taskLister.Tasks(pr.Namespace).Get(name)
@@ -408,6 +446,8 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 		},
 		append(pipelineSpec.Tasks, pipelineSpec.Finally...), providedResources,
 	)
+	tracing.SetError(span, err)
+	span.End()
 
 	if err != nil {
 		// This Run has failed, so we need to mark it as failed and stop reconciling it
@@ -469,17 +509,30 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 		}
 	}
 
+	if pr.Spec.RerunOf != "" {
+		if err := c.reuseSuccessfulTaskRuns(ctx, pr, pipelineRunFacts); err != nil {
+			return err
+		}
+	}
+
 	as, err := artifacts.InitializeArtifactStorage(ctx, c.Images, pr, pipelineSpec, c.KubeClientSet)
 	if err != nil {
 		logger.Infof("PipelineRun failed to initialize artifact storage %s", pr.Name)
 		return controller.NewPermanentError(err)
 	}
 
-	if err := c.runNextSchedulableTask(ctx, pr, pipelineRunFacts, as); err != nil {
-		return err
+	// A paused pipelinerun does not schedule any new task, the running ones are left to complete
+	if !pr.IsPaused() {
+		if err := c.runNextSchedulableTask(ctx, pr, pipelineRunFacts, as); err != nil {
+			return err
+		}
 	}
 
 	after := pipelineRunFacts.GetPipelineConditionStatus(pr, logger)
+	if pr.IsPaused() && after.Status == corev1.ConditionUnknown {
+		after.Reason = v1beta1.PipelineRunReasonPaused.String()
+		after.Message = fmt.Sprintf("PipelineRun %q is paused, no new Tasks will be scheduled until it is resumed; %s", pr.Name, after.Message)
+	}
 	switch after.Status {
 	case corev1.ConditionTrue:
 		pr.Status.MarkSucceeded(after.Reason, after.Message)
@@ -491,7 +544,11 @@ func (c *Reconciler) reconcile(ctx context.Context, pr *v1beta1.PipelineRun) err
 	// Read the condition the way it was set by the Mark* helpers
 	after = pr.Status.GetCondition(apis.ConditionSucceeded)
 	pr.Status.TaskRuns = pipelineRunFacts.State.GetTaskRunsStatus(pr)
+	previouslySkipped := pr.Status.SkippedTasks
 	pr.Status.SkippedTasks = pipelineRunFacts.GetSkippedTasks()
+	// The cloud events are queued once the skipped tasks are in the status,
+	// as they carry the when expression that skipped the task.
+	emitSkippedTaskEvents(ctx, pr, previouslySkipped, pipelineRunFacts)
 	logger.Infof("PipelineRun %s status is being set to %s", pr.Name, after)
 	return nil
 }
@@ -521,18 +578,32 @@ func (c *Reconciler) runNextSchedulableTask(ctx context.Context, pr *v1beta1.Pip
 	resources.ApplyTaskResults(nextRprts, resolvedResultRefs)
 
 	// GetFinalTasks only returns tasks when a DAG is complete
-	nextRprts = append(nextRprts, pipelineRunFacts.GetFinalTasks()...)
+	finalTasks := pipelineRunFacts.GetFinalTasks()
+	finallyStarting := len(finalTasks) > 0 && !pipelineRunFacts.IsFinallyStarted()
+	nextRprts = append(nextRprts, finalTasks...)
+
+	if finallyStarting {
+		recorder.Eventf(pr, corev1.EventTypeNormal, "FinallyStarted", "Starting the finally tasks of PipelineRun %q", pr.Name)
+	}
 
 	for _, rprt := range nextRprts {
 		if rprt == nil || rprt.Skip(pipelineRunFacts) {
 			continue
 		}
 		if rprt.ResolvedConditionChecks == nil || rprt.ResolvedConditionChecks.IsSuccess() {
+			// A PipelineTask which already has a TaskRun is scheduled for a retry
+			isRetry := rprt.TaskRun != nil
 			rprt.TaskRun, err = c.createTaskRun(ctx, rprt, pr, as.StorageBasePath(pr))
 			if err != nil {
 				recorder.Eventf(pr, corev1.EventTypeWarning, "TaskRunCreationFailed", "Failed to create TaskRun %q: %v", rprt.TaskRunName, err)
 				return fmt.Errorf("error creating TaskRun called %s for PipelineTask %s from PipelineRun %s: %w", rprt.TaskRunName, rprt.PipelineTask.Name, pr.Name, err)
 			}
+			if isRetry {
+				recorder.Eventf(pr, corev1.EventTypeNormal, "PipelineTaskRetryScheduled", "Retrying PipelineTask %q with TaskRun %q, retry %d of %d",
+					rprt.PipelineTask.Name, rprt.TaskRunName, len(rprt.TaskRun.Status.RetriesStatus), rprt.PipelineTask.Retries)
+			} else {
+				recorder.Eventf(pr, corev1.EventTypeNormal, "PipelineTaskStarted", "Started PipelineTask %q with TaskRun %q", rprt.PipelineTask.Name, rprt.TaskRunName)
+			}
 		} else if !rprt.ResolvedConditionChecks.HasStarted() {
 			for _, rcc := range rprt.ResolvedConditionChecks {
 				rcc.ConditionCheck, err = c.makeConditionCheckContainer(ctx, rprt, rcc, pr)
@@ -543,9 +614,34 @@ func (c *Reconciler) runNextSchedulableTask(ctx context.Context, pr *v1beta1.Pip
 			}
 		}
 	}
+
+	if finallyStarting {
+		events.EmitCloudEvent(ctx, cloudevent.PipelineRunFinallyStartedEventV1, pr, nil)
+	}
 	return nil
 }
 
+// emitSkippedTaskEvents records an event on the PipelineRun and queues a
+// cloud event for each PipelineTask skipped since the previous reconcile,
+// with the reason it was skipped. skippedTasks are the tasks skipped as of
+// the previous reconcile.
+func emitSkippedTaskEvents(ctx context.Context, pr *v1beta1.PipelineRun, skippedTasks []v1beta1.SkippedTask, pipelineRunFacts *resources.PipelineRunFacts) {
+	recorder := controller.GetEventRecorder(ctx)
+	previouslySkipped := map[string]bool{}
+	for _, skippedTask := range skippedTasks {
+		previouslySkipped[skippedTask.Name] = true
+	}
+	for _, rprt := range pipelineRunFacts.State {
+		if previouslySkipped[rprt.PipelineTask.Name] {
+			continue
+		}
+		if reason := rprt.SkipReason(pipelineRunFacts); reason != resources.NotSkipped {
+			recorder.Eventf(pr, corev1.EventTypeNormal, "PipelineTaskSkipped", "Skipped PipelineTask %q: %s", rprt.PipelineTask.Name, reason)
+			events.EmitCloudEvent(ctx, cloudevent.PipelineTaskSkippedEventV1, pr, &v1beta1.CloudEventPipelineTask{Name: rprt.PipelineTask.Name})
+		}
+	}
+}
+
 func getPipelineRunResults(pipelineSpec *v1beta1.PipelineSpec, resolvedResultRefs resources.ResolvedResultRefs) []v1beta1.PipelineRunResult {
 	var results []v1beta1.PipelineRunResult
 	stringReplacements := map[string]string{}
@@ -586,6 +682,9 @@ func (c *Reconciler) updateTaskRunsStatusDirectly(pr *v1beta1.PipelineRun) error
 
 func (c *Reconciler) createTaskRun(ctx context.Context, rprt *resources.ResolvedPipelineRunTask, pr *v1beta1.PipelineRun, storageBasePath string) (*v1beta1.TaskRun, error) {
 	logger := logging.FromContext(ctx)
+	ctx, span := trace.StartSpan(ctx, "CreateTaskRun")
+	defer span.End()
+	span.AddAttributes(trace.StringAttribute("pipelineTask", rprt.PipelineTask.Name))
 
 	tr, _ := c.taskRunLister.TaskRuns(pr.Namespace).Get(rprt.TaskRunName)
 	if tr != nil {
@@ -596,10 +695,19 @@ func (c *Reconciler) createTaskRun(ctx context.Context, rprt *resources.Resolved
 			Type:   apis.ConditionSucceeded,
 			Status: corev1.ConditionUnknown,
 		})
-		return c.PipelineClientSet.TektonV1beta1().TaskRuns(pr.Namespace).UpdateStatus(ctx, tr, metav1.UpdateOptions{})
+		retried, err := c.PipelineClientSet.TektonV1beta1().TaskRuns(pr.Namespace).UpdateStatus(ctx, tr, metav1.UpdateOptions{})
+		if err == nil {
+			events.EmitCloudEvent(ctx, cloudevent.PipelineTaskRetryStartedEventV1, pr, &v1beta1.CloudEventPipelineTask{
+				Name:        rprt.PipelineTask.Name,
+				TaskRunName: retried.Name,
+				RetryCount:  len(retried.Status.RetriesStatus),
+			})
+		}
+		return retried, err
 	}
 
 	serviceAccountName, podTemplate := pr.GetTaskRunSpecs(rprt.PipelineTask.Name)
+	computeResources, stepOverrides, sidecarOverrides := pr.GetTaskRunOverrides(rprt.PipelineTask.Name)
 	tr = &v1beta1.TaskRun{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:            rprt.TaskRunName,
@@ -613,6 +721,9 @@ func (c *Reconciler) createTaskRun(ctx context.Context, rprt *resources.Resolved
 			ServiceAccountName: serviceAccountName,
 			Timeout:            getTaskRunTimeout(ctx, pr, rprt),
 			PodTemplate:        podTemplate,
+			ComputeResources:   computeResources,
+			StepOverrides:      stepOverrides,
+			SidecarOverrides:   sidecarOverrides,
 		}}
 
 	if rprt.ResolvedTaskResources.TaskName != "" {
@@ -645,6 +756,11 @@ func (c *Reconciler) createTaskRun(ctx context.Context, rprt *resources.Resolved
 		tr.Annotations[workspace.AnnotationAffinityAssistantName] = getAffinityAssistantName(pipelinePVCWorkspaceName, pr.Name)
 	}
 
+	// The spans of the TaskRun are children of the span creating it.
+	if traceParent := tracing.TraceParent(ctx); traceParent != "" {
+		tr.Annotations[tracing.TraceParentAnnotation] = traceParent
+	}
+
 	resources.WrapSteps(&tr.Spec, rprt.PipelineTask, rprt.ResolvedTaskResources.Inputs, rprt.ResolvedTaskResources.Outputs, storageBasePath)
 	logger.Infof("Creating a new TaskRun object %s", rprt.TaskRunName)
 	return c.PipelineClientSet.TektonV1beta1().TaskRuns(pr.Namespace).Create(ctx, tr, metav1.CreateOptions{})
@@ -692,6 +808,7 @@ func clearStatus(tr *v1beta1.TaskRun) {
 	tr.Status.StartTime = nil
 	tr.Status.CompletionTime = nil
 	tr.Status.PodName = ""
+	tr.Status.Failure = nil
 }
 
 func getTaskrunAnnotations(pr *v1beta1.PipelineRun) map[string]string {
@@ -782,7 +899,7 @@ func getTaskRunTimeout(ctx context.Context, pr *v1beta1.PipelineRun, rprt *resou
 	// If the value of the timeout is 0 for any resource, there is no timeout.
 	// It is impossible for pr.Spec.Timeout to be nil, since SetDefault always assigns it with a value.
 	if timeout != apisconfig.NoTimeoutDuration {
-		pTimeoutTime := pr.Status.StartTime.Add(timeout)
+		pTimeoutTime := pr.GetTimeoutStartTime().Add(timeout)
 		if time.Now().After(pTimeoutTime) {
 			// Just in case something goes awry and we're creating the TaskRun after it should have already timed out,
 			// set the timeout to 1 second.
@@ -817,10 +934,14 @@ func (c *Reconciler) updateLabelsAndAnnotations(ctx context.Context, pr *v1beta1
 		// Note that this uses Update vs. Patch because the former is significantly easier to test.
 		// If we want to switch this to Patch, then we will need to teach the utilities in test/controller.go
 		// to deal with Patch (setting resourceVersion, and optimistic concurrency checks).
+		ctx, span := trace.StartSpan(ctx, "UpdateLabelsAndAnnotations")
+		defer span.End()
 		newPr = newPr.DeepCopy()
 		newPr.Labels = pr.Labels
 		newPr.Annotations = pr.Annotations
-		return c.PipelineClientSet.TektonV1beta1().PipelineRuns(pr.Namespace).Update(ctx, newPr, metav1.UpdateOptions{})
+		newPr, err = c.PipelineClientSet.TektonV1beta1().PipelineRuns(pr.Namespace).Update(ctx, newPr, metav1.UpdateOptions{})
+		tracing.SetError(span, err)
+		return newPr, err
 	}
 	return newPr, nil
 }
@@ -38,13 +38,16 @@ import (
 	taskrunresources "github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
 	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
 	"github.com/tektoncd/pipeline/pkg/system"
+	"github.com/tektoncd/pipeline/pkg/tracing"
 	"github.com/tektoncd/pipeline/test"
 	"github.com/tektoncd/pipeline/test/diff"
 	"github.com/tektoncd/pipeline/test/names"
+	"go.opencensus.io/trace"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zaptest/observer"
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/selection"
@@ -86,7 +89,7 @@ type PipelineRunTest struct {
 }
 
 func ensureConfigurationConfigMapsExist(d *test.Data) {
-	var defaultsExists, featureFlagsExists, artifactBucketExists, artifactPVCExists bool
+	var defaultsExists, featureFlagsExists, artifactBucketExists, artifactPVCExists, logArchiveExists, provenanceExists bool
 	for _, cm := range d.ConfigMaps {
 		if cm.Name == config.GetDefaultsConfigName() {
 			defaultsExists = true
@@ -100,6 +103,12 @@ func ensureConfigurationConfigMapsExist(d *test.Data) {
 		if cm.Name == config.GetArtifactPVCConfigName() {
 			artifactPVCExists = true
 		}
+		if cm.Name == config.GetLogArchiveConfigName() {
+			logArchiveExists = true
+		}
+		if cm.Name == config.GetProvenanceConfigName() {
+			provenanceExists = true
+		}
 	}
 	if !defaultsExists {
 		d.ConfigMaps = append(d.ConfigMaps, &corev1.ConfigMap{
@@ -125,6 +134,18 @@ func ensureConfigurationConfigMapsExist(d *test.Data) {
 			Data:       map[string]string{},
 		})
 	}
+	if !logArchiveExists {
+		d.ConfigMaps = append(d.ConfigMaps, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: config.GetLogArchiveConfigName(), Namespace: system.GetNamespace()},
+			Data:       map[string]string{},
+		})
+	}
+	if !provenanceExists {
+		d.ConfigMaps = append(d.ConfigMaps, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: config.GetProvenanceConfigName(), Namespace: system.GetNamespace()},
+			Data:       map[string]string{},
+		})
+	}
 }
 
 // getPipelineRunController returns an instance of the PipelineRun controller/reconciler that has been seeded with
@@ -329,6 +350,8 @@ func TestReconcile(t *testing.T) {
 
 	wantEvents := []string{
 		"Normal Started",
+		"Normal PipelineTaskStarted Started PipelineTask \"unit-test-1\"",
+		"Normal PipelineTaskStarted Started PipelineTask \"unit-test-cluster-task\"",
 		"Normal Running Tasks Completed: 0",
 	}
 	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-success", wantEvents, false)
@@ -443,6 +466,7 @@ func TestReconcile_PipelineSpecTaskSpec(t *testing.T) {
 
 	wantEvents := []string{
 		"Normal Started",
+		"Normal PipelineTaskStarted Started PipelineTask \"unit-test-task-spec\"",
 		"Normal Running Tasks Completed: 0",
 	}
 	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-success", wantEvents, false)
@@ -1144,6 +1168,7 @@ func TestReconcileWithTimeout(t *testing.T) {
 	defer prt.Cancel()
 
 	wantEvents := []string{
+		"Normal PipelineTaskStarted Started PipelineTask \"hello-world-1\"",
 		"Warning Failed PipelineRun \"test-pipeline-run-with-timeout\" failed to finish within \"12h0m0s\"",
 	}
 	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-with-timeout", wantEvents, false)
@@ -1463,7 +1488,298 @@ func TestReconcileWithDifferentServiceAccounts(t *testing.T) {
 // retries and timeout settings, and status that represents different number of
 // retries already performed.  It verifies the reconciled status and events
 // generated
+func TestReconcilePausedPipelineRun(t *testing.T) {
+	// TestReconcilePausedPipelineRun runs "Reconcile" on a PipelineRun that has been paused.
+	// The PipelineRun had no TaskRun associated yet, and no TaskRun should have been created.
+	// It verifies that reconcile is successful, the paused time is recorded and the status updated.
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+	))}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-paused", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline",
+			tb.PipelineRunServiceAccountName("test-sa"),
+			tb.PipelineRunPaused,
+		),
+		tb.PipelineRunStatus(tb.PipelineRunStartTime(time.Now())),
+	)}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-paused", []string{}, false)
+
+	condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+	if !condition.IsUnknown() || condition.Reason != v1beta1.PipelineRunReasonPaused.String() {
+		t.Errorf("Expected PipelineRun to be paused, but condition is %v", condition)
+	}
+	if reconciledRun.Status.PausedTime == nil {
+		t.Error("Expected the paused time of the PipelineRun to be set")
+	}
+
+	// Check that no TaskRun is created
+	for _, action := range clients.Pipeline.Actions() {
+		if action.GetVerb() == "create" {
+			t.Errorf("Expected no TaskRun to be created while the PipelineRun is paused, got %v", action)
+		}
+	}
+}
+
+func TestReconcileResumedPipelineRun(t *testing.T) {
+	// TestReconcileResumedPipelineRun runs "Reconcile" on a PipelineRun that has been resumed
+	// after being paused. It verifies that the paused duration is recorded and that the
+	// scheduling of TaskRuns resumes.
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+	))}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-run-resumed", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline",
+			tb.PipelineRunServiceAccountName("test-sa"),
+			tb.PipelineRunTimeout(1*time.Hour),
+		),
+		tb.PipelineRunStatus(
+			// The PipelineRun would have timed out if it had not been paused
+			tb.PipelineRunStartTime(time.Now().Add(-2*time.Hour)),
+			tb.PipelineRunPausedTime(time.Now().Add(-90*time.Minute)),
+		),
+	)}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-resumed", []string{}, false)
+
+	if reconciledRun.Status.PausedTime != nil {
+		t.Errorf("Expected the paused time of the PipelineRun to be cleared, got %v", reconciledRun.Status.PausedTime)
+	}
+	if reconciledRun.Status.PausedDuration == nil || reconciledRun.Status.PausedDuration.Duration < 90*time.Minute {
+		t.Errorf("Expected the paused duration of the PipelineRun to be at least 90m, got %v", reconciledRun.Status.PausedDuration)
+	}
+	condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+	if !condition.IsUnknown() || condition.Reason != v1beta1.PipelineRunReasonRunning.String() {
+		t.Errorf("Expected PipelineRun to be running, but condition is %v", condition)
+	}
+
+	created := false
+	for _, action := range clients.Pipeline.Actions() {
+		if action.GetVerb() == "create" && action.GetResource().Resource == "taskruns" {
+			created = true
+		}
+	}
+	if !created {
+		t.Error("Expected a TaskRun to be created once the PipelineRun is resumed")
+	}
+}
+
+func TestReconcileRerunPipelineRun(t *testing.T) {
+	// TestReconcileRerunPipelineRun runs "Reconcile" on a PipelineRun which is a rerun of a failed
+	// PipelineRun. It verifies that the TaskRun of the task which succeeded in the previous run is
+	// reused together with its results and that only the failed task is executed again, unless
+	// the successful TaskRun ran a different Task spec or different params.
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"), tb.TaskSpec(
+		tb.TaskParam("greeting", v1beta1.ParamTypeString, tb.ParamSpecDefault("hi")),
+	))}
+	resolvedSpec := ts[0].Spec.DeepCopy()
+	resolvedSpec.SetDefaults(context.Background())
+	changedSpec := resolvedSpec.DeepCopy()
+	changedSpec.Params[0].Default = v1beta1.NewArrayOrString("hey")
+
+	for _, tc := range []struct {
+		name           string
+		previousSpec   *v1beta1.TaskSpec
+		previousParams []tb.TaskRunSpecOp
+		wantCreated    []string
+	}{{
+		name:         "matching TaskRun is reused",
+		previousSpec: resolvedSpec,
+		wantCreated:  []string{"hello-world-2"},
+	}, {
+		name:         "TaskRun with a different Task spec is not reused",
+		previousSpec: changedSpec,
+		wantCreated:  []string{"hello-world-1"},
+	}, {
+		name:           "TaskRun with different params is not reused",
+		previousSpec:   resolvedSpec,
+		previousParams: []tb.TaskRunSpecOp{tb.TaskRunTaskRef("hello-world"), tb.TaskRunParam("greeting", "bonjour")},
+		wantCreated:    []string{"hello-world-1"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+				tb.PipelineTask("hello-world-1", "hello-world"),
+				tb.PipelineTask("hello-world-2", "hello-world",
+					tb.PipelineTaskParam("greeting", "$(tasks.hello-world-1.results.greeting)"),
+				),
+			))}
+			prs := []*v1beta1.PipelineRun{
+				tb.PipelineRun("test-pipeline-run-failed", tb.PipelineRunNamespace("foo"),
+					tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa")),
+					tb.PipelineRunStatus(
+						tb.PipelineRunStatusCondition(apis.Condition{
+							Type:   apis.ConditionSucceeded,
+							Status: corev1.ConditionFalse,
+							Reason: v1beta1.PipelineRunReasonFailed.String(),
+						}),
+						tb.PipelineRunTaskRunsStatus("test-pipeline-run-failed-hello-world-1", &v1beta1.PipelineRunTaskRunStatus{
+							PipelineTaskName: "hello-world-1",
+							Status: &v1beta1.TaskRunStatus{
+								Status: duckv1beta1.Status{
+									Conditions: duckv1beta1.Conditions{{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue}},
+								},
+								TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+									TaskRunResults: []v1beta1.TaskRunResult{{Name: "greeting", Value: "hello"}},
+									TaskSpec:       tc.previousSpec,
+								},
+							},
+						}),
+						tb.PipelineRunTaskRunsStatus("test-pipeline-run-failed-hello-world-2", &v1beta1.PipelineRunTaskRunStatus{
+							PipelineTaskName: "hello-world-2",
+							Status: &v1beta1.TaskRunStatus{
+								Status: duckv1beta1.Status{
+									Conditions: duckv1beta1.Conditions{{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse}},
+								},
+							},
+						}),
+					),
+				),
+				tb.PipelineRun("test-pipeline-run-rerun", tb.PipelineRunNamespace("foo"),
+					tb.PipelineRunSpec("test-pipeline",
+						tb.PipelineRunServiceAccountName("test-sa"),
+						tb.PipelineRunRerunOf("test-pipeline-run-failed"),
+					),
+				),
+			}
+			previousSpecOps := tc.previousParams
+			if previousSpecOps == nil {
+				previousSpecOps = []tb.TaskRunSpecOp{tb.TaskRunTaskRef("hello-world")}
+			}
+			trs := []*v1beta1.TaskRun{
+				tb.TaskRun("test-pipeline-run-failed-hello-world-1", tb.TaskRunNamespace("foo"),
+					tb.TaskRunSpec(previousSpecOps...),
+				),
+			}
+
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        ts,
+				TaskRuns:     trs,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-rerun", []string{}, false)
+
+			var created []string
+			var createdParams []v1beta1.Param
+			for _, action := range clients.Pipeline.Actions() {
+				if action.GetVerb() == "create" && action.GetResource().Resource == "taskruns" {
+					tr := action.(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun)
+					created = append(created, tr.Labels[pipeline.GroupName+pipeline.PipelineTaskLabelKey])
+					createdParams = tr.Spec.Params
+				}
+			}
+			if d := cmp.Diff(tc.wantCreated, created); d != "" {
+				t.Fatalf("Unexpected TaskRuns created %s", diff.PrintWantGot(d))
+			}
+
+			reused, ok := reconciledRun.Status.TaskRuns["test-pipeline-run-failed-hello-world-1"]
+			if tc.wantCreated[0] != "hello-world-2" {
+				if ok {
+					t.Errorf("Expected the TaskRun of the previous PipelineRun not to be reused, got %v", reconciledRun.Status.TaskRuns)
+				}
+				return
+			}
+			if d := cmp.Diff([]v1beta1.Param{{Name: "greeting", Value: *v1beta1.NewArrayOrString("hello")}}, createdParams); d != "" {
+				t.Errorf("Expected the result of the reused TaskRun to be passed on %s", diff.PrintWantGot(d))
+			}
+			if !ok || reused.PipelineTaskName != "hello-world-1" {
+				t.Fatalf("Expected the reused TaskRun to be part of the PipelineRun status, got %v", reconciledRun.Status.TaskRuns)
+			}
+			if !reused.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+				t.Errorf("Expected the reused TaskRun to be successful, got %v", reused.Status.Conditions)
+			}
+			condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+			if !condition.IsUnknown() || condition.Reason != v1beta1.PipelineRunReasonRunning.String() {
+				t.Errorf("Expected PipelineRun to be running, but condition is %v", condition)
+			}
+		})
+	}
+}
+
+func TestReconcileRerunPipelineRunWithPreviousNotDone(t *testing.T) {
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+	))}
+	prs := []*v1beta1.PipelineRun{
+		tb.PipelineRun("test-pipeline-run-running", tb.PipelineRunNamespace("foo"),
+			tb.PipelineRunSpec("test-pipeline", tb.PipelineRunServiceAccountName("test-sa")),
+			tb.PipelineRunStatus(tb.PipelineRunStatusCondition(apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionUnknown,
+				Reason: v1beta1.PipelineRunReasonRunning.String(),
+			})),
+		),
+		tb.PipelineRun("test-pipeline-run-rerun", tb.PipelineRunNamespace("foo"),
+			tb.PipelineRunSpec("test-pipeline",
+				tb.PipelineRunServiceAccountName("test-sa"),
+				tb.PipelineRunRerunOf("test-pipeline-run-running"),
+			),
+		),
+	}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+
+	for _, tc := range []struct {
+		name       string
+		rerunOf    string
+		wantReason string
+	}{{
+		name:       "previous pipelinerun not done",
+		rerunOf:    "test-pipeline-run-running",
+		wantReason: ReasonPreviousPipelineRunNotDone,
+	}, {
+		name:       "previous pipelinerun not found",
+		rerunOf:    "test-pipeline-run-missing",
+		wantReason: ReasonCouldntGetPreviousPipelineRun,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			prs[1].Spec.RerunOf = tc.rerunOf
+			d := test.Data{
+				PipelineRuns: prs,
+				Pipelines:    ps,
+				Tasks:        ts,
+			}
+			prt := NewPipelineRunTest(d, t)
+			defer prt.Cancel()
+
+			wantEvents := []string{"Normal Started", "Warning Failed", "Warning InternalError 1 error occurred"}
+			reconciledRun, clients := prt.reconcileRun("foo", "test-pipeline-run-rerun", wantEvents, true)
+
+			condition := reconciledRun.Status.GetCondition(apis.ConditionSucceeded)
+			if !condition.IsFalse() || condition.Reason != tc.wantReason {
+				t.Errorf("Expected PipelineRun to fail with reason %s, but condition is %v", tc.wantReason, condition)
+			}
+			for _, action := range clients.Pipeline.Actions() {
+				if action.GetVerb() == "create" && action.GetResource().Resource == "taskruns" {
+					t.Errorf("Expected no TaskRun to be created, got %v", action)
+				}
+			}
+		})
+	}
+}
+
 func TestReconcileWithTimeoutAndRetry(t *testing.T) {
+
 	for _, tc := range []struct {
 		name               string
 		retries            int
@@ -1549,6 +1865,101 @@ func TestReconcileWithTimeoutAndRetry(t *testing.T) {
 	}
 }
 
+func TestReconcileRetryEvents(t *testing.T) {
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline-retry", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world", tb.Retries(2)),
+	))}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-retry-run", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline-retry", tb.PipelineRunServiceAccountName("test-sa")),
+		tb.PipelineRunStatus(
+			tb.PipelineRunStatusCondition(apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionUnknown,
+				Reason: v1beta1.PipelineRunReasonRunning.String(),
+			}),
+			tb.PipelineRunStartTime(time.Now()),
+			tb.PipelineRunTaskRunsStatus("hello-world-1", &v1beta1.PipelineRunTaskRunStatus{
+				PipelineTaskName: "hello-world-1",
+			}),
+		),
+	)}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	trs := []*v1beta1.TaskRun{
+		tb.TaskRun("hello-world-1",
+			tb.TaskRunNamespace("foo"),
+			tb.TaskRunStatus(
+				tb.PodName("my-pod-name"),
+				tb.StatusCondition(apis.Condition{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionFalse,
+				}),
+			)),
+	}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+		TaskRuns:     trs,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	wantEvents := []string{
+		"Normal PipelineTaskRetryScheduled Retrying PipelineTask \"hello-world-1\" with TaskRun \"hello-world-1\", retry 1 of 2",
+		"Normal Running Tasks Completed: 0",
+	}
+	prt.reconcileRun("foo", "test-pipeline-retry-run", wantEvents, false)
+}
+
+func TestReconcileFinallyStartedEvents(t *testing.T) {
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline-finally", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+		tb.FinalPipelineTask("cleanup", "hello-world"),
+	))}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun("test-pipeline-finally-run", tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunSpec("test-pipeline-finally", tb.PipelineRunServiceAccountName("test-sa")),
+		tb.PipelineRunStatus(
+			tb.PipelineRunStatusCondition(apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionUnknown,
+				Reason: v1beta1.PipelineRunReasonRunning.String(),
+			}),
+			tb.PipelineRunStartTime(time.Now()),
+			tb.PipelineRunTaskRunsStatus("hello-world-1", &v1beta1.PipelineRunTaskRunStatus{
+				PipelineTaskName: "hello-world-1",
+			}),
+		),
+	)}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+	trs := []*v1beta1.TaskRun{
+		tb.TaskRun("hello-world-1",
+			tb.TaskRunNamespace("foo"),
+			tb.TaskRunStatus(
+				tb.StatusCondition(apis.Condition{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionTrue,
+				}),
+			)),
+	}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+		TaskRuns:     trs,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	wantEvents := []string{
+		"Normal FinallyStarted Starting the finally tasks of PipelineRun \"test-pipeline-finally-run\"",
+		"Normal PipelineTaskStarted Started PipelineTask \"cleanup\"",
+		"Normal Running Tasks Completed: 1",
+	}
+	prt.reconcileRun("foo", "test-pipeline-finally-run", wantEvents, false)
+}
+
 func TestReconcilePropagateAnnotations(t *testing.T) {
 	names.TestingSeed()
 
@@ -1716,6 +2127,14 @@ func TestReconcileAndPropagateCustomPipelineTaskRunSpec(t *testing.T) {
 						"workloadtype": "tekton",
 					},
 				},
+				StepOverrides: []v1beta1.TaskRunStepOverride{{
+					Name:      "build",
+					Resources: corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8")}},
+				}},
+				SidecarOverrides: []v1beta1.TaskRunSidecarOverride{{
+					Name: "registry",
+					Env:  []corev1.EnvVar{{Name: "REGISTRY_LOG_LEVEL", Value: "debug"}},
+				}},
 			}}),
 		),
 	)}
@@ -1759,6 +2178,8 @@ func TestReconcileAndPropagateCustomPipelineTaskRunSpec(t *testing.T) {
 					"workloadtype": "tekton",
 				},
 			}),
+			tb.TaskRunStepOverride("build", corev1.ResourceRequirements{Requests: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("8")}}),
+			tb.TaskRunSidecarOverride("registry", corev1.EnvVar{Name: "REGISTRY_LOG_LEVEL", Value: "debug"}),
 		),
 	)
 
@@ -1767,6 +2188,77 @@ func TestReconcileAndPropagateCustomPipelineTaskRunSpec(t *testing.T) {
 	}
 }
 
+// spanRecorder is an in-memory exporter keeping the spans that ended.
+type spanRecorder struct {
+	spans []*trace.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(s *trace.SpanData) {
+	r.spans = append(r.spans, s)
+}
+
+func TestReconcilePropagatesTraceContext(t *testing.T) {
+	names.TestingSeed()
+	prName := "test-pipeline-run"
+	traceParent := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"
+	parent, _ := tracing.SpanContextFromTraceParent(traceParent)
+	ps := []*v1beta1.Pipeline{tb.Pipeline("test-pipeline", tb.PipelineNamespace("foo"), tb.PipelineSpec(
+		tb.PipelineTask("hello-world-1", "hello-world"),
+	))}
+	prs := []*v1beta1.PipelineRun{tb.PipelineRun(prName, tb.PipelineRunNamespace("foo"),
+		tb.PipelineRunAnnotation(tracing.TraceParentAnnotation, traceParent),
+		tb.PipelineRunSpec("test-pipeline"),
+	)}
+	ts := []*v1beta1.Task{tb.Task("hello-world", tb.TaskNamespace("foo"))}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	// Sample and record the spans once the controller has read the tracing configuration.
+	recorder := &spanRecorder{}
+	trace.RegisterExporter(recorder)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	defer func() {
+		trace.UnregisterExporter(recorder)
+		trace.ApplyConfig(trace.Config{DefaultSampler: trace.NeverSample()})
+	}()
+
+	_, clients := prt.reconcileRun("foo", prName, []string{}, false)
+	actions := clients.Pipeline.Actions()
+	if len(actions) < 2 {
+		t.Fatalf("Expected client to have at least two action implementation but it has %d", len(actions))
+	}
+	actual := actions[1].(ktesting.CreateAction).GetObject().(*v1beta1.TaskRun)
+	taskRunParent, ok := tracing.SpanContextFromTraceParent(actual.Annotations[tracing.TraceParentAnnotation])
+	if !ok {
+		t.Fatalf("Expected the TaskRun to have a trace context, got annotations %v", actual.Annotations)
+	}
+
+	spans := map[string]*trace.SpanData{}
+	for _, s := range recorder.spans {
+		if s.TraceID != parent.TraceID {
+			t.Errorf("Expected span %q to be part of trace %s, got %s", s.Name, parent.TraceID, s.TraceID)
+		}
+		spans[s.Name] = s
+	}
+	for _, name := range []string{"PipelineRun.Reconcile", "ResolvePipelineRun", "CreateTaskRun"} {
+		if _, ok := spans[name]; !ok {
+			t.Fatalf("Expected a %q span, got %v", name, recorder.spans)
+		}
+	}
+	if got := spans["PipelineRun.Reconcile"].ParentSpanID; got != parent.SpanID {
+		t.Errorf("Expected the reconcile span to be a child of %s, got %s", parent.SpanID, got)
+	}
+	if got := spans["CreateTaskRun"].SpanID; got != taskRunParent.SpanID {
+		t.Errorf("Expected the TaskRun to be a child of the span creating it %s, got %s", got, taskRunParent.SpanID)
+	}
+}
+
 func TestReconcileWithConditionChecks(t *testing.T) {
 	// TestReconcileWithConditionChecks runs "Reconcile" on a PipelineRun that has a task with
 	// multiple conditions. It verifies that reconcile is successful, taskruns are created and
@@ -1941,6 +2433,8 @@ func TestReconcileWithFailingConditionChecks(t *testing.T) {
 
 	wantEvents := []string{
 		"Normal Started",
+		"Normal PipelineTaskStarted Started PipelineTask \"task-3\"",
+		"Normal PipelineTaskSkipped Skipped PipelineTask \"task-2\": Condition Checks failed",
 		"Normal Running Tasks Completed: 1 \\(Failed: 0, Cancelled 0\\), Incomplete: 1, Skipped: 1",
 	}
 	_, clients := prt.reconcileRun("foo", pipelineRunName, wantEvents, false)
@@ -2050,6 +2544,8 @@ func TestReconcileWithWhenExpressionsWithParameters(t *testing.T) {
 
 	wantEvents := []string{
 		"Normal Started",
+		"Normal PipelineTaskStarted Started PipelineTask \"hello-world-1\"",
+		"Normal PipelineTaskSkipped Skipped PipelineTask \"hello-world-2\": When Expressions evaluated to false",
 		"Normal Running Tasks Completed: 0 \\(Failed: 0, Cancelled 0\\), Incomplete: 1, Skipped: 1",
 	}
 	pipelineRun, clients := prt.reconcileRun("foo", prName, wantEvents, false)
@@ -2190,6 +2686,9 @@ func TestReconcileWithWhenExpressionsWithTaskResults(t *testing.T) {
 
 	wantEvents := []string{
 		"Normal Started",
+		"Normal PipelineTaskStarted Started PipelineTask \"b-task\"",
+		"Normal PipelineTaskSkipped Skipped PipelineTask \"c-task\": When Expressions evaluated to false",
+		"Normal PipelineTaskSkipped Skipped PipelineTask \"d-task\": Parent Tasks were skipped",
 		"Normal Running Tasks Completed: 1 \\(Failed: 0, Cancelled 0\\), Incomplete: 1, Skipped: 2",
 	}
 	pipelineRun, clients := prt.reconcileRun("foo", "test-pipeline-run-different-service-accs", wantEvents, false)
@@ -3856,6 +4355,7 @@ func TestReconcile_CloudEvents(t *testing.T) {
 
 	wantEvents := []string{
 		"Normal Started",
+		"Normal PipelineTaskStarted Started PipelineTask \"test-1\"",
 		"Normal Running Tasks Completed: 0",
 	}
 	reconciledRun, clients := prt.reconcileRun("foo", "test-pipelinerun", wantEvents, false)
@@ -3882,6 +4382,184 @@ func TestReconcile_CloudEvents(t *testing.T) {
 	if !(err == nil) {
 		t.Errorf(err.Error())
 	}
+
+	// The cloud events are sent in the background, and the outcome of their
+	// delivery is recorded in the status by the next reconcile
+	cloudevent.DeliveryTrackerFromContext(prt.TestAssets.Ctx).Wait()
+	reconciledRun, _ = prt.reconcileRun("foo", "test-pipelinerun", nil, false)
+	wantDeliveries := []string{
+		cloudevent.PipelineRunStartedEventV1.String(),
+		cloudevent.PipelineRunRunningEventV1.String(),
+	}
+	if len(reconciledRun.Status.CloudEvents) != len(wantDeliveries) {
+		t.Fatalf("Expected %d cloud event deliveries, got %v", len(wantDeliveries), reconciledRun.Status.CloudEvents)
+	}
+	for i, delivery := range reconciledRun.Status.CloudEvents {
+		if delivery.Type != wantDeliveries[i] || delivery.Target != "http://synk:8080" || delivery.Status.Condition != v1beta1.CloudEventConditionSent {
+			t.Errorf("Expected the %s cloud event to be delivered to the sink, got %v", wantDeliveries[i], delivery)
+		}
+	}
+}
+
+func TestReconcile_CloudEventsNamespaceSink(t *testing.T) {
+	names.TestingSeed()
+
+	prs := []*v1beta1.PipelineRun{
+		tb.PipelineRun("test-pipelinerun",
+			tb.PipelineRunNamespace("foo"),
+			tb.PipelineRunSelfLink("/pipeline/1234"),
+			tb.PipelineRunSpec("test-pipeline"),
+		),
+	}
+	ps := []*v1beta1.Pipeline{
+		tb.Pipeline("test-pipeline",
+			tb.PipelineNamespace("foo"),
+			tb.PipelineSpec(tb.PipelineTask("test-1", "test-task")),
+		),
+	}
+	ts := []*v1beta1.Task{
+		tb.Task("test-task", tb.TaskNamespace("foo"),
+			tb.TaskSpec(tb.Step("foo", tb.StepName("simple-step"))),
+		),
+	}
+	namespaces := []*corev1.Namespace{{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "foo",
+			Annotations: map[string]string{
+				cloudevent.SinkAnnotation:       "http://team-sink:8080",
+				cloudevent.EventTypesAnnotation: "dev.tekton.event.pipelinerun.running.v1",
+			},
+		},
+	}}
+
+	// The sink of the namespace overrides the default one
+	cms := []*corev1.ConfigMap{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: config.GetDefaultsConfigName(), Namespace: system.GetNamespace()},
+			Data: map[string]string{
+				"default-cloud-events-sink": "http://synk:8080",
+			},
+		},
+	}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+		Namespaces:   namespaces,
+		ConfigMaps:   cms,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	wantEvents := []string{
+		"Normal Started",
+		"Normal PipelineTaskStarted Started PipelineTask \"test-1\"",
+		"Normal Running Tasks Completed: 0",
+	}
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipelinerun", wantEvents, false)
+
+	// Only the event types configured on the namespace are sent, to its sink
+	wantCloudEvents := []string{
+		`(?s)dev.tekton.event.pipelinerun.running.v1.*test-pipelinerun`,
+	}
+	ceClient := clients.CloudEvents.(cloudevent.FakeClient)
+	if err := checkCloudEvents(t, &ceClient, "reconcile-namespace-sink", wantCloudEvents); err != nil {
+		t.Errorf(err.Error())
+	}
+	if len(reconciledRun.Status.CloudEvents) != 1 {
+		t.Fatalf("Expected one cloud event delivery, got %v", reconciledRun.Status.CloudEvents)
+	}
+	if got := reconciledRun.Status.CloudEvents[0].Target; got != "http://team-sink:8080" {
+		t.Errorf("Expected the cloud event to be sent to the sink of the namespace, got %q", got)
+	}
+}
+
+func TestReconcile_CloudEventsSkippedTask(t *testing.T) {
+	names.TestingSeed()
+
+	prs := []*v1beta1.PipelineRun{
+		tb.PipelineRun("test-pipelinerun",
+			tb.PipelineRunNamespace("foo"),
+			tb.PipelineRunSelfLink("/pipeline/1234"),
+			tb.PipelineRunSpec("test-pipeline"),
+		),
+	}
+	ps := []*v1beta1.Pipeline{
+		tb.Pipeline("test-pipeline",
+			tb.PipelineNamespace("foo"),
+			tb.PipelineSpec(
+				tb.PipelineTask("test-1", "test-task"),
+				tb.PipelineTask("test-2", "test-task",
+					tb.PipelineTaskWhenExpression("foo", selection.In, []string{"bar"}),
+				),
+			),
+		),
+	}
+	ts := []*v1beta1.Task{
+		tb.Task("test-task", tb.TaskNamespace("foo"),
+			tb.TaskSpec(tb.Step("foo", tb.StepName("simple-step"))),
+		),
+	}
+	cms := []*corev1.ConfigMap{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: config.GetDefaultsConfigName(), Namespace: system.GetNamespace()},
+			Data: map[string]string{
+				"default-cloud-events-sink": "http://synk:8080",
+			},
+		},
+	}
+
+	d := test.Data{
+		PipelineRuns: prs,
+		Pipelines:    ps,
+		Tasks:        ts,
+		ConfigMaps:   cms,
+	}
+	prt := NewPipelineRunTest(d, t)
+	defer prt.Cancel()
+
+	wantEvents := []string{
+		"Normal Started",
+		"Normal PipelineTaskStarted Started PipelineTask \"test-1\"",
+		"Normal PipelineTaskSkipped Skipped PipelineTask \"test-2\": When Expressions evaluated to false",
+		"Normal Running Tasks Completed: 0 \\(Failed: 0, Cancelled 0\\), Incomplete: 1, Skipped: 1",
+	}
+	reconciledRun, clients := prt.reconcileRun("foo", "test-pipelinerun", wantEvents, false)
+	if len(reconciledRun.Status.SkippedTasks) != 1 {
+		t.Fatalf("Expected one skipped task, got %v", reconciledRun.Status.SkippedTasks)
+	}
+
+	// The skipped event is sent while the run is reconciled, concurrently with
+	// the events about the condition of the run, so the order is not checked.
+	wantCloudEvents := []string{
+		`(?s)dev.tekton.event.pipelinerun.started.v1.*test-pipelinerun`,
+		`(?s)dev.tekton.event.pipelinetask.skipped.v1.*foo/test-pipelinerun/test-2.*"operator": "in"`,
+		`(?s)dev.tekton.event.pipelinerun.running.v1.*test-pipelinerun`,
+	}
+	ceClient := clients.CloudEvents.(cloudevent.FakeClient)
+	gotCloudEvents := []string{}
+	timer := time.NewTimer(1 * time.Second)
+	for len(gotCloudEvents) < len(wantCloudEvents) {
+		select {
+		case event := <-ceClient.Events:
+			gotCloudEvents = append(gotCloudEvents, event)
+		case <-timer.C:
+			t.Fatalf("Expected %d cloud events, got %d: %v", len(wantCloudEvents), len(gotCloudEvents), gotCloudEvents)
+		}
+	}
+	for _, wantCloudEvent := range wantCloudEvents {
+		found := false
+		for _, event := range gotCloudEvents {
+			if matching, _ := regexp.MatchString(wantCloudEvent, event); matching {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("Expected a cloud event matching %q, got %v", wantCloudEvent, gotCloudEvents)
+		}
+	}
 }
 
 // this test validates taskSpec metadata is embedded into task run
@@ -0,0 +1,126 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package pipelinerun
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/reconciler/pipelinerun/resources"
+	taskrunresources "github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	"k8s.io/apimachinery/pkg/api/equality"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+// reuseSuccessfulTaskRuns satisfies the tasks of a PipelineRun which is a rerun of a previous
+// PipelineRun with the TaskRuns which succeeded in the previous run, so that only the failed,
+// skipped and downstream tasks are executed again. Before the first TaskRun is created the
+// successful TaskRuns are taken from the status of the previous PipelineRun, and only the ones
+// which ran the same Task spec with the same params are reused. Afterwards they are part of the
+// status of the PipelineRun itself.
+func (c *Reconciler) reuseSuccessfulTaskRuns(ctx context.Context, pr *v1beta1.PipelineRun, facts *resources.PipelineRunFacts) error {
+	logger := logging.FromContext(ctx)
+	taskRunsStatus := pr.Status.TaskRuns
+	// Once the first TaskRun has been created, the TaskRuns to reuse have been chosen already
+	// and are part of the status of the PipelineRun.
+	matches := func(*resources.ResolvedPipelineRunTask, string, *v1beta1.TaskRunStatus) bool { return true }
+
+	if facts.State.IsBeforeFirstTaskRun() {
+		previous, err := c.pipelineRunLister.PipelineRuns(pr.Namespace).Get(pr.Spec.RerunOf)
+		if err != nil {
+			logger.Errorf("Failed to get previous PipelineRun %s of PipelineRun %s: %v", pr.Spec.RerunOf, pr.Name, err)
+			pr.Status.MarkFailed(ReasonCouldntGetPreviousPipelineRun,
+				"Error retrieving PipelineRun %s that PipelineRun %s/%s is a rerun of: %s",
+				pr.Spec.RerunOf, pr.Namespace, pr.Name, err)
+			return controller.NewPermanentError(err)
+		}
+		if !previous.IsDone() {
+			err := fmt.Errorf("previous PipelineRun %s has not completed yet", previous.Name)
+			pr.Status.MarkFailed(ReasonPreviousPipelineRunNotDone,
+				"PipelineRun %s/%s can't be a rerun of PipelineRun %s which has not completed yet",
+				pr.Namespace, pr.Name, previous.Name)
+			return controller.NewPermanentError(err)
+		}
+		taskRunsStatus = previous.Status.TaskRuns
+		matches = func(rprt *resources.ResolvedPipelineRunTask, taskRunName string, status *v1beta1.TaskRunStatus) bool {
+			if err := c.taskRunMatches(ctx, pr.Namespace, facts, rprt, taskRunName, status); err != nil {
+				logger.Infof("PipelineRun %s doesn't reuse TaskRun %s for task %s: %v", pr.Name, taskRunName, rprt.PipelineTask.Name, err)
+				return false
+			}
+			return true
+		}
+	}
+
+	if reused := facts.ReuseSuccessfulTaskRuns(pr.Namespace, taskRunsStatus, matches); len(reused) > 0 {
+		logger.Infof("PipelineRun %s reuses the TaskRuns of the successful tasks %v of PipelineRun %s", pr.Name, reused, pr.Spec.RerunOf)
+	}
+	return nil
+}
+
+// taskRunMatches returns an error if the TaskRun taskRunName of the previous PipelineRun, with
+// the status status, didn't run the same Task spec with the same params that rprt would run
+// with now. The Task spec is resolved the same way the TaskRun reconciler would resolve it.
+func (c *Reconciler) taskRunMatches(ctx context.Context, namespace string, facts *resources.PipelineRunFacts, rprt *resources.ResolvedPipelineRunTask, taskRunName string, status *v1beta1.TaskRunStatus) error {
+	if status.TaskSpec == nil {
+		return fmt.Errorf("the Task spec of TaskRun %s is unknown", taskRunName)
+	}
+	previous, err := c.taskRunLister.TaskRuns(namespace).Get(taskRunName)
+	if err != nil {
+		return err
+	}
+
+	// The params may refer to the results of the parents of the task, which have been reused.
+	target := *rprt
+	target.PipelineTask = rprt.PipelineTask.DeepCopy()
+	targets := resources.PipelineRunState{&target}
+	resolvedResultRefs, err := resources.ResolveResultRefs(facts.State, targets)
+	if err != nil {
+		return err
+	}
+	resources.ApplyTaskResults(targets, resolvedResultRefs)
+	if !equality.Semantic.DeepEqual(previous.Spec.Params, target.PipelineTask.Params) {
+		return fmt.Errorf("the params have changed")
+	}
+
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: taskRunName, Namespace: namespace},
+	}
+	if rprt.ResolvedTaskResources.TaskName != "" {
+		tr.Spec.TaskRef = &v1beta1.TaskRef{
+			Name: rprt.ResolvedTaskResources.TaskName,
+			Kind: rprt.ResolvedTaskResources.Kind,
+		}
+	} else {
+		tr.Spec.TaskSpec = rprt.ResolvedTaskResources.TaskSpec
+	}
+	resolver := &taskrunresources.LocalTaskRefResolver{
+		Namespace:    tr.Namespace,
+		Kind:         rprt.ResolvedTaskResources.Kind,
+		Tektonclient: c.PipelineClientSet,
+	}
+	_, taskSpec, err := taskrunresources.GetTaskData(ctx, tr, resolver.GetTask, resolver.GetStepAction)
+	if err != nil {
+		return err
+	}
+	if !equality.Semantic.DeepEqual(status.TaskSpec, taskSpec) {
+		return fmt.Errorf("the Task spec has changed")
+	}
+	return nil
+}
@@ -105,6 +105,15 @@ func (t ResolvedPipelineRunTask) IsFailure() bool {
 	return c.IsFalse() && retriesDone >= retries
 }
 
+// IsFailureAllowed returns true only if the taskrun itself has failed and the
+// PipelineTask is configured to continue executing the pipeline on error
+func (t ResolvedPipelineRunTask) IsFailureAllowed() bool {
+	if t.PipelineTask == nil || t.PipelineTask.OnError != v1beta1.PipelineTaskContinue {
+		return false
+	}
+	return t.IsFailure() && !t.IsCancelled()
+}
+
 // IsCancelled returns true only if the taskrun itself has cancelled
 func (t ResolvedPipelineRunTask) IsCancelled() bool {
 	if t.TaskRun == nil {
@@ -147,27 +156,53 @@ func (t *ResolvedPipelineRunTask) checkParentsDone(facts *PipelineRunFacts) bool
 	return true
 }
 
+// SkippingReason explains why a PipelineTask was skipped.
+type SkippingReason string
+
+const (
+	// WhenExpressionsSkip means the task was skipped because its When Expressions evaluated to false
+	WhenExpressionsSkip SkippingReason = "When Expressions evaluated to false"
+	// ConditionsSkip means the task was skipped because its Condition Checks failed
+	ConditionsSkip SkippingReason = "Condition Checks failed"
+	// ParentTasksSkip means the task was skipped because one of its parent tasks was skipped
+	ParentTasksSkip SkippingReason = "Parent Tasks were skipped"
+	// ParentTaskFailureSkip means the task was skipped because one of its parent tasks
+	// failed but was allowed to fail
+	ParentTaskFailureSkip SkippingReason = "Parent Tasks failed"
+	// StoppingSkip means the task was skipped because the PipelineRun is stopping
+	StoppingSkip SkippingReason = "PipelineRun was stopping"
+	// NotSkipped means the task was not skipped
+	NotSkipped SkippingReason = ""
+)
+
 // Skip returns true if a PipelineTask will not be run because
 // (1) its When Expressions evaluated to false
 // (2) its Condition Checks failed
 // (3) its parent task was skipped
-// (4) Pipeline is in stopping state (one of the PipelineTasks failed)
+// (4) its parent task failed but was allowed to fail (onError: continue)
+// (5) Pipeline is in stopping state (one of the PipelineTasks failed)
 // Note that this means Skip returns false if a conditionCheck is in progress
 func (t *ResolvedPipelineRunTask) Skip(facts *PipelineRunFacts) bool {
+	return t.SkipReason(facts) != NotSkipped
+}
+
+// SkipReason returns the reason why a PipelineTask will not be run, see Skip,
+// or NotSkipped if it will run.
+func (t *ResolvedPipelineRunTask) SkipReason(facts *PipelineRunFacts) SkippingReason {
 	// finally tasks are never skipped. If this is a final task, return false
 	if facts.isFinalTask(t.PipelineTask.Name) {
-		return false
+		return NotSkipped
 	}
 
 	// it already has TaskRun associated with it - PipelineTask not skipped
 	if t.IsStarted() {
-		return false
+		return NotSkipped
 	}
 
 	// Check if conditionChecks have failed, if so task is skipped
 	if len(t.ResolvedConditionChecks) > 0 {
 		if t.ResolvedConditionChecks.IsDone() && !t.ResolvedConditionChecks.IsSuccess() {
-			return true
+			return ConditionsSkip
 		}
 	}
 
@@ -176,7 +211,7 @@ func (t *ResolvedPipelineRunTask) Skip(facts *PipelineRunFacts) bool {
 		if len(t.PipelineTask.WhenExpressions) > 0 {
 			if !t.PipelineTask.WhenExpressions.HaveVariables() {
 				if !t.PipelineTask.WhenExpressions.AllowsExecution() {
-					return true
+					return WhenExpressionsSkip
 				}
 			}
 		}
@@ -184,19 +219,23 @@ func (t *ResolvedPipelineRunTask) Skip(facts *PipelineRunFacts) bool {
 
 	// Skip the PipelineTask if pipeline is in stopping state
 	if facts.IsStopping() {
-		return true
+		return StoppingSkip
 	}
 
 	stateMap := facts.State.ToMap()
-	// Recursively look at parent tasks to see if they have been skipped,
-	// if any of the parents have been skipped, skip as well
+	// Recursively look at parent tasks to see if they have been skipped or
+	// failed with onError: continue, if so, skip as well
 	node := facts.TasksGraph.Nodes[t.PipelineTask.Name]
 	for _, p := range node.Prev {
-		if stateMap[p.Task.HashKey()].Skip(facts) {
-			return true
+		parent := stateMap[p.Task.HashKey()]
+		if parent.IsFailureAllowed() {
+			return ParentTaskFailureSkip
+		}
+		if parent.Skip(facts) {
+			return ParentTasksSkip
 		}
 	}
-	return false
+	return NotSkipped
 }
 
 // GetTaskRun is a function that will retrieve the TaskRun name.
@@ -547,6 +547,46 @@ func DagFromState(state PipelineRunState) (*dag.Graph, error) {
 	return dag.Build(v1beta1.PipelineTaskList(pts))
 }
 
+func TestIsFailureAllowed(t *testing.T) {
+	continueTask := v1beta1.PipelineTask{
+		Name:    "mytask1",
+		TaskRef: &v1beta1.TaskRef{Name: "task"},
+		OnError: v1beta1.PipelineTaskContinue,
+	}
+	tcs := []struct {
+		name     string
+		rprt     ResolvedPipelineRunTask
+		expected bool
+	}{{
+		name:     "failed task without onError",
+		rprt:     ResolvedPipelineRunTask{PipelineTask: &pts[0], TaskRun: makeFailed(trs[0])},
+		expected: false,
+	}, {
+		name:     "failed task with onError continue",
+		rprt:     ResolvedPipelineRunTask{PipelineTask: &continueTask, TaskRun: makeFailed(trs[0])},
+		expected: true,
+	}, {
+		name:     "successful task with onError continue",
+		rprt:     ResolvedPipelineRunTask{PipelineTask: &continueTask, TaskRun: makeSucceeded(trs[0])},
+		expected: false,
+	}, {
+		name:     "cancelled task with onError continue",
+		rprt:     ResolvedPipelineRunTask{PipelineTask: &continueTask, TaskRun: withCancelled(makeFailed(trs[0]))},
+		expected: false,
+	}, {
+		name:     "task with onError continue not started",
+		rprt:     ResolvedPipelineRunTask{PipelineTask: &continueTask},
+		expected: false,
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.rprt.IsFailureAllowed(); got != tc.expected {
+				t.Errorf("expected IsFailureAllowed to be %t but got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
 func TestIsSkipped(t *testing.T) {
 
 	tcs := []struct {
@@ -857,6 +897,98 @@ func TestIsSkipped(t *testing.T) {
 	}
 }
 
+func TestSkipReason(t *testing.T) {
+	continueTask := pts[5]
+	continueTask.OnError = v1beta1.PipelineTaskContinue
+
+	tcs := []struct {
+		name     string
+		taskName string
+		state    PipelineRunState
+		expected SkippingReason
+	}{{
+		name:     "tasks-condition-passed",
+		taskName: "mytask1",
+		state: PipelineRunState{{
+			PipelineTask:            &pts[0],
+			TaskRunName:             "pipelinerun-conditionaltask",
+			ResolvedConditionChecks: successTaskConditionCheckState,
+		}},
+		expected: NotSkipped,
+	}, {
+		name:     "tasks-condition-failed",
+		taskName: "mytask1",
+		state: PipelineRunState{{
+			PipelineTask:            &pts[0],
+			TaskRunName:             "pipelinerun-conditionaltask",
+			ResolvedConditionChecks: failedTaskConditionCheckState,
+		}},
+		expected: ConditionsSkip,
+	}, {
+		name:     "tasks-when-expression-failed",
+		taskName: "mytask11",
+		state: PipelineRunState{{
+			PipelineTask: &pts[10],
+			TaskRunName:  "pipelinerun-guardedtask",
+		}},
+		expected: WhenExpressionsSkip,
+	}, {
+		name:     "tasks-parent-condition-failed",
+		taskName: "mytask7",
+		state: PipelineRunState{{
+			PipelineTask:            &pts[5],
+			TaskRunName:             "pipelinerun-conditionaltask",
+			ResolvedConditionChecks: failedTaskConditionCheckState,
+		}, {
+			PipelineTask: &pts[6],
+		}},
+		expected: ParentTasksSkip,
+	}, {
+		name:     "tasks-parent-failed",
+		taskName: "mytask7",
+		state: PipelineRunState{{
+			PipelineTask: &pts[5],
+			TaskRunName:  "pipelinerun-mytask1",
+			TaskRun:      makeFailed(trs[0]),
+		}, {
+			PipelineTask: &pts[6],
+		}},
+		expected: StoppingSkip,
+	}, {
+		name:     "tasks-parent-failure-allowed",
+		taskName: "mytask7",
+		state: PipelineRunState{{
+			PipelineTask: &continueTask,
+			TaskRunName:  "pipelinerun-mytask1",
+			TaskRun:      makeFailed(trs[0]),
+		}, {
+			PipelineTask: &pts[6],
+		}},
+		expected: ParentTaskFailureSkip,
+	}}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := DagFromState(tc.state)
+			if err != nil {
+				t.Fatalf("Could not get a dag from the TC state %#v: %v", tc.state, err)
+			}
+			rprt := tc.state.ToMap()[tc.taskName]
+			if rprt == nil {
+				t.Fatalf("Could not get task %s from the state: %v", tc.taskName, tc.state)
+			}
+			facts := PipelineRunFacts{
+				State:           tc.state,
+				TasksGraph:      d,
+				FinalTasksGraph: &dag.Graph{},
+			}
+			if d := cmp.Diff(tc.expected, rprt.SkipReason(&facts)); d != "" {
+				t.Errorf("Didn't get expected skipping reason %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
 func getExpectedMessage(status corev1.ConditionStatus, successful, incomplete, skipped, failed, cancelled int) string {
 	if status == corev1.ConditionFalse || status == corev1.ConditionTrue {
 		return fmt.Sprintf("Tasks Completed: %d (Failed: %d, Cancelled %d), Skipped: %d",
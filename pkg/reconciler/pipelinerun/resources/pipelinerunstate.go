@@ -24,6 +24,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/reconciler/pipeline/dag"
 	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"knative.dev/pkg/apis"
 )
@@ -135,14 +136,15 @@ func (state PipelineRunState) getNextTasks(candidateTasks sets.String) []*Resolv
 }
 
 // IsStopping returns true if the PipelineRun won't be scheduling any new Task because
-// at least one task already failed or was cancelled in the specified dag
+// at least one task already failed or was cancelled in the specified dag.
+// Failures of tasks which are allowed to fail (onError: continue) do not stop the PipelineRun.
 func (facts *PipelineRunFacts) IsStopping() bool {
 	for _, t := range facts.State {
 		if facts.isDAGTask(t.PipelineTask.Name) {
 			if t.IsCancelled() {
 				return true
 			}
-			if t.IsFailure() {
+			if t.IsFailure() && !t.IsFailureAllowed() {
 				return true
 			}
 		}
@@ -187,6 +189,16 @@ func (facts *PipelineRunFacts) GetFinalTasks() PipelineRunState {
 	return tasks
 }
 
+// IsFinallyStarted returns true if a TaskRun was created for any of the final tasks
+func (facts *PipelineRunFacts) IsFinallyStarted() bool {
+	for _, t := range facts.State {
+		if facts.isFinalTask(t.PipelineTask.Name) && t.TaskRun != nil {
+			return true
+		}
+	}
+	return false
+}
+
 // GetPipelineConditionStatus will return the Condition that the PipelineRun prName should be
 // updated with, based on the status of the TaskRuns in state.
 func (facts *PipelineRunFacts) GetPipelineConditionStatus(pr *v1beta1.PipelineRun, logger *zap.SugaredLogger) *apis.Condition {
@@ -208,6 +220,7 @@ func (facts *PipelineRunFacts) GetPipelineConditionStatus(pr *v1beta1.PipelineRu
 	withStatusTasks := []string{}
 	skipTasks := []v1beta1.SkippedTask{}
 	failedTasks := int(0)
+	ignoredFailedTasks := int(0)
 	cancelledTasks := int(0)
 	reason := v1beta1.PipelineRunReasonSuccessful.String()
 
@@ -221,6 +234,7 @@ func (facts *PipelineRunFacts) GetPipelineConditionStatus(pr *v1beta1.PipelineRu
 	//
 	// - All successful: ReasonSucceeded
 	// - Some successful, some skipped: ReasonCompleted
+	// - Some failed with onError: continue, none failed otherwise: ReasonCompleted
 	// - Some cancelled, none failed: ReasonCancelled
 	// - At least one failed: ReasonFailed
 	for _, rprt := range facts.State {
@@ -241,6 +255,13 @@ func (facts *PipelineRunFacts) GetPipelineConditionStatus(pr *v1beta1.PipelineRu
 			if reason != v1beta1.PipelineRunReasonFailed.String() {
 				reason = v1beta1.PipelineRunReasonCancelled.String()
 			}
+		case rprt.IsFailureAllowed():
+			withStatusTasks = append(withStatusTasks, rprt.PipelineTask.Name)
+			ignoredFailedTasks++
+			// At least one failure is ignored and no other failure yet, mark as completed
+			if reason == v1beta1.PipelineRunReasonSuccessful.String() {
+				reason = v1beta1.PipelineRunReasonCompleted.String()
+			}
 		case rprt.IsFailure():
 			withStatusTasks = append(withStatusTasks, rprt.PipelineTask.Name)
 			failedTasks++
@@ -259,7 +280,7 @@ func (facts *PipelineRunFacts) GetPipelineConditionStatus(pr *v1beta1.PipelineRu
 			Status: status,
 			Reason: reason,
 			Message: fmt.Sprintf("Tasks Completed: %d (Failed: %d, Cancelled %d), Skipped: %d",
-				len(allTasks)-len(skipTasks), failedTasks, cancelledTasks, len(skipTasks)),
+				len(allTasks)-len(skipTasks), failedTasks, cancelledTasks, len(skipTasks)) + ignoredFailuresMessage(ignoredFailedTasks),
 		}
 	}
 
@@ -277,8 +298,17 @@ func (facts *PipelineRunFacts) GetPipelineConditionStatus(pr *v1beta1.PipelineRu
 		Status: corev1.ConditionUnknown,
 		Reason: reason,
 		Message: fmt.Sprintf("Tasks Completed: %d (Failed: %d, Cancelled %d), Incomplete: %d, Skipped: %d",
-			len(withStatusTasks)-len(skipTasks), failedTasks, cancelledTasks, len(allTasks)-len(withStatusTasks), len(skipTasks)),
+			len(withStatusTasks)-len(skipTasks), failedTasks, cancelledTasks, len(allTasks)-len(withStatusTasks), len(skipTasks)) + ignoredFailuresMessage(ignoredFailedTasks),
+	}
+}
+
+// ignoredFailuresMessage returns the suffix of the PipelineRun condition message reporting
+// the number of tasks which failed but were allowed to fail (onError: continue)
+func ignoredFailuresMessage(ignoredFailedTasks int) string {
+	if ignoredFailedTasks == 0 {
+		return ""
 	}
+	return fmt.Sprintf(", Failures Ignored: %d", ignoredFailedTasks)
 }
 
 func (facts *PipelineRunFacts) GetSkippedTasks() []v1beta1.SkippedTask {
@@ -295,13 +325,76 @@ func (facts *PipelineRunFacts) GetSkippedTasks() []v1beta1.SkippedTask {
 	return skipped
 }
 
+// ReuseSuccessfulTaskRuns satisfies the DAG tasks which don't have a TaskRun yet with the
+// successful TaskRuns found in taskRunsStatus, usually the status of the previous PipelineRun
+// a PipelineRun is a rerun of. A TaskRun is only reused if matches accepts it for the task and
+// if none of the parents of the task is going to run again, since the task might consume their
+// results. The reused TaskRuns are built from their status so that their results can be resolved
+// even if the TaskRuns themselves have been deleted since. Final tasks are never reused.
+// It returns the names of the PipelineTasks which were reused.
+func (facts *PipelineRunFacts) ReuseSuccessfulTaskRuns(namespace string, taskRunsStatus map[string]*v1beta1.PipelineRunTaskRunStatus, matches func(rprt *ResolvedPipelineRunTask, taskRunName string, status *v1beta1.TaskRunStatus) bool) []string {
+	successful := map[string]string{}
+	for taskRunName, prtrs := range taskRunsStatus {
+		if prtrs == nil || prtrs.Status == nil {
+			continue
+		}
+		if prtrs.Status.GetCondition(apis.ConditionSucceeded).IsTrue() {
+			successful[prtrs.PipelineTaskName] = taskRunName
+		}
+	}
+
+	reused := []string{}
+	stateMap := facts.State.ToMap()
+	// The parents of a task have to be reused before the task itself can be, so keep going
+	// until no more tasks can be reused.
+	for progress := true; progress; {
+		progress = false
+		for _, t := range facts.State {
+			if t.TaskRun != nil || !facts.isDAGTask(t.PipelineTask.Name) {
+				continue
+			}
+			taskRunName, ok := successful[t.PipelineTask.Name]
+			if !ok || !facts.parentsSuccessful(stateMap, t.PipelineTask.Name) {
+				continue
+			}
+			status := taskRunsStatus[taskRunName].Status
+			if !matches(t, taskRunName, status) {
+				// Don't ask again, the task is going to run again.
+				delete(successful, t.PipelineTask.Name)
+				continue
+			}
+			t.TaskRunName = taskRunName
+			t.TaskRun = &v1beta1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{Name: taskRunName, Namespace: namespace},
+				Status:     *status.DeepCopy(),
+			}
+			reused = append(reused, t.PipelineTask.Name)
+			progress = true
+		}
+	}
+	return reused
+}
+
+// parentsSuccessful returns true if all the parents of the DAG task pipelineTaskName have a
+// TaskRun which succeeded
+func (facts *PipelineRunFacts) parentsSuccessful(stateMap map[string]*ResolvedPipelineRunTask, pipelineTaskName string) bool {
+	for _, parent := range facts.TasksGraph.Nodes[pipelineTaskName].Prev {
+		rprt, ok := stateMap[parent.Task.HashKey()]
+		if !ok || !rprt.IsSuccessful() {
+			return false
+		}
+	}
+	return true
+}
+
 // successfulOrSkippedTasks returns a list of the names of all of the PipelineTasks in state
-// which have successfully completed or skipped
+// which have successfully completed or skipped, including the ones which failed but were
+// allowed to fail (onError: continue)
 func (facts *PipelineRunFacts) successfulOrSkippedDAGTasks() []string {
 	tasks := []string{}
 	for _, t := range facts.State {
 		if facts.isDAGTask(t.PipelineTask.Name) {
-			if t.IsSuccessful() || t.Skip(facts) {
+			if t.IsSuccessful() || t.IsFailureAllowed() || t.Skip(facts) {
 				tasks = append(tasks, t.PipelineTask.Name)
 			}
 		}
@@ -644,6 +644,42 @@ func TestPipelineRunState_GetFinalTasks(t *testing.T) {
 	}
 }
 
+func TestPipelineRunFacts_IsFinallyStarted(t *testing.T) {
+	tcs := []struct {
+		name     string
+		state    PipelineRunState
+		expected bool
+	}{{
+		name:     "final task not started",
+		state:    oneFinishedState,
+		expected: false,
+	}, {
+		name:     "final task started",
+		state:    allFinishedState,
+		expected: true,
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			dagGraph, err := dag.Build(v1beta1.PipelineTaskList([]v1beta1.PipelineTask{pts[0]}))
+			if err != nil {
+				t.Fatalf("Unexpected error while buildig DAG for pipelineTasks: %v", err)
+			}
+			finalGraph, err := dag.Build(v1beta1.PipelineTaskList([]v1beta1.PipelineTask{pts[1]}))
+			if err != nil {
+				t.Fatalf("Unexpected error while buildig DAG for final pipelineTasks: %v", err)
+			}
+			facts := PipelineRunFacts{
+				State:           tc.state,
+				TasksGraph:      dagGraph,
+				FinalTasksGraph: finalGraph,
+			}
+			if got := facts.IsFinallyStarted(); got != tc.expected {
+				t.Errorf("Expected IsFinallyStarted to be %t, got %t", tc.expected, got)
+			}
+		})
+	}
+}
+
 func TestGetPipelineConditionStatus(t *testing.T) {
 
 	var taskRetriedState = PipelineRunState{{
@@ -906,6 +942,321 @@ func TestGetPipelineConditionStatus(t *testing.T) {
 	}
 }
 
+func TestGetPipelineConditionStatus_WithOnErrorContinue(t *testing.T) {
+	allowedFailureTask := v1beta1.PipelineTask{
+		Name:    "lint",
+		TaskRef: &v1beta1.TaskRef{Name: "task"},
+		OnError: v1beta1.PipelineTaskContinue,
+	}
+	independentTask := v1beta1.PipelineTask{
+		Name:    "build",
+		TaskRef: &v1beta1.TaskRef{Name: "task"},
+	}
+	dependentTask := v1beta1.PipelineTask{
+		Name:     "report",
+		TaskRef:  &v1beta1.TaskRef{Name: "task"},
+		RunAfter: []string{"lint"},
+	}
+
+	tcs := []struct {
+		name            string
+		state           PipelineRunState
+		expectedStatus  corev1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{{
+		name: "failure allowed with independent task still running",
+		state: PipelineRunState{{
+			PipelineTask: &allowedFailureTask,
+			TaskRunName:  "pipelinerun-lint",
+			TaskRun:      makeFailed(trs[0]),
+		}, {
+			PipelineTask: &independentTask,
+			TaskRunName:  "pipelinerun-build",
+			TaskRun:      makeStarted(trs[1]),
+		}, {
+			PipelineTask: &dependentTask,
+			TaskRunName:  "pipelinerun-report",
+		}},
+		expectedStatus:  corev1.ConditionUnknown,
+		expectedReason:  v1beta1.PipelineRunReasonRunning.String(),
+		expectedMessage: "Tasks Completed: 1 (Failed: 0, Cancelled 0), Incomplete: 1, Skipped: 1, Failures Ignored: 1",
+	}, {
+		name: "failure allowed with independent task successful",
+		state: PipelineRunState{{
+			PipelineTask: &allowedFailureTask,
+			TaskRunName:  "pipelinerun-lint",
+			TaskRun:      makeFailed(trs[0]),
+		}, {
+			PipelineTask: &independentTask,
+			TaskRunName:  "pipelinerun-build",
+			TaskRun:      makeSucceeded(trs[1]),
+		}, {
+			PipelineTask: &dependentTask,
+			TaskRunName:  "pipelinerun-report",
+		}},
+		expectedStatus:  corev1.ConditionTrue,
+		expectedReason:  v1beta1.PipelineRunReasonCompleted.String(),
+		expectedMessage: "Tasks Completed: 2 (Failed: 0, Cancelled 0), Skipped: 1, Failures Ignored: 1",
+	}, {
+		name: "failure allowed with independent task failed",
+		state: PipelineRunState{{
+			PipelineTask: &allowedFailureTask,
+			TaskRunName:  "pipelinerun-lint",
+			TaskRun:      makeFailed(trs[0]),
+		}, {
+			PipelineTask: &independentTask,
+			TaskRunName:  "pipelinerun-build",
+			TaskRun:      makeFailed(trs[1]),
+		}, {
+			PipelineTask: &dependentTask,
+			TaskRunName:  "pipelinerun-report",
+		}},
+		expectedStatus:  corev1.ConditionFalse,
+		expectedReason:  v1beta1.PipelineRunReasonFailed.String(),
+		expectedMessage: "Tasks Completed: 2 (Failed: 1, Cancelled 0), Skipped: 1, Failures Ignored: 1",
+	}}
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			pr := tb.PipelineRun("somepipelinerun")
+			d, err := DagFromState(tc.state)
+			if err != nil {
+				t.Fatalf("Unexpected error while buildig DAG for state %v: %v", tc.state, err)
+			}
+			facts := PipelineRunFacts{
+				State:           tc.state,
+				TasksGraph:      d,
+				FinalTasksGraph: &dag.Graph{},
+			}
+			c := facts.GetPipelineConditionStatus(pr, zap.NewNop().Sugar())
+			wantCondition := &apis.Condition{
+				Type:    apis.ConditionSucceeded,
+				Status:  tc.expectedStatus,
+				Reason:  tc.expectedReason,
+				Message: tc.expectedMessage,
+			}
+			if d := cmp.Diff(wantCondition, c); d != "" {
+				t.Fatalf("Mismatch in condition %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestPipelineRunFacts_DAGExecutionQueue_WithOnErrorContinue(t *testing.T) {
+	state := PipelineRunState{{
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:    "lint",
+			TaskRef: &v1beta1.TaskRef{Name: "task"},
+			OnError: v1beta1.PipelineTaskContinue,
+		},
+		TaskRunName: "pipelinerun-lint",
+		TaskRun:     makeFailed(trs[0]),
+	}, {
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:    "build",
+			TaskRef: &v1beta1.TaskRef{Name: "task"},
+		},
+		TaskRunName: "pipelinerun-build",
+	}, {
+		PipelineTask: &v1beta1.PipelineTask{
+			Name:     "report",
+			TaskRef:  &v1beta1.TaskRef{Name: "task"},
+			RunAfter: []string{"lint"},
+		},
+		TaskRunName: "pipelinerun-report",
+	}}
+	d, err := DagFromState(state)
+	if err != nil {
+		t.Fatalf("Unexpected error while buildig DAG for state %v: %v", state, err)
+	}
+	facts := PipelineRunFacts{
+		State:           state,
+		TasksGraph:      d,
+		FinalTasksGraph: &dag.Graph{},
+	}
+	if facts.IsStopping() {
+		t.Error("Expected PipelineRun not to be stopping when the failed task is allowed to fail")
+	}
+	queue, err := facts.DAGExecutionQueue()
+	if err != nil {
+		t.Fatalf("Unexpected error getting DAG execution queue: %v", err)
+	}
+	var names []string
+	for _, rprt := range queue {
+		if !rprt.Skip(&facts) {
+			names = append(names, rprt.PipelineTask.Name)
+		}
+	}
+	if d := cmp.Diff([]string{"build"}, names); d != "" {
+		t.Errorf("Unexpected tasks scheduled %s", diff.PrintWantGot(d))
+	}
+	if d := cmp.Diff([]v1beta1.SkippedTask{{Name: "report"}}, facts.GetSkippedTasks()); d != "" {
+		t.Errorf("Unexpected skipped tasks %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineRunFacts_ReuseSuccessfulTaskRuns(t *testing.T) {
+	previousStatus := map[string]*v1beta1.PipelineRunTaskRunStatus{
+		"previous-lint": {
+			PipelineTaskName: "lint",
+			Status: &v1beta1.TaskRunStatus{
+				Status: duckv1beta1.Status{
+					Conditions: duckv1beta1.Conditions{{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue}},
+				},
+				TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+					TaskRunResults: []v1beta1.TaskRunResult{{Name: "report", Value: "clean"}},
+				},
+			},
+		},
+		"previous-build": {
+			PipelineTaskName: "build",
+			Status: &v1beta1.TaskRunStatus{
+				Status: duckv1beta1.Status{
+					Conditions: duckv1beta1.Conditions{{Type: apis.ConditionSucceeded, Status: corev1.ConditionFalse}},
+				},
+			},
+		},
+		"previous-notify": {
+			PipelineTaskName: "notify",
+			Status: &v1beta1.TaskRunStatus{
+				Status: duckv1beta1.Status{
+					Conditions: duckv1beta1.Conditions{{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue}},
+				},
+			},
+		},
+	}
+	state := PipelineRunState{{
+		PipelineTask: &v1beta1.PipelineTask{Name: "lint", TaskRef: &v1beta1.TaskRef{Name: "task"}},
+		TaskRunName:  "pipelinerun-lint",
+	}, {
+		PipelineTask: &v1beta1.PipelineTask{Name: "build", TaskRef: &v1beta1.TaskRef{Name: "task"}, RunAfter: []string{"lint"}},
+		TaskRunName:  "pipelinerun-build",
+	}, {
+		PipelineTask: &v1beta1.PipelineTask{Name: "notify", TaskRef: &v1beta1.TaskRef{Name: "task"}},
+		TaskRunName:  "pipelinerun-notify",
+	}}
+	d, err := dag.Build(v1beta1.PipelineTaskList{*state[0].PipelineTask, *state[1].PipelineTask})
+	if err != nil {
+		t.Fatalf("Unexpected error while buildig DAG for state %v: %v", state, err)
+	}
+	df, err := dag.Build(v1beta1.PipelineTaskList{*state[2].PipelineTask})
+	if err != nil {
+		t.Fatalf("Unexpected error while buildig final tasks DAG for state %v: %v", state, err)
+	}
+	facts := PipelineRunFacts{
+		State:           state,
+		TasksGraph:      d,
+		FinalTasksGraph: df,
+	}
+
+	matchesAll := func(*ResolvedPipelineRunTask, string, *v1beta1.TaskRunStatus) bool { return true }
+	reused := facts.ReuseSuccessfulTaskRuns("foo", previousStatus, matchesAll)
+	if d := cmp.Diff([]string{"lint"}, reused); d != "" {
+		t.Errorf("Unexpected reused tasks %s", diff.PrintWantGot(d))
+	}
+	if state[0].TaskRunName != "previous-lint" || state[0].TaskRun == nil {
+		t.Fatalf("Expected task lint to reuse TaskRun previous-lint but got %q, %v", state[0].TaskRunName, state[0].TaskRun)
+	}
+	if d := cmp.Diff(previousStatus["previous-lint"].Status.TaskRunResults, state[0].TaskRun.Status.TaskRunResults); d != "" {
+		t.Errorf("Unexpected results of the reused TaskRun %s", diff.PrintWantGot(d))
+	}
+	if state[1].TaskRun != nil || state[1].TaskRunName != "pipelinerun-build" {
+		t.Errorf("Expected failed task build not to be reused but got %q", state[1].TaskRunName)
+	}
+	if state[2].TaskRun != nil || state[2].TaskRunName != "pipelinerun-notify" {
+		t.Errorf("Expected final task notify not to be reused but got %q", state[2].TaskRunName)
+	}
+
+	queue, err := facts.DAGExecutionQueue()
+	if err != nil {
+		t.Fatalf("Unexpected error getting DAG execution queue: %v", err)
+	}
+	var names []string
+	for _, rprt := range queue {
+		names = append(names, rprt.PipelineTask.Name)
+	}
+	if d := cmp.Diff([]string{"build"}, names); d != "" {
+		t.Errorf("Unexpected tasks scheduled %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestPipelineRunFacts_ReuseSuccessfulTaskRuns_Mismatch(t *testing.T) {
+	succeeded := &v1beta1.TaskRunStatus{
+		Status: duckv1beta1.Status{
+			Conditions: duckv1beta1.Conditions{{Type: apis.ConditionSucceeded, Status: corev1.ConditionTrue}},
+		},
+	}
+	previousStatus := map[string]*v1beta1.PipelineRunTaskRunStatus{
+		"previous-lint":  {PipelineTaskName: "lint", Status: succeeded},
+		"previous-build": {PipelineTaskName: "build", Status: succeeded},
+		"previous-test":  {PipelineTaskName: "test", Status: succeeded},
+	}
+	// build runs after lint, test is independent of both.
+	newState := func() PipelineRunState {
+		return PipelineRunState{{
+			PipelineTask: &v1beta1.PipelineTask{Name: "build", TaskRef: &v1beta1.TaskRef{Name: "task"}, RunAfter: []string{"lint"}},
+			TaskRunName:  "pipelinerun-build",
+		}, {
+			PipelineTask: &v1beta1.PipelineTask{Name: "lint", TaskRef: &v1beta1.TaskRef{Name: "task"}},
+			TaskRunName:  "pipelinerun-lint",
+		}, {
+			PipelineTask: &v1beta1.PipelineTask{Name: "test", TaskRef: &v1beta1.TaskRef{Name: "task"}},
+			TaskRunName:  "pipelinerun-test",
+		}}
+	}
+	state := newState()
+	d, err := dag.Build(v1beta1.PipelineTaskList{*state[0].PipelineTask, *state[1].PipelineTask, *state[2].PipelineTask})
+	if err != nil {
+		t.Fatalf("Unexpected error while buildig DAG for state %v: %v", state, err)
+	}
+
+	for _, tc := range []struct {
+		name       string
+		mismatched string
+		want       []string
+	}{{
+		name: "all tasks match",
+		want: []string{"lint", "test", "build"},
+	}, {
+		name:       "mismatched task is not reused",
+		mismatched: "test",
+		want:       []string{"lint", "build"},
+	}, {
+		name:       "children of a mismatched task are not reused",
+		mismatched: "lint",
+		want:       []string{"test"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			state := newState()
+			facts := PipelineRunFacts{
+				State:           state,
+				TasksGraph:      d,
+				FinalTasksGraph: &dag.Graph{},
+			}
+			var asked []string
+			matches := func(rprt *ResolvedPipelineRunTask, taskRunName string, _ *v1beta1.TaskRunStatus) bool {
+				asked = append(asked, taskRunName)
+				return rprt.PipelineTask.Name != tc.mismatched
+			}
+
+			reused := facts.ReuseSuccessfulTaskRuns("foo", previousStatus, matches)
+			if d := cmp.Diff(tc.want, reused); d != "" {
+				t.Errorf("Unexpected reused tasks %s", diff.PrintWantGot(d))
+			}
+			for _, taskRunName := range asked {
+				if taskRunName == "previous-build" && tc.mismatched == "lint" {
+					t.Errorf("Expected the TaskRun of build not to be matched once its parent lint runs again")
+				}
+			}
+			for _, rprt := range state {
+				if rprt.PipelineTask.Name == tc.mismatched && (rprt.TaskRun != nil || rprt.TaskRunName != "pipelinerun-"+tc.mismatched) {
+					t.Errorf("Expected mismatched task %s not to be reused but got %q", tc.mismatched, rprt.TaskRunName)
+				}
+			}
+		})
+	}
+}
+
 func TestGetPipelineConditionStatus_WithFinalTasks(t *testing.T) {
 
 	// pipeline state with one DAG successful, one final task failed
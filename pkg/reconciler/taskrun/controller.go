@@ -32,8 +32,10 @@ import (
 	cloudeventclient "github.com/tektoncd/pipeline/pkg/reconciler/events/cloudevent"
 	"github.com/tektoncd/pipeline/pkg/reconciler/volumeclaim"
 	"github.com/tektoncd/pipeline/pkg/timeout"
+	"github.com/tektoncd/pipeline/pkg/tracing"
 	"k8s.io/client-go/tools/cache"
 	kubeclient "knative.dev/pkg/client/injection/kube/client"
+	namespaceinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/namespace"
 	podinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/pod"
 	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/controller"
@@ -52,7 +54,9 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 		clusterTaskInformer := clustertaskinformer.Get(ctx)
 		podInformer := podinformer.Get(ctx)
 		resourceInformer := resourceinformer.Get(ctx)
+		namespaceInformer := namespaceinformer.Get(ctx)
 		timeoutHandler := timeout.NewHandler(ctx.Done(), logger)
+		cloudEventDeliveries := cloudeventclient.DeliveryTrackerFromContext(ctx)
 		metrics, err := NewRecorder()
 		if err != nil {
 			logger.Errorf("Failed to create taskrun metrics recorder %v", err)
@@ -64,22 +68,26 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 		}
 
 		c := &Reconciler{
-			KubeClientSet:     kubeclientset,
-			PipelineClientSet: pipelineclientset,
-			Images:            images,
-			taskRunLister:     taskRunInformer.Lister(),
-			taskLister:        taskInformer.Lister(),
-			clusterTaskLister: clusterTaskInformer.Lister(),
-			resourceLister:    resourceInformer.Lister(),
-			timeoutHandler:    timeoutHandler,
-			cloudEventClient:  cloudeventclient.Get(ctx),
-			metrics:           metrics,
-			entrypointCache:   entrypointCache,
-			pvcHandler:        volumeclaim.NewPVCHandler(kubeclientset, logger),
+			KubeClientSet:        kubeclientset,
+			PipelineClientSet:    pipelineclientset,
+			Images:               images,
+			taskRunLister:        taskRunInformer.Lister(),
+			taskLister:           taskInformer.Lister(),
+			clusterTaskLister:    clusterTaskInformer.Lister(),
+			resourceLister:       resourceInformer.Lister(),
+			namespaceLister:      namespaceInformer.Lister(),
+			timeoutHandler:       timeoutHandler,
+			cloudEventClient:     cloudeventclient.Get(ctx),
+			cloudEventDeliveries: cloudEventDeliveries,
+			metrics:              metrics,
+			entrypointCache:      entrypointCache,
+			pvcHandler:           volumeclaim.NewPVCHandler(kubeclientset, logger),
 		}
 		impl := taskrunreconciler.NewImpl(ctx, c, func(impl *controller.Impl) controller.Options {
 			configStore := config.NewStore(logger.Named("config-store"))
 			configStore.WatchConfigs(cmw)
+			tracing.WatchConfig(cmw, logger)
+			metrics.WatchConfig(cmw, logger)
 
 			return controller.Options{
 				AgentName:   pipeline.TaskRunControllerName,
@@ -88,6 +96,7 @@ func NewController(namespace string, images pipeline.Images) func(context.Contex
 		})
 
 		timeoutHandler.SetCallbackFunc(impl.EnqueueKey)
+		cloudEventDeliveries.SetCallbackFunc(impl.EnqueueKeyAfter)
 		timeoutHandler.CheckTimeouts(ctx, namespace, kubeclientset, pipelineclientset)
 
 		logger.Info("Setting up event handlers")
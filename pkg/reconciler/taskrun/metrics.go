@@ -22,14 +22,17 @@ import (
 	"time"
 
 	"github.com/pkg/errors"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	listers "github.com/tektoncd/pipeline/pkg/client/listers/pipeline/v1beta1"
 	"go.opencensus.io/stats"
 	"go.opencensus.io/stats/view"
 	"go.opencensus.io/tag"
+	"go.uber.org/zap"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	"knative.dev/pkg/configmap"
 	"knative.dev/pkg/logging"
 	"knative.dev/pkg/metrics"
 )
@@ -62,6 +65,31 @@ var (
 	cloudEvents = stats.Int64("cloudevent_count",
 		"number of cloud events sent including retries",
 		stats.UnitDimensionless)
+
+	resourceQuotaWait = stats.Float64("taskrun_resource_quota_wait_seconds",
+		"The time taskruns spent queued waiting for a ResourceQuota in seconds",
+		stats.UnitDimensionless)
+	resourceQuotaWaitDistribution = view.Distribution(10, 30, 60, 300, 900, 1800, 3600, 5400, 10800, 21600, 43200, 86400)
+
+	stepDuration = stats.Float64("taskrun_step_duration_seconds",
+		"The execution time of the taskruns steps in seconds",
+		stats.UnitDimensionless)
+	stepDurationDistribution = view.Distribution(1, 5, 10, 30, 60, 300, 900, 1800, 3600, 5400, 10800, 21600, 43200, 86400)
+
+	podScheduled = stats.Float64("taskrun_pod_scheduled_seconds",
+		"The time from the taskruns creation to their pod being scheduled in seconds",
+		stats.UnitDimensionless)
+	podScheduledDistribution = view.Distribution(1, 5, 10, 30, 60, 300, 900, 1800, 3600)
+
+	firstStepStarted = stats.Float64("taskrun_first_step_started_seconds",
+		"The time from the taskruns pod being scheduled to their first step starting in seconds",
+		stats.UnitDimensionless)
+	firstStepStartedDistribution = view.Distribution(1, 5, 10, 30, 60, 300, 900, 1800, 3600)
+
+	resolutionWait = stats.Float64("taskrun_resolution_wait_seconds",
+		"The time from the taskruns creation to their task being resolved in seconds",
+		stats.UnitDimensionless)
+	resolutionWaitDistribution = view.Distribution(0.1, 0.5, 1, 5, 10, 30, 60, 300, 900)
 )
 
 type Recorder struct {
@@ -74,6 +102,11 @@ type Recorder struct {
 	pipeline    tag.Key
 	pipelineRun tag.Key
 	pod         tag.Key
+	step        tag.Key
+
+	// views are the views of the metrics, registered for the metrics
+	// configuration
+	views *config.MetricsViews
 
 	ReportingPeriod time.Duration
 }
@@ -130,44 +163,15 @@ func NewRecorder() (*Recorder, error) {
 	}
 	r.pod = pod
 
-	err = view.Register(
-		&view.View{
-			Description: trDuration.Description(),
-			Measure:     trDuration,
-			Aggregation: trDistribution,
-			TagKeys:     []tag.Key{r.task, r.taskRun, r.namespace, r.status},
-		},
-		&view.View{
-			Description: prTRDuration.Description(),
-			Measure:     prTRDuration,
-			Aggregation: prTRLatencyDistribution,
-			TagKeys:     []tag.Key{r.task, r.taskRun, r.namespace, r.status, r.pipeline, r.pipelineRun},
-		},
-		&view.View{
-			Description: trCount.Description(),
-			Measure:     trCount,
-			Aggregation: view.Count(),
-			TagKeys:     []tag.Key{r.status},
-		},
-		&view.View{
-			Description: runningTRsCount.Description(),
-			Measure:     runningTRsCount,
-			Aggregation: view.LastValue(),
-		},
-		&view.View{
-			Description: podLatency.Description(),
-			Measure:     podLatency,
-			Aggregation: view.LastValue(),
-			TagKeys:     []tag.Key{r.task, r.taskRun, r.namespace, r.pod},
-		},
-		&view.View{
-			Description: cloudEvents.Description(),
-			Measure:     cloudEvents,
-			Aggregation: view.Sum(),
-			TagKeys:     []tag.Key{r.task, r.taskRun, r.namespace, r.status, r.pipeline, r.pipelineRun},
-		},
-	)
+	step, err := tag.NewKey("step")
+	if err != nil {
+		return nil, err
+	}
+	r.step = step
 
+	r.views = config.NewMetricsViews(r.buildViews)
+	cfg, _ := config.NewMetricsFromMap(map[string]string{})
+	err = r.views.Register(cfg)
 	if err != nil {
 		r.initialized = false
 		return r, err
@@ -176,6 +180,116 @@ func NewRecorder() (*Recorder, error) {
 	return r, nil
 }
 
+// buildViews returns the views of the metrics, tagged at the level of the
+// given configuration and with the tags of the run labels.
+func (r *Recorder) buildViews(cfg *config.Metrics, labelTags []tag.Key) []*view.View {
+	// The tags identifying the TaskRuns, their pods and their PipelineRuns,
+	// depending on the level the metrics are aggregated at
+	var taskRunTags, podTags, pipelineRunTags []tag.Key
+	switch cfg.TaskRunLevel {
+	case config.TaskRunLevelAtTaskRun:
+		taskRunTags = []tag.Key{r.task, r.taskRun}
+		podTags = []tag.Key{r.pod}
+	case config.TaskRunLevelAtTask:
+		taskRunTags = []tag.Key{r.task}
+	}
+	switch cfg.PipelineRunLevel {
+	case config.PipelineRunLevelAtPipelineRun:
+		pipelineRunTags = []tag.Key{r.pipeline, r.pipelineRun}
+	case config.PipelineRunLevelAtPipeline:
+		pipelineRunTags = []tag.Key{r.pipeline}
+	}
+	taskTags := taskRunTags
+	if len(taskTags) > 1 {
+		taskTags = taskTags[:1]
+	}
+
+	return []*view.View{{
+		Description: trDuration.Description(),
+		Measure:     trDuration,
+		Aggregation: trDistribution,
+		TagKeys:     tagKeys(taskRunTags, []tag.Key{r.namespace, r.status}, labelTags),
+	}, {
+		Description: prTRDuration.Description(),
+		Measure:     prTRDuration,
+		Aggregation: prTRLatencyDistribution,
+		TagKeys:     tagKeys(taskRunTags, []tag.Key{r.namespace, r.status}, pipelineRunTags, labelTags),
+	}, {
+		Description: trCount.Description(),
+		Measure:     trCount,
+		Aggregation: view.Count(),
+		TagKeys:     tagKeys([]tag.Key{r.status}, labelTags),
+	}, {
+		Description: runningTRsCount.Description(),
+		Measure:     runningTRsCount,
+		Aggregation: view.LastValue(),
+	}, {
+		Description: podLatency.Description(),
+		Measure:     podLatency,
+		Aggregation: view.LastValue(),
+		TagKeys:     tagKeys(taskRunTags, []tag.Key{r.namespace}, podTags, labelTags),
+	}, {
+		Description: cloudEvents.Description(),
+		Measure:     cloudEvents,
+		Aggregation: view.Sum(),
+		TagKeys:     tagKeys(taskRunTags, []tag.Key{r.namespace, r.status}, pipelineRunTags, labelTags),
+	}, {
+		Description: resourceQuotaWait.Description(),
+		Measure:     resourceQuotaWait,
+		Aggregation: resourceQuotaWaitDistribution,
+		TagKeys:     tagKeys(taskRunTags, []tag.Key{r.namespace}, labelTags),
+	}, {
+		Description: stepDuration.Description(),
+		Measure:     stepDuration,
+		Aggregation: stepDurationDistribution,
+		TagKeys:     tagKeys(taskTags, []tag.Key{r.step, r.namespace}, labelTags),
+	}, {
+		Description: podScheduled.Description(),
+		Measure:     podScheduled,
+		Aggregation: podScheduledDistribution,
+		TagKeys:     tagKeys(taskRunTags, []tag.Key{r.namespace}, labelTags),
+	}, {
+		Description: firstStepStarted.Description(),
+		Measure:     firstStepStarted,
+		Aggregation: firstStepStartedDistribution,
+		TagKeys:     tagKeys(taskRunTags, []tag.Key{r.namespace}, labelTags),
+	}, {
+		Description: resolutionWait.Description(),
+		Measure:     resolutionWait,
+		Aggregation: resolutionWaitDistribution,
+		TagKeys:     tagKeys(taskRunTags, []tag.Key{r.namespace}, labelTags),
+	}}
+}
+
+// WatchConfig updates the tags of the metrics when the configuration of the
+// metrics in the config-observability ConfigMap changes. The data aggregated
+// so far is dropped when the tags change.
+func (r *Recorder) WatchConfig(cmw configmap.Watcher, logger *zap.SugaredLogger) {
+	config.WatchMetricsConfig(cmw, logger, r.updateConfig)
+}
+
+func (r *Recorder) updateConfig(cfg *config.Metrics) error {
+	if !r.initialized {
+		return errors.New("ignoring the metrics configuration, failed to initialize the metrics recorder")
+	}
+
+	return r.views.Register(cfg)
+}
+
+// runLabelsContext returns a context tagged with the values of the labels of
+// the run configured to be copied to the metrics.
+func (r *Recorder) runLabelsContext(labels map[string]string) context.Context {
+	return r.views.RunLabelsContext(labels)
+}
+
+func tagKeys(keys ...[]tag.Key) []tag.Key {
+	var all []tag.Key
+	for _, k := range keys {
+		all = append(all, k...)
+	}
+	return all
+}
+
 // DurationAndCount logs the duration of TaskRun execution and
 // count for number of TaskRuns succeed or failed
 // returns an error if its failed to log the metrics
@@ -201,7 +315,7 @@ func (r *Recorder) DurationAndCount(tr *v1beta1.TaskRun) error {
 
 	if ok, pipeline, pipelinerun := tr.IsPartOfPipeline(); ok {
 		ctx, err := tag.New(
-			context.Background(),
+			r.runLabelsContext(tr.Labels),
 			tag.Insert(r.task, taskName),
 			tag.Insert(r.taskRun, tr.Name),
 			tag.Insert(r.namespace, tr.Namespace),
@@ -220,7 +334,7 @@ func (r *Recorder) DurationAndCount(tr *v1beta1.TaskRun) error {
 	}
 
 	ctx, err := tag.New(
-		context.Background(),
+		r.runLabelsContext(tr.Labels),
 		tag.Insert(r.task, taskName),
 		tag.Insert(r.taskRun, tr.Name),
 		tag.Insert(r.namespace, tr.Namespace),
@@ -304,7 +418,7 @@ func (r *Recorder) RecordPodLatency(pod *corev1.Pod, tr *v1beta1.TaskRun) error
 	}
 
 	ctx, err := tag.New(
-		context.Background(),
+		r.runLabelsContext(tr.Labels),
 		tag.Insert(r.task, taskName),
 		tag.Insert(r.taskRun, tr.Name),
 		tag.Insert(r.namespace, tr.Namespace),
@@ -338,7 +452,7 @@ func (r *Recorder) CloudEvents(tr *v1beta1.TaskRun) error {
 
 	if ok, pipeline, pipelinerun := tr.IsPartOfPipeline(); ok {
 		ctx, err := tag.New(
-			context.Background(),
+			r.runLabelsContext(tr.Labels),
 			tag.Insert(r.task, taskName),
 			tag.Insert(r.taskRun, tr.Name),
 			tag.Insert(r.namespace, tr.Namespace),
@@ -355,7 +469,7 @@ func (r *Recorder) CloudEvents(tr *v1beta1.TaskRun) error {
 	}
 
 	ctx, err := tag.New(
-		context.Background(),
+		r.runLabelsContext(tr.Labels),
 		tag.Insert(r.task, taskName),
 		tag.Insert(r.taskRun, tr.Name),
 		tag.Insert(r.namespace, tr.Namespace),
@@ -370,6 +484,151 @@ func (r *Recorder) CloudEvents(tr *v1beta1.TaskRun) error {
 	return nil
 }
 
+// RecordResourceQuotaWait logs the time a TaskRun spent queued waiting for a
+// ResourceQuota to admit its pod. The wait ends when the pod is created, or
+// when the TaskRun completes without one.
+// returns an error if its failed to log the metrics
+func (r *Recorder) RecordResourceQuotaWait(pod *corev1.Pod, tr *v1beta1.TaskRun) error {
+	if !r.initialized {
+		return errors.New("ignoring the metrics recording for resource quota wait, failed to initialize the metrics recorder")
+	}
+
+	if tr.Status.QueuedTime == nil {
+		return nil
+	}
+
+	waitEnd := tr.Status.CompletionTime
+	if pod != nil && !pod.CreationTimestamp.IsZero() {
+		waitEnd = &pod.CreationTimestamp
+	}
+	if waitEnd == nil {
+		return errors.New("taskrun is still waiting for a resource quota")
+	}
+
+	wait := waitEnd.Sub(tr.Status.QueuedTime.Time)
+	taskName := "anonymous"
+	if tr.Spec.TaskRef != nil {
+		taskName = tr.Spec.TaskRef.Name
+	}
+
+	ctx, err := tag.New(
+		r.runLabelsContext(tr.Labels),
+		tag.Insert(r.task, taskName),
+		tag.Insert(r.taskRun, tr.Name),
+		tag.Insert(r.namespace, tr.Namespace),
+	)
+	if err != nil {
+		return err
+	}
+
+	metrics.Record(ctx, resourceQuotaWait.M(float64(wait/time.Second)))
+
+	return nil
+}
+
+// RecordStepDurations logs the execution time of each of the terminated steps
+// of the TaskRun. The start of a step is the time its entrypoint recorded in
+// the StartedAt internal result, so that the time spent waiting on the
+// previous steps is not included.
+// returns an error if its failed to log the metrics
+func (r *Recorder) RecordStepDurations(tr *v1beta1.TaskRun) error {
+	if !r.initialized {
+		return fmt.Errorf("ignoring the metrics recording for %s , failed to initialize the metrics recorder", tr.Name)
+	}
+
+	taskName := "anonymous"
+	if tr.Spec.TaskRef != nil {
+		taskName = tr.Spec.TaskRef.Name
+	}
+
+	for _, s := range tr.Status.Steps {
+		if s.Terminated == nil || s.Terminated.StartedAt.IsZero() || s.Terminated.FinishedAt.IsZero() {
+			continue
+		}
+		ctx, err := tag.New(
+			r.runLabelsContext(tr.Labels),
+			tag.Insert(r.task, taskName),
+			tag.Insert(r.step, s.Name),
+			tag.Insert(r.namespace, tr.Namespace),
+		)
+		if err != nil {
+			return err
+		}
+		duration := s.Terminated.FinishedAt.Sub(s.Terminated.StartedAt.Time)
+		metrics.Record(ctx, stepDuration.M(duration.Seconds()))
+	}
+
+	return nil
+}
+
+// RecordStartupLatency logs the time from the creation of the TaskRun to its
+// pod being scheduled, and from the pod being scheduled to the first step of
+// the TaskRun starting.
+// returns an error if its failed to log the metrics
+func (r *Recorder) RecordStartupLatency(pod *corev1.Pod, tr *v1beta1.TaskRun) error {
+	if !r.initialized {
+		return errors.New("ignoring the metrics recording for startup latency, failed to initialize the metrics recorder")
+	}
+
+	if pod == nil {
+		return errors.New("taskrun has no pod")
+	}
+	scheduledTime := getScheduledTime(pod)
+	if scheduledTime.IsZero() {
+		return errors.New("pod has never got scheduled")
+	}
+
+	taskName := "anonymous"
+	if tr.Spec.TaskRef != nil {
+		taskName = tr.Spec.TaskRef.Name
+	}
+
+	ctx, err := tag.New(
+		r.runLabelsContext(tr.Labels),
+		tag.Insert(r.task, taskName),
+		tag.Insert(r.taskRun, tr.Name),
+		tag.Insert(r.namespace, tr.Namespace),
+	)
+	if err != nil {
+		return err
+	}
+
+	metrics.Record(ctx, podScheduled.M(scheduledTime.Sub(tr.CreationTimestamp.Time).Seconds()))
+	if startedTime := getFirstStepStartedTime(tr); !startedTime.IsZero() {
+		metrics.Record(ctx, firstStepStarted.M(startedTime.Sub(scheduledTime.Time).Seconds()))
+	}
+
+	return nil
+}
+
+// RecordResolutionWait logs the time from the creation of the TaskRun to its
+// Task being resolved for the first time.
+// returns an error if its failed to log the metrics
+func (r *Recorder) RecordResolutionWait(tr *v1beta1.TaskRun, resolvedTime time.Time) error {
+	if !r.initialized {
+		return fmt.Errorf("ignoring the metrics recording for %s , failed to initialize the metrics recorder", tr.Name)
+	}
+
+	taskName := "anonymous"
+	if tr.Spec.TaskRef != nil {
+		taskName = tr.Spec.TaskRef.Name
+	}
+
+	ctx, err := tag.New(
+		r.runLabelsContext(tr.Labels),
+		tag.Insert(r.task, taskName),
+		tag.Insert(r.taskRun, tr.Name),
+		tag.Insert(r.namespace, tr.Namespace),
+	)
+	if err != nil {
+		return err
+	}
+
+	metrics.Record(ctx, resolutionWait.M(resolvedTime.Sub(tr.CreationTimestamp.Time).Seconds()))
+
+	return nil
+}
+
 func sentCloudEvents(tr *v1beta1.TaskRun) int64 {
 	var sent int64
 	for _, event := range tr.Status.CloudEvents {
@@ -380,6 +639,23 @@ func sentCloudEvents(tr *v1beta1.TaskRun) int64 {
 	return sent
 }
 
+func getFirstStepStartedTime(tr *v1beta1.TaskRun) metav1.Time {
+	var started metav1.Time
+	for _, s := range tr.Status.Steps {
+		var stepStarted metav1.Time
+		switch {
+		case s.Terminated != nil:
+			stepStarted = s.Terminated.StartedAt
+		case s.Running != nil:
+			stepStarted = s.Running.StartedAt
+		}
+		if !stepStarted.IsZero() && (started.IsZero() || stepStarted.Before(&started)) {
+			started = stepStarted
+		}
+	}
+	return started
+}
+
 func getScheduledTime(pod *corev1.Pod) metav1.Time {
 	for _, c := range pod.Status.Conditions {
 		if c.Type == corev1.PodScheduled {
@@ -21,6 +21,7 @@ import (
 	"time"
 
 	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	faketaskruninformer "github.com/tektoncd/pipeline/pkg/client/injection/informers/pipeline/v1beta1/taskrun/fake"
@@ -54,6 +55,18 @@ func TestUninitializedMetrics(t *testing.T) {
 	if err := metrics.CloudEvents(&v1beta1.TaskRun{}); err == nil {
 		t.Error("Cloud Events recording expected to return error but got nil")
 	}
+	if err := metrics.RecordResourceQuotaWait(nil, &v1beta1.TaskRun{}); err == nil {
+		t.Error("Resource Quota Wait recording expected to return error but got nil")
+	}
+	if err := metrics.RecordStepDurations(&v1beta1.TaskRun{}); err == nil {
+		t.Error("Step Durations recording expected to return error but got nil")
+	}
+	if err := metrics.RecordStartupLatency(nil, &v1beta1.TaskRun{}); err == nil {
+		t.Error("Startup Latency recording expected to return error but got nil")
+	}
+	if err := metrics.RecordResolutionWait(&v1beta1.TaskRun{}, time.Now()); err == nil {
+		t.Error("Resolution Wait recording expected to return error but got nil")
+	}
 }
 
 func TestRecordTaskRunDurationCount(t *testing.T) {
@@ -227,6 +240,91 @@ func TestRecordTaskRunDurationCount(t *testing.T) {
 	}
 }
 
+func TestRecordTaskRunDurationCountWithMetricsConfig(t *testing.T) {
+	taskRun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "taskrun-1",
+			Namespace: "ns",
+			Labels: map[string]string{
+				pipeline.GroupName + pipeline.PipelineLabelKey:    "pipeline-1",
+				pipeline.GroupName + pipeline.PipelineRunLabelKey: "pipelinerun-1",
+				"example.com/team": "team-1",
+			},
+		},
+		Spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "task-1"},
+		},
+		Status: v1beta1.TaskRunStatus{
+			Status: duckv1beta1.Status{
+				Conditions: duckv1beta1.Conditions{{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionTrue,
+				}},
+			},
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				StartTime:      &startTime,
+				CompletionTime: &completionTime,
+			},
+		},
+	}
+	for _, c := range []struct {
+		name              string
+		cfg               *config.Metrics
+		expectedTags      map[string]string
+		expectedCountTags map[string]string
+	}{{
+		name: "at task and pipeline level",
+		cfg: &config.Metrics{
+			TaskRunLevel:     config.TaskRunLevelAtTask,
+			PipelineRunLevel: config.PipelineRunLevelAtPipeline,
+		},
+		expectedTags: map[string]string{
+			"pipeline":  "pipeline-1",
+			"task":      "task-1",
+			"namespace": "ns",
+			"status":    "success",
+		},
+		expectedCountTags: map[string]string{
+			"status": "success",
+		},
+	}, {
+		name: "at namespace level with run labels",
+		cfg: &config.Metrics{
+			TaskRunLevel:     config.TaskRunLevelAtNS,
+			PipelineRunLevel: config.PipelineRunLevelAtNS,
+			RunLabels:        []string{"example.com/team", "example.com/repo"},
+		},
+		expectedTags: map[string]string{
+			"namespace":        "ns",
+			"status":           "success",
+			"example_com_team": "team-1",
+		},
+		expectedCountTags: map[string]string{
+			"status":           "success",
+			"example_com_team": "team-1",
+		},
+	}} {
+		t.Run(c.name, func(t *testing.T) {
+			unregisterMetrics()
+
+			metrics, err := NewRecorder()
+			if err != nil {
+				t.Fatalf("NewRecorder: %v", err)
+			}
+			if err := metrics.updateConfig(c.cfg); err != nil {
+				t.Fatalf("updateConfig: %v", err)
+			}
+			defer unregisterMetrics()
+
+			if err := metrics.DurationAndCount(taskRun); err != nil {
+				t.Errorf("DurationAndCount: %v", err)
+			}
+			metricstest.CheckDistributionData(t, "pipelinerun_taskrun_duration_seconds", c.expectedTags, 1, 60, 60)
+			metricstest.CheckCountData(t, "taskrun_count", c.expectedCountTags, 1)
+		})
+	}
+}
+
 func TestRecordRunningTaskRunsCount(t *testing.T) {
 	unregisterMetrics()
 	newTaskRun := func(status corev1.ConditionStatus) *v1beta1.TaskRun {
@@ -451,6 +549,243 @@ func TestRecordCloudEvents(t *testing.T) {
 	}
 }
 
+func TestRecordResourceQuotaWait(t *testing.T) {
+	queuedTime := metav1.Now()
+	podCreationTime := metav1.NewTime(queuedTime.Add(2 * time.Minute))
+	failedTime := metav1.NewTime(queuedTime.Add(5 * time.Minute))
+
+	for _, td := range []struct {
+		name          string
+		pod           *corev1.Pod
+		taskRun       *v1beta1.TaskRun
+		expectedTags  map[string]string
+		expectedValue float64
+	}{{
+		name: "for taskrun admitted after waiting",
+		pod: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              "test-taskrun-pod-123456",
+				Namespace:         "foo",
+				CreationTimestamp: podCreationTime,
+			},
+		},
+		taskRun: &v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun", Namespace: "foo"},
+			Spec: v1beta1.TaskRunSpec{
+				TaskRef: &v1beta1.TaskRef{Name: "task-1"},
+			},
+			Status: v1beta1.TaskRunStatus{
+				TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+					QueuedTime: &queuedTime,
+				},
+			},
+		},
+		expectedTags: map[string]string{
+			"task":      "task-1",
+			"taskrun":   "test-taskrun",
+			"namespace": "foo",
+		},
+		expectedValue: 120,
+	}, {
+		name: "for taskrun that failed waiting",
+		taskRun: &v1beta1.TaskRun{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun", Namespace: "foo"},
+			Status: v1beta1.TaskRunStatus{
+				TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+					QueuedTime:     &queuedTime,
+					CompletionTime: &failedTime,
+				},
+			},
+		},
+		expectedTags: map[string]string{
+			"task":      "anonymous",
+			"taskrun":   "test-taskrun",
+			"namespace": "foo",
+		},
+		expectedValue: 300,
+	}} {
+		t.Run(td.name, func(t *testing.T) {
+			unregisterMetrics()
+
+			metrics, err := NewRecorder()
+			if err != nil {
+				t.Fatalf("NewRecorder: %v", err)
+			}
+
+			if err := metrics.RecordResourceQuotaWait(td.pod, td.taskRun); err != nil {
+				t.Fatalf("RecordResourceQuotaWait: %v", err)
+			}
+			metricstest.CheckDistributionData(t, "taskrun_resource_quota_wait_seconds", td.expectedTags, 1, td.expectedValue, td.expectedValue)
+		})
+	}
+}
+
+func TestRecordResourceQuotaWaitNotQueued(t *testing.T) {
+	unregisterMetrics()
+
+	metrics, err := NewRecorder()
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+
+	taskRun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun", Namespace: "foo"},
+	}
+	if err := metrics.RecordResourceQuotaWait(nil, taskRun); err != nil {
+		t.Fatalf("RecordResourceQuotaWait: %v", err)
+	}
+	metricstest.CheckStatsNotReported(t, "taskrun_resource_quota_wait_seconds")
+}
+
+func TestRecordStepDurations(t *testing.T) {
+	unregisterMetrics()
+
+	metrics, err := NewRecorder()
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+
+	stepStarted := metav1.Now()
+	taskRun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun", Namespace: "foo"},
+		Spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "task-1"},
+		},
+		Status: v1beta1.TaskRunStatus{
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					Name: "build",
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							StartedAt:  stepStarted,
+							FinishedAt: metav1.NewTime(stepStarted.Add(90 * time.Second)),
+						},
+					},
+				}, {
+					Name: "push",
+					ContainerState: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{StartedAt: stepStarted},
+					},
+				}},
+			},
+		},
+	}
+	if err := metrics.RecordStepDurations(taskRun); err != nil {
+		t.Fatalf("RecordStepDurations: %v", err)
+	}
+	expectedTags := map[string]string{
+		"task":      "task-1",
+		"step":      "build",
+		"namespace": "foo",
+	}
+	metricstest.CheckDistributionData(t, "taskrun_step_duration_seconds", expectedTags, 1, 90, 90)
+}
+
+func TestRecordStartupLatency(t *testing.T) {
+	creationTime := metav1.Now()
+	scheduledTime := metav1.NewTime(creationTime.Add(4 * time.Second))
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun-pod-123456", Namespace: "foo"},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{
+				Type:               corev1.PodScheduled,
+				LastTransitionTime: scheduledTime,
+			}},
+		},
+	}
+	taskRun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun", Namespace: "foo", CreationTimestamp: creationTime},
+		Spec: v1beta1.TaskRunSpec{
+			TaskRef: &v1beta1.TaskRef{Name: "task-1"},
+		},
+		Status: v1beta1.TaskRunStatus{
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				Steps: []v1beta1.StepState{{
+					Name: "second",
+					ContainerState: corev1.ContainerState{
+						Running: &corev1.ContainerStateRunning{StartedAt: metav1.NewTime(scheduledTime.Add(30 * time.Second))},
+					},
+				}, {
+					Name: "first",
+					ContainerState: corev1.ContainerState{
+						Terminated: &corev1.ContainerStateTerminated{
+							StartedAt:  metav1.NewTime(scheduledTime.Add(10 * time.Second)),
+							FinishedAt: metav1.NewTime(scheduledTime.Add(30 * time.Second)),
+						},
+					},
+				}},
+			},
+		},
+	}
+	expectedTags := map[string]string{
+		"task":      "task-1",
+		"taskrun":   "test-taskrun",
+		"namespace": "foo",
+	}
+
+	for _, td := range []struct {
+		name           string
+		pod            *corev1.Pod
+		expectingError bool
+	}{{
+		name: "for scheduled pod",
+		pod:  pod,
+	}, {
+		name: "for non scheduled pod",
+		pod: &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun-pod-123456", Namespace: "foo"},
+		},
+		expectingError: true,
+	}, {
+		name:           "without pod",
+		expectingError: true,
+	}} {
+		t.Run(td.name, func(t *testing.T) {
+			unregisterMetrics()
+
+			metrics, err := NewRecorder()
+			if err != nil {
+				t.Fatalf("NewRecorder: %v", err)
+			}
+
+			if err := metrics.RecordStartupLatency(td.pod, taskRun); td.expectingError && err == nil {
+				t.Error("RecordStartupLatency wanted error, got nil")
+			} else if !td.expectingError {
+				if err != nil {
+					t.Errorf("RecordStartupLatency: %v", err)
+				}
+				metricstest.CheckDistributionData(t, "taskrun_pod_scheduled_seconds", expectedTags, 1, 4, 4)
+				metricstest.CheckDistributionData(t, "taskrun_first_step_started_seconds", expectedTags, 1, 10, 10)
+			}
+		})
+	}
+}
+
+func TestRecordResolutionWait(t *testing.T) {
+	unregisterMetrics()
+
+	metrics, err := NewRecorder()
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+
+	creationTime := metav1.Now()
+	taskRun := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-taskrun", Namespace: "foo", CreationTimestamp: creationTime},
+	}
+	if err := metrics.RecordResolutionWait(taskRun, creationTime.Add(1500*time.Millisecond)); err != nil {
+		t.Fatalf("RecordResolutionWait: %v", err)
+	}
+	expectedTags := map[string]string{
+		"task":      "anonymous",
+		"taskrun":   "test-taskrun",
+		"namespace": "foo",
+	}
+	metricstest.CheckDistributionData(t, "taskrun_resolution_wait_seconds", expectedTags, 1, 1.5, 1.5)
+}
+
 func unregisterMetrics() {
-	metricstest.Unregister("taskrun_duration_seconds", "pipelinerun_taskrun_duration_seconds", "taskrun_count", "running_taskruns_count", "taskruns_pod_latency", "cloudevent_count")
+	metricstest.Unregister("taskrun_duration_seconds", "pipelinerun_taskrun_duration_seconds", "taskrun_count", "running_taskruns_count", "taskruns_pod_latency", "cloudevent_count", "taskrun_resource_quota_wait_seconds",
+		"taskrun_step_duration_seconds", "taskrun_pod_scheduled_seconds", "taskrun_first_step_started_seconds", "taskrun_resolution_wait_seconds")
 }
@@ -0,0 +1,153 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package taskrun
+
+import (
+	"context"
+	"crypto"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/google/go-containerregistry/pkg/authn/k8schain"
+	"github.com/google/go-containerregistry/pkg/v1/remote/transport"
+	"github.com/tektoncd/pipeline/pkg/apis/config"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/pkg/provenance"
+	"github.com/tektoncd/pipeline/pkg/system"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/controller"
+	"knative.dev/pkg/logging"
+)
+
+const (
+	// provenanceSigningKey is the key of the signing secret holding the
+	// PEM-encoded private key the provenance is signed with.
+	provenanceSigningKey = "private-key"
+	// totalAnnotationSizeLimit is the maximum total size of the annotations
+	// of an object accepted by the API server.
+	totalAnnotationSizeLimit = 256 * 1024
+)
+
+// recordProvenance generates the signed provenance of a successful TaskRun and
+// stores it as configured. It returns where the provenance was stored, to be
+// recorded in the status of the TaskRun once its annotations are updated, and
+// nil when there is nothing to record. It does nothing when the status of the
+// TaskRun records that its provenance was already handled. The failures that
+// retrying would not fix are returned as the outcome instead of an error, so
+// that they are not attempted again.
+func (c *Reconciler) recordProvenance(ctx context.Context, tr *v1beta1.TaskRun) (*v1beta1.TaskRunProvenance, error) {
+	cfg := config.FromContextOrDefaults(ctx).Provenance
+	if cfg == nil || cfg.Storage == "" || !tr.IsSuccessful() || tr.Status.Provenance != nil {
+		return nil, nil
+	}
+	location, err := c.storeProvenance(ctx, cfg, tr)
+	if err != nil {
+		if !controller.IsPermanentError(err) {
+			return nil, err
+		}
+		logging.FromContext(ctx).Errorf("Failed to record the provenance of TaskRun %q, it is not attempted again: %v", tr.Name, err)
+		return &v1beta1.TaskRunProvenance{Error: err.Error()}, nil
+	}
+	return &v1beta1.TaskRunProvenance{Location: location}, nil
+}
+
+// storeProvenance signs and stores the provenance of the TaskRun, and returns
+// where it is stored. Errors that retrying would not fix are permanent.
+func (c *Reconciler) storeProvenance(ctx context.Context, cfg *config.Provenance, tr *v1beta1.TaskRun) (string, error) {
+	signer, err := c.provenanceSigner(ctx, cfg)
+	if err != nil {
+		return "", err
+	}
+	env, err := provenance.Sign(provenance.Generate(tr), signer)
+	if err != nil {
+		return "", controller.NewPermanentError(err)
+	}
+
+	switch cfg.Storage {
+	case config.ProvenanceStorageAnnotation:
+		data, err := json.Marshal(env)
+		if err != nil {
+			return "", controller.NewPermanentError(fmt.Errorf("failed to serialize provenance of TaskRun %s: %w", tr.Name, err))
+		}
+		size := len(provenance.Annotation) + len(data)
+		for k, v := range tr.Annotations {
+			size += len(k) + len(v)
+		}
+		if size > totalAnnotationSizeLimit {
+			return "", controller.NewPermanentError(fmt.Errorf("provenance of TaskRun %s does not fit in its annotations: they would take %d bytes, the limit is %d", tr.Name, size, totalAnnotationSizeLimit))
+		}
+		if tr.Annotations == nil {
+			tr.Annotations = map[string]string{}
+		}
+		tr.Annotations[provenance.Annotation] = string(data)
+		return provenance.Annotation, nil
+	case config.ProvenanceStorageOCI:
+		// The provenance is pushed with the credentials of the controller, so
+		// that the TaskRuns cannot write to, or read from, the repository.
+		opts := k8schain.Options{Namespace: system.GetNamespace()}
+		if cfg.OCISecretName != "" {
+			opts.ImagePullSecrets = []string{cfg.OCISecretName}
+		}
+		kc, err := k8schain.New(ctx, c.KubeClientSet, opts)
+		if err != nil {
+			if k8serrors.IsNotFound(err) {
+				return "", controller.NewPermanentError(fmt.Errorf("error creating k8schain: %w", err))
+			}
+			return "", fmt.Errorf("error creating k8schain: %w", err)
+		}
+		ref, err := provenance.Push(env, cfg.OCIRepository, "taskrun-"+string(tr.UID), kc)
+		if err != nil {
+			var terr *transport.Error
+			if errors.As(err, &terr) && (terr.StatusCode == http.StatusUnauthorized || terr.StatusCode == http.StatusForbidden) {
+				return "", controller.NewPermanentError(err)
+			}
+			return "", err
+		}
+		if tr.Annotations == nil {
+			tr.Annotations = map[string]string{}
+		}
+		tr.Annotations[provenance.ImageAnnotation] = ref
+		return ref, nil
+	}
+	return "", controller.NewPermanentError(fmt.Errorf("unsupported provenance storage %q", cfg.Storage))
+}
+
+// provenanceSigner loads the key the provenance is signed with from the
+// configured secret, in the namespace of the controller. A missing secret or
+// an invalid key is a permanent error.
+func (c *Reconciler) provenanceSigner(ctx context.Context, cfg *config.Provenance) (crypto.Signer, error) {
+	secret, err := c.KubeClientSet.CoreV1().Secrets(system.GetNamespace()).Get(ctx, cfg.SigningSecretName, metav1.GetOptions{})
+	if err != nil {
+		wrapped := fmt.Errorf("failed to get provenance signing secret %s: %w", cfg.SigningSecretName, err)
+		if k8serrors.IsNotFound(err) {
+			return nil, controller.NewPermanentError(wrapped)
+		}
+		return nil, wrapped
+	}
+	data, ok := secret.Data[provenanceSigningKey]
+	if !ok {
+		return nil, controller.NewPermanentError(fmt.Errorf("provenance signing secret %s has no %s key", cfg.SigningSecretName, provenanceSigningKey))
+	}
+	signer, err := provenance.LoadSigner(data)
+	if err != nil {
+		return nil, controller.NewPermanentError(fmt.Errorf("failed to load provenance signing key from secret %s: %w", cfg.SigningSecretName, err))
+	}
+	return signer, nil
+}
@@ -174,6 +174,11 @@ func ApplyReplacements(spec *v1beta1.TaskSpec, stringReplacements map[string]str
 		v1beta1.ApplyStepReplacements(&steps[i], stringReplacements, arrayReplacements)
 	}
 
+	// Apply variable expansion to the steps run on failure.
+	for i := range spec.OnFailure {
+		v1beta1.ApplyStepReplacements(&spec.OnFailure[i], stringReplacements, arrayReplacements)
+	}
+
 	// Apply variable expansion to stepTemplate fields.
 	if spec.StepTemplate != nil {
 		v1beta1.ApplyStepReplacements(&v1beta1.Step{Container: *spec.StepTemplate}, stringReplacements, arrayReplacements)
@@ -787,6 +787,21 @@ func TestApplyWorkspaces(t *testing.T) {
 		want: &v1beta1.TaskSpec{Steps: []v1beta1.Step{{
 			Script: `test "false" = "true" && echo ""`,
 		}}},
+	}, {
+		name: "onFailure-step-variable-replacement",
+		spec: &v1beta1.TaskSpec{OnFailure: []v1beta1.Step{{
+			Script: `cat "$(workspaces.logs.path)/build.log"`,
+		}}},
+		decls: []v1beta1.WorkspaceDeclaration{{
+			Name: "logs",
+		}},
+		binds: []v1beta1.WorkspaceBinding{{
+			Name:     "logs",
+			EmptyDir: &corev1.EmptyDirVolumeSource{},
+		}},
+		want: &v1beta1.TaskSpec{OnFailure: []v1beta1.Step{{
+			Script: `cat "/workspace/logs/build.log"`,
+		}}},
 	}} {
 		t.Run(tc.name, func(t *testing.T) {
 			vols := workspace.CreateVolumes(tc.binds)
@@ -0,0 +1,61 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+)
+
+// StepPod is a group of consecutive Steps of a Task running in the same Pod.
+type StepPod struct {
+	// Start is the index of the first Step of the Pod, and End the index
+	// following its last Step.
+	Start, End int
+	// PodTemplate is the Pod template used for the Pod.
+	PodTemplate *v1beta1.PodTemplate
+}
+
+// StepPods splits the Steps of a Task into the Pods they run in, in order.
+// Each Step listed in stepPods runs in its own Pod, and the consecutive Steps
+// between them run together in a Pod using the Pod template of the TaskRun.
+func StepPods(steps []v1beta1.Step, stepPods []v1beta1.TaskRunStepPod, podTemplate *v1beta1.PodTemplate) []StepPod {
+	own := make(map[string]v1beta1.TaskRunStepPod, len(stepPods))
+	for _, sp := range stepPods {
+		own[sp.Name] = sp
+	}
+	var pods []StepPod
+	// shared is true when the last Pod can take the next Step.
+	shared := false
+	for i, s := range steps {
+		if sp, ok := own[s.Name]; ok {
+			template := podTemplate
+			if sp.PodTemplate != nil {
+				template = sp.PodTemplate
+			}
+			pods = append(pods, StepPod{Start: i, End: i + 1, PodTemplate: template})
+			shared = false
+			continue
+		}
+		if shared {
+			pods[len(pods)-1].End = i + 1
+			continue
+		}
+		pods = append(pods, StepPod{Start: i, End: i + 1, PodTemplate: podTemplate})
+		shared = true
+	}
+	return pods
+}
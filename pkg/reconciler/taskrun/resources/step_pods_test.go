@@ -0,0 +1,79 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resources
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
+	"github.com/tektoncd/pipeline/test/diff"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestStepPods(t *testing.T) {
+	steps := []v1beta1.Step{{
+		Container: corev1.Container{Name: "clone"},
+	}, {
+		Container: corev1.Container{Name: "build"},
+	}, {
+		Container: corev1.Container{Name: "test"},
+	}, {
+		Container: corev1.Container{Name: "push"},
+	}}
+	podTemplate := &v1beta1.PodTemplate{SchedulerName: "default"}
+	gpuTemplate := &v1beta1.PodTemplate{NodeSelector: map[string]string{"accelerator": "nvidia-tesla-t4"}}
+	for _, tc := range []struct {
+		name     string
+		stepPods []v1beta1.TaskRunStepPod
+		want     []StepPod
+	}{{
+		name: "all steps in one pod",
+		want: []StepPod{{Start: 0, End: 4, PodTemplate: podTemplate}},
+	}, {
+		name: "step in the middle in its own pod",
+		stepPods: []v1beta1.TaskRunStepPod{{
+			Name:        "test",
+			PodTemplate: gpuTemplate,
+		}},
+		want: []StepPod{
+			{Start: 0, End: 2, PodTemplate: podTemplate},
+			{Start: 2, End: 3, PodTemplate: gpuTemplate},
+			{Start: 3, End: 4, PodTemplate: podTemplate},
+		},
+	}, {
+		name: "consecutive steps in their own pods",
+		stepPods: []v1beta1.TaskRunStepPod{{
+			Name: "clone",
+		}, {
+			Name:        "build",
+			PodTemplate: gpuTemplate,
+		}},
+		want: []StepPod{
+			{Start: 0, End: 1, PodTemplate: podTemplate},
+			{Start: 1, End: 2, PodTemplate: gpuTemplate},
+			{Start: 2, End: 4, PodTemplate: podTemplate},
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := StepPods(steps, tc.stepPods, podTemplate)
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("StepPods() %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
@@ -49,3 +49,12 @@ func (l *LocalTaskRefResolver) GetTask(ctx context.Context, name string) (v1beta
 	}
 	return l.Tektonclient.TektonV1beta1().Tasks(l.Namespace).Get(ctx, name, metav1.GetOptions{})
 }
+
+// GetStepAction will resolve a StepAction from the namespace of the resolver using a
+// versioned Tekton client.
+func (l *LocalTaskRefResolver) GetStepAction(ctx context.Context, name string) (*v1beta1.StepAction, error) {
+	if l.Namespace == "" {
+		return nil, fmt.Errorf("Must specify namespace to resolve reference to StepAction %s", name)
+	}
+	return l.Tektonclient.TektonV1beta1().StepActions(l.Namespace).Get(ctx, name, metav1.GetOptions{})
+}
@@ -29,13 +29,17 @@ import (
 type GetTask func(context.Context, string) (v1beta1.TaskInterface, error)
 type GetTaskRun func(string) (*v1beta1.TaskRun, error)
 
+// GetStepAction is a function used to retrieve StepActions.
+type GetStepAction func(context.Context, string) (*v1beta1.StepAction, error)
+
 // GetClusterTask is a function that will retrieve the Task from name and namespace.
 type GetClusterTask func(name string) (v1beta1.TaskInterface, error)
 
 // GetTaskData will retrieve the Task metadata and Spec associated with the
 // provided TaskRun. This can come from a reference Task or from the TaskRun's
-// metadata and embedded TaskSpec.
-func GetTaskData(ctx context.Context, taskRun *v1beta1.TaskRun, getTask GetTask) (*metav1.ObjectMeta, *v1beta1.TaskSpec, error) {
+// metadata and embedded TaskSpec. Steps referencing a StepAction are resolved
+// in the returned Spec.
+func GetTaskData(ctx context.Context, taskRun *v1beta1.TaskRun, getTask GetTask, getStepAction GetStepAction) (*metav1.ObjectMeta, *v1beta1.TaskSpec, error) {
 	taskMeta := metav1.ObjectMeta{}
 	taskSpec := v1beta1.TaskSpec{}
 	switch {
@@ -54,5 +58,57 @@ func GetTaskData(ctx context.Context, taskRun *v1beta1.TaskRun, getTask GetTask)
 	default:
 		return nil, nil, fmt.Errorf("taskRun %s not providing TaskRef or TaskSpec", taskRun.Name)
 	}
+	if err := resolveStepActions(ctx, &taskSpec, getStepAction); err != nil {
+		return nil, nil, fmt.Errorf("error when resolving steps for taskRun %s: %w", taskRun.Name, err)
+	}
 	return &taskMeta, &taskSpec, nil
 }
+
+// resolveStepActions replaces the Steps referencing a StepAction with the Step
+// the StepAction defines, and adds the Results of the StepActions to the Task.
+func resolveStepActions(ctx context.Context, taskSpec *v1beta1.TaskSpec, getStepAction GetStepAction) error {
+	hasRef := false
+	for _, step := range taskSpec.Steps {
+		if step.Ref != nil {
+			hasRef = true
+			break
+		}
+	}
+	if !hasRef {
+		return nil
+	}
+
+	// Don't modify the Steps and Results of the Task or TaskRun the spec comes from.
+	steps := make([]v1beta1.Step, 0, len(taskSpec.Steps))
+	results := append([]v1beta1.TaskResult(nil), taskSpec.Results...)
+	resultNames := map[string]struct{}{}
+	for _, r := range results {
+		resultNames[r.Name] = struct{}{}
+	}
+	for _, step := range taskSpec.Steps {
+		if step.Ref == nil {
+			steps = append(steps, step)
+			continue
+		}
+		action, err := getStepAction(ctx, step.Ref.Name)
+		if err != nil {
+			return fmt.Errorf("failed to get StepAction %s for step %q: %w", step.Ref.Name, step.Name, err)
+		}
+		actionSpec := action.Spec.DeepCopy()
+		actionSpec.SetDefaults(ctx)
+		resolved, err := v1beta1.MergeStepWithStepAction(step, *actionSpec)
+		if err != nil {
+			return fmt.Errorf("failed to resolve StepAction %s for step %q: %w", step.Ref.Name, step.Name, err)
+		}
+		steps = append(steps, resolved)
+		for _, r := range actionSpec.Results {
+			if _, ok := resultNames[r.Name]; !ok {
+				resultNames[r.Name] = struct{}{}
+				results = append(results, r)
+			}
+		}
+	}
+	taskSpec.Steps = steps
+	taskSpec.Results = results
+	return nil
+}
@@ -21,11 +21,16 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/google/go-cmp/cmp"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
+func getStepActionNotCalled(ctx context.Context, n string) (*v1beta1.StepAction, error) {
+	return nil, errors.New("shouldn't be called")
+}
+
 func TestGetTaskSpec_Ref(t *testing.T) {
 	task := &v1beta1.Task{
 		ObjectMeta: metav1.ObjectMeta{
@@ -48,7 +53,7 @@ func TestGetTaskSpec_Ref(t *testing.T) {
 		},
 	}
 	gt := func(ctx context.Context, n string) (v1beta1.TaskInterface, error) { return task, nil }
-	taskMeta, taskSpec, err := GetTaskData(context.Background(), tr, gt)
+	taskMeta, taskSpec, err := GetTaskData(context.Background(), tr, gt, getStepActionNotCalled)
 
 	if err != nil {
 		t.Fatalf("Did not expect error getting task spec but got: %s", err)
@@ -79,7 +84,7 @@ func TestGetTaskSpec_Embedded(t *testing.T) {
 	gt := func(ctx context.Context, n string) (v1beta1.TaskInterface, error) {
 		return nil, errors.New("shouldn't be called")
 	}
-	taskMeta, taskSpec, err := GetTaskData(context.Background(), tr, gt)
+	taskMeta, taskSpec, err := GetTaskData(context.Background(), tr, gt, getStepActionNotCalled)
 
 	if err != nil {
 		t.Fatalf("Did not expect error getting task spec but got: %s", err)
@@ -103,7 +108,7 @@ func TestGetTaskSpec_Invalid(t *testing.T) {
 	gt := func(ctx context.Context, n string) (v1beta1.TaskInterface, error) {
 		return nil, errors.New("shouldn't be called")
 	}
-	_, _, err := GetTaskData(context.Background(), tr, gt)
+	_, _, err := GetTaskData(context.Background(), tr, gt, getStepActionNotCalled)
 	if err == nil {
 		t.Fatalf("Expected error resolving spec with no embedded or referenced task spec but didn't get error")
 	}
@@ -123,8 +128,168 @@ func TestGetTaskSpec_Error(t *testing.T) {
 	gt := func(ctx context.Context, n string) (v1beta1.TaskInterface, error) {
 		return nil, errors.New("something went wrong")
 	}
-	_, _, err := GetTaskData(context.Background(), tr, gt)
+	_, _, err := GetTaskData(context.Background(), tr, gt, getStepActionNotCalled)
 	if err == nil {
 		t.Fatalf("Expected error when unable to find referenced Task but got none")
 	}
 }
+
+func TestGetTaskSpec_StepActionRef(t *testing.T) {
+	stepAction := &v1beta1.StepAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "git-clone",
+		},
+		Spec: v1beta1.StepActionSpec{
+			Image:  "alpine/git",
+			Script: "git clone $(params.url) $(params.dir) && git rev-parse HEAD > $(results.commit.path)",
+			Params: []v1beta1.ParamSpec{{
+				Name: "url",
+			}, {
+				Name:    "dir",
+				Default: v1beta1.NewArrayOrString("/workspace/source"),
+			}},
+			Results: []v1beta1.TaskResult{{
+				Name: "commit",
+			}},
+		},
+	}
+	tr := &v1beta1.TaskRun{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "mytaskrun",
+		},
+		Spec: v1beta1.TaskRunSpec{
+			TaskSpec: &v1beta1.TaskSpec{
+				Params: []v1beta1.ParamSpec{{
+					Name: "repo",
+				}},
+				Steps: []v1beta1.Step{{
+					Container: corev1.Container{
+						Name: "clone",
+					},
+					Ref: &v1beta1.Ref{Name: "git-clone"},
+					Params: []v1beta1.Param{{
+						Name:  "url",
+						Value: *v1beta1.NewArrayOrString("$(params.repo)"),
+					}},
+				}, {
+					Container: corev1.Container{
+						Name:  "build",
+						Image: "golang",
+					},
+				}},
+				Results: []v1beta1.TaskResult{{
+					Name: "digest",
+				}},
+			},
+		},
+	}
+	original := tr.Spec.TaskSpec.DeepCopy()
+	gsa := func(ctx context.Context, n string) (*v1beta1.StepAction, error) {
+		if n != "git-clone" {
+			return nil, errors.New("unexpected StepAction")
+		}
+		return stepAction, nil
+	}
+	_, taskSpec, err := GetTaskData(context.Background(), tr, nil, gsa)
+	if err != nil {
+		t.Fatalf("Did not expect error getting task spec but got: %s", err)
+	}
+
+	want := &v1beta1.TaskSpec{
+		Params: []v1beta1.ParamSpec{{
+			Name: "repo",
+		}},
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{
+				Name:  "clone",
+				Image: "alpine/git",
+			},
+			Script: "git clone $(params.repo) /workspace/source && git rev-parse HEAD > $(results.commit.path)",
+		}, {
+			Container: corev1.Container{
+				Name:  "build",
+				Image: "golang",
+			},
+		}},
+		Results: []v1beta1.TaskResult{{
+			Name: "digest",
+		}, {
+			Name: "commit",
+		}},
+	}
+	if d := cmp.Diff(want, taskSpec); d != "" {
+		t.Errorf("Task Spec not resolved as expected %s", d)
+	}
+	if d := cmp.Diff(original, tr.Spec.TaskSpec); d != "" {
+		t.Errorf("Embedded Task Spec was modified %s", d)
+	}
+}
+
+func TestGetTaskSpec_StepActionRefError(t *testing.T) {
+	stepAction := &v1beta1.StepAction{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "notify",
+		},
+		Spec: v1beta1.StepActionSpec{
+			Image:  "curlimages/curl",
+			Script: "curl -X POST $(params.url)",
+			Params: []v1beta1.ParamSpec{{
+				Name: "url",
+				Type: v1beta1.ParamTypeString,
+			}},
+		},
+	}
+	for _, tc := range []struct {
+		name          string
+		step          v1beta1.Step
+		getStepAction GetStepAction
+	}{{
+		name: "StepAction not found",
+		step: v1beta1.Step{Ref: &v1beta1.Ref{Name: "notify"}, Params: []v1beta1.Param{{
+			Name: "url", Value: *v1beta1.NewArrayOrString("https://example.com"),
+		}}},
+		getStepAction: func(ctx context.Context, n string) (*v1beta1.StepAction, error) {
+			return nil, errors.New("something went wrong")
+		},
+	}, {
+		name: "missing param",
+		step: v1beta1.Step{Ref: &v1beta1.Ref{Name: "notify"}},
+		getStepAction: func(ctx context.Context, n string) (*v1beta1.StepAction, error) {
+			return stepAction, nil
+		},
+	}, {
+		name: "undeclared param",
+		step: v1beta1.Step{Ref: &v1beta1.Ref{Name: "notify"}, Params: []v1beta1.Param{{
+			Name: "url", Value: *v1beta1.NewArrayOrString("https://example.com"),
+		}, {
+			Name: "method", Value: *v1beta1.NewArrayOrString("PUT"),
+		}}},
+		getStepAction: func(ctx context.Context, n string) (*v1beta1.StepAction, error) {
+			return stepAction, nil
+		},
+	}, {
+		name: "param of the wrong type",
+		step: v1beta1.Step{Ref: &v1beta1.Ref{Name: "notify"}, Params: []v1beta1.Param{{
+			Name: "url", Value: *v1beta1.NewArrayOrString("https://example.com", "https://example.org"),
+		}}},
+		getStepAction: func(ctx context.Context, n string) (*v1beta1.StepAction, error) {
+			return stepAction, nil
+		},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			tr := &v1beta1.TaskRun{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "mytaskrun",
+				},
+				Spec: v1beta1.TaskRunSpec{
+					TaskSpec: &v1beta1.TaskSpec{
+						Steps: []v1beta1.Step{tc.step},
+					},
+				},
+			}
+			if _, _, err := GetTaskData(context.Background(), tr, nil, tc.getStepAction); err == nil {
+				t.Fatalf("Expected error when unable to resolve referenced StepAction but got none")
+			}
+		})
+	}
+}
@@ -42,11 +42,14 @@ import (
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
 	"github.com/tektoncd/pipeline/pkg/reconciler/volumeclaim"
 	"github.com/tektoncd/pipeline/pkg/timeout"
+	"github.com/tektoncd/pipeline/pkg/tracing"
 	"github.com/tektoncd/pipeline/pkg/workspace"
+	"go.opencensus.io/trace"
 	corev1 "k8s.io/api/core/v1"
 	k8serrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	corev1listers "k8s.io/client-go/listers/core/v1"
 	"knative.dev/pkg/apis"
 	"knative.dev/pkg/controller"
 	"knative.dev/pkg/logging"
@@ -65,12 +68,15 @@ type Reconciler struct {
 	taskLister        listers.TaskLister
 	clusterTaskLister listers.ClusterTaskLister
 	resourceLister    resourcelisters.PipelineResourceLister
+	namespaceLister   corev1listers.NamespaceLister
 	cloudEventClient  cloudevent.CEClient
-	tracker           tracker.Interface
-	entrypointCache   podconvert.EntrypointCache
-	timeoutHandler    *timeout.Handler
-	metrics           *Recorder
-	pvcHandler        volumeclaim.PvcHandler
+	// cloudEventDeliveries delivers the queued cloud events in the background
+	cloudEventDeliveries *cloudevent.DeliveryTracker
+	tracker              tracker.Interface
+	entrypointCache      podconvert.EntrypointCache
+	timeoutHandler       *timeout.Handler
+	metrics              *Recorder
+	pvcHandler           volumeclaim.PvcHandler
 }
 
 // Check that our Reconciler implements taskrunreconciler.Interface
@@ -82,6 +88,10 @@ var _ taskrunreconciler.Interface = (*Reconciler)(nil)
 func (c *Reconciler) ReconcileKind(ctx context.Context, tr *v1beta1.TaskRun) pkgreconciler.Event {
 	logger := logging.FromContext(ctx)
 	ctx = cloudevent.ToContext(ctx, c.cloudEventClient)
+	ctx = cloudevent.WithDeliveryTracker(ctx, c.cloudEventDeliveries)
+	ctx = cloudevent.WithNamespaceLister(ctx, c.namespaceLister)
+	ctx, span := tracing.StartRunSpan(ctx, "TaskRun.Reconcile", tr)
+	defer span.End()
 
 	// Read the initial condition
 	before := tr.Status.GetCondition(apis.ConditionSucceeded)
@@ -110,10 +120,27 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, tr *v1beta1.TaskRun) pkg
 		var merr *multierror.Error
 		// Try to send cloud events first
 		cloudEventErr := cloudevent.SendCloudEvents(tr, c.cloudEventClient, logger)
+		// The metrics are recorded once the cloud events to the default sink
+		// are delivered, so that they are not recorded again by the reconciles
+		// that record the outcome of their delivery
+		cloudEventsPending := events.HasPendingCloudEvents(ctx, tr)
+		// Send again the cloud events that could not be delivered to the default sink
+		queuedCloudEventErr := events.SendQueuedCloudEvents(ctx, tr)
+		// Record the provenance of the TaskRun, its location is written back
+		// to the annotations below.
+		provenanceStatus, provenanceErr := c.recordProvenance(ctx, tr)
+		if provenanceErr != nil {
+			logger.Errorf("Failed to record the provenance of TaskRun %q: %v", tr.Name, provenanceErr)
+		}
 		// Regardless of `err`, we must write back any status update that may have
 		// been generated by `sendCloudEvents`
 		_, updateErr := c.updateLabelsAndAnnotations(ctx, tr)
-		merr = multierror.Append(cloudEventErr, updateErr)
+		if updateErr == nil && provenanceStatus != nil {
+			// The provenance is only recorded as stored once the annotation
+			// holding it, or its location, was written.
+			tr.Status.Provenance = provenanceStatus
+		}
+		merr = multierror.Append(cloudEventErr, queuedCloudEventErr, provenanceErr, updateErr)
 		if cloudEventErr != nil {
 			// Let's keep timeouts and sidecars running as long as we're trying to
 			// send cloud events. So we stop here an return errors encountered this far.
@@ -139,6 +166,10 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, tr *v1beta1.TaskRun) pkg
 			merr = multierror.Append(merr, err)
 		}
 
+		if cloudEventsPending {
+			return merr.ErrorOrNil()
+		}
+
 		go func(metrics *Recorder) {
 			err := metrics.DurationAndCount(tr)
 			if err != nil {
@@ -152,6 +183,18 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, tr *v1beta1.TaskRun) pkg
 			if err != nil {
 				logger.Warnf("Failed to log the metrics : %v", err)
 			}
+			err = metrics.RecordResourceQuotaWait(pod, tr)
+			if err != nil {
+				logger.Warnf("Failed to log the metrics : %v", err)
+			}
+			err = metrics.RecordStepDurations(tr)
+			if err != nil {
+				logger.Warnf("Failed to log the metrics : %v", err)
+			}
+			err = metrics.RecordStartupLatency(pod, tr)
+			if err != nil {
+				logger.Warnf("Failed to log the metrics : %v", err)
+			}
 		}(c.metrics)
 
 		return merr.ErrorOrNil()
@@ -166,8 +209,11 @@ func (c *Reconciler) ReconcileKind(ctx context.Context, tr *v1beta1.TaskRun) pkg
 
 	// Check if the TaskRun has timed out; if it is, this will set its status
 	// accordingly.
-	if tr.HasTimedOut() {
+	if hasTimedOut(ctx, tr) {
 		message := fmt.Sprintf("TaskRun %q failed to finish within %q", tr.Name, tr.GetTimeout())
+		if isTimeoutDeferredToPodCreation(ctx, tr) {
+			message = fmt.Sprintf("TaskRun %q failed to create its pod within %q", tr.Name, tr.GetTimeout())
+		}
 		err := c.failTaskRun(ctx, tr, v1beta1.TaskRunReasonTimedOut, message)
 		return c.finishReconcileUpdateEmitEvents(ctx, tr, before, err)
 	}
@@ -201,15 +247,17 @@ func (c *Reconciler) finishReconcileUpdateEmitEvents(ctx context.Context, tr *v1
 
 	// Send k8s events and cloud events (when configured)
 	events.Emit(ctx, beforeCondition, afterCondition, tr)
+	// Send again the cloud events that could not be delivered so far
+	cloudEventErr := events.SendQueuedCloudEvents(ctx, tr)
 
 	_, err := c.updateLabelsAndAnnotations(ctx, tr)
 	if err != nil {
 		events.EmitError(controller.GetEventRecorder(ctx), err, tr)
 	}
 	if controller.IsPermanentError(previousError) {
-		return controller.NewPermanentError(multierror.Append(previousError, err))
+		return controller.NewPermanentError(multierror.Append(previousError, err, cloudEventErr))
 	}
-	return multierror.Append(previousError, err).ErrorOrNil()
+	return multierror.Append(previousError, err, cloudEventErr).ErrorOrNil()
 }
 
 func (c *Reconciler) getTaskResolver(tr *v1beta1.TaskRun) (*resources.LocalTaskRefResolver, v1beta1.TaskKind) {
@@ -242,13 +290,23 @@ func (c *Reconciler) prepare(ctx context.Context, tr *v1beta1.TaskRun) (*v1beta1
 	tr.SetDefaults(contexts.WithUpgradeViaDefaulting(ctx))
 
 	resolver, kind := c.getTaskResolver(tr)
-	taskMeta, taskSpec, err := resources.GetTaskData(ctx, tr, resolver.GetTask)
+	getTaskDataCtx, span := trace.StartSpan(ctx, "GetTaskData")
+	taskMeta, taskSpec, err := resources.GetTaskData(getTaskDataCtx, tr, resolver.GetTask, resolver.GetStepAction)
+	tracing.SetError(span, err)
+	span.End()
 	if err != nil {
 		logger.Errorf("Failed to determine Task spec to use for taskrun %s: %v", tr.Name, err)
 		tr.Status.MarkResourceFailed(podconvert.ReasonFailedResolution, err)
 		return nil, nil, controller.NewPermanentError(err)
 	}
 
+	// The TaskSpec is only stored once, the first time the Task is resolved
+	if tr.Status.TaskSpec == nil {
+		if err := c.metrics.RecordResolutionWait(tr, time.Now()); err != nil {
+			logger.Warnf("Failed to log the metrics : %v", err)
+		}
+	}
+
 	// Store the fetched TaskSpec on the TaskRun for auditing
 	if err := storeTaskSpec(ctx, tr, taskSpec); err != nil {
 		logger.Errorf("Failed to store TaskSpec on TaskRun.Statusfor taskrun %s: %v", tr.Name, err)
@@ -295,6 +353,24 @@ func (c *Reconciler) prepare(ctx context.Context, tr *v1beta1.TaskRun) (*v1beta1
 		return nil, nil, controller.NewPermanentError(err)
 	}
 
+	if err := ValidateStepOverrides(taskSpec, tr.Spec.StepOverrides); err != nil {
+		logger.Errorf("TaskRun %q step overrides are invalid: %v", tr.Name, err)
+		tr.Status.MarkResourceFailed(podconvert.ReasonFailedValidation, err)
+		return nil, nil, controller.NewPermanentError(err)
+	}
+
+	if err := ValidateSidecarOverrides(taskSpec, tr.Spec.SidecarOverrides); err != nil {
+		logger.Errorf("TaskRun %q sidecar overrides are invalid: %v", tr.Name, err)
+		tr.Status.MarkResourceFailed(podconvert.ReasonFailedValidation, err)
+		return nil, nil, controller.NewPermanentError(err)
+	}
+
+	if err := ValidateStepPods(taskSpec, tr.Spec.StepPods); err != nil {
+		logger.Errorf("TaskRun %q step pods are invalid: %v", tr.Name, err)
+		tr.Status.MarkResourceFailed(podconvert.ReasonFailedValidation, err)
+		return nil, nil, controller.NewPermanentError(err)
+	}
+
 	if err := c.updateTaskRunWithDefaultWorkspaces(ctx, tr, taskSpec); err != nil {
 		logger.Errorf("Failed to update taskrun %s with default workspace: %v", tr.Name, err)
 		tr.Status.MarkResourceFailed(podconvert.ReasonFailedResolution, err)
@@ -380,12 +456,28 @@ func (c *Reconciler) reconcile(ctx context.Context, tr *v1beta1.TaskRun,
 			tr.Spec.Workspaces = taskRunWorkspaces
 		}
 
-		pod, err = c.createPod(ctx, tr, rtr)
+		// When the Pod of the current Steps was lost, recreate it rather
+		// than moving on to the Steps of the next Pod.
+		stepPodIndex := len(tr.Status.PodNames)
+		if stepPodIndex > 0 {
+			stepPodIndex--
+		}
+		firstPod := tr.Status.PodName == ""
+		pod, err = c.createPod(ctx, tr, rtr, stepPodIndex)
 		if err != nil {
 			newErr := c.handlePodCreationError(ctx, tr, err)
 			logger.Errorf("Failed to create task run pod for taskrun %q: %v", tr.Name, newErr)
 			return newErr
 		}
+		if len(tr.Status.PodNames) > 0 {
+			tr.Status.PodNames[stepPodIndex] = pod.Name
+		}
+		if firstPod && config.FromContextOrDefaults(ctx).FeatureFlags.StartTimeoutAtPodCreation {
+			// The timeout clock starts now that the first pod exists, time spent
+			// waiting on a ResourceQuota is recorded in QueuedTime instead.
+			// A pod that is recreated later does not restart the clock.
+			tr.Status.StartTime = &metav1.Time{Time: time.Now()}
+		}
 		go c.timeoutHandler.Wait(tr.GetNamespacedName(), *tr.Status.StartTime, *tr.Spec.Timeout)
 	}
 
@@ -398,11 +490,31 @@ func (c *Reconciler) reconcile(ctx context.Context, tr *v1beta1.TaskRun,
 		recorder.Eventf(tr, corev1.EventTypeWarning, podconvert.ReasonExceededNodeResources, "Insufficient resources to schedule pod %q", pod.Name)
 	}
 
-	if podconvert.SidecarsReady(pod.Status) {
+	if podconvert.UngatedSidecarsReady(*pod) {
 		if err := podconvert.UpdateReady(ctx, c.KubeClientSet, *pod); err != nil {
 			return err
 		}
 	}
+	if err := podconvert.UpdateSidecarsReady(ctx, c.KubeClientSet, *pod); err != nil {
+		return err
+	}
+	if err := podconvert.StopUnneededSidecars(ctx, c.Images.NopImage, c.KubeClientSet, *pod); err != nil {
+		return err
+	}
+
+	// When some Steps run in their own Pod, the Pod only reports the state of
+	// its own Steps, so keep the state of the Steps of the previous Pods.
+	var stepPods []resources.StepPod
+	var previousSteps []v1beta1.StepState
+	if len(tr.Spec.StepPods) > 0 {
+		stepPods = resources.StepPods(taskSpec.Steps, tr.Spec.StepPods, tr.Spec.PodTemplate)
+		if len(tr.Status.PodNames) == 0 {
+			tr.Status.PodNames = []string{pod.Name}
+		}
+		if start := stepPods[len(tr.Status.PodNames)-1].Start; len(tr.Status.Steps) >= start {
+			previousSteps = append(previousSteps, tr.Status.Steps[:start]...)
+		}
+	}
 
 	// Convert the Pod's status to the equivalent TaskRun Status.
 	tr.Status, err = podconvert.MakeTaskRunStatus(logger, *tr, pod)
@@ -410,10 +522,58 @@ func (c *Reconciler) reconcile(ctx context.Context, tr *v1beta1.TaskRun,
 		return err
 	}
 
+	// A step waiting for a Sidecar which failed would never start, and a
+	// step whose image can never be pulled would stay pending until the
+	// TaskRun times out, so fail the TaskRun right away.
+	if failure, message := podconvert.RequiredSidecarFailure(pod); failure != nil {
+		tr.Status.Failure = failure
+		return c.failTaskRun(ctx, tr, failure.Reason, message)
+	}
+	if failure, message := podconvert.PermanentImagePullFailure(pod); failure != nil {
+		tr.Status.Failure = failure
+		return c.failTaskRun(ctx, tr, failure.Reason, message)
+	}
+
+	if len(stepPods) > 0 {
+		tr.Status.Steps = append(previousSteps, tr.Status.Steps...)
+		if tr.IsSuccessful() && len(tr.Status.PodNames) < len(stepPods) {
+			return c.createNextStepPod(ctx, tr, rtr)
+		}
+	}
+
 	logger.Infof("Successfully reconciled taskrun %s/%s with status: %#v", tr.Name, tr.Namespace, tr.Status.GetCondition(apis.ConditionSucceeded))
 	return nil
 }
 
+// createNextStepPod creates the next Pod of a TaskRun whose Steps run in
+// several Pods, once the previous Pod succeeded. The TaskRun keeps running
+// until the Steps of its last Pod have finished.
+func (c *Reconciler) createNextStepPod(ctx context.Context, tr *v1beta1.TaskRun, rtr *resources.ResolvedTaskResources) error {
+	logger := logging.FromContext(ctx)
+
+	podconvert.MarkStatusRunning(&tr.Status, v1beta1.TaskRunReasonRunning.String(), "Not all Steps in the Task have finished executing")
+	tr.Status.CompletionTime = nil
+
+	if tr.HasVolumeClaimTemplate() {
+		// The PVCs were created with the first Pod of the TaskRun.
+		tr.Spec.Workspaces = applyVolumeClaimTemplates(tr.Spec.Workspaces, tr.GetOwnerReference())
+	}
+	pod, err := c.createPod(ctx, tr, rtr, len(tr.Status.PodNames))
+	if err != nil {
+		newErr := c.handlePodCreationError(ctx, tr, err)
+		logger.Errorf("Failed to create task run pod for taskrun %q: %v", tr.Name, newErr)
+		return newErr
+	}
+	tr.Status.PodName = pod.Name
+	tr.Status.PodNames = append(tr.Status.PodNames, pod.Name)
+
+	if err := c.tracker.Track(tr.GetBuildPodRef(), tr); err != nil {
+		logger.Errorf("Failed to create tracker for build pod %q for taskrun %q: %v", tr.Name, tr.Name, err)
+		return err
+	}
+	return nil
+}
+
 func (c *Reconciler) updateTaskRunWithDefaultWorkspaces(ctx context.Context, tr *v1beta1.TaskRun, taskSpec *v1beta1.TaskSpec) error {
 	configMap := config.FromContextOrDefaults(ctx)
 	defaults := configMap.Defaults
@@ -458,10 +618,14 @@ func (c *Reconciler) updateLabelsAndAnnotations(ctx context.Context, tr *v1beta1
 		// Note that this uses Update vs. Patch because the former is significantly easier to test.
 		// If we want to switch this to Patch, then we will need to teach the utilities in test/controller.go
 		// to deal with Patch (setting resourceVersion, and optimistic concurrency checks).
+		ctx, span := trace.StartSpan(ctx, "UpdateLabelsAndAnnotations")
+		defer span.End()
 		newTr = newTr.DeepCopy()
 		newTr.Labels = tr.Labels
 		newTr.Annotations = tr.Annotations
-		return c.PipelineClientSet.TektonV1beta1().TaskRuns(tr.Namespace).Update(ctx, newTr, metav1.UpdateOptions{})
+		newTr, err = c.PipelineClientSet.TektonV1beta1().TaskRuns(tr.Namespace).Update(ctx, newTr, metav1.UpdateOptions{})
+		tracing.SetError(span, err)
+		return newTr, err
 	}
 	return newTr, nil
 }
@@ -469,15 +633,27 @@ func (c *Reconciler) updateLabelsAndAnnotations(ctx context.Context, tr *v1beta1
 func (c *Reconciler) handlePodCreationError(ctx context.Context, tr *v1beta1.TaskRun, err error) error {
 	switch {
 	case isExceededResourceQuotaError(err):
-		backoff, currentlyBackingOff := c.timeoutHandler.GetBackoff(tr.GetNamespacedName(), *tr.Status.StartTime, *tr.Spec.Timeout)
+		if tr.Status.QueuedTime == nil {
+			tr.Status.QueuedTime = &metav1.Time{Time: time.Now()}
+		}
+		maxWait := time.Duration(config.FromContextOrDefaults(ctx).Defaults.DefaultResourceQuotaMaxWaitMinutes) * time.Minute
+		if maxWait > 0 && time.Since(tr.Status.QueuedTime.Time) > maxWait {
+			msg := fmt.Sprintf("TaskRun Pod exceeded available resources for longer than %q", maxWait)
+			tr.Status.MarkResourceFailed(podconvert.ReasonExceededResourceQuota, fmt.Errorf("%s: %v", msg, err))
+			tr.Status.CompletionTime = &metav1.Time{Time: time.Now()}
+			return controller.NewPermanentError(err)
+		}
+		startTime, timeout := resourceQuotaDeadline(ctx, tr, maxWait)
+		backoff, currentlyBackingOff := c.timeoutHandler.GetBackoff(tr.GetNamespacedName(), startTime, timeout)
 		if !currentlyBackingOff {
 			go c.timeoutHandler.SetTimer(tr.GetNamespacedName(), time.Until(backoff.NextAttempt))
 		}
-		msg := fmt.Sprintf("TaskRun Pod exceeded available resources, reattempted %d times", backoff.NumAttempts)
+		msg := fmt.Sprintf("TaskRun Pod is queued waiting for a ResourceQuota since %s, reattempted %d times",
+			tr.Status.QueuedTime.Format(time.RFC3339), backoff.NumAttempts)
 		tr.Status.SetCondition(&apis.Condition{
 			Type:    apis.ConditionSucceeded,
 			Status:  corev1.ConditionUnknown,
-			Reason:  podconvert.ReasonExceededResourceQuota,
+			Reason:  podconvert.ReasonResourceQuotaQueued,
 			Message: fmt.Sprintf("%s: %v", msg, err),
 		})
 	case isTaskRunValidationFailed(err):
@@ -506,6 +682,18 @@ func (c *Reconciler) failTaskRun(ctx context.Context, tr *v1beta1.TaskRun, reaso
 	logger := logging.FromContext(ctx)
 
 	logger.Warnf("stopping task run %q because of %q", tr.Name, reason)
+	// Classify the failure, unless the caller did it already in more detail.
+	// A TaskRun which failed, e.g. timed out, while waiting for an image that
+	// the kubelet kept failing to pull is classified as such.
+	if reason != v1beta1.TaskRunReasonCancelled && tr.Status.Failure == nil {
+		if failure, imageMessage := podconvert.ImagePullFailure(tr.Status); failure != nil {
+			tr.Status.Failure = failure
+			reason = failure.Reason
+			message = fmt.Sprintf("%s; %s", message, imageMessage)
+		} else {
+			tr.Status.Failure = &v1beta1.TaskRunFailure{Reason: reason}
+		}
+	}
 	tr.Status.MarkResourceFailed(reason, errors.New(message))
 
 	completionTime := metav1.Time{Time: time.Now()}
@@ -555,9 +743,13 @@ func (c *Reconciler) failTaskRun(ctx context.Context, tr *v1beta1.TaskRun, reaso
 }
 
 // createPod creates a Pod based on the Task's configuration, with pvcName as a volumeMount
+// When some Steps run in their own Pod, stepPodIndex is the index of the Pod to create
+// among the Pods of the TaskRun.
 // TODO(dibyom): Refactor resource setup/substitution logic to its own function in the resources package
-func (c *Reconciler) createPod(ctx context.Context, tr *v1beta1.TaskRun, rtr *resources.ResolvedTaskResources) (*corev1.Pod, error) {
+func (c *Reconciler) createPod(ctx context.Context, tr *v1beta1.TaskRun, rtr *resources.ResolvedTaskResources, stepPodIndex int) (*corev1.Pod, error) {
 	logger := logging.FromContext(ctx)
+	ctx, span := trace.StartSpan(ctx, "CreatePod")
+	defer span.End()
 	ts := rtr.TaskSpec.DeepCopy()
 	inputResources, err := resourceImplBinding(rtr.Inputs, c.Images)
 	if err != nil {
@@ -624,18 +816,34 @@ func (c *Reconciler) createPod(ctx context.Context, tr *v1beta1.TaskRun, rtr *re
 	// Apply creds-init path substitutions.
 	ts = resources.ApplyCredentialsPath(ts, pipeline.CredsDir)
 
+	// When some Steps run in their own Pod, only the Steps of the given Pod
+	// of the TaskRun run in this one, with the Pod template of these Steps.
+	// The OnFailure steps run in the last Pod.
+	podTaskRun := tr
+	if len(tr.Spec.StepPods) > 0 {
+		stepPods := resources.StepPods(ts.Steps, tr.Spec.StepPods, tr.Spec.PodTemplate)
+		stepPod := stepPods[stepPodIndex]
+		ts.Steps = ts.Steps[stepPod.Start:stepPod.End]
+		if stepPodIndex < len(stepPods)-1 {
+			ts.OnFailure = nil
+		}
+		podTaskRun = tr.DeepCopy()
+		podTaskRun.Spec.PodTemplate = stepPod.PodTemplate
+	}
+
 	podbuilder := podconvert.Builder{
 		Images:          c.Images,
 		KubeClient:      c.KubeClientSet,
 		EntrypointCache: c.entrypointCache,
 		OverrideHomeEnv: shouldOverrideHomeEnv,
 	}
-	pod, err := podbuilder.Build(ctx, tr, *ts)
+	pod, err := podbuilder.Build(ctx, podTaskRun, *ts)
 	if err != nil {
 		return nil, fmt.Errorf("translating TaskSpec to Pod: %w", err)
 	}
 
 	pod, err = c.KubeClientSet.CoreV1().Pods(tr.Namespace).Create(ctx, pod, metav1.CreateOptions{})
+	tracing.SetError(span, err)
 	if err == nil && willOverwritePodSetAffinity(tr) {
 		if recorder := controller.GetEventRecorder(ctx); recorder != nil {
 			recorder.Eventf(tr, corev1.EventTypeWarning, "PodAffinityOverwrite", "Pod template affinity is overwritten by affinity assistant for pod %q", pod.Name)
@@ -646,6 +854,47 @@ func (c *Reconciler) createPod(ctx context.Context, tr *v1beta1.TaskRun, rtr *re
 
 type DeletePod func(podName string, options *metav1.DeleteOptions) error
 
+// isTimeoutDeferredToPodCreation returns true if the TaskRun timeout only starts
+// counting down once the pod is created, and the pod has not been created yet.
+func isTimeoutDeferredToPodCreation(ctx context.Context, tr *v1beta1.TaskRun) bool {
+	return config.FromContextOrDefaults(ctx).FeatureFlags.StartTimeoutAtPodCreation && tr.Status.PodName == ""
+}
+
+// hasTimedOut returns true if the TaskRun ran for longer than its timeout.
+// When the timeout is deferred to pod creation, the time spent queued before
+// the pod is created is bounded by the timeout as well.
+func hasTimedOut(ctx context.Context, tr *v1beta1.TaskRun) bool {
+	if !isTimeoutDeferredToPodCreation(ctx, tr) {
+		return tr.HasTimedOut()
+	}
+	if tr.Status.QueuedTime == nil {
+		return false
+	}
+	timeout := tr.GetTimeout()
+	if timeout == config.NoTimeoutDuration {
+		return false
+	}
+	return time.Since(tr.Status.QueuedTime.Time) > timeout
+}
+
+// resourceQuotaDeadline returns the start time and the duration that bound how
+// long a TaskRun keeps retrying to create a pod rejected by a ResourceQuota.
+// A zero duration means that the TaskRun can wait indefinitely, which only
+// happens when the TaskRun has no timeout and there is no max wait.
+func resourceQuotaDeadline(ctx context.Context, tr *v1beta1.TaskRun, maxWait time.Duration) (metav1.Time, metav1.Duration) {
+	startTime, timeout := *tr.Status.StartTime, metav1.Duration{Duration: tr.GetTimeout()}
+	if isTimeoutDeferredToPodCreation(ctx, tr) {
+		startTime = *tr.Status.QueuedTime
+	}
+	if maxWait > 0 {
+		maxWaitDeadline := tr.Status.QueuedTime.Add(maxWait)
+		if timeout.Duration == config.NoTimeoutDuration || maxWaitDeadline.Before(startTime.Add(timeout.Duration)) {
+			startTime, timeout = *tr.Status.QueuedTime, metav1.Duration{Duration: maxWait}
+		}
+	}
+	return startTime, timeout
+}
+
 func isExceededResourceQuotaError(err error) bool {
 	return err != nil && k8serrors.IsForbidden(err) && strings.Contains(err.Error(), "exceeded quota")
 }
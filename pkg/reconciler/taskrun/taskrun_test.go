@@ -18,8 +18,16 @@ package taskrun
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"errors"
 	"fmt"
+	"net/http/httptest"
+	"net/url"
 	"regexp"
 	"strings"
 	"testing"
@@ -27,22 +35,26 @@ import (
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-containerregistry/pkg/registry"
 	tb "github.com/tektoncd/pipeline/internal/builder/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/apis/config"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
 	resourcev1alpha1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1alpha1"
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	podconvert "github.com/tektoncd/pipeline/pkg/pod"
+	"github.com/tektoncd/pipeline/pkg/provenance"
 	"github.com/tektoncd/pipeline/pkg/reconciler/events/cloudevent"
 	ttesting "github.com/tektoncd/pipeline/pkg/reconciler/testing"
 	"github.com/tektoncd/pipeline/pkg/reconciler/volumeclaim"
 	"github.com/tektoncd/pipeline/pkg/system"
 	"github.com/tektoncd/pipeline/pkg/timeout"
+	"github.com/tektoncd/pipeline/pkg/tracing"
 	"github.com/tektoncd/pipeline/pkg/version"
 	"github.com/tektoncd/pipeline/pkg/workspace"
 	test "github.com/tektoncd/pipeline/test"
 	"github.com/tektoncd/pipeline/test/diff"
 	"github.com/tektoncd/pipeline/test/names"
+	"go.opencensus.io/trace"
 	corev1 "k8s.io/api/core/v1"
 	k8sapierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -235,7 +247,7 @@ func getRunName(tr *v1beta1.TaskRun) string {
 }
 
 func ensureConfigurationConfigMapsExist(d *test.Data) {
-	var defaultsExists, featureFlagsExists, artifactBucketExists, artifactPVCExists bool
+	var defaultsExists, featureFlagsExists, artifactBucketExists, artifactPVCExists, logArchiveExists, provenanceExists bool
 	for _, cm := range d.ConfigMaps {
 		if cm.Name == config.GetDefaultsConfigName() {
 			defaultsExists = true
@@ -249,6 +261,12 @@ func ensureConfigurationConfigMapsExist(d *test.Data) {
 		if cm.Name == config.GetArtifactPVCConfigName() {
 			artifactPVCExists = true
 		}
+		if cm.Name == config.GetLogArchiveConfigName() {
+			logArchiveExists = true
+		}
+		if cm.Name == config.GetProvenanceConfigName() {
+			provenanceExists = true
+		}
 	}
 	if !defaultsExists {
 		d.ConfigMaps = append(d.ConfigMaps, &corev1.ConfigMap{
@@ -274,6 +292,18 @@ func ensureConfigurationConfigMapsExist(d *test.Data) {
 			Data:       map[string]string{},
 		})
 	}
+	if !logArchiveExists {
+		d.ConfigMaps = append(d.ConfigMaps, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: config.GetLogArchiveConfigName(), Namespace: system.GetNamespace()},
+			Data:       map[string]string{},
+		})
+	}
+	if !provenanceExists {
+		d.ConfigMaps = append(d.ConfigMaps, &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: config.GetProvenanceConfigName(), Namespace: system.GetNamespace()},
+			Data:       map[string]string{},
+		})
+	}
 }
 
 // getTaskRunController returns an instance of the TaskRun controller/reconciler that has been seeded with
@@ -838,6 +868,74 @@ func TestReconcile_CloudEvents(t *testing.T) {
 	}
 }
 
+func TestReconcile_ResendCloudEvents(t *testing.T) {
+	taskRun := tb.TaskRun("test-taskrun-done",
+		tb.TaskRunSelfLink("/test/taskrun1"),
+		tb.TaskRunNamespace("foo"),
+		tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name)),
+		tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+			Type:   apis.ConditionSucceeded,
+			Status: corev1.ConditionTrue,
+		})),
+	)
+	// The delivery of the event failed before the controller restarted
+	taskRun.Status.CloudEvents = []v1beta1.CloudEventDelivery{{
+		Target: "http://synk:8080",
+		Type:   cloudevent.TaskRunSuccessfulEventV1.String(),
+		ID:     "test-event-id",
+		Status: v1beta1.CloudEventDeliveryState{
+			Condition:  v1beta1.CloudEventConditionFailed,
+			SentAt:     &metav1.Time{Time: time.Now().Add(-time.Hour)},
+			Error:      "connection refused",
+			RetryCount: 1,
+		},
+	}}
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		Tasks:    []*v1beta1.Task{simpleTask},
+	}
+
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	clients := testAssets.Clients
+
+	if err := testAssets.Controller.Reconciler.Reconcile(testAssets.Ctx, getRunName(taskRun)); err != nil {
+		t.Errorf("expected no error. Got error %v", err)
+	}
+	// The event is sent in the background, and the outcome of the delivery
+	// is recorded by the next reconcile
+	cloudevent.DeliveryTrackerFromContext(testAssets.Ctx).Wait()
+	tr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(testAssets.Ctx, taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("getting updated taskrun: %v", err)
+	}
+	if status := tr.Status.CloudEvents[0].Status; status.RetryCount != 1 {
+		t.Errorf("Expected the outcome of the second attempt to be recorded by the next reconcile, got %v", status)
+	}
+	if err := testAssets.Controller.Reconciler.Reconcile(testAssets.Ctx, getRunName(taskRun)); err != nil {
+		t.Errorf("expected no error. Got error %v", err)
+	}
+
+	tr, err = clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(testAssets.Ctx, taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("getting updated taskrun: %v", err)
+	}
+	if len(tr.Status.CloudEvents) != 1 {
+		t.Fatalf("Expected one cloud event delivery, got %v", tr.Status.CloudEvents)
+	}
+	if status := tr.Status.CloudEvents[0].Status; status.Condition != v1beta1.CloudEventConditionSent || status.RetryCount != 2 {
+		t.Errorf("Expected the cloud event to be delivered on the second attempt, got %v", status)
+	}
+
+	wantCloudEvents := []string{
+		`(?s)dev.tekton.event.taskrun.successful.v1.*test-event-id.*test-taskrun-done`,
+	}
+	ceClient := clients.CloudEvents.(cloudevent.FakeClient)
+	if err := checkCloudEvents(t, &ceClient, "resend-cloud-events", wantCloudEvents); err != nil {
+		t.Errorf(err.Error())
+	}
+}
+
 func TestReconcile(t *testing.T) {
 	taskRunSuccess := tb.TaskRun("test-taskrun-run-success", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
 		tb.TaskRunTaskRef(simpleTask.Name, tb.TaskRefAPIVersion("a1")),
@@ -1878,6 +1976,238 @@ func TestReconcileOnCompletedTaskRun(t *testing.T) {
 	}
 }
 
+func TestReconcileProvenance(t *testing.T) {
+	key, signingSecret := provenanceSigningSecret(t)
+	taskRun := tb.TaskRun("test-taskrun-provenance", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
+		tb.TaskRunTaskRef(simpleTask.Name),
+	), tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionTrue,
+	}), tb.TaskRunResult("IMAGE_URL", "gcr.io/foo/bar"),
+		tb.TaskRunResult("IMAGE_DIGEST", "sha256:05f95b26ed10668b7183c1e2da98610e91372fa9f510046d4ce5812addad86b5")))
+	// An annotation set by the user does not prevent the provenance from
+	// being recorded
+	taskRun.Annotations = map[string]string{provenance.Annotation: "forged"}
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		Tasks:    []*v1beta1.Task{simpleTask},
+		ConfigMaps: []*corev1.ConfigMap{{
+			ObjectMeta: metav1.ObjectMeta{Name: config.GetProvenanceConfigName(), Namespace: system.GetNamespace()},
+			Data: map[string]string{
+				config.ProvenanceStorageKey:           config.ProvenanceStorageAnnotation,
+				config.ProvenanceSigningSecretNameKey: "provenance-signing-key",
+			},
+		}},
+	}
+
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	c := testAssets.Controller
+	clients := testAssets.Clients
+	if _, err := clients.Kube.CoreV1().Secrets(system.GetNamespace()).Create(testAssets.Ctx, signingSecret, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Reconciler.Reconcile(testAssets.Ctx, getRunName(taskRun)); err != nil {
+		t.Fatalf("Unexpected error when reconciling completed TaskRun : %v", err)
+	}
+	newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(testAssets.Ctx, taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected completed TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+	if d := cmp.Diff(&v1beta1.TaskRunProvenance{Location: provenance.Annotation}, newTr.Status.Provenance); d != "" {
+		t.Errorf("Unexpected provenance status %s", diff.PrintWantGot(d))
+	}
+	var env provenance.Envelope
+	if err := json.Unmarshal([]byte(newTr.Annotations[provenance.Annotation]), &env); err != nil {
+		t.Fatalf("Expected the provenance in the %s annotation: %v", provenance.Annotation, err)
+	}
+	if len(env.Signatures) != 1 {
+		t.Fatalf("Expected one signature of the provenance, got %d", len(env.Signatures))
+	}
+	keyID, err := provenance.KeyID(key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if env.Signatures[0].KeyID != keyID {
+		t.Errorf("Expected the provenance to be signed with key %s, got %s", keyID, env.Signatures[0].KeyID)
+	}
+	var statement provenance.Statement
+	if err := json.Unmarshal(env.Payload, &statement); err != nil {
+		t.Fatal(err)
+	}
+	wantSubject := []provenance.Subject{{
+		Name:   "gcr.io/foo/bar",
+		Digest: provenance.DigestSet{"sha256": "05f95b26ed10668b7183c1e2da98610e91372fa9f510046d4ce5812addad86b5"},
+	}}
+	if d := cmp.Diff(wantSubject, statement.Subject); d != "" {
+		t.Errorf("Unexpected provenance subject %s", diff.PrintWantGot(d))
+	}
+	if statement.Predicate.Recipe.EntryPoint != simpleTask.Name {
+		t.Errorf("Expected provenance entry point %q, got %q", simpleTask.Name, statement.Predicate.Recipe.EntryPoint)
+	}
+}
+
+func TestReconcileProvenance_OCI(t *testing.T) {
+	s := httptest.NewServer(registry.New())
+	defer s.Close()
+	u, err := url.Parse(s.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repository := u.Host + "/tekton/provenance"
+	_, signingSecret := provenanceSigningSecret(t)
+	// The ServiceAccount of the TaskRun does not exist, the provenance is
+	// pushed with the credentials of the controller.
+	taskRun := tb.TaskRun("test-taskrun-provenance-oci", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
+		tb.TaskRunTaskRef(simpleTask.Name),
+		tb.TaskRunServiceAccountName("tenant"),
+	), tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionTrue,
+	})))
+	taskRun.UID = "1234"
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		Tasks:    []*v1beta1.Task{simpleTask},
+		ConfigMaps: []*corev1.ConfigMap{{
+			ObjectMeta: metav1.ObjectMeta{Name: config.GetProvenanceConfigName(), Namespace: system.GetNamespace()},
+			Data: map[string]string{
+				config.ProvenanceStorageKey:           config.ProvenanceStorageOCI,
+				config.ProvenanceOCIRepositoryKey:     repository,
+				config.ProvenanceOCISecretNameKey:     "provenance-push-credentials",
+				config.ProvenanceSigningSecretNameKey: "provenance-signing-key",
+			},
+		}},
+	}
+
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	c := testAssets.Controller
+	clients := testAssets.Clients
+	for _, secret := range []*corev1.Secret{signingSecret, {
+		ObjectMeta: metav1.ObjectMeta{Name: "provenance-push-credentials", Namespace: system.GetNamespace()},
+		Type:       corev1.SecretTypeDockerConfigJson,
+		Data: map[string][]byte{
+			corev1.DockerConfigJsonKey: []byte(`{"auths":{"` + u.Host + `":{"username":"tekton","password":"secret"}}}`),
+		},
+	}} {
+		if _, err := clients.Kube.CoreV1().Secrets(system.GetNamespace()).Create(testAssets.Ctx, secret, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := clients.Kube.CoreV1().ServiceAccounts(system.GetNamespace()).Create(testAssets.Ctx, &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: system.GetNamespace()},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Reconciler.Reconcile(testAssets.Ctx, getRunName(taskRun)); err != nil {
+		t.Fatalf("Unexpected error when reconciling completed TaskRun : %v", err)
+	}
+	newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(testAssets.Ctx, taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected completed TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+	ref := newTr.Annotations[provenance.ImageAnnotation]
+	if !strings.HasPrefix(ref, repository+"@sha256:") {
+		t.Errorf("Expected the provenance to be pushed to %s, got %q", repository, ref)
+	}
+	if d := cmp.Diff(&v1beta1.TaskRunProvenance{Location: ref}, newTr.Status.Provenance); d != "" {
+		t.Errorf("Unexpected provenance status %s", diff.PrintWantGot(d))
+	}
+}
+
+func TestReconcileProvenance_PermanentFailure(t *testing.T) {
+	_, signingSecret := provenanceSigningSecret(t)
+	for _, tc := range []struct {
+		name        string
+		annotations map[string]string
+		secrets     []*corev1.Secret
+		wantError   string
+	}{{
+		name:      "missing signing secret",
+		wantError: "failed to get provenance signing secret provenance-signing-key",
+	}, {
+		name: "invalid signing key",
+		secrets: []*corev1.Secret{{
+			ObjectMeta: metav1.ObjectMeta{Name: "provenance-signing-key", Namespace: system.GetNamespace()},
+			Data:       map[string][]byte{"private-key": []byte("not a key")},
+		}},
+		wantError: "failed to load provenance signing key from secret provenance-signing-key",
+	}, {
+		name:        "annotations too large",
+		annotations: map[string]string{"large": strings.Repeat("x", 256*1024)},
+		secrets:     []*corev1.Secret{signingSecret},
+		wantError:   "does not fit in its annotations",
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			taskRun := tb.TaskRun("test-taskrun-provenance-failure", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
+				tb.TaskRunTaskRef(simpleTask.Name),
+			), tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+				Type:   apis.ConditionSucceeded,
+				Status: corev1.ConditionTrue,
+			})))
+			taskRun.Annotations = tc.annotations
+			d := test.Data{
+				TaskRuns: []*v1beta1.TaskRun{taskRun},
+				Tasks:    []*v1beta1.Task{simpleTask},
+				ConfigMaps: []*corev1.ConfigMap{{
+					ObjectMeta: metav1.ObjectMeta{Name: config.GetProvenanceConfigName(), Namespace: system.GetNamespace()},
+					Data: map[string]string{
+						config.ProvenanceStorageKey:           config.ProvenanceStorageAnnotation,
+						config.ProvenanceSigningSecretNameKey: "provenance-signing-key",
+					},
+				}},
+			}
+			testAssets, cancel := getTaskRunController(t, d)
+			defer cancel()
+			c := testAssets.Controller
+			clients := testAssets.Clients
+			for _, secret := range tc.secrets {
+				if _, err := clients.Kube.CoreV1().Secrets(system.GetNamespace()).Create(testAssets.Ctx, secret, metav1.CreateOptions{}); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// The failure is recorded, and not retried
+			if err := c.Reconciler.Reconcile(testAssets.Ctx, getRunName(taskRun)); err != nil {
+				t.Fatalf("Unexpected error when reconciling completed TaskRun : %v", err)
+			}
+			newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(testAssets.Ctx, taskRun.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected completed TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+			}
+			if newTr.Status.Provenance == nil || !strings.Contains(newTr.Status.Provenance.Error, tc.wantError) {
+				t.Errorf("Expected the provenance failure %q to be recorded, got %v", tc.wantError, newTr.Status.Provenance)
+			}
+			if _, ok := newTr.Annotations[provenance.Annotation]; ok {
+				t.Errorf("Expected no provenance annotation, got %v", newTr.Annotations)
+			}
+		})
+	}
+}
+
+// provenanceSigningSecret returns a new signing key, and the Secret holding it
+// in the namespace of the controller.
+func provenanceSigningSecret(t *testing.T) (*ecdsa.PrivateKey, *corev1.Secret) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "provenance-signing-key", Namespace: system.GetNamespace()},
+		Data: map[string][]byte{
+			"private-key": pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}),
+		},
+	}
+}
+
 func TestReconcileOnCancelledTaskRun(t *testing.T) {
 	taskRun := tb.TaskRun("test-taskrun-run-cancelled",
 		tb.TaskRunNamespace("foo"),
@@ -2081,7 +2411,7 @@ func TestHandlePodCreationError(t *testing.T) {
 		err:            k8sapierrors.NewForbidden(k8sruntimeschema.GroupResource{Group: "foo", Resource: "bar"}, "baz", errors.New("exceeded quota")),
 		expectedType:   apis.ConditionSucceeded,
 		expectedStatus: corev1.ConditionUnknown,
-		expectedReason: podconvert.ReasonExceededResourceQuota,
+		expectedReason: podconvert.ReasonResourceQuotaQueued,
 	}, {
 		description:    "taskrun validation failed",
 		err:            errors.New("TaskRun validation failed"),
@@ -2112,6 +2442,286 @@ func TestHandlePodCreationError(t *testing.T) {
 	}
 }
 
+func TestHandlePodCreationErrorResourceQuotaMaxWait(t *testing.T) {
+	quotaErr := k8sapierrors.NewForbidden(k8sruntimeschema.GroupResource{Group: "foo", Resource: "bar"}, "baz", errors.New("exceeded quota"))
+	testcases := []struct {
+		description    string
+		queuedTime     time.Time
+		expectedStatus corev1.ConditionStatus
+		expectedReason string
+		wantCompletion bool
+	}{{
+		description:    "taskrun queued for less than the max wait keeps waiting",
+		queuedTime:     time.Now().Add(-5 * time.Minute),
+		expectedStatus: corev1.ConditionUnknown,
+		expectedReason: podconvert.ReasonResourceQuotaQueued,
+	}, {
+		description:    "taskrun queued for longer than the max wait fails",
+		queuedTime:     time.Now().Add(-15 * time.Minute),
+		expectedStatus: corev1.ConditionFalse,
+		expectedReason: podconvert.ReasonExceededResourceQuota,
+		wantCompletion: true,
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.description, func(t *testing.T) {
+			taskRun := tb.TaskRun("test-taskrun-pod-creation-queued", tb.TaskRunSpec(
+				tb.TaskRunTaskRef(simpleTask.Name),
+			), tb.TaskRunStatus(
+				tb.TaskRunStartTime(time.Now().Add(-20*time.Minute)),
+				tb.StatusCondition(apis.Condition{
+					Type:   apis.ConditionSucceeded,
+					Status: corev1.ConditionUnknown,
+				}),
+			))
+			taskRun.Status.QueuedTime = &metav1.Time{Time: tc.queuedTime}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			cfg := config.FromContextOrDefaults(ctx)
+			cfg.Defaults.DefaultResourceQuotaMaxWaitMinutes = 10
+			ctx = config.ToContext(ctx, cfg)
+
+			c := &Reconciler{
+				timeoutHandler: timeout.NewHandler(ctx.Done(), logging.FromContext(ctx)),
+			}
+			// Prevent backoff timer from starting
+			c.timeoutHandler.SetCallbackFunc(nil)
+
+			c.handlePodCreationError(ctx, taskRun, quotaErr)
+			condition := taskRun.Status.GetCondition(apis.ConditionSucceeded)
+			if condition.Status != tc.expectedStatus || condition.Reason != tc.expectedReason {
+				t.Errorf("expected condition status %q and reason %q, got %q and %q", tc.expectedStatus, tc.expectedReason, condition.Status, condition.Reason)
+			}
+			if gotCompletion := taskRun.Status.CompletionTime != nil; gotCompletion != tc.wantCompletion {
+				t.Errorf("expected completion time to be set: %t, got %t", tc.wantCompletion, gotCompletion)
+			}
+			if !taskRun.Status.QueuedTime.Time.Equal(tc.queuedTime) {
+				t.Errorf("expected queued time %s to be preserved, got %s", tc.queuedTime, taskRun.Status.QueuedTime)
+			}
+		})
+	}
+}
+
+func TestResourceQuotaDeadline(t *testing.T) {
+	startTime := metav1.NewTime(time.Now().Add(-30 * time.Minute))
+	queuedTime := metav1.NewTime(time.Now().Add(-20 * time.Minute))
+	for _, tc := range []struct {
+		description               string
+		timeout                   time.Duration
+		maxWait                   time.Duration
+		startTimeoutAtPodCreation bool
+		expectedStartTime         metav1.Time
+		expectedTimeout           time.Duration
+	}{{
+		description:       "bounded by the taskrun timeout",
+		timeout:           time.Hour,
+		expectedStartTime: startTime,
+		expectedTimeout:   time.Hour,
+	}, {
+		description:       "bounded by the max wait when it expires first",
+		timeout:           time.Hour,
+		maxWait:           10 * time.Minute,
+		expectedStartTime: queuedTime,
+		expectedTimeout:   10 * time.Minute,
+	}, {
+		description:       "bounded by the taskrun timeout when it expires first",
+		timeout:           time.Hour,
+		maxWait:           2 * time.Hour,
+		expectedStartTime: startTime,
+		expectedTimeout:   time.Hour,
+	}, {
+		description:       "bounded by the max wait when there is no timeout",
+		timeout:           config.NoTimeoutDuration,
+		maxWait:           2 * time.Hour,
+		expectedStartTime: queuedTime,
+		expectedTimeout:   2 * time.Hour,
+	}, {
+		description:               "timeout starts at pod creation and no max wait",
+		timeout:                   time.Hour,
+		startTimeoutAtPodCreation: true,
+		expectedStartTime:         queuedTime,
+		expectedTimeout:           time.Hour,
+	}, {
+		description:               "timeout starts at pod creation with max wait",
+		timeout:                   time.Hour,
+		maxWait:                   10 * time.Minute,
+		startTimeoutAtPodCreation: true,
+		expectedStartTime:         queuedTime,
+		expectedTimeout:           10 * time.Minute,
+	}, {
+		description:               "timeout starts at pod creation and no timeout",
+		timeout:                   config.NoTimeoutDuration,
+		maxWait:                   2 * time.Hour,
+		startTimeoutAtPodCreation: true,
+		expectedStartTime:         queuedTime,
+		expectedTimeout:           2 * time.Hour,
+	}} {
+		t.Run(tc.description, func(t *testing.T) {
+			tr := tb.TaskRun("test-taskrun", tb.TaskRunSpec(
+				tb.TaskRunTaskRef(simpleTask.Name),
+				tb.TaskRunTimeout(tc.timeout),
+			))
+			tr.Status.StartTime = &startTime
+			tr.Status.QueuedTime = &queuedTime
+
+			ctx := context.Background()
+			cfg := config.FromContextOrDefaults(ctx)
+			cfg.FeatureFlags.StartTimeoutAtPodCreation = tc.startTimeoutAtPodCreation
+			ctx = config.ToContext(ctx, cfg)
+
+			gotStartTime, gotTimeout := resourceQuotaDeadline(ctx, tr, tc.maxWait)
+			if !gotStartTime.Equal(&tc.expectedStartTime) {
+				t.Errorf("expected start time %s, got %s", tc.expectedStartTime, gotStartTime)
+			}
+			if gotTimeout.Duration != tc.expectedTimeout {
+				t.Errorf("expected timeout %s, got %s", tc.expectedTimeout, gotTimeout.Duration)
+			}
+		})
+	}
+}
+
+func TestReconcileTimeoutStartsAtPodCreation(t *testing.T) {
+	// The taskrun was started long before its timeout, but it has not been
+	// able to create its pod yet.
+	taskRun := tb.TaskRun("test-taskrun-timeout-at-pod-creation", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
+		tb.TaskRunTaskRef(simpleTask.Name),
+		tb.TaskRunTimeout(10*time.Minute),
+	), tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionUnknown}),
+		tb.TaskRunStartTime(time.Now().Add(-time.Hour)),
+	))
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		Tasks:    []*v1beta1.Task{simpleTask},
+		ConfigMaps: []*corev1.ConfigMap{{
+			ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+			Data: map[string]string{
+				"start-timeout-at-pod-creation": "true",
+			},
+		}},
+	}
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	c := testAssets.Controller
+	clients := testAssets.Clients
+
+	if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(testAssets.Ctx, &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Reconciler.Reconcile(testAssets.Ctx, getRunName(taskRun)); err != nil {
+		t.Fatalf("Unexpected error when reconciling TaskRun: %v", err)
+	}
+	newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(testAssets.Ctx, taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+	if newTr.IsDone() {
+		t.Errorf("Expected TaskRun not to time out before its pod was created, got condition %v", newTr.Status.GetCondition(apis.ConditionSucceeded))
+	}
+	if newTr.Status.PodName == "" {
+		t.Errorf("Expected a pod to be created for TaskRun %s", newTr.Name)
+	}
+	if time.Since(newTr.Status.StartTime.Time) > time.Minute {
+		t.Errorf("Expected the start time to be reset at pod creation, got %s", newTr.Status.StartTime)
+	}
+}
+
+func TestReconcileTimeoutStartsAtPodCreationQueuedTooLong(t *testing.T) {
+	// The pod of the taskrun has been rejected by a ResourceQuota for longer
+	// than its timeout, so it times out even though it never got a pod.
+	taskRun := tb.TaskRun("test-taskrun-queued-too-long", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
+		tb.TaskRunTaskRef(simpleTask.Name),
+		tb.TaskRunTimeout(10*time.Minute),
+	), tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionUnknown,
+		Reason: podconvert.ReasonResourceQuotaQueued}),
+		tb.TaskRunStartTime(time.Now().Add(-time.Hour)),
+	))
+	taskRun.Status.QueuedTime = &metav1.Time{Time: time.Now().Add(-time.Hour)}
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		Tasks:    []*v1beta1.Task{simpleTask},
+		ConfigMaps: []*corev1.ConfigMap{{
+			ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+			Data: map[string]string{
+				"start-timeout-at-pod-creation": "true",
+			},
+		}},
+	}
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	c := testAssets.Controller
+	clients := testAssets.Clients
+
+	if err := c.Reconciler.Reconcile(testAssets.Ctx, getRunName(taskRun)); err != nil {
+		t.Fatalf("Unexpected error when reconciling TaskRun: %v", err)
+	}
+	newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(testAssets.Ctx, taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+	condition := newTr.Status.GetCondition(apis.ConditionSucceeded)
+	if condition == nil || condition.Reason != v1beta1.TaskRunReasonTimedOut.String() {
+		t.Errorf("Expected TaskRun to time out while queued, got condition %v", condition)
+	}
+	if newTr.Status.PodName != "" {
+		t.Errorf("Expected no pod to be created for TaskRun %s, got %s", newTr.Name, newTr.Status.PodName)
+	}
+}
+
+func TestReconcileTimeoutStartsAtPodCreationRecreatedPod(t *testing.T) {
+	// The pod of the taskrun was lost, recreating it must not restart the
+	// timeout of the taskrun.
+	taskRun := tb.TaskRun("test-taskrun-recreated-pod", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
+		tb.TaskRunTaskRef(simpleTask.Name),
+		tb.TaskRunTimeout(10*time.Minute),
+	), tb.TaskRunStatus(tb.StatusCondition(apis.Condition{
+		Type:   apis.ConditionSucceeded,
+		Status: corev1.ConditionUnknown}),
+		tb.TaskRunStartTime(time.Now().Add(-5*time.Minute)),
+		tb.PodName("test-taskrun-recreated-pod-lost"),
+	))
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		Tasks:    []*v1beta1.Task{simpleTask},
+		ConfigMaps: []*corev1.ConfigMap{{
+			ObjectMeta: metav1.ObjectMeta{Name: config.GetFeatureFlagsConfigName(), Namespace: system.GetNamespace()},
+			Data: map[string]string{
+				"start-timeout-at-pod-creation": "true",
+			},
+		}},
+	}
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	c := testAssets.Controller
+	clients := testAssets.Clients
+
+	if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(testAssets.Ctx, &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Reconciler.Reconcile(testAssets.Ctx, getRunName(taskRun)); err != nil {
+		t.Fatalf("Unexpected error when reconciling TaskRun: %v", err)
+	}
+	newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(testAssets.Ctx, taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+	if newTr.Status.PodName == taskRun.Status.PodName {
+		t.Errorf("Expected the lost pod of TaskRun %s to be recreated", newTr.Name)
+	}
+	if time.Since(newTr.Status.StartTime.Time) < 4*time.Minute {
+		t.Errorf("Expected the start time not to be reset when the pod is recreated, got %s", newTr.Status.StartTime)
+	}
+}
+
 func TestReconcileCloudEvents(t *testing.T) {
 
 	taskRunWithNoCEResources := tb.TaskRun("test-taskrun-no-ce-resources",
@@ -2877,6 +3487,7 @@ func TestFailTaskRun(t *testing.T) {
 		message            string
 		expectedStatus     apis.Condition
 		expectedStepStates []v1beta1.StepState
+		expectedFailure    *v1beta1.TaskRunFailure
 	}{{
 		name: "no-pod-scheduled",
 		taskRun: tb.TaskRun("test-taskrun-run-failed", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
@@ -2886,8 +3497,9 @@ func TestFailTaskRun(t *testing.T) {
 			Type:   apis.ConditionSucceeded,
 			Status: corev1.ConditionUnknown,
 		}))),
-		reason:  "some reason",
-		message: "some message",
+		reason:          "some reason",
+		message:         "some message",
+		expectedFailure: &v1beta1.TaskRunFailure{Reason: "some reason"},
 		expectedStatus: apis.Condition{
 			Type:    apis.ConditionSucceeded,
 			Status:  corev1.ConditionFalse,
@@ -2907,8 +3519,9 @@ func TestFailTaskRun(t *testing.T) {
 			Namespace: "foo",
 			Name:      "foo-is-bar",
 		}},
-		reason:  "some reason",
-		message: "some message",
+		reason:          "some reason",
+		message:         "some message",
+		expectedFailure: &v1beta1.TaskRunFailure{Reason: "some reason"},
 		expectedStatus: apis.Condition{
 			Type:    apis.ConditionSucceeded,
 			Status:  corev1.ConditionFalse,
@@ -2963,8 +3576,9 @@ func TestFailTaskRun(t *testing.T) {
 			Namespace: "foo",
 			Name:      "foo-is-bar",
 		}},
-		reason:  v1beta1.TaskRunReasonTimedOut,
-		message: "TaskRun test-taskrun-run-timeout failed to finish within 10s",
+		reason:          v1beta1.TaskRunReasonTimedOut,
+		message:         "TaskRun test-taskrun-run-timeout failed to finish within 10s",
+		expectedFailure: &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonTimedOut},
 		expectedStatus: apis.Condition{
 			Type:    apis.ConditionSucceeded,
 			Status:  corev1.ConditionFalse,
@@ -3001,8 +3615,9 @@ func TestFailTaskRun(t *testing.T) {
 			Namespace: "foo",
 			Name:      "foo-is-bar",
 		}},
-		reason:  v1beta1.TaskRunReasonTimedOut,
-		message: "TaskRun test-taskrun-run-timeout-multiple-steps failed to finish within 10s",
+		reason:          v1beta1.TaskRunReasonTimedOut,
+		message:         "TaskRun test-taskrun-run-timeout-multiple-steps failed to finish within 10s",
+		expectedFailure: &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonTimedOut},
 		expectedStatus: apis.Condition{
 			Type:    apis.ConditionSucceeded,
 			Status:  corev1.ConditionFalse,
@@ -3055,8 +3670,9 @@ func TestFailTaskRun(t *testing.T) {
 			Namespace: "foo",
 			Name:      "foo-is-bar",
 		}},
-		reason:  v1beta1.TaskRunReasonTimedOut,
-		message: "TaskRun test-taskrun-run-timeout-multiple-steps-waiting failed to finish within 10s",
+		reason:          v1beta1.TaskRunReasonTimedOut,
+		message:         "TaskRun test-taskrun-run-timeout-multiple-steps-waiting failed to finish within 10s",
+		expectedFailure: &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonTimedOut},
 		expectedStatus: apis.Condition{
 			Type:    apis.ConditionSucceeded,
 			Status:  corev1.ConditionFalse,
@@ -3125,6 +3741,9 @@ func TestFailTaskRun(t *testing.T) {
 			if d := cmp.Diff(tc.taskRun.Status.GetCondition(apis.ConditionSucceeded), &tc.expectedStatus, ignoreLastTransitionTime); d != "" {
 				t.Fatalf(diff.PrintWantGot(d))
 			}
+			if d := cmp.Diff(tc.expectedFailure, tc.taskRun.Status.Failure); d != "" {
+				t.Errorf("Unexpected failure %s", diff.PrintWantGot(d))
+			}
 
 			if tc.expectedStepStates != nil {
 				ignoreTerminatedFields := cmpopts.IgnoreFields(corev1.ContainerStateTerminated{}, "StartedAt", "FinishedAt")
@@ -3136,6 +3755,481 @@ func TestFailTaskRun(t *testing.T) {
 	}
 }
 
+// TestReconcileStepActionRef tests a reconcile of a TaskRun whose Task has a Step
+// referencing a StepAction, which is resolved before the TaskSpec is stored.
+func TestReconcileStepActionRef(t *testing.T) {
+	stepAction := &v1beta1.StepAction{
+		ObjectMeta: metav1.ObjectMeta{Name: "notify", Namespace: "foo"},
+		Spec: v1beta1.StepActionSpec{
+			Image:  "curlimages/curl",
+			Script: "curl -X POST $(params.url)",
+			Params: []v1beta1.ParamSpec{{Name: "url", Type: v1beta1.ParamTypeString}},
+		},
+	}
+	task := &v1beta1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-task-with-step-ref", Namespace: "foo"},
+		Spec: v1beta1.TaskSpec{
+			Params: []v1beta1.ParamSpec{{Name: "webhook", Type: v1beta1.ParamTypeString}},
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{Name: "notify"},
+				Ref:       &v1beta1.Ref{Name: "notify"},
+				Params: []v1beta1.Param{{
+					Name:  "url",
+					Value: *v1beta1.NewArrayOrString("$(params.webhook)"),
+				}},
+			}},
+		},
+	}
+	taskRun := tb.TaskRun("test-taskrun-step-ref", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(
+		tb.TaskRunTaskRef(task.Name),
+		tb.TaskRunParam("webhook", "https://example.com/hook"),
+	))
+	d := test.Data{
+		Tasks:       []*v1beta1.Task{task},
+		TaskRuns:    []*v1beta1.TaskRun{taskRun},
+		StepActions: []*v1beta1.StepAction{stepAction},
+	}
+	names.TestingSeed()
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	clients := testAssets.Clients
+
+	if _, err := clients.Kube.CoreV1().ServiceAccounts("foo").Create(testAssets.Ctx, &corev1.ServiceAccount{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+	}, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := testAssets.Controller.Reconciler.Reconcile(testAssets.Ctx, getRunName(taskRun)); err != nil {
+		t.Errorf("expected no error. Got error %v", err)
+	}
+
+	tr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(testAssets.Ctx, taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+	wantSteps := []v1beta1.Step{{
+		Container: corev1.Container{Name: "notify", Image: "curlimages/curl"},
+		Script:    "curl -X POST $(params.webhook)",
+	}}
+	if tr.Status.TaskSpec == nil {
+		t.Fatal("Expected the resolved TaskSpec to be stored in the TaskRun status")
+	}
+	if d := cmp.Diff(wantSteps, tr.Status.TaskSpec.Steps); d != "" {
+		t.Errorf("Stored TaskSpec steps don't match %s", diff.PrintWantGot(d))
+	}
+
+	pod, err := clients.Kube.CoreV1().Pods(tr.Namespace).Get(testAssets.Ctx, tr.Status.PodName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Failed to fetch build pod: %v", err)
+	}
+	if got := pod.Spec.Containers[0].Image; got != "curlimages/curl" {
+		t.Errorf("Expected step container to run image %q, got %q", "curlimages/curl", got)
+	}
+}
+
+func TestReconcileRequiredSidecarFailed(t *testing.T) {
+	task := &v1beta1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-task-with-sidecar", Namespace: "foo"},
+		Spec: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{Name: "test", Image: "foo", Command: []string{"/mycmd"}},
+				Sidecars:  []string{"database"},
+			}},
+			Sidecars: []v1beta1.Sidecar{{
+				Container: corev1.Container{Name: "database", Image: "postgres"},
+			}},
+		},
+	}
+	taskRun := tb.TaskRun("test-taskrun-sidecar-failed", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(tb.TaskRunTaskRef(task.Name)))
+	pod, err := makePod(taskRun, task)
+	if err != nil {
+		t.Fatalf("MakePod: %v", err)
+	}
+	pod.Status = corev1.PodStatus{
+		Phase: corev1.PodRunning,
+		ContainerStatuses: []corev1.ContainerStatus{{
+			Name:  "step-test",
+			State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		}, {
+			Name:    "sidecar-database",
+			ImageID: "postgres",
+			State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+				ExitCode: 1,
+				Message:  "database files are incompatible with server",
+			}},
+		}},
+	}
+	taskRun.Status = v1beta1.TaskRunStatus{
+		TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+			PodName: pod.Name,
+		},
+	}
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		Tasks:    []*v1beta1.Task{task},
+		Pods:     []*corev1.Pod{pod},
+	}
+
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	clients := testAssets.Clients
+
+	if err := testAssets.Controller.Reconciler.Reconcile(testAssets.Ctx, getRunName(taskRun)); err != nil {
+		t.Fatalf("Unexpected error when Reconcile() : %v", err)
+	}
+	newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(testAssets.Ctx, taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+	if d := cmp.Diff(&apis.Condition{
+		Type:    apis.ConditionSucceeded,
+		Status:  corev1.ConditionFalse,
+		Reason:  v1beta1.TaskRunReasonSidecarFailed.String(),
+		Message: fmt.Sprintf("sidecar \"sidecar-database\" exited with code 1 (image: \"postgres\"): database files are incompatible with server; for logs run: kubectl -n foo logs %s -c sidecar-database\n", pod.Name),
+	}, newTr.Status.GetCondition(apis.ConditionSucceeded), ignoreLastTransitionTime); d != "" {
+		t.Errorf("Did not get expected condition %s", diff.PrintWantGot(d))
+	}
+	if d := cmp.Diff(&v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonSidecarFailed, Sidecar: "database", ExitCode: 1}, newTr.Status.Failure); d != "" {
+		t.Errorf("Did not get expected failure %s", diff.PrintWantGot(d))
+	}
+	if _, err := clients.Kube.CoreV1().Pods(taskRun.Namespace).Get(testAssets.Ctx, pod.Name, metav1.GetOptions{}); !k8sapierrors.IsNotFound(err) {
+		t.Errorf("Expected the Pod of the failed TaskRun to be deleted, got %v", err)
+	}
+}
+
+func TestReconcileImagePullFailed(t *testing.T) {
+	task := &v1beta1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-task-image-pull", Namespace: "foo"},
+		Spec: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{Name: "build", Image: "registry.example.com/missing", Command: []string{"/mycmd"}},
+			}},
+		},
+	}
+	for _, tc := range []struct {
+		name          string
+		waiting       *corev1.ContainerStateWaiting
+		timedOut      bool
+		wantCondition *apis.Condition
+		wantFailure   *v1beta1.TaskRunFailure
+	}{{
+		name:    "invalid image name",
+		waiting: &corev1.ContainerStateWaiting{Reason: "InvalidImageName", Message: "invalid reference format"},
+		wantCondition: &apis.Condition{
+			Type:    apis.ConditionSucceeded,
+			Status:  corev1.ConditionFalse,
+			Reason:  v1beta1.TaskRunReasonImagePullFailed.String(),
+			Message: `the image "registry.example.com/missing" of "step-build" cannot be pulled: invalid reference format`,
+		},
+		wantFailure: &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonImagePullFailed, Step: "build"},
+	}, {
+		name:    "image pull backing off",
+		waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"},
+		wantCondition: &apis.Condition{
+			Type:    apis.ConditionSucceeded,
+			Status:  corev1.ConditionUnknown,
+			Reason:  podconvert.ReasonPending,
+			Message: `build step "step-build" is pending with reason "Back-off pulling image"`,
+		},
+	}, {
+		name:     "timed out while image pull backing off",
+		waiting:  &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff", Message: "Back-off pulling image"},
+		timedOut: true,
+		wantCondition: &apis.Condition{
+			Type:    apis.ConditionSucceeded,
+			Status:  corev1.ConditionFalse,
+			Reason:  v1beta1.TaskRunReasonImagePullFailed.String(),
+			Message: `TaskRun "test-taskrun-image-pull-failed" failed to finish within "10s"; the image of "step-build" could not be pulled: Back-off pulling image`,
+		},
+		wantFailure: &v1beta1.TaskRunFailure{Reason: v1beta1.TaskRunReasonImagePullFailed, Step: "build"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			taskRun := tb.TaskRun("test-taskrun-image-pull-failed", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(tb.TaskRunTaskRef(task.Name), tb.TaskRunTimeout(10*time.Second)))
+			pod, err := makePod(taskRun, task)
+			if err != nil {
+				t.Fatalf("MakePod: %v", err)
+			}
+			pod.Status = corev1.PodStatus{
+				Phase: corev1.PodPending,
+				ContainerStatuses: []corev1.ContainerStatus{{
+					Name:  "step-build",
+					Image: "registry.example.com/missing",
+					State: corev1.ContainerState{Waiting: tc.waiting},
+				}},
+			}
+			taskRun.Status = v1beta1.TaskRunStatus{
+				TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+					PodName:   pod.Name,
+					StartTime: &metav1.Time{Time: time.Now()},
+				},
+			}
+			if tc.timedOut {
+				taskRun.Status.StartTime = &metav1.Time{Time: time.Now().Add(-15 * time.Second)}
+				taskRun.Status.Steps = []v1beta1.StepState{{
+					Name:           "build",
+					ContainerName:  "step-build",
+					ContainerState: corev1.ContainerState{Waiting: tc.waiting},
+				}}
+			}
+			d := test.Data{
+				TaskRuns: []*v1beta1.TaskRun{taskRun},
+				Tasks:    []*v1beta1.Task{task},
+				Pods:     []*corev1.Pod{pod},
+			}
+
+			testAssets, cancel := getTaskRunController(t, d)
+			defer cancel()
+			clients := testAssets.Clients
+
+			if err := testAssets.Controller.Reconciler.Reconcile(testAssets.Ctx, getRunName(taskRun)); err != nil {
+				t.Fatalf("Unexpected error when Reconcile() : %v", err)
+			}
+			newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(testAssets.Ctx, taskRun.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+			}
+			if d := cmp.Diff(tc.wantCondition, newTr.Status.GetCondition(apis.ConditionSucceeded), ignoreLastTransitionTime); d != "" {
+				t.Errorf("Did not get expected condition %s", diff.PrintWantGot(d))
+			}
+			if d := cmp.Diff(tc.wantFailure, newTr.Status.Failure); d != "" {
+				t.Errorf("Did not get expected failure %s", diff.PrintWantGot(d))
+			}
+			_, err = clients.Kube.CoreV1().Pods(taskRun.Namespace).Get(testAssets.Ctx, pod.Name, metav1.GetOptions{})
+			if tc.wantFailure != nil && !k8sapierrors.IsNotFound(err) {
+				t.Errorf("Expected the Pod of the failed TaskRun to be deleted, got %v", err)
+			}
+			if tc.wantFailure == nil && err != nil {
+				t.Errorf("Expected the Pod of the running TaskRun to be kept, got %v", err)
+			}
+		})
+	}
+}
+
+func TestReconcileStepPods(t *testing.T) {
+	task := &v1beta1.Task{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-task-step-pods", Namespace: "foo"},
+		Spec: v1beta1.TaskSpec{
+			Steps: []v1beta1.Step{{
+				Container: corev1.Container{Name: "build", Image: "foo", Command: []string{"/mycmd"}},
+			}, {
+				Container: corev1.Container{Name: "test", Image: "foo", Command: []string{"/mycmd"}},
+			}},
+			OnFailure: []v1beta1.Step{{
+				Container: corev1.Container{Name: "notify", Image: "foo", Command: []string{"/mycmd"}},
+			}},
+		},
+	}
+	gpuTemplate := &v1beta1.PodTemplate{NodeSelector: map[string]string{"accelerator": "nvidia-tesla-t4"}}
+	newTaskRun := func() *v1beta1.TaskRun {
+		tr := tb.TaskRun("test-taskrun-step-pods", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(tb.TaskRunTaskRef(task.Name)))
+		tr.Spec.StepPods = []v1beta1.TaskRunStepPod{{Name: "test", PodTemplate: gpuTemplate}}
+		return tr
+	}
+	stepContainerNames := func(pod *corev1.Pod) []string {
+		var names []string
+		for _, c := range pod.Spec.Containers {
+			names = append(names, c.Name)
+		}
+		return names
+	}
+
+	t.Run("first pod", func(t *testing.T) {
+		taskRun := newTaskRun()
+		d := test.Data{
+			TaskRuns: []*v1beta1.TaskRun{taskRun},
+			Tasks:    []*v1beta1.Task{task},
+			ServiceAccounts: []*corev1.ServiceAccount{{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+			}},
+		}
+		testAssets, cancel := getTaskRunController(t, d)
+		defer cancel()
+		clients := testAssets.Clients
+
+		if err := testAssets.Controller.Reconciler.Reconcile(testAssets.Ctx, getRunName(taskRun)); err != nil {
+			t.Fatalf("Unexpected error when Reconcile() : %v", err)
+		}
+		newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(testAssets.Ctx, taskRun.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+		}
+		if d := cmp.Diff([]string{newTr.Status.PodName}, newTr.Status.PodNames); d != "" {
+			t.Errorf("Unexpected pod names %s", diff.PrintWantGot(d))
+		}
+		pod, err := clients.Kube.CoreV1().Pods(taskRun.Namespace).Get(testAssets.Ctx, newTr.Status.PodName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected the Pod of the TaskRun to exist: %v", err)
+		}
+		if d := cmp.Diff([]string{"step-build"}, stepContainerNames(pod)); d != "" {
+			t.Errorf("Unexpected containers of the first pod %s", diff.PrintWantGot(d))
+		}
+	})
+
+	t.Run("next pod once the first one succeeded", func(t *testing.T) {
+		taskRun := newTaskRun()
+		pod, err := makePod(taskRun, task)
+		if err != nil {
+			t.Fatalf("MakePod: %v", err)
+		}
+		pod.Status = corev1.PodStatus{
+			Phase: corev1.PodSucceeded,
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "step-build",
+				State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{}},
+			}},
+		}
+		taskRun.Status = v1beta1.TaskRunStatus{
+			TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+				PodName:  pod.Name,
+				PodNames: []string{pod.Name},
+			},
+		}
+		d := test.Data{
+			TaskRuns: []*v1beta1.TaskRun{taskRun},
+			Tasks:    []*v1beta1.Task{task},
+			Pods:     []*corev1.Pod{pod},
+			ServiceAccounts: []*corev1.ServiceAccount{{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+			}},
+		}
+		testAssets, cancel := getTaskRunController(t, d)
+		defer cancel()
+		clients := testAssets.Clients
+
+		if err := testAssets.Controller.Reconciler.Reconcile(testAssets.Ctx, getRunName(taskRun)); err != nil {
+			t.Fatalf("Unexpected error when Reconcile() : %v", err)
+		}
+		newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(testAssets.Ctx, taskRun.Name, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+		}
+		if c := newTr.Status.GetCondition(apis.ConditionSucceeded); c == nil || c.Status != corev1.ConditionUnknown {
+			t.Errorf("Expected the TaskRun to keep running, got condition %v", c)
+		}
+		if len(newTr.Status.PodNames) != 2 || newTr.Status.PodNames[0] != pod.Name || newTr.Status.PodNames[1] != newTr.Status.PodName {
+			t.Errorf("Expected the pod names to be %q and the current pod, got %v", pod.Name, newTr.Status.PodNames)
+		}
+		if len(newTr.Status.Steps) != 1 || newTr.Status.Steps[0].Name != "build" {
+			t.Errorf("Expected the state of the build step to be kept, got %v", newTr.Status.Steps)
+		}
+		nextPod, err := clients.Kube.CoreV1().Pods(taskRun.Namespace).Get(testAssets.Ctx, newTr.Status.PodName, metav1.GetOptions{})
+		if err != nil {
+			t.Fatalf("Expected the next Pod of the TaskRun to exist: %v", err)
+		}
+		if d := cmp.Diff([]string{"step-test", "step-on-failure-notify"}, stepContainerNames(nextPod)); d != "" {
+			t.Errorf("Unexpected containers of the next pod %s", diff.PrintWantGot(d))
+		}
+		if d := cmp.Diff(gpuTemplate.NodeSelector, nextPod.Spec.NodeSelector); d != "" {
+			t.Errorf("Unexpected node selector of the next pod %s", diff.PrintWantGot(d))
+		}
+	})
+
+	for _, tc := range []struct {
+		name           string
+		lostPod        int
+		wantContainers []string
+	}{{
+		name:           "first pod deleted",
+		lostPod:        0,
+		wantContainers: []string{"step-build"},
+	}, {
+		name:           "last pod deleted",
+		lostPod:        1,
+		wantContainers: []string{"step-test", "step-on-failure-notify"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			taskRun := newTaskRun()
+			podNames := []string{"test-taskrun-step-pods-pod-1", "test-taskrun-step-pods-pod-2"}[:tc.lostPod+1]
+			taskRun.Status = v1beta1.TaskRunStatus{
+				TaskRunStatusFields: v1beta1.TaskRunStatusFields{
+					PodName:   podNames[tc.lostPod],
+					PodNames:  podNames,
+					StartTime: &metav1.Time{Time: time.Now()},
+				},
+			}
+			d := test.Data{
+				TaskRuns: []*v1beta1.TaskRun{taskRun},
+				Tasks:    []*v1beta1.Task{task},
+				ServiceAccounts: []*corev1.ServiceAccount{{
+					ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+				}},
+			}
+			testAssets, cancel := getTaskRunController(t, d)
+			defer cancel()
+			clients := testAssets.Clients
+
+			if err := testAssets.Controller.Reconciler.Reconcile(testAssets.Ctx, getRunName(taskRun)); err != nil {
+				t.Fatalf("Unexpected error when Reconcile() : %v", err)
+			}
+			newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(testAssets.Ctx, taskRun.Name, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+			}
+			wantPodNames := append(append([]string{}, podNames[:tc.lostPod]...), newTr.Status.PodName)
+			if d := cmp.Diff(wantPodNames, newTr.Status.PodNames); d != "" {
+				t.Errorf("Expected the lost pod to be replaced in the pod names %s", diff.PrintWantGot(d))
+			}
+			if newTr.Status.PodName == podNames[tc.lostPod] {
+				t.Errorf("Expected a new pod to replace %q", podNames[tc.lostPod])
+			}
+			pod, err := clients.Kube.CoreV1().Pods(taskRun.Namespace).Get(testAssets.Ctx, newTr.Status.PodName, metav1.GetOptions{})
+			if err != nil {
+				t.Fatalf("Expected the recreated Pod of the TaskRun to exist: %v", err)
+			}
+			if d := cmp.Diff(tc.wantContainers, stepContainerNames(pod)); d != "" {
+				t.Errorf("Unexpected containers of the recreated pod %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestReconcileTraceContext(t *testing.T) {
+	taskRun := tb.TaskRun("test-taskrun-trace", tb.TaskRunNamespace("foo"), tb.TaskRunSpec(tb.TaskRunTaskRef(simpleTask.Name)))
+	d := test.Data{
+		TaskRuns: []*v1beta1.TaskRun{taskRun},
+		Tasks:    []*v1beta1.Task{simpleTask},
+		ServiceAccounts: []*corev1.ServiceAccount{{
+			ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: "foo"},
+		}},
+	}
+	testAssets, cancel := getTaskRunController(t, d)
+	defer cancel()
+	clients := testAssets.Clients
+
+	// Sample the spans once the controller has read the tracing configuration.
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	defer trace.ApplyConfig(trace.Config{DefaultSampler: trace.NeverSample()})
+
+	if err := testAssets.Controller.Reconciler.Reconcile(testAssets.Ctx, getRunName(taskRun)); err != nil {
+		t.Fatalf("Unexpected error when Reconcile() : %v", err)
+	}
+	newTr, err := clients.Pipeline.TektonV1beta1().TaskRuns(taskRun.Namespace).Get(testAssets.Ctx, taskRun.Name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected TaskRun %s to exist but instead got error when getting it: %v", taskRun.Name, err)
+	}
+	taskRunParent, ok := tracing.SpanContextFromTraceParent(newTr.Annotations[tracing.TraceParentAnnotation])
+	if !ok {
+		t.Fatalf("Expected the TaskRun to be annotated with its trace context, got annotations %v", newTr.Annotations)
+	}
+	pod, err := clients.Kube.CoreV1().Pods(taskRun.Namespace).Get(testAssets.Ctx, newTr.Status.PodName, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("Expected the Pod of the TaskRun to exist: %v", err)
+	}
+	for _, c := range pod.Spec.Containers {
+		var traceParent string
+		for _, e := range c.Env {
+			if e.Name == tracing.TraceParentEnvVar {
+				traceParent = e.Value
+			}
+		}
+		sc, ok := tracing.SpanContextFromTraceParent(traceParent)
+		if !ok || sc.TraceID != taskRunParent.TraceID {
+			t.Errorf("Expected step %q to join trace %s, got %s=%q", c.Name, taskRunParent.TraceID, tracing.TraceParentEnvVar, traceParent)
+		}
+	}
+}
+
 func Test_storeTaskSpec(t *testing.T) {
 
 	ctx := context.Background()
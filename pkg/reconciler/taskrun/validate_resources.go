@@ -128,3 +128,64 @@ func ValidateResolvedTaskResources(params []v1beta1.Param, rtr *resources.Resolv
 
 	return nil
 }
+
+// ValidateStepOverrides makes sure the Steps overridden by a TaskRun are Steps
+// of its Task.
+func ValidateStepOverrides(ts *v1beta1.TaskSpec, stepOverrides []v1beta1.TaskRunStepOverride) error {
+	stepNames := make([]string, 0, len(ts.Steps)+len(ts.OnFailure))
+	for _, s := range ts.Steps {
+		stepNames = append(stepNames, s.Name)
+	}
+	for _, s := range ts.OnFailure {
+		stepNames = append(stepNames, s.Name)
+	}
+	overriddenNames := make([]string, 0, len(stepOverrides))
+	for _, o := range stepOverrides {
+		overriddenNames = append(overriddenNames, o.Name)
+	}
+	if unknownSteps := list.DiffLeft(overriddenNames, stepNames); len(unknownSteps) != 0 {
+		return fmt.Errorf("invalid step overrides, these steps are not part of the task: %s", unknownSteps)
+	}
+	return nil
+}
+
+// ValidateSidecarOverrides makes sure the Sidecars overridden by a TaskRun
+// are Sidecars of its Task.
+func ValidateSidecarOverrides(ts *v1beta1.TaskSpec, sidecarOverrides []v1beta1.TaskRunSidecarOverride) error {
+	sidecarNames := make([]string, 0, len(ts.Sidecars))
+	for _, s := range ts.Sidecars {
+		sidecarNames = append(sidecarNames, s.Name)
+	}
+	overriddenNames := make([]string, 0, len(sidecarOverrides))
+	for _, o := range sidecarOverrides {
+		overriddenNames = append(overriddenNames, o.Name)
+	}
+	if unknownSidecars := list.DiffLeft(overriddenNames, sidecarNames); len(unknownSidecars) != 0 {
+		return fmt.Errorf("invalid sidecar overrides, these sidecars are not part of the task: %s", unknownSidecars)
+	}
+	return nil
+}
+
+// ValidateStepPods makes sure the Steps running in their own Pod are Steps of
+// the Task. The Steps added for PipelineResources would not know which Pod to
+// run in, so Tasks using PipelineResources cannot split their Steps.
+func ValidateStepPods(ts *v1beta1.TaskSpec, stepPods []v1beta1.TaskRunStepPod) error {
+	if len(stepPods) == 0 {
+		return nil
+	}
+	if ts.Resources != nil && (len(ts.Resources.Inputs) > 0 || len(ts.Resources.Outputs) > 0) {
+		return fmt.Errorf("steps cannot run in their own pod in a task using resources")
+	}
+	stepNames := make([]string, 0, len(ts.Steps))
+	for _, s := range ts.Steps {
+		stepNames = append(stepNames, s.Name)
+	}
+	podStepNames := make([]string, 0, len(stepPods))
+	for _, sp := range stepPods {
+		podStepNames = append(podStepNames, sp.Name)
+	}
+	if unknownSteps := list.DiffLeft(podStepNames, stepNames); len(unknownSteps) != 0 {
+		return fmt.Errorf("invalid step pods, these steps are not part of the task: %s", unknownSteps)
+	}
+	return nil
+}
@@ -24,6 +24,7 @@ import (
 	"github.com/tektoncd/pipeline/pkg/apis/pipeline/v1beta1"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun"
 	"github.com/tektoncd/pipeline/pkg/reconciler/taskrun/resources"
+	corev1 "k8s.io/api/core/v1"
 )
 
 func TestValidateResolvedTaskResources_ValidResources(t *testing.T) {
@@ -251,3 +252,74 @@ func TestValidateResolvedTaskResources_InvalidResources(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateStepOverrides(t *testing.T) {
+	ts := &v1beta1.TaskSpec{
+		Steps: []v1beta1.Step{{
+			Container: corev1.Container{Name: "build"},
+		}},
+		OnFailure: []v1beta1.Step{{
+			Container: corev1.Container{Name: "collect"},
+		}},
+	}
+	for _, tc := range []struct {
+		name          string
+		stepOverrides []v1beta1.TaskRunStepOverride
+		wantErr       bool
+	}{{
+		name: "no overrides",
+	}, {
+		name: "steps of the task",
+		stepOverrides: []v1beta1.TaskRunStepOverride{{
+			Name: "build",
+		}, {
+			Name: "collect",
+		}},
+	}, {
+		name: "step not in the task",
+		stepOverrides: []v1beta1.TaskRunStepOverride{{
+			Name: "test",
+		}},
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := taskrun.ValidateStepOverrides(ts, tc.stepOverrides)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ValidateStepOverrides() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSidecarOverrides(t *testing.T) {
+	ts := &v1beta1.TaskSpec{
+		Sidecars: []v1beta1.Sidecar{{
+			Container: corev1.Container{Name: "registry"},
+		}},
+	}
+	for _, tc := range []struct {
+		name             string
+		sidecarOverrides []v1beta1.TaskRunSidecarOverride
+		wantErr          bool
+	}{{
+		name: "no overrides",
+	}, {
+		name: "sidecars of the task",
+		sidecarOverrides: []v1beta1.TaskRunSidecarOverride{{
+			Name: "registry",
+		}},
+	}, {
+		name: "sidecar not in the task",
+		sidecarOverrides: []v1beta1.TaskRunSidecarOverride{{
+			Name: "docker",
+		}},
+		wantErr: true,
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			err := taskrun.ValidateSidecarOverrides(ts, tc.sidecarOverrides)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("ValidateSidecarOverrides() error = %v, wantErr %t", err, tc.wantErr)
+			}
+		})
+	}
+}
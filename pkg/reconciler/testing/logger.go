@@ -17,6 +17,7 @@ func SetupFakeContext(t *testing.T) (context.Context, []controller.Informer) {
 		SendSuccessfully: true,
 	}
 	ctx = cloudevent.WithClient(ctx, &cloudEventClientBehaviour)
+	ctx = cloudevent.WithDeliveryTracker(ctx, cloudevent.NewDeliveryTracker())
 	return WithLogger(ctx, t), informer
 }
 
@@ -130,7 +130,8 @@ func (t *Handler) getOrCreateDoneChan(n types.NamespacedName) chan bool {
 // may be active at any moment. Requests for a new backoff in the face of an
 // existing one will be ignored and details of the existing backoff will be returned
 // instead. Further, if a calculated backoff time is after the timeout of the runKey
-// then the time of the timeout will be returned instead.
+// then the time of the timeout will be returned instead. A zero timeout means
+// that the backoff is not bounded by any timeout.
 //
 // Returned values are a backoff struct containing a NumAttempts field with the
 // number of attempts performed for this n and a NextAttempt field
@@ -146,9 +147,11 @@ func (t *Handler) GetBackoff(n types.NamespacedName, startTime metav1.Time, time
 	}
 	b.NumAttempts++
 	b.NextAttempt = time.Now().Add(backoffDuration(b.NumAttempts, rand.Intn))
-	timeoutDeadline := startTime.Time.Add(timeout.Duration)
-	if timeoutDeadline.Before(b.NextAttempt) {
-		b.NextAttempt = timeoutDeadline
+	if timeout.Duration > 0 {
+		timeoutDeadline := startTime.Time.Add(timeout.Duration)
+		if timeoutDeadline.Before(b.NextAttempt) {
+			b.NextAttempt = timeoutDeadline
+		}
 	}
 	t.backoffs[n.String()] = b
 	return b, false
@@ -178,11 +181,12 @@ func (t *Handler) checkPipelineRunTimeouts(ctx context.Context, namespace string
 	for _, pipelineRun := range pipelineRuns.Items {
 		pipelineRun := pipelineRun
 		pipelineRun.SetDefaults(contexts.WithUpgradeViaDefaulting(ctx))
-		if pipelineRun.IsDone() || pipelineRun.IsCancelled() {
+		// The timeout of a paused pipelinerun is tracked again once it is resumed
+		if pipelineRun.IsDone() || pipelineRun.IsCancelled() || pipelineRun.IsPaused() {
 			continue
 		}
 		if pipelineRun.HasStarted() {
-			go t.Wait(pipelineRun.GetNamespacedName(), *pipelineRun.Status.StartTime, *pipelineRun.Spec.Timeout)
+			go t.Wait(pipelineRun.GetNamespacedName(), pipelineRun.GetTimeoutStartTime(), *pipelineRun.Spec.Timeout)
 		}
 	}
 }
@@ -461,3 +461,43 @@ func TestBackoffDuration(t *testing.T) {
 		})
 	}
 }
+
+// TestGetBackoff asserts that the backoff deadline is capped by the timeout,
+// unless the timeout is zero.
+func TestGetBackoff(t *testing.T) {
+	testcases := []struct {
+		description  string
+		startTime    metav1.Time
+		timeout      time.Duration
+		expectCapped bool
+	}{{
+		description:  "the backoff is capped by an expiring timeout",
+		startTime:    metav1.NewTime(time.Now().Add(-time.Minute)),
+		timeout:      time.Minute,
+		expectCapped: true,
+	}, {
+		description:  "the backoff is not capped by a zero timeout",
+		startTime:    metav1.NewTime(time.Now().Add(-time.Minute)),
+		timeout:      0,
+		expectCapped: false,
+	}}
+	for _, tc := range testcases {
+		t.Run(tc.description, func(t *testing.T) {
+			stopCh := make(chan struct{})
+			defer close(stopCh)
+			th := NewHandler(stopCh, zap.NewNop().Sugar())
+			n := types.NamespacedName{Namespace: testNs, Name: "test-backoff"}
+			backoff, inProgress := th.GetBackoff(n, tc.startTime, metav1.Duration{Duration: tc.timeout})
+			if inProgress {
+				t.Errorf("expected no backoff to be in progress")
+			}
+			if backoff.NumAttempts != 1 {
+				t.Errorf("expected 1 attempt, got %d", backoff.NumAttempts)
+			}
+			deadline := tc.startTime.Add(tc.timeout)
+			if capped := backoff.NextAttempt.Equal(deadline); capped != tc.expectCapped {
+				t.Errorf("expected the next attempt %s to be capped at %s: %t", backoff.NextAttempt, deadline, tc.expectCapped)
+			}
+		})
+	}
+}
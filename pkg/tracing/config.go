@@ -0,0 +1,90 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	backendKey    = "tracing.backend"
+	endpointKey   = "tracing.endpoint"
+	sampleRateKey = "tracing.sample-rate"
+
+	// BackendNone disables tracing.
+	BackendNone = "none"
+	// BackendOpenCensus exports the spans to an OpenCensus agent or to an
+	// OpenTelemetry collector with an OpenCensus receiver.
+	BackendOpenCensus = "opencensus"
+
+	// DefaultBackend is the tracing backend used when none is configured.
+	DefaultBackend = BackendNone
+	// DefaultSampleRate is the fraction of the traces exported when no sample
+	// rate is configured.
+	DefaultSampleRate = 1.0
+)
+
+// Config holds the tracing configuration, read from the config-observability
+// ConfigMap.
+type Config struct {
+	Backend    string
+	Endpoint   string
+	SampleRate float64
+}
+
+// NewConfigFromMap returns a Config given a map corresponding to a ConfigMap
+func NewConfigFromMap(cfgMap map[string]string) (*Config, error) {
+	tc := Config{
+		Backend:    DefaultBackend,
+		SampleRate: DefaultSampleRate,
+	}
+
+	if backend, ok := cfgMap[backendKey]; ok {
+		switch backend {
+		case BackendNone, BackendOpenCensus:
+			tc.Backend = backend
+		default:
+			return nil, fmt.Errorf("unsupported tracing backend %q, must be one of %q or %q", backend, BackendNone, BackendOpenCensus)
+		}
+	}
+
+	tc.Endpoint = cfgMap[endpointKey]
+	if tc.Backend == BackendOpenCensus && tc.Endpoint == "" {
+		return nil, fmt.Errorf("%q must be set for the %q tracing backend", endpointKey, BackendOpenCensus)
+	}
+
+	if rate, ok := cfgMap[sampleRateKey]; ok {
+		value, err := strconv.ParseFloat(rate, 64)
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing tracing config %q: %v", sampleRateKey, err)
+		}
+		if value < 0 || value > 1 {
+			return nil, fmt.Errorf("%q must be between 0 and 1, got %v", sampleRateKey, value)
+		}
+		tc.SampleRate = value
+	}
+
+	return &tc, nil
+}
+
+// NewConfigFromConfigMap returns a Config for the given configmap
+func NewConfigFromConfigMap(config *corev1.ConfigMap) (*Config, error) {
+	return NewConfigFromMap(config.Data)
+}
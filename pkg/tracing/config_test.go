@@ -0,0 +1,86 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/tektoncd/pipeline/pkg/tracing"
+	"github.com/tektoncd/pipeline/test/diff"
+)
+
+func TestNewConfigFromMap(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data map[string]string
+		want *tracing.Config
+	}{{
+		name: "empty",
+		data: map[string]string{},
+		want: &tracing.Config{Backend: tracing.BackendNone, SampleRate: 1},
+	}, {
+		name: "opencensus",
+		data: map[string]string{
+			"tracing.backend":     "opencensus",
+			"tracing.endpoint":    "otel-collector.observability:55678",
+			"tracing.sample-rate": "0.25",
+		},
+		want: &tracing.Config{Backend: tracing.BackendOpenCensus, Endpoint: "otel-collector.observability:55678", SampleRate: 0.25},
+	}, {
+		name: "metrics keys are ignored",
+		data: map[string]string{
+			"metrics.backend-destination": "prometheus",
+		},
+		want: &tracing.Config{Backend: tracing.BackendNone, SampleRate: 1},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tracing.NewConfigFromMap(tc.data)
+			if err != nil {
+				t.Fatalf("NewConfigFromMap() = %v", err)
+			}
+			if d := cmp.Diff(tc.want, got); d != "" {
+				t.Errorf("Unexpected config %s", diff.PrintWantGot(d))
+			}
+		})
+	}
+}
+
+func TestNewConfigFromMap_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		data map[string]string
+	}{{
+		name: "unknown backend",
+		data: map[string]string{"tracing.backend": "zipkin"},
+	}, {
+		name: "opencensus without endpoint",
+		data: map[string]string{"tracing.backend": "opencensus"},
+	}, {
+		name: "sample rate not a number",
+		data: map[string]string{"tracing.sample-rate": "all"},
+	}, {
+		name: "sample rate out of range",
+		data: map[string]string{"tracing.sample-rate": "1.5"},
+	}} {
+		t.Run(tc.name, func(t *testing.T) {
+			if _, err := tracing.NewConfigFromMap(tc.data); err == nil {
+				t.Error("Expected an error, got nil")
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"sync"
+
+	"contrib.go.opencensus.io/exporter/ocagent"
+	"go.opencensus.io/trace"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"knative.dev/pkg/configmap"
+	"knative.dev/pkg/metrics"
+	"knative.dev/pkg/system"
+)
+
+const serviceName = "tekton-pipelines-controller"
+
+var (
+	exporterMu     sync.Mutex
+	exporter       *ocagent.Exporter
+	exporterConfig *Config
+)
+
+// WatchConfig sets up the exporter and the sampler of the spans of the
+// controller from the config-observability ConfigMap, and updates them when
+// it changes. Tracing stays disabled if the ConfigMap does not exist.
+func WatchConfig(cmw configmap.Watcher, logger *zap.SugaredLogger) {
+	observer := func(cm *corev1.ConfigMap) {
+		cfg, err := NewConfigFromConfigMap(cm)
+		if err != nil {
+			logger.Errorf("Failed to read the tracing configuration from %q: %v", cm.Name, err)
+			return
+		}
+		if err := setupExporter(cfg); err != nil {
+			logger.Errorf("Failed to set up the %q tracing exporter: %v", cfg.Backend, err)
+		}
+	}
+	if dw, ok := cmw.(configmap.DefaultingWatcher); ok {
+		dw.WatchWithDefault(corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: metrics.ConfigMapName(), Namespace: system.Namespace()},
+		}, observer)
+		return
+	}
+	cmw.Watch(metrics.ConfigMapName(), observer)
+}
+
+// setupExporter replaces the exporter and the sampler of the spans with the
+// ones of the given configuration. Both the PipelineRun and the TaskRun
+// controllers watch the configuration, so nothing is done when it did not
+// change.
+func setupExporter(cfg *Config) error {
+	exporterMu.Lock()
+	defer exporterMu.Unlock()
+
+	if exporterConfig != nil && *exporterConfig == *cfg {
+		return nil
+	}
+	if exporter != nil {
+		trace.UnregisterExporter(exporter)
+		if err := exporter.Stop(); err != nil {
+			return err
+		}
+		exporter = nil
+	}
+
+	if cfg.Backend != BackendOpenCensus {
+		trace.ApplyConfig(trace.Config{DefaultSampler: trace.NeverSample()})
+		exporterConfig = cfg
+		return nil
+	}
+	e, err := ocagent.NewExporter(
+		ocagent.WithAddress(cfg.Endpoint),
+		ocagent.WithInsecure(),
+		ocagent.WithServiceName(serviceName),
+	)
+	if err != nil {
+		return err
+	}
+	exporter = e
+	trace.RegisterExporter(exporter)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.ProbabilitySampler(cfg.SampleRate)})
+	exporterConfig = cfg
+	return nil
+}
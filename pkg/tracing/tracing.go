@@ -0,0 +1,124 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	"github.com/tektoncd/pipeline/pkg/apis/pipeline"
+	"go.opencensus.io/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// TraceParentAnnotation holds the W3C trace context of the span a
+	// PipelineRun or a TaskRun belongs to. The spans of the reconciles of the
+	// run, and of the TaskRuns it creates, are children of that span.
+	TraceParentAnnotation = pipeline.GroupName + "/traceparent"
+
+	// TraceParentEnvVar is the environment variable holding the W3C trace
+	// context of the Pod creation span, set on the Steps so that they can
+	// join the trace of their TaskRun.
+	TraceParentEnvVar = "TRACEPARENT"
+
+	traceParentVersion = "00"
+)
+
+func init() {
+	// Nothing is sampled until a tracing backend is configured.
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.NeverSample()})
+}
+
+// StartRunSpan starts the span of a reconcile of the given PipelineRun or
+// TaskRun. The span is a child of the span recorded in the
+// TraceParentAnnotation of the run. When the run has no such annotation, it
+// is set to the new span, if sampled, so that the next reconciles join its
+// trace.
+func StartRunSpan(ctx context.Context, name string, run metav1.Object) (context.Context, *trace.Span) {
+	var span *trace.Span
+	annotations := run.GetAnnotations()
+	if parent, ok := SpanContextFromTraceParent(annotations[TraceParentAnnotation]); ok {
+		ctx, span = trace.StartSpanWithRemoteParent(ctx, name, parent)
+	} else {
+		ctx, span = trace.StartSpan(ctx, name)
+		if traceParent := TraceParent(ctx); traceParent != "" {
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			annotations[TraceParentAnnotation] = traceParent
+			run.SetAnnotations(annotations)
+		}
+	}
+	span.AddAttributes(
+		trace.StringAttribute("namespace", run.GetNamespace()),
+		trace.StringAttribute("name", run.GetName()),
+	)
+	return ctx, span
+}
+
+// SetError marks the span as failed with the given error, if any.
+func SetError(span *trace.Span, err error) {
+	if err != nil {
+		span.SetStatus(trace.Status{Code: trace.StatusCodeUnknown, Message: err.Error()})
+	}
+}
+
+// TraceParent returns the W3C trace context of the span of the context, or
+// an empty string if there is no span or if it is not sampled.
+func TraceParent(ctx context.Context) string {
+	span := trace.FromContext(ctx)
+	if span == nil {
+		return ""
+	}
+	sc := span.SpanContext()
+	if !sc.IsSampled() {
+		return ""
+	}
+	return fmt.Sprintf("%s-%s-%s-%02x", traceParentVersion, sc.TraceID, sc.SpanID, uint8(sc.TraceOptions))
+}
+
+// SpanContextFromTraceParent parses a W3C trace context, as returned by
+// TraceParent. It returns false if the trace context is not valid.
+func SpanContextFromTraceParent(traceParent string) (trace.SpanContext, bool) {
+	sc := trace.SpanContext{}
+	parts := strings.Split(traceParent, "-")
+	if len(parts) != 4 || parts[0] != traceParentVersion {
+		return sc, false
+	}
+	traceID, err := hex.DecodeString(parts[1])
+	if err != nil || len(traceID) != len(sc.TraceID) {
+		return sc, false
+	}
+	spanID, err := hex.DecodeString(parts[2])
+	if err != nil || len(spanID) != len(sc.SpanID) {
+		return sc, false
+	}
+	options, err := hex.DecodeString(parts[3])
+	if err != nil || len(options) != 1 {
+		return sc, false
+	}
+	copy(sc.TraceID[:], traceID)
+	copy(sc.SpanID[:], spanID)
+	if sc.TraceID == (trace.TraceID{}) || sc.SpanID == (trace.SpanID{}) {
+		return trace.SpanContext{}, false
+	}
+	sc.TraceOptions = trace.TraceOptions(options[0])
+	return sc, true
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2020 The Tekton Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tracing_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/tektoncd/pipeline/pkg/tracing"
+	"go.opencensus.io/trace"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// spanRecorder is an in-memory exporter keeping the spans that ended.
+type spanRecorder struct {
+	mu    sync.Mutex
+	spans []*trace.SpanData
+}
+
+func (r *spanRecorder) ExportSpan(s *trace.SpanData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.spans = append(r.spans, s)
+}
+
+// recordSpans samples and records all the spans until the returned function
+// is called.
+func recordSpans() (*spanRecorder, func()) {
+	r := &spanRecorder{}
+	trace.RegisterExporter(r)
+	trace.ApplyConfig(trace.Config{DefaultSampler: trace.AlwaysSample()})
+	return r, func() {
+		trace.UnregisterExporter(r)
+		trace.ApplyConfig(trace.Config{DefaultSampler: trace.NeverSample()})
+	}
+}
+
+func TestTraceParent(t *testing.T) {
+	_, stop := recordSpans()
+	defer stop()
+
+	ctx, span := trace.StartSpan(context.Background(), "test")
+	defer span.End()
+
+	traceParent := tracing.TraceParent(ctx)
+	sc, ok := tracing.SpanContextFromTraceParent(traceParent)
+	if !ok {
+		t.Fatalf("Expected %q to be a valid trace context", traceParent)
+	}
+	if sc != span.SpanContext() {
+		t.Errorf("Expected the span context %v, got %v", span.SpanContext(), sc)
+	}
+}
+
+func TestTraceParent_NotSampled(t *testing.T) {
+	if got := tracing.TraceParent(context.Background()); got != "" {
+		t.Errorf("Expected no trace context without a span, got %q", got)
+	}
+	ctx, span := trace.StartSpan(context.Background(), "test")
+	defer span.End()
+	if got := tracing.TraceParent(ctx); got != "" {
+		t.Errorf("Expected no trace context for a span that is not sampled, got %q", got)
+	}
+}
+
+func TestSpanContextFromTraceParent(t *testing.T) {
+	sc, ok := tracing.SpanContextFromTraceParent("00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01")
+	if !ok {
+		t.Fatal("Expected the trace context to be valid")
+	}
+	if got := sc.TraceID.String(); got != "0af7651916cd43dd8448eb211c80319c" {
+		t.Errorf("Unexpected trace ID %s", got)
+	}
+	if got := sc.SpanID.String(); got != "b7ad6b7169203331" {
+		t.Errorf("Unexpected span ID %s", got)
+	}
+	if !sc.IsSampled() {
+		t.Error("Expected the span context to be sampled")
+	}
+}
+
+func TestSpanContextFromTraceParent_Invalid(t *testing.T) {
+	for _, traceParent := range []string{
+		"",
+		"not-a-trace-context",
+		"01-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01",
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331",
+		"00-0af7651916cd43dd-b7ad6b7169203331-01",
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b71-01",
+		"00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-zz",
+		"00-00000000000000000000000000000000-b7ad6b7169203331-01",
+		"00-0af7651916cd43dd8448eb211c80319c-0000000000000000-01",
+	} {
+		if _, ok := tracing.SpanContextFromTraceParent(traceParent); ok {
+			t.Errorf("Expected %q to be an invalid trace context", traceParent)
+		}
+	}
+}
+
+func TestStartRunSpan(t *testing.T) {
+	recorder, stop := recordSpans()
+	defer stop()
+
+	run := &metav1.ObjectMeta{Name: "run", Namespace: "foo"}
+	_, first := tracing.StartRunSpan(context.Background(), "Run.Reconcile", run)
+	first.End()
+	traceParent := run.Annotations[tracing.TraceParentAnnotation]
+	if sc, ok := tracing.SpanContextFromTraceParent(traceParent); !ok || sc != first.SpanContext() {
+		t.Fatalf("Expected the run to be annotated with the span of its first reconcile, got %q", traceParent)
+	}
+
+	_, second := tracing.StartRunSpan(context.Background(), "Run.Reconcile", run)
+	second.End()
+	if got := run.Annotations[tracing.TraceParentAnnotation]; got != traceParent {
+		t.Errorf("Expected the annotation of the run to stay %q, got %q", traceParent, got)
+	}
+
+	if len(recorder.spans) != 2 {
+		t.Fatalf("Expected 2 spans, got %d", len(recorder.spans))
+	}
+	if got := recorder.spans[1]; got.TraceID != first.SpanContext().TraceID || got.ParentSpanID != first.SpanContext().SpanID {
+		t.Errorf("Expected the span of the second reconcile to be a child of the first one, got parent %s in trace %s", got.ParentSpanID, got.TraceID)
+	}
+	if got := recorder.spans[1].Attributes["name"]; got != "run" {
+		t.Errorf("Expected the span to have the name of the run, got %v", got)
+	}
+}
+
+func TestStartRunSpan_NotSampled(t *testing.T) {
+	run := &metav1.ObjectMeta{Name: "run", Namespace: "foo"}
+	_, span := tracing.StartRunSpan(context.Background(), "Run.Reconcile", run)
+	span.End()
+	if run.Annotations != nil {
+		t.Errorf("Expected no annotation when the span is not sampled, got %v", run.Annotations)
+	}
+}
@@ -53,6 +53,7 @@ import (
 	"k8s.io/client-go/tools/record"
 	fakekubeclient "knative.dev/pkg/client/injection/kube/client/fake"
 	fakeconfigmapinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/configmap/fake"
+	fakenamespaceinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/namespace/fake"
 	fakepodinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/pod/fake"
 	fakeserviceaccountinformer "knative.dev/pkg/client/injection/kube/informers/core/v1/serviceaccount/fake"
 	"knative.dev/pkg/controller"
@@ -66,6 +67,7 @@ type Data struct {
 	TaskRuns          []*v1beta1.TaskRun
 	Tasks             []*v1beta1.Task
 	ClusterTasks      []*v1beta1.ClusterTask
+	StepActions       []*v1beta1.StepAction
 	PipelineResources []*v1alpha1.PipelineResource
 	Conditions        []*v1alpha1.Condition
 	Pods              []*corev1.Pod
@@ -94,6 +96,7 @@ type Informers struct {
 	Pod              coreinformers.PodInformer
 	ConfigMap        coreinformers.ConfigMapInformer
 	ServiceAccount   coreinformers.ServiceAccountInformer
+	Namespace        coreinformers.NamespaceInformer
 }
 
 // Assets holds references to the controller, logs, clients, and informers.
@@ -173,6 +176,7 @@ func SeedTestData(t *testing.T, ctx context.Context, d Data) (Clients, Informers
 		Pod:              fakepodinformer.Get(ctx),
 		ConfigMap:        fakeconfigmapinformer.Get(ctx),
 		ServiceAccount:   fakeserviceaccountinformer.Get(ctx),
+		Namespace:        fakenamespaceinformer.Get(ctx),
 	}
 
 	// Attach reactors that add resource mutations to the appropriate
@@ -213,6 +217,12 @@ func SeedTestData(t *testing.T, ctx context.Context, d Data) (Clients, Informers
 			t.Fatal(err)
 		}
 	}
+	for _, sa := range d.StepActions {
+		sa := sa.DeepCopy() // Avoid assumptions that the informer's copy is modified.
+		if _, err := c.Pipeline.TektonV1beta1().StepActions(sa.Namespace).Create(ctx, sa, metav1.CreateOptions{}); err != nil {
+			t.Fatal(err)
+		}
+	}
 	c.Resource.PrependReactor("*", "pipelineresources", AddToInformer(t, i.PipelineResource.Informer().GetIndexer()))
 	for _, r := range d.PipelineResources {
 		r := r.DeepCopy() // Avoid assumptions that the informer's copy is modified.
@@ -234,6 +244,7 @@ func SeedTestData(t *testing.T, ctx context.Context, d Data) (Clients, Informers
 			t.Fatal(err)
 		}
 	}
+	c.Kube.PrependReactor("*", "namespaces", AddToInformer(t, i.Namespace.Informer().GetIndexer()))
 	for _, n := range d.Namespaces {
 		n := n.DeepCopy() // Avoid assumptions that the informer's copy is modified.
 		if _, err := c.Kube.CoreV1().Namespaces().Create(ctx, n, metav1.CreateOptions{}); err != nil {